@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -19,7 +20,9 @@ import (
 	"github.com/gocronx-team/gocron/internal/modules/i18n"
 	"github.com/gocronx-team/gocron/internal/modules/leader"
 	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/notify"
 	"github.com/gocronx-team/gocron/internal/modules/setting"
+	"github.com/gocronx-team/gocron/internal/modules/sqlrunner"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 	"github.com/gocronx-team/gocron/internal/routers"
 	"github.com/gocronx-team/gocron/internal/service"
@@ -86,7 +89,7 @@ func getCommands() []*cli.Command {
 		},
 	}
 
-	return []*cli.Command{command, resetPasswordCommand(), taskCommand(), statsCommand()}
+	return []*cli.Command{command, resetPasswordCommand(), taskCommand(), statsCommand(), templateCommand()}
 }
 
 func runWeb(ctx *cli.Context) error {
@@ -152,6 +155,9 @@ func initModule() {
 	// 设置服务端默认语言（影响调度器/RPC 等无请求上下文场景的消息语言）
 	i18n.SetDefaultLocale(i18n.ParseLocale(config.Lang))
 
+	// Key for credentials stored encrypted in the database (SQL data sources)
+	models.SetCredentialKey(config.SecretKey)
+
 	// Initialize DB
 	models.Db = models.CreateDb()
 
@@ -161,11 +167,32 @@ func initModule() {
 	// Auto-create missing tables
 	ensureTables()
 
+	// Internal CA: issue node certificates and use them for agent gRPC mTLS
+	if config.InternalCA {
+		initInternalCA(config)
+	}
+
+	// Load template bundles shipped alongside the config
+	if config.TemplateDir != "" {
+		loadTemplateDir(config)
+	}
+
+	// SQL tasks may only open SQLite files under sqlite_dir
+	if config.SqliteDir != "" {
+		sqlrunner.SqliteDir = config.SqliteDir
+		if !filepath.IsAbs(sqlrunner.SqliteDir) {
+			sqlrunner.SqliteDir = filepath.Join(app.ConfDir, sqlrunner.SqliteDir)
+		}
+	}
+
 	// Repair missing settings records
 	if err := models.RepairSettings(); err != nil {
 		logger.Error("Failed to repair settings records", err)
 	}
 
+	// Start notification outbox workers (every node delivers, claims are exclusive)
+	notify.Start(config.NotifyWorkers)
+
 	// Initialize scheduler infrastructure
 	service.ServiceTask.Initialize()
 
@@ -193,6 +220,36 @@ func initModule() {
 	leaderElection.Start()
 }
 
+// initInternalCA loads or creates the internal CA shared through the database
+// and points the TLS settings at the files it writes under the config directory
+func initInternalCA(config *setting.Setting) {
+	if err := service.CertManager.Init(filepath.Join(app.ConfDir, "ca")); err != nil {
+		logger.Fatal("Failed to initialize internal CA", err)
+	}
+	paths := service.CertManager.Paths()
+	config.CAFile = paths.CAFile
+	config.CertFile = paths.CertFile
+	config.KeyFile = paths.KeyFile
+	config.CRLFile = paths.CRLFile
+	logger.Infof("Internal CA enabled, certificates stored in %s", filepath.Dir(paths.CAFile))
+}
+
+// loadTemplateDir imports the template bundles found in template_dir in
+// addition to the builtin templates seeded during install/upgrade
+func loadTemplateDir(config *setting.Setting) {
+	dir := config.TemplateDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(app.ConfDir, dir)
+	}
+	// rename would add another copy of every template on each restart
+	mode := config.TemplateDirConflict
+	if mode != models.TemplateConflictSkip && mode != models.TemplateConflictOverwrite {
+		logger.Warnf("Invalid template_dir_conflict %q, falling back to skip", mode)
+		mode = models.TemplateConflictSkip
+	}
+	models.LoadTemplateDir(dir, mode)
+}
+
 // parsePort parses the port from CLI flags
 func parsePort(ctx *cli.Context) int {
 	port := DefaultPort
@@ -357,4 +414,46 @@ func ensureTables() {
 	if err := models.Db.AutoMigrate(&models.TaskTemplate{}); err != nil {
 		logger.Error("Failed to migrate task_template table", err)
 	}
+	if err := models.Db.AutoMigrate(&models.HostCertificate{}); err != nil {
+		logger.Error("Failed to migrate host_certificate table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.InternalCA{}); err != nil {
+		logger.Error("Failed to migrate internal_ca table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.TaskChangeRequest{}); err != nil {
+		logger.Error("Failed to migrate task_change_request table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.TaskNotification{}); err != nil {
+		logger.Error("Failed to migrate task_notification table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.NotificationOutbox{}, &models.NotificationAttempt{}); err != nil {
+		logger.Error("Failed to migrate notification_outbox table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.TaskIncident{}); err != nil {
+		logger.Error("Failed to migrate task_incident table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.TaskSlaBreach{}); err != nil {
+		logger.Error("Failed to migrate task_sla_breach table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.TaskHeartbeatCheck{}); err != nil {
+		logger.Error("Failed to migrate task_heartbeat_check table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.Secret{}); err != nil {
+		logger.Error("Failed to migrate secret table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.HttpProfile{}); err != nil {
+		logger.Error("Failed to migrate http_profile table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.TaskCallback{}); err != nil {
+		logger.Error("Failed to migrate task_callback table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.DataSource{}); err != nil {
+		logger.Error("Failed to migrate data_source table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.GrpcDescriptor{}); err != nil {
+		logger.Error("Failed to migrate grpc_descriptor table", err)
+	}
+	if err := models.Db.AutoMigrate(&models.TaskArtifact{}); err != nil {
+		logger.Error("Failed to migrate task_artifact table", err)
+	}
 }
@@ -239,6 +239,14 @@ func protocolLabel(p models.TaskProtocol) string {
 		return "HTTP"
 	case models.TaskRPC:
 		return "RPC"
+	case models.TaskHeartbeat:
+		return "Heartbeat"
+	case models.TaskSSH:
+		return "SSH"
+	case models.TaskSQL:
+		return "SQL"
+	case models.TaskGRPC:
+		return "gRPC"
 	default:
 		return strconv.Itoa(int(p))
 	}
@@ -150,7 +150,8 @@ func TestLabels(t *testing.T) {
 	if taskStatusLabel(models.Enabled) != "enabled" || taskStatusLabel(models.Disabled) != "disabled" {
 		t.Error("task status label wrong")
 	}
-	if protocolLabel(models.TaskHTTP) != "HTTP" || protocolLabel(models.TaskRPC) != "RPC" {
+	if protocolLabel(models.TaskHTTP) != "HTTP" || protocolLabel(models.TaskRPC) != "RPC" ||
+		protocolLabel(models.TaskHeartbeat) != "Heartbeat" {
 		t.Error("protocol label wrong")
 	}
 	cases := map[models.Status]string{
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/urfave/cli/v2"
+)
+
+// templateCommand 导出/导入模板包，用于在多个 gocron 实例之间共享模板。
+func templateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "template",
+		Usage: "export / import task template bundles",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "export",
+				Usage:  "export templates to a JSON/YAML bundle (all templates when no --id is given)",
+				Action: runTemplateExport,
+				Flags: []cli.Flag{
+					&cli.IntSliceFlag{Name: "id", Usage: "template id, repeatable"},
+					&cli.StringFlag{Name: "format", Value: models.TemplateBundleJSON, Usage: "bundle format: json|yaml"},
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "output file (default stdout)"},
+				},
+			},
+			{
+				Name:      "import",
+				Usage:     "import template bundles from files or directories",
+				ArgsUsage: "<file|dir> [file|dir...]",
+				Action:    runTemplateImport,
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "conflict", Value: models.TemplateConflictSkip, Usage: "on name conflict: skip|overwrite|rename"},
+					&cli.BoolFlag{Name: "json", Usage: "output JSON"},
+				},
+			},
+		},
+	}
+}
+
+func runTemplateExport(ctx *cli.Context) error {
+	format := strings.ToLower(ctx.String("format"))
+	if format == "yml" {
+		format = models.TemplateBundleYAML
+	}
+	if format != models.TemplateBundleJSON && format != models.TemplateBundleYAML {
+		return fmt.Errorf("invalid --format %q (use json|yaml)", ctx.String("format"))
+	}
+	if err := bootstrapDB(); err != nil {
+		return err
+	}
+	bundle, err := models.ExportTemplates(ctx.IntSlice("id"))
+	if err != nil {
+		return fmt.Errorf("export templates: %w", err)
+	}
+	data, err := models.MarshalTemplateBundle(bundle, format)
+	if err != nil {
+		return err
+	}
+	output := ctx.String("output")
+	if output == "" {
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d template(s) to %s\n", len(bundle.Templates), output)
+	return nil
+}
+
+func runTemplateImport(ctx *cli.Context) error {
+	mode := ctx.String("conflict")
+	if !models.ValidTemplateConflictMode(mode) {
+		return fmt.Errorf("invalid --conflict %q (use skip|overwrite|rename)", mode)
+	}
+	if ctx.Args().Len() == 0 {
+		return fmt.Errorf("missing bundle file or directory")
+	}
+	if err := bootstrapDB(); err != nil {
+		return err
+	}
+
+	result := models.NewTemplateImportResult()
+	for _, path := range ctx.Args().Slice() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		var r models.TemplateImportResult
+		if info.IsDir() {
+			r, err = models.ImportTemplateDir(path, mode, "cli")
+		} else {
+			r, err = models.ImportTemplateFile(path, mode, "cli")
+		}
+		if err != nil {
+			return fmt.Errorf("import %s: %w", path, err)
+		}
+		result.Merge(r)
+	}
+
+	if ctx.Bool("json") {
+		return printJSON(result)
+	}
+	fmt.Print(formatImportResult(result))
+	return nil
+}
+
+// region pure logic (no I/O, unit-testable)
+
+func formatImportResult(r models.TemplateImportResult) string {
+	var sb strings.Builder
+	for _, name := range r.Created {
+		fmt.Fprintf(&sb, "created      %s\n", name)
+	}
+	for _, name := range r.Overwritten {
+		fmt.Fprintf(&sb, "overwritten  %s\n", name)
+	}
+	for _, rename := range r.Renamed {
+		fmt.Fprintf(&sb, "renamed      %s -> %s\n", rename.From, rename.To)
+	}
+	for _, name := range r.Skipped {
+		fmt.Fprintf(&sb, "skipped      %s\n", name)
+	}
+	for _, failure := range r.Failed {
+		fmt.Fprintf(&sb, "failed       %s: %s\n", failure.Name, failure.Error)
+	}
+	fmt.Fprintf(&sb, "\n%d created, %d overwritten, %d renamed, %d skipped, %d failed\n",
+		len(r.Created), len(r.Overwritten), len(r.Renamed), len(r.Skipped), len(r.Failed))
+	return sb.String()
+}
+
+// endregion
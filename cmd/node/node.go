@@ -6,9 +6,12 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/gocronx-team/gocron/internal/modules/artifact"
 	"github.com/gocronx-team/gocron/internal/modules/rpc/auth"
 	"github.com/gocronx-team/gocron/internal/modules/rpc/server"
+	"github.com/gocronx-team/gocron/internal/modules/sqlrunner"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 	log "github.com/sirupsen/logrus"
 )
@@ -17,6 +20,9 @@ var (
 	AppVersion, BuildDate, GitCommit string
 )
 
+// artifactMaxAge 附件缓存未使用的保留时间
+const artifactMaxAge = 30 * 24 * time.Hour
+
 func main() {
 	var serverAddr string
 	var allowRoot bool
@@ -24,8 +30,11 @@ func main() {
 	var CAFile string
 	var certFile string
 	var keyFile string
+	var crlFile string
 	var enableTLS bool
 	var logLevel string
+	var artifactDir string
+	var sqliteDir string
 	flag.BoolVar(&allowRoot, "allow-root", false, "./gocron-node -allow-root")
 	flag.StringVar(&serverAddr, "s", "0.0.0.0:5921", "./gocron-node -s ip:port")
 	flag.BoolVar(&version, "v", false, "./gocron-node -v")
@@ -33,7 +42,10 @@ func main() {
 	flag.StringVar(&CAFile, "ca-file", "", "./gocron-node -ca-file path")
 	flag.StringVar(&certFile, "cert-file", "", "./gocron-node -cert-file path")
 	flag.StringVar(&keyFile, "key-file", "", "./gocron-node -key-file path")
+	flag.StringVar(&crlFile, "crl-file", "", "./gocron-node -crl-file path")
 	flag.StringVar(&logLevel, "log-level", "info", "-log-level error")
+	flag.StringVar(&artifactDir, "artifact-dir", artifact.DefaultDir(), "./gocron-node -artifact-dir path")
+	flag.StringVar(&sqliteDir, "sqlite-dir", "", "./gocron-node -sqlite-dir path")
 	flag.Parse()
 	level, err := log.ParseLevel(logLevel)
 	if err != nil {
@@ -64,6 +76,7 @@ func main() {
 		CAFile:   strings.TrimSpace(CAFile),
 		CertFile: strings.TrimSpace(certFile),
 		KeyFile:  strings.TrimSpace(keyFile),
+		CRLFile:  strings.TrimSpace(crlFile),
 	}
 
 	if runtime.GOOS != "windows" && os.Getuid() == 0 && !allowRoot {
@@ -71,5 +84,17 @@ func main() {
 		return
 	}
 
-	server.Start(serverAddr, enableTLS, certificate)
+	// 任务附件按内容缓存，30 天未使用的文件启动时清理
+	artifacts, err := artifact.NewCache(strings.TrimSpace(artifactDir))
+	if err != nil {
+		log.Fatalf("failed to create artifact dir: %s", err)
+	}
+	if removed, err := artifacts.Prune(artifactMaxAge); err == nil && removed > 0 {
+		log.Infof("Removed %d unused artifacts from %s", removed, artifacts.Dir)
+	}
+
+	// SQL 任务只能访问该目录下的 SQLite 文件，未指定时不允许 SQLite 数据源
+	sqlrunner.SqliteDir = strings.TrimSpace(sqliteDir)
+
+	server.Start(serverAddr, enableTLS, certificate, artifacts)
 }
@@ -17,7 +17,7 @@ func setupTestDb(t *testing.T) func() {
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&models.Task{}, &models.TaskLog{}, &models.Host{}, &models.TaskHost{}); err != nil {
+	if err := db.AutoMigrate(&models.Task{}, &models.TaskLog{}, &models.Host{}, &models.TaskHost{}, &models.TaskNotification{}); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	original := models.Db
@@ -148,6 +148,17 @@ func TestGetTask(t *testing.T) {
 		t.Fatalf("unexpected task: %+v", got)
 	}
 
+	// HTTP 任务的请求方法和请求体类型
+	httpTask := models.Task{Name: "hook", Spec: "0 * * * * *", Protocol: models.TaskHTTP, Command: "https://example.com",
+		HttpMethod: models.TaskHTTPMethodPatch, HttpBody: "a=1", HttpBodyType: "form", Status: models.Enabled}
+	if _, err := httpTask.Create(); err != nil {
+		t.Fatalf("create http task: %v", err)
+	}
+	got, err = getTask(getTaskInput{Id: httpTask.Id})
+	if err != nil || got.HttpMethod != models.TaskHTTPMethodPatch || got.HttpBodyType != "form" || got.HttpBody != "a=1" {
+		t.Fatalf("unexpected http task: %+v %v", got, err)
+	}
+
 	// 不存在的 ID：返回空 task，不报错
 	missing, err := getTask(getTaskInput{Id: 9999})
 	if err != nil {
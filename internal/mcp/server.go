@@ -36,6 +36,9 @@ func newServerForUser(u *authUser) *mcp.Server {
 	return s
 }
 
+const getTaskDescription = "按 ID 获取单个定时任务的详细配置。HTTP 任务的 http_method：1 GET、2 POST、3 PUT、4 PATCH、5 DELETE、6 HEAD；" +
+	"http_body_type：json、form、multipart、raw，为空表示 POST 有请求体时按 JSON 发送；http_content_type 为 raw 请求体的 Content-Type。"
+
 func registerTools(s *mcp.Server, u *authUser) {
 	// 说明：返回 GORM models 的工具（list_tasks / get_task / query_task_logs / list_hosts）其 Out 用 any。
 	// 原因是这些模型的 JSON 形态与反射推断出的 Schema 不一致，会导致 SDK 输出校验失败：
@@ -55,7 +58,7 @@ func registerTools(s *mcp.Server, u *authUser) {
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "get_task",
-		Description: "按 ID 获取单个定时任务的详细配置。",
+		Description: getTaskDescription,
 	}, func(_ context.Context, _ *mcp.CallToolRequest, in getTaskInput) (*mcp.CallToolResult, any, error) {
 		out, err := getTask(in)
 		if err != nil {
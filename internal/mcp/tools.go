@@ -143,6 +143,10 @@ func runTask(in runTaskInput) (runTaskOutput, error) {
 	if task.Id <= 0 {
 		return runTaskOutput{Started: false, Message: "task not found"}, nil
 	}
+	// 受保护任务的手动执行需要在控制台提交审批，MCP 无法代为审批
+	if models.IsProtectedTag(task.Tag, new(models.Setting).GetProtectedTags()) {
+		return runTaskOutput{Started: false, Message: "task is protected, submit a run request for approval in the console"}, nil
+	}
 	task.Spec = "MCP manual run"
 	service.ServiceTask.Run(task)
 	return runTaskOutput{Started: true, Message: "task started, check logs for result"}, nil
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/modules/sqlrunner"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+// credentialKey 加密数据源密码的密钥，启动时由配置中的 secret_key 生成
+var credentialKey = utils.DeriveKey("")
+
+// SetCredentialKey 设置加密凭据使用的密钥
+func SetCredentialKey(secret string) {
+	credentialKey = utils.DeriveKey(secret)
+}
+
+// DataSource SQL 任务的数据源，密码加密保存，不通过接口返回
+type DataSource struct {
+	Id       int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name     string `json:"name" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Driver   string `json:"driver" gorm:"type:varchar(16);not null"`
+	Host     string `json:"host" gorm:"type:varchar(255);not null;default:''"`
+	Port     int    `json:"port" gorm:"not null;default:0"`
+	User     string `json:"user" gorm:"type:varchar(128);not null;default:''"`
+	Password string `json:"-" gorm:"type:text"`
+	// Database 数据库名称，SQLite 为相对 sqlite_dir 的数据库文件路径
+	Database string `json:"database" gorm:"type:varchar(512);not null;default:''"`
+	// Params 追加到连接字符串的参数，如 tls=true、sslmode=require
+	Params    string    `json:"params" gorm:"type:varchar(512);not null;default:''"`
+	Remark    string    `json:"remark" gorm:"type:varchar(200);not null;default:''"`
+	CreatedAt time.Time `json:"created" gorm:"column:created;autoCreateTime"`
+	UpdatedAt time.Time `json:"updated" gorm:"column:updated;autoUpdateTime"`
+}
+
+// SetPassword 加密保存密码
+func (d *DataSource) SetPassword(password string) error {
+	encrypted, err := utils.EncryptString(credentialKey, password)
+	if err != nil {
+		return err
+	}
+	d.Password = encrypted
+	return nil
+}
+
+// Source 解密密码，返回连接信息
+func (d DataSource) Source() (sqlrunner.Source, error) {
+	password, err := utils.DecryptString(credentialKey, d.Password)
+	if err != nil {
+		return sqlrunner.Source{}, err
+	}
+	return sqlrunner.Source{
+		Driver:   d.Driver,
+		Host:     d.Host,
+		Port:     d.Port,
+		User:     d.User,
+		Password: password,
+		Database: d.Database,
+		Params:   d.Params,
+	}, nil
+}
+
+func (d *DataSource) Create() (int, error) {
+	result := Db.Create(d)
+	return d.Id, result.Error
+}
+
+// Update 更新连接信息，keepPassword 为 true 时保留原密码
+func (d *DataSource) Update(id int, keepPassword bool) error {
+	data := map[string]interface{}{
+		"name":     d.Name,
+		"driver":   d.Driver,
+		"host":     d.Host,
+		"port":     d.Port,
+		"user":     d.User,
+		"database": d.Database,
+		"params":   d.Params,
+		"remark":   d.Remark,
+		"updated":  time.Now(),
+	}
+	if !keepPassword {
+		data["password"] = d.Password
+	}
+	return Db.Model(&DataSource{}).Where("id = ?", id).UpdateColumns(data).Error
+}
+
+func (d *DataSource) Delete(id int) (int64, error) {
+	result := Db.Delete(&DataSource{}, id)
+	return result.RowsAffected, result.Error
+}
+
+func (d *DataSource) List() ([]DataSource, error) {
+	list := make([]DataSource, 0)
+	err := Db.Order("name").Find(&list).Error
+	return list, err
+}
+
+func (d *DataSource) Detail(id int) (DataSource, error) {
+	var source DataSource
+	err := Db.Where("id = ?", id).First(&source).Error
+	return source, err
+}
+
+// NameExist 名称是否已被其他数据源使用
+func (d *DataSource) NameExist(name string, id int) (bool, error) {
+	var count int64
+	err := Db.Model(&DataSource{}).Where("name = ? AND id != ?", name, id).Count(&count).Error
+	return count > 0, err
+}
+
+// UsedByTasks 返回使用该数据源的任务名称
+func (d *DataSource) UsedByTasks(id int) ([]string, error) {
+	names := make([]string, 0)
+	err := Db.Model(&Task{}).Where("protocol = ? AND sql_data_source_id = ?", TaskSQL, id).Order("name").Pluck("name", &names).Error
+	return names, err
+}
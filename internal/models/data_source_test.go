@@ -0,0 +1,78 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDataSource_PasswordEncryptedAtRest(t *testing.T) {
+	cleanup := setupDefinitionTestDB(t)
+	defer cleanup()
+	if err := Db.AutoMigrate(&DataSource{}); err != nil {
+		t.Fatal(err)
+	}
+	SetCredentialKey("test-secret")
+	defer SetCredentialKey("")
+
+	dataSource := DataSource{Name: "orders", Driver: "mysql", Host: "db1", User: "app"}
+	if err := dataSource.SetPassword("p@ssw0rd"); err != nil {
+		t.Fatal(err)
+	}
+	id, err := dataSource.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stored string
+	if err := Db.Model(&DataSource{}).Where("id = ?", id).Pluck("password", &stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored == "" || strings.Contains(stored, "p@ssw0rd") {
+		t.Fatalf("password should be encrypted, got %q", stored)
+	}
+	saved, err := dataSource.Detail(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source, err := saved.Source(); err != nil || source.Password != "p@ssw0rd" || source.Host != "db1" {
+		t.Fatalf("unexpected source %+v %v", source, err)
+	}
+
+	// 修改时不传密码保留原密码
+	saved.Host = "db2"
+	if err := saved.Update(id, true); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ = dataSource.Detail(id)
+	if source, _ := saved.Source(); source.Password != "p@ssw0rd" || source.Host != "db2" {
+		t.Fatalf("password should be kept, got %+v", source)
+	}
+
+	// 密钥不同时无法解密
+	SetCredentialKey("other-secret")
+	if _, err := saved.Source(); err == nil {
+		t.Fatal("expected decrypt error with a different key")
+	}
+	SetCredentialKey("test-secret")
+
+	if exists, _ := dataSource.NameExist("orders", 0); !exists {
+		t.Fatal("name should exist")
+	}
+	if exists, _ := dataSource.NameExist("orders", id); exists {
+		t.Fatal("name of the same data source should be allowed")
+	}
+
+	tasks := []Task{
+		{Name: "report", Protocol: TaskSQL, SqlDataSourceId: id},
+		{Name: "shell", Protocol: TaskRPC, SqlDataSourceId: id},
+	}
+	for i := range tasks {
+		if err := Db.Create(&tasks[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if names, err := dataSource.UsedByTasks(id); err != nil || !reflect.DeepEqual(names, []string{"report"}) {
+		t.Fatalf("unexpected tasks %v %v", names, err)
+	}
+}
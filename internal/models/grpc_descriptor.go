@@ -0,0 +1,86 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// GrpcDescriptor gRPC 任务使用的描述文件集，目标服务未开启反射时上传
+type GrpcDescriptor struct {
+	Id   int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name string `json:"name" gorm:"type:varchar(64);not null;uniqueIndex"`
+	// Content protoc --include_imports --descriptor_set_out 生成的二进制内容
+	Content []byte `json:"-" gorm:"not null"`
+	// Services 文件中定义的服务全名，逗号分隔，上传时解析
+	Services  string    `json:"services" gorm:"type:text"`
+	Size      int       `json:"size" gorm:"not null;default:0"`
+	Remark    string    `json:"remark" gorm:"type:varchar(200);not null;default:''"`
+	CreatedAt time.Time `json:"created" gorm:"column:created;autoCreateTime"`
+	UpdatedAt time.Time `json:"updated" gorm:"column:updated;autoUpdateTime"`
+}
+
+// SetContent 设置文件内容和其中的服务
+func (d *GrpcDescriptor) SetContent(content []byte, services []string) {
+	d.Content = content
+	d.Size = len(content)
+	d.Services = strings.Join(services, ",")
+}
+
+func (d *GrpcDescriptor) Create() (int, error) {
+	result := Db.Create(d)
+	return d.Id, result.Error
+}
+
+// Update 更新名称和备注，keepContent 为 false 时同时替换文件内容
+func (d *GrpcDescriptor) Update(id int, keepContent bool) error {
+	data := map[string]interface{}{
+		"name":    d.Name,
+		"remark":  d.Remark,
+		"updated": time.Now(),
+	}
+	if !keepContent {
+		data["content"] = d.Content
+		data["services"] = d.Services
+		data["size"] = d.Size
+	}
+	return Db.Model(&GrpcDescriptor{}).Where("id = ?", id).UpdateColumns(data).Error
+}
+
+func (d *GrpcDescriptor) Delete(id int) (int64, error) {
+	result := Db.Delete(&GrpcDescriptor{}, id)
+	return result.RowsAffected, result.Error
+}
+
+// List 列表不加载文件内容
+func (d *GrpcDescriptor) List() ([]GrpcDescriptor, error) {
+	list := make([]GrpcDescriptor, 0)
+	err := Db.Omit("content").Order("name").Find(&list).Error
+	return list, err
+}
+
+func (d *GrpcDescriptor) Detail(id int) (GrpcDescriptor, error) {
+	var descriptor GrpcDescriptor
+	err := Db.Where("id = ?", id).First(&descriptor).Error
+	return descriptor, err
+}
+
+// Exists 描述文件集是否存在
+func (d *GrpcDescriptor) Exists(id int) (bool, error) {
+	var count int64
+	err := Db.Model(&GrpcDescriptor{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// NameExist 名称是否已被其他描述文件集使用
+func (d *GrpcDescriptor) NameExist(name string, id int) (bool, error) {
+	var count int64
+	err := Db.Model(&GrpcDescriptor{}).Where("name = ? AND id != ?", name, id).Count(&count).Error
+	return count > 0, err
+}
+
+// UsedByTasks 返回使用该描述文件集的任务名称
+func (d *GrpcDescriptor) UsedByTasks(id int) ([]string, error) {
+	names := make([]string, 0)
+	err := Db.Model(&Task{}).Where("protocol = ? AND grpc_descriptor_id = ?", TaskGRPC, id).Order("name").Pluck("name", &names).Error
+	return names, err
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGrpcDescriptor(t *testing.T) {
+	cleanup := setupDefinitionTestDB(t)
+	defer cleanup()
+	if err := Db.AutoMigrate(&GrpcDescriptor{}); err != nil {
+		t.Fatal(err)
+	}
+
+	descriptor := GrpcDescriptor{Name: "orders"}
+	descriptor.SetContent([]byte{0x0a, 0x01, 0x02}, []string{"orders.v1.Orders", "orders.v1.Refunds"})
+	id, err := descriptor.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := descriptor.List()
+	if err != nil || len(list) != 1 || list[0].Content != nil || list[0].Size != 3 || list[0].Services != "orders.v1.Orders,orders.v1.Refunds" {
+		t.Fatalf("unexpected list %+v %v", list, err)
+	}
+
+	// 只修改备注时保留文件内容
+	update := GrpcDescriptor{Name: "orders", Remark: "v2"}
+	if err := update.Update(id, true); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := descriptor.Detail(id)
+	if err != nil || saved.Remark != "v2" || !reflect.DeepEqual(saved.Content, []byte{0x0a, 0x01, 0x02}) {
+		t.Fatalf("unexpected detail %+v %v", saved, err)
+	}
+	update.SetContent([]byte{0x0a}, []string{"orders.v2.Orders"})
+	if err := update.Update(id, false); err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ = descriptor.Detail(id); saved.Size != 1 || saved.Services != "orders.v2.Orders" {
+		t.Fatalf("content should be replaced, got %+v", saved)
+	}
+
+	if exists, _ := descriptor.NameExist("orders", 0); !exists {
+		t.Fatal("name should exist")
+	}
+	if exists, _ := descriptor.NameExist("orders", id); exists {
+		t.Fatal("name of the same descriptor set should be allowed")
+	}
+
+	tasks := []Task{
+		{Name: "sync", Protocol: TaskGRPC, GrpcDescriptorId: id},
+		{Name: "shell", Protocol: TaskRPC, GrpcDescriptorId: id},
+	}
+	for i := range tasks {
+		if err := Db.Create(&tasks[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if names, err := descriptor.UsedByTasks(id); err != nil || !reflect.DeepEqual(names, []string{"sync"}) {
+		t.Fatalf("unexpected tasks %v %v", names, err)
+	}
+}
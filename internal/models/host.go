@@ -1,18 +1,29 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 // 主机
 type Host struct {
-	Id        int    `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name      string `json:"name" gorm:"type:varchar(64);not null"`
-	Alias     string `json:"alias" gorm:"type:varchar(32);not null;default:''"`
-	Port      int    `json:"port" gorm:"not null;default:5921"`
-	Remark    string `json:"remark" gorm:"type:varchar(100);not null;default:''"`
-	BaseModel `json:"-" gorm:"-"`
-	Selected  bool `json:"-" gorm:"-"`
+	Id     int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name   string `json:"name" gorm:"type:varchar(64);not null"`
+	Alias  string `json:"alias" gorm:"type:varchar(32);not null;default:''"`
+	Port   int    `json:"port" gorm:"not null;default:5921"`
+	Remark string `json:"remark" gorm:"type:varchar(100);not null;default:''"`
+	// SSH 任务使用的连接信息，密码和私钥保存在密钥库中，这里只引用密钥名称
+	SshPort           int    `json:"ssh_port" gorm:"not null;default:0"`
+	SshUser           string `json:"ssh_user" gorm:"type:varchar(64);not null;default:''"`
+	SshPasswordSecret string `json:"ssh_password_secret" gorm:"type:varchar(64);not null;default:''"`
+	SshKeySecret      string `json:"ssh_key_secret" gorm:"type:varchar(64);not null;default:''"`
+	// SshHostKey 主机公钥（authorized_keys 格式），为空时首次连接后自动保存，之后公钥变化时拒绝连接
+	SshHostKey string `json:"ssh_host_key" gorm:"type:text"`
+	BaseModel  `json:"-" gorm:"-"`
+	Selected   bool `json:"-" gorm:"-"`
+	// CertNotAfter 内置 CA 签发的当前证书到期时间，未签发时为空
+	CertNotAfter *time.Time `json:"cert_not_after" gorm:"-"`
 }
 
 // 新增
@@ -27,7 +38,7 @@ func (host *Host) Create() (insertId int, err error) {
 
 func (host *Host) UpdateBean(id int) (int64, error) {
 	result := Db.Model(&Host{}).Where("id = ?", id).
-		Select("name", "alias", "port", "remark").
+		Select("name", "alias", "port", "remark", "ssh_port", "ssh_user", "ssh_password_secret", "ssh_key_secret", "ssh_host_key").
 		Updates(host)
 	return result.RowsAffected, result.Error
 }
@@ -52,6 +63,10 @@ func (host *Host) Find(id int) error {
 	return Db.First(host, id).Error
 }
 
+func (host *Host) FindByName(name string) error {
+	return Db.Where("name = ?", name).First(host).Error
+}
+
 func (host *Host) NameExists(name string, id int) (bool, error) {
 	var count int64
 	query := Db.Model(&Host{}).Where("name = ?", name)
@@ -101,3 +116,18 @@ func (host *Host) parseWhere(query *gorm.DB, params CommonMap) {
 		query.Where("name = ?", name)
 	}
 }
+
+// HostSecretUsage 返回 SSH 设置引用了该密钥的主机名称
+func HostSecretUsage(name string) ([]string, error) {
+	hosts := make([]Host, 0)
+	err := Db.Select("name").Where("ssh_password_secret = ? OR ssh_key_secret = ?", name, name).Order("name").Find(&hosts).Error
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		names = append(names, host.Name)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+)
+
+// HostCertificate 内置 CA 为节点签发的证书记录。
+// 私钥只下发给节点，数据库仅保存序列号和有效期，用于轮换和生成 CRL。
+type HostCertificate struct {
+	Id         int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	HostId     int        `json:"host_id" gorm:"index;not null"`
+	Serial     string     `json:"serial" gorm:"type:varchar(64);uniqueIndex;not null"`
+	CommonName string     `json:"common_name" gorm:"type:varchar(64);not null;default:''"`
+	NotBefore  time.Time  `json:"not_before" gorm:"not null"`
+	NotAfter   time.Time  `json:"not_after" gorm:"index;not null"`
+	Revoked    int8       `json:"revoked" gorm:"not null;default:0"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"default:null"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (c *HostCertificate) Create() error {
+	return Db.Create(c).Error
+}
+
+// ActiveByHost 返回主机当前有效（未吊销）的最新证书
+func (c *HostCertificate) ActiveByHost(hostId int) error {
+	return Db.Where("host_id = ? AND revoked = 0", hostId).Order("not_after DESC").First(c).Error
+}
+
+// LatestNotAfterByHosts 批量查询主机最新有效证书的到期时间，用于主机列表展示
+func (c *HostCertificate) LatestNotAfterByHosts(hostIds []int) (map[int]time.Time, error) {
+	result := make(map[int]time.Time)
+	if len(hostIds) == 0 {
+		return result, nil
+	}
+	list := make([]HostCertificate, 0)
+	err := Db.Select("host_id", "not_after").
+		Where("host_id IN ? AND revoked = 0", hostIds).
+		Find(&list).Error
+	if err != nil {
+		return result, err
+	}
+	for _, item := range list {
+		if item.NotAfter.After(result[item.HostId]) {
+			result[item.HostId] = item.NotAfter
+		}
+	}
+
+	return result, nil
+}
+
+// ExpiringBefore 返回在指定时间之前到期、且是该主机最新有效证书的记录
+func (c *HostCertificate) ExpiringBefore(deadline time.Time) ([]HostCertificate, error) {
+	list := make([]HostCertificate, 0)
+	err := Db.Where("revoked = 0").Order("host_id ASC, not_after DESC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	expiring := make([]HostCertificate, 0)
+	seen := make(map[int]bool)
+	for _, item := range list {
+		if seen[item.HostId] {
+			continue
+		}
+		seen[item.HostId] = true
+		if item.NotAfter.Before(deadline) {
+			expiring = append(expiring, item)
+		}
+	}
+
+	return expiring, nil
+}
+
+// RevokeByHost 吊销主机的全部有效证书，exceptSerial 非空时保留该证书（轮换时保留新证书）
+func (c *HostCertificate) RevokeByHost(hostId int, exceptSerial string) (int64, error) {
+	now := time.Now()
+	query := Db.Model(&HostCertificate{}).Where("host_id = ? AND revoked = 0", hostId)
+	if exceptSerial != "" {
+		query = query.Where("serial != ?", exceptSerial)
+	}
+	result := query.Updates(map[string]interface{}{"revoked": 1, "revoked_at": &now})
+	return result.RowsAffected, result.Error
+}
+
+// RevokedList 返回尚未过期的已吊销证书，过期证书握手时本身就会失败，无需写入 CRL
+func (c *HostCertificate) RevokedList() ([]HostCertificate, error) {
+	list := make([]HostCertificate, 0)
+	err := Db.Where("revoked = 1 AND not_after > ?", time.Now()).Order("id ASC").Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+)
+
+// HttpProfile HTTP 配置：自定义 CA、客户端证书（mTLS）、跳过证书校验和代理，多个 HTTP 任务可共用。
+// 客户端私钥和代理密码保存在密钥库中，这里只引用密钥名称
+type HttpProfile struct {
+	Id                  int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name                string    `json:"name" gorm:"type:varchar(64);not null;uniqueIndex"`
+	CaCert              string    `json:"ca_cert" gorm:"type:text"`
+	ClientCert          string    `json:"client_cert" gorm:"type:text"`
+	ClientKeySecret     string    `json:"client_key_secret" gorm:"type:varchar(64);not null;default:''"`
+	InsecureSkipVerify  bool      `json:"insecure_skip_verify" gorm:"not null;default:false"`
+	ProxyUrl            string    `json:"proxy_url" gorm:"type:varchar(512);not null;default:''"`
+	ProxyUsername       string    `json:"proxy_username" gorm:"type:varchar(128);not null;default:''"`
+	ProxyPasswordSecret string    `json:"proxy_password_secret" gorm:"type:varchar(64);not null;default:''"`
+	Remark              string    `json:"remark" gorm:"type:varchar(200);not null;default:''"`
+	CreatedAt           time.Time `json:"created" gorm:"column:created;autoCreateTime"`
+	UpdatedAt           time.Time `json:"updated" gorm:"column:updated;autoUpdateTime"`
+}
+
+func (p *HttpProfile) Create() (int, error) {
+	result := Db.Create(p)
+	return p.Id, result.Error
+}
+
+func (p *HttpProfile) Update(id int) error {
+	return Db.Model(&HttpProfile{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"name":                  p.Name,
+			"ca_cert":               p.CaCert,
+			"client_cert":           p.ClientCert,
+			"client_key_secret":     p.ClientKeySecret,
+			"insecure_skip_verify":  p.InsecureSkipVerify,
+			"proxy_url":             p.ProxyUrl,
+			"proxy_username":        p.ProxyUsername,
+			"proxy_password_secret": p.ProxyPasswordSecret,
+			"remark":                p.Remark,
+			"updated":               time.Now(),
+		}).Error
+}
+
+func (p *HttpProfile) Delete(id int) (int64, error) {
+	result := Db.Delete(&HttpProfile{}, id)
+	return result.RowsAffected, result.Error
+}
+
+func (p *HttpProfile) List() ([]HttpProfile, error) {
+	list := make([]HttpProfile, 0)
+	err := Db.Order("name").Find(&list).Error
+	return list, err
+}
+
+func (p *HttpProfile) Detail(id int) (HttpProfile, error) {
+	var profile HttpProfile
+	err := Db.Where("id = ?", id).First(&profile).Error
+	return profile, err
+}
+
+// NameExist 名称是否已被其他配置使用
+func (p *HttpProfile) NameExist(name string, id int) (bool, error) {
+	var count int64
+	err := Db.Model(&HttpProfile{}).Where("name = ? AND id != ?", name, id).Count(&count).Error
+	return count > 0, err
+}
+
+// UsedByTasks 返回使用该配置的任务名称
+func (p *HttpProfile) UsedByTasks(id int) ([]string, error) {
+	names := make([]string, 0)
+	err := Db.Model(&Task{}).Where("http_profile_id = ?", id).Order("name").Pluck("name", &names).Error
+	return names, err
+}
+
+// References 是否引用了指定名称的密钥
+func (p HttpProfile) References(name string) bool {
+	return name != "" && (p.ClientKeySecret == name || p.ProxyPasswordSecret == name)
+}
+
+// Resolve 从密钥库取出客户端私钥和代理密码，lookup 按名称返回密钥的值
+func (p HttpProfile) Resolve(lookup func(name string) (string, error)) (*httpclient.Profile, error) {
+	profile := &httpclient.Profile{
+		// 连接池按配置 id 区分，改名不影响
+		Name:               fmt.Sprintf("profile-%d", p.Id),
+		CaCert:             p.CaCert,
+		ClientCert:         p.ClientCert,
+		InsecureSkipVerify: p.InsecureSkipVerify,
+		ProxyUrl:           strings.TrimSpace(p.ProxyUrl),
+		ProxyUsername:      p.ProxyUsername,
+	}
+	var err error
+	if p.ClientKeySecret != "" {
+		if profile.ClientKey, err = lookup(p.ClientKeySecret); err != nil {
+			return nil, fmt.Errorf("secret %q: %w", p.ClientKeySecret, err)
+		}
+	}
+	if p.ProxyPasswordSecret != "" {
+		if profile.ProxyPassword, err = lookup(p.ProxyPasswordSecret); err != nil {
+			return nil, fmt.Errorf("secret %q: %w", p.ProxyPasswordSecret, err)
+		}
+	}
+
+	return profile, nil
+}
+
+// HttpProfileSecretUsage 返回引用了该密钥的 HTTP 配置名称
+func HttpProfileSecretUsage(name string) ([]string, error) {
+	profiles := make([]HttpProfile, 0)
+	err := Db.Where("client_key_secret = ? OR proxy_password_secret = ?", name, name).Find(&profiles).Error
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
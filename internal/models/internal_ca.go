@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"gorm.io/gorm/clause"
+)
+
+// internalCAId 内置 CA 只有一条记录
+const internalCAId = 1
+
+// InternalCA 内置 CA 的证书和私钥（PEM），所有 web 实例共用同一个 CA，私钥加密保存
+type InternalCA struct {
+	Id        int       `gorm:"primaryKey"`
+	CertPEM   string    `gorm:"column:cert_pem;type:text;not null"`
+	KeyPEM    string    `gorm:"column:key_pem;type:text;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+// LoadInternalCA 读取并解密内置 CA，尚未生成时返回空值
+func LoadInternalCA() (certPEM, keyPEM []byte, err error) {
+	list := make([]InternalCA, 0, 1)
+	if err = Db.Where("id = ?", internalCAId).Limit(1).Find(&list).Error; err != nil || len(list) == 0 {
+		return nil, nil, err
+	}
+	key, err := utils.DecryptString(credentialKey, list[0].KeyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []byte(list[0].CertPEM), []byte(key), nil
+}
+
+// SaveInternalCA 保存内置 CA。多个实例同时启动时只保留最先保存的，返回实际使用的 CA
+func SaveInternalCA(certPEM, keyPEM []byte) ([]byte, []byte, error) {
+	key, err := utils.EncryptString(credentialKey, string(keyPEM))
+	if err != nil {
+		return nil, nil, err
+	}
+	record := &InternalCA{Id: internalCAId, CertPEM: string(certPEM), KeyPEM: key}
+	if err = Db.Clauses(clause.OnConflict{DoNothing: true}).Create(record).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return LoadInternalCA()
+}
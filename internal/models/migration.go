@@ -14,6 +14,9 @@ func (migration *Migration) Install(dbName string) error {
 	setting := new(Setting)
 	tables := []interface{}{
 		&User{}, &Task{}, &TaskLog{}, &Host{}, setting, &LoginLog{}, &TaskHost{}, &AgentToken{}, &AuditLog{}, &TaskScriptVersion{}, &TaskTemplate{}, &ApiToken{},
+		&HostCertificate{}, &InternalCA{}, &TaskChangeRequest{}, &TaskNotification{},
+		&NotificationOutbox{}, &NotificationAttempt{}, &TaskIncident{}, &TaskSlaBreach{}, &Secret{}, &HttpProfile{}, &TaskCallback{},
+		&DataSource{}, &GrpcDescriptor{}, &TaskArtifact{}, &TaskHeartbeatCheck{},
 	}
 
 	for _, table := range tables {
@@ -46,7 +49,7 @@ func (migration *Migration) Upgrade(oldVersionId int) {
 		return
 	}
 
-	versionIds := []int{110, 122, 130, 140, 150, 151, 152, 153, 154, 155, 156, 157, 158, 159, 1510, 160, 163}
+	versionIds := []int{110, 122, 130, 140, 150, 151, 152, 153, 154, 155, 156, 157, 158, 159, 1510, 160, 163, 170}
 	upgradeFuncs := []func(*gorm.DB) error{
 		migration.upgradeFor110,
 		migration.upgradeFor122,
@@ -65,6 +68,7 @@ func (migration *Migration) Upgrade(oldVersionId int) {
 		migration.upgradeFor1510,
 		migration.upgradeFor160,
 		migration.upgradeFor163,
+		migration.upgradeFor170,
 	}
 
 	startIndex := -1
@@ -205,8 +209,8 @@ func (m *Migration) upgradeFor150(tx *gorm.DB) error {
 	logger.Info("开始升级到v1.5")
 
 	// task表增加字段 notify_keyword
-	if !tx.Migrator().HasColumn(&Task{}, "notify_keyword") {
-		err := tx.Migrator().AddColumn(&Task{}, "notify_keyword")
+	if !tx.Migrator().HasColumn(&legacyTaskNotify{}, "notify_keyword") {
+		err := tx.Migrator().AddColumn(&legacyTaskNotify{}, "NotifyKeyword")
 		if err != nil {
 			return err
 		}
@@ -666,6 +670,185 @@ func (m *Migration) upgradeFor163(tx *gorm.DB) error {
 	return nil
 }
 
+func (m *Migration) upgradeFor170(tx *gorm.DB) error {
+	logger.Info("开始升级到v1.7.0")
+
+	if err := tx.AutoMigrate(&HostCertificate{}, &InternalCA{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已创建 host_certificate、internal_ca 表")
+
+	if err := tx.AutoMigrate(&TaskTemplate{}); err != nil {
+		return err
+	}
+	if err := backfillBuiltinTemplateParameters(tx); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加 task_template.parameters 字段")
+
+	if err := tx.AutoMigrate(&TaskScriptVersion{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加 task_script_version.definition 字段")
+
+	if err := tx.AutoMigrate(&TaskChangeRequest{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已创建 task_change_request 表")
+
+	if err := tx.AutoMigrate(&TaskNotification{}); err != nil {
+		return err
+	}
+	migrated, err := migrateLegacyTaskNotifications(tx)
+	if err != nil {
+		return err
+	}
+	logger.Infof("✓ 已创建 task_notification 表，迁移 %d 个任务的通知配置", migrated)
+
+	if err := tx.AutoMigrate(&NotificationOutbox{}, &NotificationAttempt{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已创建 notification_outbox、notification_attempt 表")
+
+	if err := tx.AutoMigrate(&TaskIncident{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已创建 task_incident 表")
+
+	if err := tx.AutoMigrate(&TaskNotification{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加 task_notification.repeat_every、min_interval、template 字段")
+
+	for _, column := range []string{"sla_max_duration", "sla_success_interval", "heartbeat_token", "heartbeat_grace"} {
+		if !tx.Migrator().HasColumn(&Task{}, column) {
+			if err := tx.Migrator().AddColumn(&Task{}, column); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.AutoMigrate(&TaskSlaBreach{}, &TaskHeartbeatCheck{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加任务 SLA、心跳字段，创建 task_sla_breach、task_heartbeat_check 表")
+
+	if err := tx.AutoMigrate(&NotificationAttempt{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加 notification_attempt.response 字段")
+
+	for _, column := range []string{"http_body_type", "http_content_type", "http_success_codes", "http_assertions", "http_max_response_time"} {
+		if !tx.Migrator().HasColumn(&Task{}, column) {
+			if err := tx.Migrator().AddColumn(&Task{}, column); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.AutoMigrate(&TaskTemplate{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加 task、task_template 的 http_body_type、http_content_type 字段，task 的 HTTP 响应断言字段")
+
+	for _, column := range []string{"http_auth_type", "http_auth"} {
+		if !tx.Migrator().HasColumn(&Task{}, column) {
+			if err := tx.Migrator().AddColumn(&Task{}, column); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.AutoMigrate(&Secret{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加 task 的 HTTP 认证字段，创建 secret 表")
+
+	if !tx.Migrator().HasColumn(&Task{}, "http_profile_id") {
+		if err := tx.Migrator().AddColumn(&Task{}, "http_profile_id"); err != nil {
+			return err
+		}
+	}
+	if err := tx.AutoMigrate(&HttpProfile{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加 task.http_profile_id 字段，创建 http_profile 表")
+
+	for _, column := range []string{"http_async_mode", "http_async"} {
+		if !tx.Migrator().HasColumn(&Task{}, column) {
+			if err := tx.Migrator().AddColumn(&Task{}, column); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.AutoMigrate(&TaskCallback{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加 task 的 HTTP 异步字段，创建 task_callback 表")
+
+	if !tx.Migrator().HasColumn(&Task{}, "http_max_body_size") {
+		if err := tx.Migrator().AddColumn(&Task{}, "http_max_body_size"); err != nil {
+			return err
+		}
+	}
+	if !tx.Migrator().HasColumn(&TaskLog{}, "http_exchange") {
+		if err := tx.Migrator().AddColumn(&TaskLog{}, "http_exchange"); err != nil {
+			return err
+		}
+	}
+	logger.Info("✓ 已添加 task.http_max_body_size、task_log.http_exchange 字段")
+
+	for _, column := range []string{"ssh_port", "ssh_user", "ssh_password_secret", "ssh_key_secret", "ssh_host_key"} {
+		if !tx.Migrator().HasColumn(&Host{}, column) {
+			if err := tx.Migrator().AddColumn(&Host{}, column); err != nil {
+				return err
+			}
+		}
+	}
+	logger.Info("✓ 已添加 host 的 SSH 连接字段")
+
+	for _, column := range []string{"sql_data_source_id", "sql_transaction", "sql_max_rows", "sql_assertions"} {
+		if !tx.Migrator().HasColumn(&Task{}, column) {
+			if err := tx.Migrator().AddColumn(&Task{}, column); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.AutoMigrate(&DataSource{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加 task 的 SQL 任务字段，创建 data_source 表")
+
+	for _, column := range []string{"grpc_method", "grpc_request", "grpc_metadata", "grpc_tls", "grpc_server_name", "grpc_descriptor_id"} {
+		if !tx.Migrator().HasColumn(&Task{}, column) {
+			if err := tx.Migrator().AddColumn(&Task{}, column); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.AutoMigrate(&GrpcDescriptor{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已添加 task 的 gRPC 任务字段，创建 grpc_descriptor 表")
+
+	if err := tx.AutoMigrate(&TaskArtifact{}); err != nil {
+		return err
+	}
+	logger.Info("✓ 已创建 task_artifact 表")
+
+	if err := updateBuiltinTemplates(tx); err != nil {
+		return err
+	}
+	logger.Info("✓ 已更新内置模板的命令和调度规则，参数值在 Shell 命令中加引号")
+
+	encrypted, err := encryptSecretValues(tx)
+	if err != nil {
+		return err
+	}
+	logger.Infof("✓ 已加密 %d 个密钥的值", encrypted)
+
+	logger.Info("已升级到v1.7.0\n")
+
+	return nil
+}
+
 // contains 检查字符串是否包含子串
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsMiddle(s, substr)))
@@ -0,0 +1,230 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 通知投递状态
+const (
+	NotificationPending   = "pending"
+	NotificationSending   = "sending"
+	NotificationSucceeded = "succeeded"
+	NotificationFailed    = "failed"
+)
+
+// ErrNotificationNotFinished 通知仍在投递中，不能重发
+var ErrNotificationNotFinished = errors.New("notification is still being delivered")
+
+// NotificationOutbox 待投递的通知，每个发送目标一条记录。
+// 通知先持久化再由后台 worker 投递，进程崩溃或渠道故障时不会丢失，失败后按退避时间重试。
+type NotificationOutbox struct {
+	Id       int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId   int           `json:"task_id" gorm:"not null;index;default:0"`
+	TaskName string        `json:"task_name" gorm:"type:varchar(32);not null;default:''"`
+	Channel  NotifyChannel `json:"channel" gorm:"not null;default:0"`
+	// RuleId 产生通知的任务通知规则，按规则计算最小通知间隔，0 表示不是由规则产生
+	RuleId int `json:"rule_id" gorm:"not null;default:0;index"`
+	// Target 投递目标：邮件地址列表、Slack 频道，其他渠道为 "配置ID:名称"
+	Target string `json:"target" gorm:"type:varchar(512);not null;default:''"`
+	// Content 渲染后的通知内容
+	Content       string     `json:"content" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"type:varchar(16);not null;index"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts   int        `json:"max_attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	LastError     string     `json:"last_error" gorm:"type:varchar(512);not null;default:''"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	DeliveredAt   *time.Time `json:"delivered_at" gorm:"default:null"`
+	BaseModel     `json:"-" gorm:"-"`
+}
+
+// NotificationAttempt 通知的一次投递记录
+type NotificationAttempt struct {
+	Id       int  `json:"id" gorm:"primaryKey;autoIncrement"`
+	OutboxId int  `json:"outbox_id" gorm:"not null;index"`
+	Attempt  int  `json:"attempt" gorm:"not null;default:0"`
+	Success  bool `json:"success" gorm:"not null;default:false"`
+	// StatusCode HTTP 状态码，邮件等非 HTTP 渠道为 0
+	StatusCode int    `json:"status_code" gorm:"not null;default:0"`
+	Error      string `json:"error" gorm:"type:varchar(512);not null;default:''"`
+	// Response 截断后的响应内容，目前只有 WebHook 记录
+	Response  string    `json:"response" gorm:"type:varchar(1024);not null;default:''"`
+	LatencyMs int64     `json:"latency_ms" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (o *NotificationOutbox) Create() (int, error) {
+	o.Status = NotificationPending
+	if o.NextAttemptAt.IsZero() {
+		o.NextAttemptAt = time.Now()
+	}
+	result := Db.Create(o)
+	return o.Id, result.Error
+}
+
+func (o *NotificationOutbox) Detail(id int) (NotificationOutbox, error) {
+	var item NotificationOutbox
+	err := Db.Where("id = ?", id).First(&item).Error
+	return item, err
+}
+
+func (o *NotificationOutbox) List(params CommonMap) ([]NotificationOutbox, error) {
+	o.parsePageAndPageSize(params)
+	list := make([]NotificationOutbox, 0)
+	query := Db.Model(&NotificationOutbox{}).Omit("content")
+	o.parseWhere(query, params)
+	err := query.Order("id DESC").Limit(o.PageSize).Offset(o.pageLimitOffset()).Find(&list).Error
+	return list, err
+}
+
+func (o *NotificationOutbox) Total(params CommonMap) (int64, error) {
+	var count int64
+	query := Db.Model(&NotificationOutbox{})
+	o.parseWhere(query, params)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (o *NotificationOutbox) parseWhere(query *gorm.DB, params CommonMap) {
+	if status, ok := params["Status"]; ok && status.(string) != "" {
+		query.Where("status = ?", status)
+	}
+	if taskId, ok := params["TaskId"]; ok && taskId.(int) > 0 {
+		query.Where("task_id = ?", taskId)
+	}
+	if channel, ok := params["Channel"]; ok && channel.(int) >= 0 {
+		query.Where("channel = ?", channel)
+	}
+}
+
+// AttemptList 返回通知的全部投递记录
+func (o *NotificationOutbox) AttemptList(id int) ([]NotificationAttempt, error) {
+	list := make([]NotificationAttempt, 0)
+	err := Db.Where("outbox_id = ?", id).Order("id").Find(&list).Error
+	return list, err
+}
+
+// LastNotificationTime 任务的通知规则最近一次产生通知的时间，没有记录时返回零值
+func LastNotificationTime(taskId int, ruleId int) (time.Time, error) {
+	var item NotificationOutbox
+	err := Db.Select("created_at").Where("task_id = ? AND rule_id = ?", taskId, ruleId).
+		Order("id DESC").Limit(1).Find(&item).Error
+	return item.CreatedAt, err
+}
+
+// ClaimDueNotifications 领取已到投递时间的通知并标记为 sending。
+// 使用条件更新抢占，多个实例同时领取时每条通知只会被一个实例拿到。
+func ClaimDueNotifications(limit int) ([]NotificationOutbox, error) {
+	candidates := make([]NotificationOutbox, 0)
+	err := Db.Where("status = ? AND next_attempt_at <= ?", NotificationPending, time.Now()).
+		Order("next_attempt_at, id").Limit(limit).Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]NotificationOutbox, 0, len(candidates))
+	for _, item := range candidates {
+		result := Db.Model(&NotificationOutbox{}).
+			Where("id = ? AND status = ?", item.Id, NotificationPending).
+			Update("status", NotificationSending)
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			item.Status = NotificationSending
+			claimed = append(claimed, item)
+		}
+	}
+
+	return claimed, nil
+}
+
+// Complete 记录一次投递结果。成功则结束；失败且未达到最大次数时在 retryAt 重新投递，否则标记为失败
+func (o *NotificationOutbox) Complete(attempt NotificationAttempt, retryAt time.Time) error {
+	return Db.Transaction(func(tx *gorm.DB) error {
+		attempt.Id = 0
+		attempt.OutboxId = o.Id
+		attempt.Attempt = o.Attempts + 1
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"attempts":   attempt.Attempt,
+			"last_error": attempt.Error,
+		}
+		switch {
+		case attempt.Success:
+			updates["status"] = NotificationSucceeded
+			updates["delivered_at"] = attempt.CreatedAt
+		case attempt.Attempt >= o.MaxAttempts:
+			updates["status"] = NotificationFailed
+		default:
+			updates["status"] = NotificationPending
+			updates["next_attempt_at"] = retryAt
+		}
+		if err := tx.Model(&NotificationOutbox{}).Where("id = ?", o.Id).Updates(updates).Error; err != nil {
+			return err
+		}
+		o.Attempts = attempt.Attempt
+		o.Status = updates["status"].(string)
+
+		return nil
+	})
+}
+
+// Resend 重新投递已结束的通知，重置重试次数
+func (o *NotificationOutbox) Resend(id int) error {
+	result := Db.Model(&NotificationOutbox{}).
+		Where("id = ? AND status IN ?", id, []string{NotificationSucceeded, NotificationFailed}).
+		Updates(map[string]interface{}{
+			"status":          NotificationPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := o.Detail(id); err != nil {
+			return err
+		}
+		return ErrNotificationNotFinished
+	}
+
+	return nil
+}
+
+// RecoverStaleNotifications 把长时间停留在 sending 的通知（投递过程中进程退出）重新放回队列
+func RecoverStaleNotifications(before time.Time) (int64, error) {
+	result := Db.Model(&NotificationOutbox{}).
+		Where("status = ? AND updated_at < ?", NotificationSending, before).
+		Updates(map[string]interface{}{
+			"status":          NotificationPending,
+			"next_attempt_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// RemoveNotificationsBefore 删除指定天数之前已结束的通知及其投递记录
+func RemoveNotificationsBefore(days int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -days)
+	var removed int64
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		finished := tx.Model(&NotificationOutbox{}).Select("id").
+			Where("status IN ? AND created_at < ?", []string{NotificationSucceeded, NotificationFailed}, cutoff)
+		if err := tx.Where("outbox_id IN (?)", finished).Delete(&NotificationAttempt{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("status IN ? AND created_at < ?", []string{NotificationSucceeded, NotificationFailed}, cutoff).
+			Delete(&NotificationOutbox{})
+		removed = result.RowsAffected
+		return result.Error
+	})
+
+	return removed, err
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ncruces/go-sqlite3/gormlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func setupOutboxTestDB(t *testing.T) func() {
+	t.Helper()
+	originalDb := Db
+	db, err := gorm.Open(gormlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&NotificationOutbox{}, &NotificationAttempt{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	Db = db
+
+	return func() {
+		Db = originalDb
+	}
+}
+
+func createOutboxItem(t *testing.T, maxAttempts int) NotificationOutbox {
+	t.Helper()
+	item := NotificationOutbox{TaskId: 1, TaskName: "backup", Channel: NotifyChannelWebhook,
+		Target: "http://example.com/hook", Content: "{}", MaxAttempts: maxAttempts}
+	if _, err := item.Create(); err != nil {
+		t.Fatal(err)
+	}
+	return item
+}
+
+func TestNotificationOutbox_ClaimAndComplete(t *testing.T) {
+	cleanup := setupOutboxTestDB(t)
+	defer cleanup()
+
+	createOutboxItem(t, 2)
+	createOutboxItem(t, 2)
+
+	claimed, err := ClaimDueNotifications(10)
+	if err != nil || len(claimed) != 2 {
+		t.Fatalf("expected 2 claimed notifications, got %d %v", len(claimed), err)
+	}
+	if again, _ := ClaimDueNotifications(10); len(again) != 0 {
+		t.Fatalf("claimed notifications must not be claimed twice, got %d", len(again))
+	}
+
+	// 第一次失败：等待重试
+	first := claimed[0]
+	err = first.Complete(NotificationAttempt{StatusCode: 500, Error: "HTTP 500"}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	detail, _ := first.Detail(first.Id)
+	if detail.Status != NotificationPending || detail.Attempts != 1 || detail.LastError != "HTTP 500" {
+		t.Fatalf("unexpected state after first failure: %+v", detail)
+	}
+	if due, _ := ClaimDueNotifications(10); len(due) != 0 {
+		t.Fatalf("retry should wait for next_attempt_at, got %d", len(due))
+	}
+
+	// 达到最大次数：失败
+	if err := first.Complete(NotificationAttempt{Error: "timeout"}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	detail, _ = first.Detail(first.Id)
+	if detail.Status != NotificationFailed || detail.Attempts != 2 {
+		t.Fatalf("expected failed after max attempts: %+v", detail)
+	}
+
+	// 成功
+	second := claimed[1]
+	if err := second.Complete(NotificationAttempt{Success: true, StatusCode: 200, LatencyMs: 12}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	detail, _ = second.Detail(second.Id)
+	if detail.Status != NotificationSucceeded || detail.DeliveredAt == nil || detail.LastError != "" {
+		t.Fatalf("expected succeeded: %+v", detail)
+	}
+
+	attempts, _ := first.AttemptList(first.Id)
+	if len(attempts) != 2 || attempts[0].Attempt != 1 || attempts[0].StatusCode != 500 || attempts[1].Attempt != 2 {
+		t.Fatalf("unexpected attempts: %+v", attempts)
+	}
+}
+
+func TestNotificationOutbox_Resend(t *testing.T) {
+	cleanup := setupOutboxTestDB(t)
+	defer cleanup()
+
+	item := createOutboxItem(t, 1)
+	outboxModel := new(NotificationOutbox)
+	if err := outboxModel.Resend(item.Id); !errors.Is(err, ErrNotificationNotFinished) {
+		t.Fatalf("pending notification cannot be resent, got %v", err)
+	}
+	if err := outboxModel.Resend(9999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	claimed, _ := ClaimDueNotifications(1)
+	_ = claimed[0].Complete(NotificationAttempt{Error: "refused"}, time.Now())
+	if err := outboxModel.Resend(item.Id); err != nil {
+		t.Fatal(err)
+	}
+	detail, _ := outboxModel.Detail(item.Id)
+	if detail.Status != NotificationPending || detail.Attempts != 0 {
+		t.Fatalf("unexpected state after resend: %+v", detail)
+	}
+	if due, _ := ClaimDueNotifications(10); len(due) != 1 {
+		t.Fatalf("resent notification should be due, got %d", len(due))
+	}
+}
+
+func TestRecoverStaleNotifications(t *testing.T) {
+	cleanup := setupOutboxTestDB(t)
+	defer cleanup()
+
+	item := createOutboxItem(t, 3)
+	_, _ = ClaimDueNotifications(1)
+	Db.Model(&NotificationOutbox{}).Where("id = ?", item.Id).UpdateColumn("updated_at", time.Now().Add(-time.Hour))
+
+	recovered, err := RecoverStaleNotifications(time.Now().Add(-5 * time.Minute))
+	if err != nil || recovered != 1 {
+		t.Fatalf("expected 1 recovered notification, got %d %v", recovered, err)
+	}
+	if due, _ := ClaimDueNotifications(10); len(due) != 1 {
+		t.Fatalf("recovered notification should be due, got %d", len(due))
+	}
+}
+
+func TestRemoveNotificationsBefore(t *testing.T) {
+	cleanup := setupOutboxTestDB(t)
+	defer cleanup()
+
+	old := createOutboxItem(t, 1)
+	claimed, _ := ClaimDueNotifications(1)
+	_ = claimed[0].Complete(NotificationAttempt{Success: true}, time.Now())
+	pending := createOutboxItem(t, 1)
+	Db.Model(&NotificationOutbox{}).Where("id IN ?", []int{old.Id, pending.Id}).
+		UpdateColumn("created_at", time.Now().AddDate(0, 0, -10))
+
+	removed, err := RemoveNotificationsBefore(7)
+	if err != nil || removed != 1 {
+		t.Fatalf("expected 1 removed notification, got %d %v", removed, err)
+	}
+	var attempts int64
+	Db.Model(&NotificationAttempt{}).Count(&attempts)
+	if attempts != 0 {
+		t.Fatalf("attempts of removed notifications should be deleted, got %d", attempts)
+	}
+	if _, err := old.Detail(pending.Id); err != nil {
+		t.Fatal("pending notification must be kept")
+	}
+}
+
+func TestLastNotificationTime(t *testing.T) {
+	cleanup := setupOutboxTestDB(t)
+	defer cleanup()
+
+	// 同一渠道上的两条规则分别计算最小间隔
+	item := NotificationOutbox{TaskId: 1, TaskName: "backup", Channel: NotifyChannelWebhook, RuleId: 5, Target: "a", MaxAttempts: 1}
+	if _, err := item.Create(); err != nil {
+		t.Fatal(err)
+	}
+	last, err := LastNotificationTime(1, 5)
+	if err != nil || last.IsZero() {
+		t.Fatalf("expected the notification of rule 5, got %v %v", last, err)
+	}
+	if last, err = LastNotificationTime(1, 6); err != nil || !last.IsZero() {
+		t.Fatalf("rule 6 has not notified, got %v %v", last, err)
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"gorm.io/gorm"
+)
+
+// ErrSecretNotFound 密钥不存在
+var ErrSecretNotFound = errors.New("secret not found")
+
+// secretNamePattern 密钥名称只允许字母、数字和 _ . -，便于在配置中引用
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// Secret 密钥库中的密钥。任务和配置按名称引用密钥，值加密保存，只在执行任务时读取，不通过接口返回
+type Secret struct {
+	Id        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Value     string    `json:"-" gorm:"type:text;not null"`
+	Remark    string    `json:"remark" gorm:"type:varchar(200);not null;default:''"`
+	CreatedAt time.Time `json:"created" gorm:"column:created;autoCreateTime"`
+	UpdatedAt time.Time `json:"updated" gorm:"column:updated;autoUpdateTime"`
+}
+
+// ValidSecretName 检查密钥名称格式
+func ValidSecretName(name string) bool {
+	return secretNamePattern.MatchString(name)
+}
+
+// SetValue 加密保存密钥的值
+func (s *Secret) SetValue(value string) error {
+	encrypted, err := utils.EncryptString(credentialKey, value)
+	if err != nil {
+		return err
+	}
+	s.Value = encrypted
+	return nil
+}
+
+func (s *Secret) Create() (int, error) {
+	result := Db.Create(s)
+	return s.Id, result.Error
+}
+
+func (s *Secret) Delete(id int) (int64, error) {
+	result := Db.Delete(&Secret{}, id)
+	return result.RowsAffected, result.Error
+}
+
+func (s *Secret) List() ([]Secret, error) {
+	list := make([]Secret, 0)
+	err := Db.Order("name").Find(&list).Error
+	return list, err
+}
+
+func (s *Secret) Detail(id int) (Secret, error) {
+	var secret Secret
+	err := Db.Where("id = ?", id).First(&secret).Error
+	return secret, err
+}
+
+// NameExist 名称是否已被其他密钥使用
+func (s *Secret) NameExist(name string, id int) (bool, error) {
+	var count int64
+	err := Db.Model(&Secret{}).Where("name = ? AND id != ?", name, id).Count(&count).Error
+	return count > 0, err
+}
+
+// LookupSecret 按名称读取密钥的值
+func LookupSecret(name string) (string, error) {
+	var secret Secret
+	err := Db.Where("name = ?", name).Limit(1).Find(&secret).Error
+	if err != nil {
+		return "", err
+	}
+	if secret.Id == 0 {
+		return "", ErrSecretNotFound
+	}
+	return utils.DecryptString(credentialKey, secret.Value)
+}
+
+// encryptSecretValues 加密以明文保存的密钥值
+func encryptSecretValues(tx *gorm.DB) (int, error) {
+	list := make([]Secret, 0)
+	if err := tx.Find(&list).Error; err != nil {
+		return 0, err
+	}
+	encrypted := 0
+	for _, secret := range list {
+		if utils.IsEncrypted(secret.Value) {
+			continue
+		}
+		if err := secret.SetValue(secret.Value); err != nil {
+			return encrypted, err
+		}
+		if err := tx.Model(&Secret{}).Where("id = ?", secret.Id).UpdateColumn("value", secret.Value).Error; err != nil {
+			return encrypted, err
+		}
+		encrypted++
+	}
+	return encrypted, nil
+}
+
+// SecretUsage 返回认证配置引用了该密钥的任务名称
+func SecretUsage(name string) ([]string, error) {
+	tasks := make([]Task, 0)
+	err := Db.Select("name", "http_auth_type", "http_auth").
+		Where("http_auth_type != '' AND http_auth LIKE ?", "%"+name+"%").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, task := range tasks {
+		if task.AuthConfig().References(name) {
+			names = append(names, task.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Update 更新名称和备注，value 为空时保留原值
+func (s *Secret) Update(id int, name, value, remark string) error {
+	data := map[string]interface{}{"name": name, "remark": remark, "updated": time.Now()}
+	if value != "" {
+		encrypted, err := utils.EncryptString(credentialKey, value)
+		if err != nil {
+			return err
+		}
+		data["value"] = encrypted
+	}
+	return Db.Model(&Secret{}).Where("id = ?", id).UpdateColumns(data).Error
+}
+
+// AuthConfig 解析任务的认证配置，配置错误时返回空配置
+func (task Task) AuthConfig() httpclient.AuthConfig {
+	config, _ := httpclient.ParseAuthConfig(task.HttpAuthType, strings.TrimSpace(task.HttpAuth))
+	return config
+}
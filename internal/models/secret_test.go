@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+func TestSecret_LookupAndUsage(t *testing.T) {
+	cleanup := setupDefinitionTestDB(t)
+	defer cleanup()
+	if err := Db.AutoMigrate(&Secret{}); err != nil {
+		t.Fatal(err)
+	}
+
+	SetCredentialKey("test-secret")
+	defer SetCredentialKey("")
+
+	secret := Secret{Name: "api-token"}
+	if err := secret.SetValue("t0ken"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := secret.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := secret.Detail(secret.Id); !utils.IsEncrypted(saved.Value) || strings.Contains(saved.Value, "t0ken") {
+		t.Fatalf("secret value should be stored encrypted, got %q", saved.Value)
+	}
+	if value, err := LookupSecret("api-token"); err != nil || value != "t0ken" {
+		t.Fatalf("unexpected lookup result %q %v", value, err)
+	}
+	if _, err := LookupSecret("missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("expected ErrSecretNotFound, got %v", err)
+	}
+
+	// 值为空时保留原值
+	if err := secret.Update(secret.Id, "api-token", "", "remark"); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := LookupSecret("api-token"); value != "t0ken" {
+		t.Fatalf("empty value should keep the secret, got %q", value)
+	}
+
+	// 升级时加密以明文保存的旧值
+	legacy := Secret{Name: "legacy", Value: "plain"}
+	if _, err := legacy.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := encryptSecretValues(Db); err != nil || count != 1 {
+		t.Fatalf("expected one legacy secret to be encrypted, got %d %v", count, err)
+	}
+	if saved, _ := legacy.Detail(legacy.Id); !utils.IsEncrypted(saved.Value) {
+		t.Fatalf("legacy secret should be encrypted, got %q", saved.Value)
+	}
+	if value, _ := LookupSecret("legacy"); value != "plain" {
+		t.Fatalf("unexpected legacy value %q", value)
+	}
+
+	tasks := []Task{
+		{Name: "bearer", Protocol: TaskHTTP, HttpAuthType: "bearer", HttpAuth: `{"token_secret":"api-token"}`},
+		{Name: "basic", Protocol: TaskHTTP, HttpAuthType: "basic", HttpAuth: `{"username":"api-token","password_secret":"other"}`},
+		{Name: "none", Protocol: TaskHTTP, HttpAuth: `{"token_secret":"api-token"}`},
+	}
+	for i := range tasks {
+		if _, err := tasks[i].Create(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := SecretUsage("api-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"bearer"}) {
+		t.Fatalf("only tasks referencing the secret should be listed, got %v", names)
+	}
+
+	if !ValidSecretName("db.password_1") || ValidSecretName("with space") || ValidSecretName("") {
+		t.Fatal("unexpected secret name validation")
+	}
+
+	// HTTP 配置引用的密钥
+	if err := Db.AutoMigrate(&HttpProfile{}); err != nil {
+		t.Fatal(err)
+	}
+	profile := HttpProfile{Name: "mtls", ClientKeySecret: "api-token"}
+	if _, err := profile.Create(); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := HttpProfileSecretUsage("api-token")
+	if err != nil || !reflect.DeepEqual(profiles, []string{"mtls"}) {
+		t.Fatalf("unexpected profile usage %v %v", profiles, err)
+	}
+	resolved, err := profile.Resolve(LookupSecret)
+	if err != nil || resolved.ClientKey != "t0ken" || resolved.Name != fmt.Sprintf("profile-%d", profile.Id) {
+		t.Fatalf("unexpected resolved profile %+v %v", resolved, err)
+	}
+	tasks[0].HttpProfileId = profile.Id
+	if _, err := tasks[0].UpdateBean(tasks[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := profile.UsedByTasks(profile.Id); !reflect.DeepEqual(names, []string{"bearer"}) {
+		t.Fatalf("unexpected profile tasks %v", names)
+	}
+}
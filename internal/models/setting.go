@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"strconv"
+	"strings"
 )
 
 type Setting struct {
@@ -58,6 +59,8 @@ const (
 	LogRetentionDaysKey = "log_retention_days"
 	LogCleanupTimeKey   = "log_cleanup_time"
 	LogFileSizeLimitKey = "log_file_size_limit"
+	ProtectedTagsKey    = "protected_tags"
+	SiteUrlKey          = "site_url"
 )
 
 const (
@@ -228,7 +231,31 @@ type WebhookUrl struct {
 	Id   int    `json:"id"`
 	Name string `json:"name"`
 	Url  string `json:"url"`
-}
+	// Method 请求方法 POST/PUT/PATCH，为空时使用 POST
+	Method string `json:"method,omitempty"`
+	// Headers 自定义请求头
+	Headers map[string]string `json:"headers,omitempty"`
+	// AuthType 认证方式，空表示不认证
+	AuthType string `json:"auth_type,omitempty"`
+	// Token bearer 认证的令牌
+	Token string `json:"token,omitempty"`
+	// Username、Password basic 认证的用户名和密码
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Timeout 请求超时时间（秒），0 表示使用默认值
+	Timeout int `json:"timeout,omitempty"`
+	// Secret 签名密钥，设置后每次请求带上请求体的 HMAC-SHA256 签名
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebHook 认证方式
+const (
+	WebhookAuthBearer = "bearer"
+	WebhookAuthBasic  = "basic"
+)
+
+// WebhookMethods 支持的请求方法
+var WebhookMethods = []string{"POST", "PUT", "PATCH"}
 
 func (setting *Setting) Webhook() (WebHook, error) {
 	list := make([]Setting, 0)
@@ -265,10 +292,23 @@ func (setting *Setting) UpdateWebHook(template string) error {
 }
 
 func (setting *Setting) CreateWebhookUrl(name, url string) (int64, error) {
-	webhookUrl := WebhookUrl{0, name, url}
+	_, rows, err := setting.createWebhookUrl(WebhookUrl{Name: name, Url: url})
+
+	return rows, err
+}
+
+// AddWebhookUrl 添加带请求方法、认证和签名配置的 webhook 地址，返回配置 ID
+func (setting *Setting) AddWebhookUrl(webhookUrl WebhookUrl) (int, error) {
+	id, _, err := setting.createWebhookUrl(webhookUrl)
+
+	return id, err
+}
+
+func (setting *Setting) createWebhookUrl(webhookUrl WebhookUrl) (int, int64, error) {
+	webhookUrl.Id = 0
 	jsonByte, err := json.Marshal(webhookUrl)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	newSetting := Setting{
@@ -278,7 +318,33 @@ func (setting *Setting) CreateWebhookUrl(name, url string) (int64, error) {
 	}
 
 	result := Db.Create(&newSetting)
-	return result.RowsAffected, result.Error
+	return newSetting.Id, result.RowsAffected, result.Error
+}
+
+// WebhookUrlById 按 ID 查询 webhook 地址
+func (setting *Setting) WebhookUrlById(id int) (WebhookUrl, error) {
+	var s Setting
+	webhookUrl := WebhookUrl{}
+	err := Db.Where(map[string]interface{}{"code": WebhookCode, "key": WebhookUrlKey, "id": id}).First(&s).Error
+	if err != nil {
+		return webhookUrl, err
+	}
+	err = json.Unmarshal([]byte(s.Value), &webhookUrl)
+	webhookUrl.Id = s.Id
+
+	return webhookUrl, err
+}
+
+// UpdateWebhookUrl 修改 webhook 地址配置
+func (setting *Setting) UpdateWebhookUrl(webhookUrl WebhookUrl) error {
+	id := webhookUrl.Id
+	webhookUrl.Id = 0
+	jsonByte, err := json.Marshal(webhookUrl)
+	if err != nil {
+		return err
+	}
+	return Db.Model(&Setting{}).Where(map[string]interface{}{"code": WebhookCode, "key": WebhookUrlKey, "id": id}).
+		Update("value", string(jsonByte)).Error
 }
 
 func (setting *Setting) RemoveWebhookUrl(id int) (int64, error) {
@@ -350,6 +416,23 @@ func (setting *Setting) UpdateLogCleanupTime(cleanupTime string) error {
 
 // endregion
 
+// region 通知配置
+
+// GetSiteUrl 访问 gocron 的外部地址，用于生成通知中的日志链接，未配置时为空
+func (setting *Setting) GetSiteUrl() string {
+	value, err := setting.getSettingValue(SystemCode, SiteUrlKey)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+func (setting *Setting) UpdateSiteUrl(siteUrl string) error {
+	return setting.updateOrCreateSetting(SystemCode, SiteUrlKey, strings.TrimRight(siteUrl, "/"))
+}
+
+// endregion
+
 // region LLM配置
 
 // LLM OpenAI 兼容的大模型接入配置。
@@ -418,3 +501,34 @@ func (setting *Setting) UpdateLogFileSizeLimit(size int) error {
 }
 
 // endregion
+
+// region 审批配置
+
+// GetProtectedTags 受保护的任务标签，带这些标签的任务修改和手动执行需要审批
+func (setting *Setting) GetProtectedTags() []string {
+	value, err := setting.getSettingValue(SystemCode, ProtectedTagsKey)
+	tags := make([]string, 0)
+	if err != nil || value == "" {
+		return tags
+	}
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (setting *Setting) UpdateProtectedTags(tags []string) error {
+	items := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			items = append(items, tag)
+		}
+	}
+	return setting.updateOrCreateSetting(SystemCode, ProtectedTagsKey, strings.Join(items, ","))
+}
+
+// endregion
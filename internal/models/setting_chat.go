@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// region 聊天应用配置（Telegram、Discord、Microsoft Teams）
+
+const (
+	TelegramCode    = "telegram"
+	DiscordCode     = "discord"
+	TeamsCode       = "teams"
+	ChatTokenKey    = "token"
+	ChatReceiverKey = "receiver"
+)
+
+// ChatCodes 支持的聊天应用渠道
+var ChatCodes = []string{TelegramCode, DiscordCode, TeamsCode}
+
+// ChatReceiver 聊天应用的接收者
+// Telegram 为 chat id，Discord 和 Teams 为频道的 Incoming Webhook 地址
+type ChatReceiver struct {
+	Id     int    `json:"id"`
+	Name   string `json:"name"`
+	Target string `json:"target"`
+}
+
+// ChatSetting 某个聊天应用渠道的配置
+type ChatSetting struct {
+	// Token Telegram 机器人 token，其他渠道为空
+	Token     string         `json:"token"`
+	Receivers []ChatReceiver `json:"receivers"`
+}
+
+// IsChatCode 判断是否为支持的聊天应用渠道
+func IsChatCode(code string) bool {
+	for _, c := range ChatCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Chat 读取聊天应用渠道配置
+func (setting *Setting) Chat(code string) (ChatSetting, error) {
+	list := make([]Setting, 0)
+	err := Db.Where("code = ?", code).Find(&list).Error
+	chatSetting := ChatSetting{Receivers: make([]ChatReceiver, 0)}
+	if err != nil {
+		return chatSetting, err
+	}
+	for _, v := range list {
+		switch v.Key {
+		case ChatTokenKey:
+			chatSetting.Token = v.Value
+		case ChatReceiverKey:
+			receiver := ChatReceiver{}
+			if err := json.Unmarshal([]byte(v.Value), &receiver); err != nil {
+				continue
+			}
+			receiver.Id = v.Id
+			chatSetting.Receivers = append(chatSetting.Receivers, receiver)
+		}
+	}
+
+	return chatSetting, nil
+}
+
+// ChatReceiver 按 ID 查询聊天应用接收者
+func (setting *Setting) ChatReceiver(code string, id int) (ChatReceiver, error) {
+	var s Setting
+	receiver := ChatReceiver{}
+	err := Db.Where(map[string]interface{}{"code": code, "key": ChatReceiverKey, "id": id}).First(&s).Error
+	if err != nil {
+		return receiver, err
+	}
+	err = json.Unmarshal([]byte(s.Value), &receiver)
+	receiver.Id = s.Id
+
+	return receiver, err
+}
+
+func (setting *Setting) UpdateChatToken(code, token string) error {
+	return setting.updateOrCreateSetting(code, ChatTokenKey, token)
+}
+
+func (setting *Setting) CreateChatReceiver(code string, receiver ChatReceiver) (int, error) {
+	receiver.Id = 0
+	jsonByte, err := json.Marshal(receiver)
+	if err != nil {
+		return 0, err
+	}
+	newSetting := Setting{
+		Code:  code,
+		Key:   ChatReceiverKey,
+		Value: string(jsonByte),
+	}
+	result := Db.Create(&newSetting)
+
+	return newSetting.Id, result.Error
+}
+
+func (setting *Setting) RemoveChatReceiver(code string, id int) (int64, error) {
+	result := Db.Where(map[string]interface{}{"code": code, "key": ChatReceiverKey, "id": id}).Delete(&Setting{})
+	return result.RowsAffected, result.Error
+}
+
+// endregion
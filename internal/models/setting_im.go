@@ -0,0 +1,130 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// region 即时通讯群机器人配置（钉钉、企业微信、飞书）
+
+const (
+	DingTalkCode  = "dingtalk"
+	WeComCode     = "wecom"
+	FeishuCode    = "feishu"
+	IMBotKey      = "bot"
+	IMTemplateKey = "template"
+)
+
+// 群机器人消息使用 markdown，标题由通知渠道根据任务名称和状态生成
+const imTemplate = `**Task ID:** {{.TaskId}}
+
+**Task Name:** {{.TaskName}}
+
+**Status:** {{.Status}}
+
+**Remark:** {{.Remark}}
+
+**Result:**
+
+{{.Result}}`
+
+// IMCodes 支持的群机器人渠道
+var IMCodes = []string{DingTalkCode, WeComCode, FeishuCode}
+
+// IMBot 群机器人
+type IMBot struct {
+	Id      int    `json:"id"`
+	Name    string `json:"name"`
+	Webhook string `json:"webhook"`
+	// Secret 加签密钥（钉钉、飞书），企业微信机器人不支持加签
+	Secret string `json:"secret"`
+	// 以下为任务失败时 @ 的成员
+	// AtMobiles 手机号，逗号分隔（钉钉、企业微信）
+	AtMobiles string `json:"at_mobiles"`
+	// AtUserIds 用户ID，逗号分隔（钉钉 userId、企业微信 userid、飞书 open_id）
+	AtUserIds string `json:"at_user_ids"`
+	AtAll     bool   `json:"at_all"`
+}
+
+// IMSetting 某个群机器人渠道的配置
+type IMSetting struct {
+	Bots     []IMBot `json:"bots"`
+	Template string  `json:"template"`
+}
+
+// IsIMCode 判断是否为支持的群机器人渠道
+func IsIMCode(code string) bool {
+	for _, c := range IMCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IM 读取群机器人渠道配置，未配置模板时使用默认模板
+func (setting *Setting) IM(code string) (IMSetting, error) {
+	list := make([]Setting, 0)
+	err := Db.Where("code = ?", code).Find(&list).Error
+	imSetting := IMSetting{Bots: make([]IMBot, 0), Template: imTemplate}
+	if err != nil {
+		return imSetting, err
+	}
+	for _, v := range list {
+		switch v.Key {
+		case IMBotKey:
+			bot := IMBot{}
+			if err := json.Unmarshal([]byte(v.Value), &bot); err != nil {
+				continue
+			}
+			bot.Id = v.Id
+			imSetting.Bots = append(imSetting.Bots, bot)
+		case IMTemplateKey:
+			if v.Value != "" {
+				imSetting.Template = v.Value
+			}
+		}
+	}
+
+	return imSetting, nil
+}
+
+// IMBot 按 ID 查询群机器人
+func (setting *Setting) IMBot(code string, id int) (IMBot, error) {
+	var s Setting
+	bot := IMBot{}
+	err := Db.Where(map[string]interface{}{"code": code, "key": IMBotKey, "id": id}).First(&s).Error
+	if err != nil {
+		return bot, err
+	}
+	err = json.Unmarshal([]byte(s.Value), &bot)
+	bot.Id = s.Id
+
+	return bot, err
+}
+
+func (setting *Setting) UpdateIMTemplate(code, template string) error {
+	return setting.updateOrCreateSetting(code, IMTemplateKey, template)
+}
+
+func (setting *Setting) CreateIMBot(code string, bot IMBot) (int, error) {
+	bot.Id = 0
+	jsonByte, err := json.Marshal(bot)
+	if err != nil {
+		return 0, err
+	}
+	newSetting := Setting{
+		Code:  code,
+		Key:   IMBotKey,
+		Value: string(jsonByte),
+	}
+	result := Db.Create(&newSetting)
+
+	return newSetting.Id, result.Error
+}
+
+func (setting *Setting) RemoveIMBot(code string, id int) (int64, error) {
+	result := Db.Where(map[string]interface{}{"code": code, "key": IMBotKey, "id": id}).Delete(&Setting{})
+	return result.RowsAffected, result.Error
+}
+
+// endregion
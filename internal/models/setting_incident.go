@@ -0,0 +1,106 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// region 告警平台配置（PagerDuty、Opsgenie）
+
+const (
+	PagerDutyCode       = "pagerduty"
+	OpsgenieCode        = "opsgenie"
+	IncidentServiceKey  = "service"
+	DefaultPagerDutyApi = "https://events.pagerduty.com/v2/enqueue"
+	DefaultOpsgenieApi  = "https://api.opsgenie.com"
+)
+
+// IncidentCodes 支持的告警平台渠道
+var IncidentCodes = []string{PagerDutyCode, OpsgenieCode}
+
+// IncidentService 告警平台的接收服务
+type IncidentService struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+	// Key PagerDuty 为 Events v2 integration key（routing key），Opsgenie 为 API integration key
+	Key string `json:"key"`
+	// ApiUrl 接口地址，为空时使用平台默认地址，Opsgenie 欧洲区为 https://api.eu.opsgenie.com
+	ApiUrl string `json:"api_url"`
+}
+
+// Endpoint 返回接口地址
+func (s IncidentService) Endpoint(code string) string {
+	if s.ApiUrl != "" {
+		return s.ApiUrl
+	}
+	if code == OpsgenieCode {
+		return DefaultOpsgenieApi
+	}
+	return DefaultPagerDutyApi
+}
+
+// IsIncidentCode 判断是否为支持的告警平台渠道
+func IsIncidentCode(code string) bool {
+	for _, c := range IncidentCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IncidentServices 读取告警平台的接收服务列表
+func (setting *Setting) IncidentServices(code string) ([]IncidentService, error) {
+	list := make([]Setting, 0)
+	services := make([]IncidentService, 0)
+	err := Db.Where(map[string]interface{}{"code": code, "key": IncidentServiceKey}).Find(&list).Error
+	if err != nil {
+		return services, err
+	}
+	for _, v := range list {
+		service := IncidentService{}
+		if err := json.Unmarshal([]byte(v.Value), &service); err != nil {
+			continue
+		}
+		service.Id = v.Id
+		services = append(services, service)
+	}
+
+	return services, nil
+}
+
+// IncidentService 按 ID 查询告警平台接收服务
+func (setting *Setting) IncidentService(code string, id int) (IncidentService, error) {
+	var s Setting
+	service := IncidentService{}
+	err := Db.Where(map[string]interface{}{"code": code, "key": IncidentServiceKey, "id": id}).First(&s).Error
+	if err != nil {
+		return service, err
+	}
+	err = json.Unmarshal([]byte(s.Value), &service)
+	service.Id = s.Id
+
+	return service, err
+}
+
+func (setting *Setting) CreateIncidentService(code string, service IncidentService) (int, error) {
+	service.Id = 0
+	jsonByte, err := json.Marshal(service)
+	if err != nil {
+		return 0, err
+	}
+	newSetting := Setting{
+		Code:  code,
+		Key:   IncidentServiceKey,
+		Value: string(jsonByte),
+	}
+	result := Db.Create(&newSetting)
+
+	return newSetting.Id, result.Error
+}
+
+func (setting *Setting) RemoveIncidentService(code string, id int) (int64, error) {
+	result := Db.Where(map[string]interface{}{"code": code, "key": IncidentServiceKey, "id": id}).Delete(&Setting{})
+	return result.RowsAffected, result.Error
+}
+
+// endregion
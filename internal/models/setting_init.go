@@ -25,6 +25,11 @@ func RepairSettings() error {
 		{WebhookCode, WebhookUrlKey, ""},
 		{WebhookCode, WebhookTemplateKey, webhookTemplate},
 
+		// 群机器人配置
+		{DingTalkCode, IMTemplateKey, imTemplate},
+		{WeComCode, IMTemplateKey, imTemplate},
+		{FeishuCode, IMTemplateKey, imTemplate},
+
 		// 系统配置
 		{SystemCode, LogRetentionDaysKey, "0"},
 		{SystemCode, LogCleanupTimeKey, "03:00"},
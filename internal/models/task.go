@@ -7,16 +7,31 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gocronx-team/gocron/internal/modules/utils"
 	"gorm.io/gorm"
 )
 
 type TaskProtocol int8
 
 const (
-	TaskHTTP TaskProtocol = iota + 1 // HTTP协议
-	TaskRPC                          // RPC方式执行命令
+	TaskHTTP      TaskProtocol = iota + 1 // HTTP协议
+	TaskRPC                               // RPC方式执行命令
+	TaskHeartbeat                         // 心跳检测，任务在外部运行，通过 ping 地址上报执行结果
+	TaskSSH                               // 通过 SSH 在主机上执行命令，主机不需要运行 gocron-node
+	TaskSQL                               // 在数据源上执行 SQL，未关联主机时由服务端执行
+	TaskGRPC                              // 调用 gRPC 方法，由服务端执行
 )
 
+// UsesHosts 任务是否在关联的主机上执行
+func (p TaskProtocol) UsesHosts() bool {
+	return p == TaskRPC || p == TaskSSH || p == TaskSQL
+}
+
+// RequiresHosts 任务是否必须关联主机
+func (p TaskProtocol) RequiresHosts() bool {
+	return p == TaskRPC || p == TaskSSH
+}
+
 type TaskLevel int8
 
 const (
@@ -34,10 +49,31 @@ const (
 type TaskHTTPMethod int8
 
 const (
-	TaskHTTPMethodGet  TaskHTTPMethod = 1
-	TaskHttpMethodPost TaskHTTPMethod = 2
+	TaskHTTPMethodGet    TaskHTTPMethod = 1
+	TaskHttpMethodPost   TaskHTTPMethod = 2
+	TaskHTTPMethodPut    TaskHTTPMethod = 3
+	TaskHTTPMethodPatch  TaskHTTPMethod = 4
+	TaskHTTPMethodDelete TaskHTTPMethod = 5
+	TaskHTTPMethodHead   TaskHTTPMethod = 6
 )
 
+var taskHTTPMethodNames = map[TaskHTTPMethod]string{
+	TaskHTTPMethodGet:    "GET",
+	TaskHttpMethodPost:   "POST",
+	TaskHTTPMethodPut:    "PUT",
+	TaskHTTPMethodPatch:  "PATCH",
+	TaskHTTPMethodDelete: "DELETE",
+	TaskHTTPMethodHead:   "HEAD",
+}
+
+// String 请求方法名称，未知值按 GET 处理
+func (method TaskHTTPMethod) String() string {
+	if name, ok := taskHTTPMethodNames[method]; ok {
+		return name
+	}
+	return "GET"
+}
+
 // NextRunTime 自定义时间类型，零值时序列化为空字符串
 type NextRunTime time.Time
 
@@ -79,37 +115,76 @@ type Task struct {
 	HttpMethod       TaskHTTPMethod       `json:"http_method" gorm:"not null;default:1"`
 	HttpBody         string               `json:"http_body" gorm:"type:text"`
 	HttpHeaders      string               `json:"http_headers" gorm:"type:text"`
-	SuccessPattern   string               `json:"success_pattern" gorm:"type:varchar(512);not null;default:''"`
-	Timeout          int                  `json:"timeout" gorm:"not null;default:0"`
-	Multi            int8                 `json:"multi" gorm:"not null;default:0"`
-	RetryTimes       int8                 `json:"retry_times" gorm:"not null;default:0"`
-	RetryInterval    int16                `json:"retry_interval" gorm:"type:smallint;not null;default:0"`
-	NotifyStatus     int8                 `json:"notify_status" gorm:"not null;default:0"`
-	NotifyType       int8                 `json:"notify_type" gorm:"not null;default:0"`
-	NotifyReceiverId string               `json:"notify_receiver_id" gorm:"type:varchar(256);not null;default:''"`
-	NotifyKeyword    string               `json:"notify_keyword" gorm:"type:varchar(128);not null;default:''"`
-	Tag              string               `json:"tag" gorm:"type:varchar(255);not null;default:''"`
-	LogRetentionDays int                  `json:"log_retention_days" gorm:"type:smallint;not null;default:0"`
-	Remark           string               `json:"remark" gorm:"type:varchar(100);not null;default:''"`
-	Status           Status               `json:"status" gorm:"not null;index;default:0"`
-	CreatedAt        time.Time            `json:"created" gorm:"column:created;autoCreateTime"`
-	DeletedAt        *time.Time           `json:"deleted" gorm:"column:deleted;index"`
-	BaseModel        `json:"-" gorm:"-"`
-	Hosts            []TaskHostDetail `json:"hosts" gorm:"-"`
-	NextRunTime      NextRunTime      `json:"next_run_time" gorm:"-"`
+	// HttpBodyType 请求体类型 json、form、multipart、raw，为空时兼容旧任务：POST 有请求体按 JSON 发送，否则发送 URL 中的参数
+	HttpBodyType    string `json:"http_body_type" gorm:"type:varchar(16);not null;default:''"`
+	HttpContentType string `json:"http_content_type" gorm:"type:varchar(128);not null;default:''"`
+	SuccessPattern  string `json:"success_pattern" gorm:"type:varchar(512);not null;default:''"`
+	// HTTP 响应断言：可接受的状态码（如 200-299,304，为空只接受 200）、JSONPath 和响应头断言（JSON 数组）、最大响应时间（毫秒）
+	HttpSuccessCodes    string `json:"http_success_codes" gorm:"type:varchar(128);not null;default:''"`
+	HttpAssertions      string `json:"http_assertions" gorm:"type:text"`
+	HttpMaxResponseTime int    `json:"http_max_response_time" gorm:"not null;default:0"`
+	Timeout             int    `json:"timeout" gorm:"not null;default:0"`
+	Multi               int8   `json:"multi" gorm:"not null;default:0"`
+	RetryTimes          int8   `json:"retry_times" gorm:"not null;default:0"`
+	RetryInterval       int16  `json:"retry_interval" gorm:"type:smallint;not null;default:0"`
+	Tag                 string `json:"tag" gorm:"type:varchar(255);not null;default:''"`
+	LogRetentionDays    int    `json:"log_retention_days" gorm:"type:smallint;not null;default:0"`
+	// HTTP 认证方式 basic、bearer、oauth2 和认证配置（JSON），凭据保存在密钥库中，这里只引用密钥名称
+	HttpAuthType string `json:"http_auth_type" gorm:"type:varchar(16);not null;default:''"`
+	HttpAuth     string `json:"http_auth" gorm:"type:text"`
+	// HTTP 配置 id（CA、客户端证书、代理），0 表示使用默认客户端
+	HttpProfileId int `json:"http_profile_id" gorm:"not null;default:0"`
+	// 异步模式 poll、callback 和异步配置（JSON），触发请求成功后轮询状态地址或等待回调
+	HttpAsyncMode string `json:"http_async_mode" gorm:"type:varchar(16);not null;default:''"`
+	HttpAsync     string `json:"http_async" gorm:"type:text"`
+	// 读取的响应体大小上限（KB），0 表示 1024，超出部分不写入日志
+	HttpMaxBodySize int `json:"http_max_body_size" gorm:"not null;default:0"`
+	// SQL 任务的数据源、是否在一个事务中执行、每条查询写入日志的行数（0 表示 20）和行数断言（JSON 数组）
+	SqlDataSourceId int    `json:"sql_data_source_id" gorm:"not null;default:0"`
+	SqlTransaction  bool   `json:"sql_transaction" gorm:"not null;default:false"`
+	SqlMaxRows      int    `json:"sql_max_rows" gorm:"not null;default:0"`
+	SqlAssertions   string `json:"sql_assertions" gorm:"type:text"`
+	// gRPC 任务的方法全名、请求 JSON、元数据（JSON 对象），Command 为服务地址。
+	// 开启 TLS 时使用 HttpProfileId 对应配置中的 CA 和客户端证书；描述文件集 id 为 0 时使用服务端反射
+	GrpcMethod       string `json:"grpc_method" gorm:"type:varchar(255);not null;default:''"`
+	GrpcRequest      string `json:"grpc_request" gorm:"type:text"`
+	GrpcMetadata     string `json:"grpc_metadata" gorm:"type:text"`
+	GrpcTls          bool   `json:"grpc_tls" gorm:"not null;default:false"`
+	GrpcServerName   string `json:"grpc_server_name" gorm:"type:varchar(255);not null;default:''"`
+	GrpcDescriptorId int    `json:"grpc_descriptor_id" gorm:"not null;default:0"`
+	// SLA 阈值（秒），0 表示不检查
+	SlaMaxDuration     int `json:"sla_max_duration" gorm:"not null;default:0"`
+	SlaSuccessInterval int `json:"sla_success_interval" gorm:"not null;default:0"`
+	// 心跳任务的 ping 令牌和宽限时间（秒），调度时间加宽限时间内没有收到 ping 记为失败
+	HeartbeatToken string     `json:"heartbeat_token" gorm:"type:varchar(64);not null;default:'';index"`
+	HeartbeatGrace int        `json:"heartbeat_grace" gorm:"not null;default:0"`
+	Remark         string     `json:"remark" gorm:"type:varchar(100);not null;default:''"`
+	Status         Status     `json:"status" gorm:"not null;index;default:0"`
+	CreatedAt      time.Time  `json:"created" gorm:"column:created;autoCreateTime"`
+	DeletedAt      *time.Time `json:"deleted" gorm:"column:deleted;index"`
+	BaseModel      `json:"-" gorm:"-"`
+	Hosts          []TaskHostDetail   `json:"hosts" gorm:"-"`
+	Notifications  []TaskNotification `json:"notifications" gorm:"-"`
+	NextRunTime    NextRunTime        `json:"next_run_time" gorm:"-"`
 }
 
 // 新增
 func (task *Task) Create() (insertId int, err error) {
+	if task.Protocol == TaskHeartbeat && task.HeartbeatToken == "" {
+		task.HeartbeatToken = utils.RandAuthToken()
+	}
 	// 使用 Select 显式列出所有列，确保零值字段（如 Multi=0）也会被写入，
 	// 覆盖 gorm 标签中的 default 值，同时 GORM 会将自增主键回填到 task.Id。
 	result := Db.Select(
 		"name", "level", "dependency_task_id", "dependency_status",
 		"spec", "protocol", "command", "http_method", "http_body",
-		"http_headers", "success_pattern", "timeout", "multi",
-		"retry_times", "retry_interval", "notify_status", "notify_type",
-		"notify_receiver_id", "notify_keyword", "tag", "log_retention_days",
-		"remark", "status",
+		"http_headers", "http_body_type", "http_content_type", "success_pattern", "http_success_codes",
+		"http_assertions", "http_max_response_time", "http_auth_type", "http_auth", "http_profile_id", "http_async_mode", "http_async", "http_max_body_size",
+		"sql_data_source_id", "sql_transaction", "sql_max_rows", "sql_assertions",
+		"grpc_method", "grpc_request", "grpc_metadata", "grpc_tls", "grpc_server_name", "grpc_descriptor_id", "timeout", "multi",
+		"retry_times", "retry_interval", "tag", "log_retention_days",
+		"sla_max_duration", "sla_success_interval", "heartbeat_token",
+		"heartbeat_grace", "remark", "status",
 	).Create(task)
 	if result.Error == nil {
 		insertId = task.Id
@@ -119,37 +194,70 @@ func (task *Task) Create() (insertId int, err error) {
 }
 
 func (task *Task) UpdateBean(id int) (int64, error) {
-	result := Db.Model(&Task{}).Where("id = ?", id).
+	return task.updateWith(Db, id)
+}
+
+func (task *Task) updateWith(db *gorm.DB, id int) (int64, error) {
+	result := db.Model(&Task{}).Where("id = ?", id).
 		Select("name", "spec", "protocol", "command", "timeout", "multi",
-			"retry_times", "retry_interval", "remark", "notify_status",
-			"notify_type", "notify_receiver_id", "dependency_task_id",
+			"retry_times", "retry_interval", "remark", "dependency_task_id",
 			"dependency_status", "tag", "http_method", "http_body",
-			"http_headers", "success_pattern", "notify_keyword",
-			"log_retention_days").
+			"http_headers", "http_body_type", "http_content_type", "success_pattern", "http_success_codes",
+			"http_assertions", "http_max_response_time", "http_auth_type", "http_auth", "http_profile_id", "http_async_mode", "http_async", "http_max_body_size",
+			"sql_data_source_id", "sql_transaction", "sql_max_rows", "sql_assertions",
+			"grpc_method", "grpc_request", "grpc_metadata", "grpc_tls", "grpc_server_name", "grpc_descriptor_id", "log_retention_days",
+			"sla_max_duration", "sla_success_interval", "heartbeat_grace").
 		UpdateColumns(map[string]interface{}{
-			"name":               task.Name,
-			"spec":               task.Spec,
-			"protocol":           task.Protocol,
-			"command":            task.Command,
-			"timeout":            task.Timeout,
-			"multi":              task.Multi,
-			"retry_times":        task.RetryTimes,
-			"retry_interval":     task.RetryInterval,
-			"remark":             task.Remark,
-			"notify_status":      task.NotifyStatus,
-			"notify_type":        task.NotifyType,
-			"notify_receiver_id": task.NotifyReceiverId,
-			"dependency_task_id": task.DependencyTaskId,
-			"dependency_status":  task.DependencyStatus,
-			"tag":                task.Tag,
-			"http_method":        task.HttpMethod,
-			"http_body":          task.HttpBody,
-			"http_headers":       task.HttpHeaders,
-			"success_pattern":    task.SuccessPattern,
-			"notify_keyword":     task.NotifyKeyword,
-			"log_retention_days": task.LogRetentionDays,
+			"name":                   task.Name,
+			"spec":                   task.Spec,
+			"protocol":               task.Protocol,
+			"command":                task.Command,
+			"timeout":                task.Timeout,
+			"multi":                  task.Multi,
+			"retry_times":            task.RetryTimes,
+			"retry_interval":         task.RetryInterval,
+			"remark":                 task.Remark,
+			"dependency_task_id":     task.DependencyTaskId,
+			"dependency_status":      task.DependencyStatus,
+			"tag":                    task.Tag,
+			"http_method":            task.HttpMethod,
+			"http_body":              task.HttpBody,
+			"http_headers":           task.HttpHeaders,
+			"http_body_type":         task.HttpBodyType,
+			"http_content_type":      task.HttpContentType,
+			"success_pattern":        task.SuccessPattern,
+			"http_success_codes":     task.HttpSuccessCodes,
+			"http_assertions":        task.HttpAssertions,
+			"http_max_response_time": task.HttpMaxResponseTime,
+			"http_auth_type":         task.HttpAuthType,
+			"http_auth":              task.HttpAuth,
+			"http_profile_id":        task.HttpProfileId,
+			"http_async_mode":        task.HttpAsyncMode,
+			"http_async":             task.HttpAsync,
+			"http_max_body_size":     task.HttpMaxBodySize,
+			"sql_data_source_id":     task.SqlDataSourceId,
+			"sql_transaction":        task.SqlTransaction,
+			"sql_max_rows":           task.SqlMaxRows,
+			"sql_assertions":         task.SqlAssertions,
+			"grpc_method":            task.GrpcMethod,
+			"grpc_request":           task.GrpcRequest,
+			"grpc_metadata":          task.GrpcMetadata,
+			"grpc_tls":               task.GrpcTls,
+			"grpc_server_name":       task.GrpcServerName,
+			"grpc_descriptor_id":     task.GrpcDescriptorId,
+			"log_retention_days":     task.LogRetentionDays,
+			"sla_max_duration":       task.SlaMaxDuration,
+			"sla_success_interval":   task.SlaSuccessInterval,
+			"heartbeat_grace":        task.HeartbeatGrace,
 		})
-	return result.RowsAffected, result.Error
+	if result.Error != nil || task.Protocol != TaskHeartbeat {
+		return result.RowsAffected, result.Error
+	}
+	// 改为心跳任务时生成 ping 令牌，已有令牌保持不变
+	err := db.Model(&Task{}).Where("id = ? AND heartbeat_token = ''", id).
+		UpdateColumn("heartbeat_token", utils.RandAuthToken()).Error
+
+	return result.RowsAffected, err
 }
 
 // 更新
@@ -194,6 +302,18 @@ func (task *Task) ActiveList(page, pageSize int) ([]Task, error) {
 	return task.setHostsForTasks(list)
 }
 
+// SlaList 设置了 SLA 阈值的激活任务，包含通知规则
+func (task *Task) SlaList() ([]Task, error) {
+	list := make([]Task, 0)
+	err := Db.Where("status = ? AND (sla_max_duration > 0 OR sla_success_interval > 0)", Enabled).
+		Find(&list).Error
+	if err != nil {
+		return list, err
+	}
+
+	return task.setHostsForTasks(list)
+}
+
 // 获取某个主机下的所有激活任务
 func (task *Task) ActiveListByHostId(hostId int) ([]Task, error) {
 	taskHostModel := new(TaskHost)
@@ -215,7 +335,7 @@ func (task *Task) ActiveListByHostId(hostId int) ([]Task, error) {
 	return task.setHostsForTasks(list)
 }
 
-// 优化：批量查询任务主机信息，避免N+1查询问题
+// 优化：批量查询任务主机信息和通知规则，避免N+1查询问题
 func (task *Task) setHostsForTasks(tasks []Task) ([]Task, error) {
 	if len(tasks) == 0 {
 		return tasks, nil
@@ -234,13 +354,24 @@ func (task *Task) setHostsForTasks(tasks []Task) ([]Task, error) {
 		return nil, err
 	}
 
-	// 分配主机信息到对应任务
+	// 批量查询所有任务的通知规则
+	notificationsMap, err := new(TaskNotification).ListByTaskIds(taskIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// 分配主机信息和通知规则到对应任务
 	for i := range tasks {
 		if hosts, ok := hostsMap[tasks[i].Id]; ok {
 			tasks[i].Hosts = hosts
 		} else {
 			tasks[i].Hosts = []TaskHostDetail{}
 		}
+		if notifications, ok := notificationsMap[tasks[i].Id]; ok {
+			tasks[i].Notifications = notifications
+		} else {
+			tasks[i].Notifications = []TaskNotification{}
+		}
 	}
 
 	return tasks, nil
@@ -279,10 +410,42 @@ func (task *Task) Detail(id int) (Task, error) {
 
 	taskHostModel := new(TaskHost)
 	t.Hosts, err = taskHostModel.GetHostIdsByTaskId(id)
+	if err != nil {
+		return t, err
+	}
+	notifications, err := new(TaskNotification).ListByTaskIds([]int{id})
+	t.Notifications = notifications[id]
+	if t.Notifications == nil {
+		t.Notifications = []TaskNotification{}
+	}
 
 	return t, err
 }
 
+// DetailByHeartbeatToken 按 ping 令牌查询心跳任务，不存在时返回零值
+func (task *Task) DetailByHeartbeatToken(token string) (Task, error) {
+	if token == "" {
+		return Task{}, nil
+	}
+	var id int
+	err := Db.Model(&Task{}).Select("id").
+		Where("heartbeat_token = ? AND protocol = ?", token, TaskHeartbeat).
+		Limit(1).Scan(&id).Error
+	if err != nil || id == 0 {
+		return Task{}, err
+	}
+
+	return task.Detail(id)
+}
+
+// ResetHeartbeatToken 生成新的 ping 令牌，旧地址立即失效
+func (task *Task) ResetHeartbeatToken(id int) (string, error) {
+	token := utils.RandAuthToken()
+	_, err := task.Update(id, CommonMap{"heartbeat_token": token})
+
+	return token, err
+}
+
 func (task *Task) List(params CommonMap) ([]Task, error) {
 	task.parsePageAndPageSize(params)
 	list := make([]Task, 0)
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaskArtifactKeepVersions 每个附件保留的版本数
+const TaskArtifactKeepVersions = 10
+
+var ErrArtifactToken = errors.New("invalid or expired artifact token")
+
+// TaskArtifact 随 Shell 任务分发到节点的文件，同名文件每次上传生成新版本，执行时使用最新版本
+type TaskArtifact struct {
+	Id      int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId  int    `json:"task_id" gorm:"not null;uniqueIndex:idx_task_artifact_version"`
+	Name    string `json:"name" gorm:"type:varchar(128);not null;uniqueIndex:idx_task_artifact_version"`
+	Version int    `json:"version" gorm:"not null;uniqueIndex:idx_task_artifact_version"`
+	// Sha256 文件内容的十六进制 SHA-256，节点按此校验和缓存
+	Sha256    string    `json:"sha256" gorm:"type:varchar(64);not null"`
+	Size      int64     `json:"size" gorm:"not null;default:0"`
+	Content   []byte    `json:"-" gorm:"not null"`
+	Username  string    `json:"username" gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt time.Time `json:"created" gorm:"column:created;autoCreateTime"`
+}
+
+// SetContent 设置文件内容和校验值
+func (a *TaskArtifact) SetContent(content []byte) {
+	sum := sha256.Sum256(content)
+	a.Content = content
+	a.Size = int64(len(content))
+	a.Sha256 = hex.EncodeToString(sum[:])
+}
+
+// Create 保存为同名附件的下一个版本，并清理超出保留数量的旧版本
+func (a *TaskArtifact) Create() (int, error) {
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&TaskArtifact{}).Where("task_id = ? AND name = ?", a.TaskId, a.Name).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		a.Id = 0
+		a.Version = latest + 1
+		if err := tx.Create(a).Error; err != nil {
+			return err
+		}
+		return tx.Where("task_id = ? AND name = ? AND version <= ?", a.TaskId, a.Name, a.Version-TaskArtifactKeepVersions).
+			Delete(&TaskArtifact{}).Error
+	})
+	return a.Id, err
+}
+
+// List 任务的全部附件版本，不加载文件内容
+func (a *TaskArtifact) List(taskId int) ([]TaskArtifact, error) {
+	list := make([]TaskArtifact, 0)
+	err := Db.Omit("content").Where("task_id = ?", taskId).Order("name, version DESC").Find(&list).Error
+	return list, err
+}
+
+// Latest 任务每个附件的最新版本，不加载文件内容
+func (a *TaskArtifact) Latest(taskId int) ([]TaskArtifact, error) {
+	versions, err := a.List(taskId)
+	if err != nil {
+		return nil, err
+	}
+	// 按名称排序、版本倒序，每个名称的第一条为最新版本
+	list := make([]TaskArtifact, 0)
+	for _, artifact := range versions {
+		if len(list) == 0 || list[len(list)-1].Name != artifact.Name {
+			list = append(list, artifact)
+		}
+	}
+	return list, nil
+}
+
+func (a *TaskArtifact) Detail(id int) (TaskArtifact, error) {
+	var artifact TaskArtifact
+	err := Db.Where("id = ?", id).First(&artifact).Error
+	return artifact, err
+}
+
+// Names 任务已有的附件名称
+func (a *TaskArtifact) Names(taskId int) ([]string, error) {
+	names := make([]string, 0)
+	err := Db.Model(&TaskArtifact{}).Where("task_id = ?", taskId).Distinct("name").Order("name").Pluck("name", &names).Error
+	return names, err
+}
+
+// Remove 删除附件的全部版本
+func (a *TaskArtifact) Remove(taskId int, name string) (int64, error) {
+	result := Db.Where("task_id = ? AND name = ?", taskId, name).Delete(&TaskArtifact{})
+	return result.RowsAffected, result.Error
+}
+
+// RemoveByTask 删除任务时清理附件
+func (a *TaskArtifact) RemoveByTask(taskId int) error {
+	return Db.Where("task_id = ?", taskId).Delete(&TaskArtifact{}).Error
+}
+
+// DownloadToken 生成节点下载附件的令牌，格式为 id.过期时间.签名
+func (a TaskArtifact) DownloadToken(expires time.Time) string {
+	payload := fmt.Sprintf("%d.%d", a.Id, expires.Unix())
+	return payload + "." + signArtifact(payload)
+}
+
+// ParseArtifactToken 校验下载令牌，返回附件 ID
+func ParseArtifactToken(token string) (int, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, ErrArtifactToken
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signArtifact(payload))) {
+		return 0, ErrArtifactToken
+	}
+	id, err := strconv.Atoi(parts[0])
+	expires, expErr := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || expErr != nil || time.Now().Unix() > expires {
+		return 0, ErrArtifactToken
+	}
+	return id, nil
+}
+
+// signArtifact 使用加密凭据的密钥签名，多实例部署时任一实例都能校验
+func signArtifact(payload string) string {
+	mac := hmac.New(sha256.New, credentialKey)
+	mac.Write([]byte("artifact:" + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
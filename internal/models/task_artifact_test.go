@@ -0,0 +1,84 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskArtifact_Versions(t *testing.T) {
+	cleanup := setupDefinitionTestDB(t)
+	defer cleanup()
+	if err := Db.AutoMigrate(&TaskArtifact{}); err != nil {
+		t.Fatal(err)
+	}
+
+	artifactModel := new(TaskArtifact)
+	for i := 0; i < TaskArtifactKeepVersions+2; i++ {
+		artifact := TaskArtifact{TaskId: 1, Name: "deploy.sh", Username: "admin"}
+		artifact.SetContent([]byte("echo v" + string(rune('a'+i))))
+		if _, err := artifact.Create(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := TaskArtifact{TaskId: 1, Name: "config.ini"}
+	config.SetContent([]byte("env=prod"))
+	configId, err := config.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := TaskArtifact{TaskId: 2, Name: "deploy.sh"}
+	other.SetContent([]byte("echo other"))
+	if _, err := other.Create(); err != nil || other.Version != 1 {
+		t.Fatalf("versions are numbered per task, got %d %v", other.Version, err)
+	}
+
+	// 只保留最近的版本
+	list, err := artifactModel.List(1)
+	if err != nil || len(list) != TaskArtifactKeepVersions+1 || list[0].Content != nil {
+		t.Fatalf("unexpected list %d %v", len(list), err)
+	}
+	latest, err := artifactModel.Latest(1)
+	if err != nil || len(latest) != 2 {
+		t.Fatalf("unexpected latest %+v %v", latest, err)
+	}
+	if latest[0].Name != "config.ini" || latest[1].Name != "deploy.sh" || latest[1].Version != TaskArtifactKeepVersions+2 {
+		t.Fatalf("unexpected latest %+v", latest)
+	}
+	if sum := sha256.Sum256([]byte("env=prod")); latest[0].Sha256 != hex.EncodeToString(sum[:]) || latest[0].Size != 8 {
+		t.Fatalf("unexpected checksum %s", latest[0].Sha256)
+	}
+	if names, _ := artifactModel.Names(1); !reflect.DeepEqual(names, []string{"config.ini", "deploy.sh"}) {
+		t.Fatalf("unexpected names %v", names)
+	}
+
+	if n, err := artifactModel.Remove(1, "deploy.sh"); err != nil || n != TaskArtifactKeepVersions {
+		t.Fatalf("unexpected removed %d %v", n, err)
+	}
+	if err := artifactModel.RemoveByTask(2); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := artifactModel.List(2); len(list) != 0 {
+		t.Fatalf("artifacts of removed task should be deleted, got %d", len(list))
+	}
+
+	// 下载令牌
+	saved, err := artifactModel.Detail(configId)
+	if err != nil || string(saved.Content) != "env=prod" {
+		t.Fatalf("unexpected detail %+v %v", saved, err)
+	}
+	token := saved.DownloadToken(time.Now().Add(time.Minute))
+	if id, err := ParseArtifactToken(token); err != nil || id != configId {
+		t.Fatalf("unexpected token result %d %v", id, err)
+	}
+	expired := saved.DownloadToken(time.Now().Add(-time.Minute))
+	forged := strings.Replace(token, token[:strings.Index(token, ".")], "999", 1)
+	for _, bad := range []string{expired, forged, "", "1.2", token + "0"} {
+		if _, err := ParseArtifactToken(bad); err != ErrArtifactToken {
+			t.Errorf("expected invalid token for %q, got %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+// 回调状态
+const (
+	TaskCallbackWaiting int8 = 0
+	TaskCallbackSuccess int8 = 1
+	TaskCallbackFailed  int8 = 2
+)
+
+var (
+	ErrTaskCallbackNotFound = errors.New("task callback not found")
+	ErrTaskCallbackReceived = errors.New("task callback already received")
+)
+
+// TaskCallback 异步 HTTP 任务一次执行的回调地址。
+// 回调可能被集群中任意节点接收，执行任务的节点通过查询记录等待回调
+type TaskCallback struct {
+	Id        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId    int       `json:"task_id" gorm:"not null;index"`
+	TaskLogId int64     `json:"task_log_id" gorm:"not null;default:0"`
+	Token     string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Status    int8      `json:"status" gorm:"not null;default:0"`
+	Body      string    `json:"body" gorm:"type:text"`
+	Source    string    `json:"source" gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt time.Time `json:"created" gorm:"column:created;autoCreateTime"`
+}
+
+// CreateTaskCallback 生成一次执行的回调令牌
+func CreateTaskCallback(taskId int, taskLogId int64) (TaskCallback, error) {
+	callback := TaskCallback{TaskId: taskId, TaskLogId: taskLogId, Token: utils.RandAuthToken()}
+	err := Db.Create(&callback).Error
+	return callback, err
+}
+
+// ReceiveTaskCallback 记录回调结果，每个令牌只接受一次回调
+func ReceiveTaskCallback(token string, status int8, body, source string) error {
+	result := Db.Model(&TaskCallback{}).
+		Where("token = ? AND status = ?", token, TaskCallbackWaiting).
+		UpdateColumns(map[string]interface{}{"status": status, "body": body, "source": source})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	var count int64
+	if err := Db.Model(&TaskCallback{}).Where("token = ?", token).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTaskCallbackReceived
+	}
+	return ErrTaskCallbackNotFound
+}
+
+// Detail 查询回调记录，不存在时返回 ErrTaskCallbackNotFound
+func (c *TaskCallback) Detail(id int) (TaskCallback, error) {
+	var callback TaskCallback
+	err := Db.Where("id = ?", id).Limit(1).Find(&callback).Error
+	if err == nil && callback.Id == 0 {
+		err = ErrTaskCallbackNotFound
+	}
+	return callback, err
+}
+
+// Delete 等待结束后删除回调记录，令牌随之失效
+func (c *TaskCallback) Delete(id int) error {
+	return Db.Delete(&TaskCallback{}, id).Error
+}
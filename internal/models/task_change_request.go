@@ -0,0 +1,220 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 变更申请类型
+const (
+	ChangeRequestUpdate   = "update"
+	ChangeRequestRollback = "rollback"
+	ChangeRequestRun      = "run"
+	ChangeRequestEnable   = "enable"
+	ChangeRequestDisable  = "disable"
+	ChangeRequestRemove   = "remove"
+)
+
+// 变更申请状态
+const (
+	ChangeRequestPending   = "pending"
+	ChangeRequestApproved  = "approved"
+	ChangeRequestRejected  = "rejected"
+	ChangeRequestCancelled = "cancelled"
+)
+
+var (
+	ErrChangeRequestNotPending   = errors.New("change request is not pending")
+	ErrChangeRequestSelfApproval = errors.New("author cannot review own change request")
+	ErrChangeRequestNotAuthor    = errors.New("only the author can cancel a change request")
+	// ErrChangeRequestStale 提交后任务已被修改，申请基于过期的定义
+	ErrChangeRequestStale = errors.New("task has changed since the change request was submitted")
+)
+
+// TaskChangeRequest 受保护任务的变更申请。修改、回滚、手动执行、启用、停用和删除受保护任务时
+// 先生成申请，由作者以外的用户审批后才生效。
+type TaskChangeRequest struct {
+	Id       int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId   int    `json:"task_id" gorm:"not null;index"`
+	TaskName string `json:"task_name" gorm:"type:varchar(32);not null;default:''"`
+	Type     string `json:"type" gorm:"type:varchar(16);not null"`
+	Status   string `json:"status" gorm:"type:varchar(16);not null;index"`
+	// Base 提交时的任务定义，审批时用于检测冲突
+	Base *TaskDefinition `json:"base" gorm:"type:text"`
+	// Definition 申请生效后的任务定义，手动执行、启停和删除申请为空
+	Definition *TaskDefinition `json:"definition" gorm:"type:text"`
+	// Remark 提交人说明
+	Remark   string `json:"remark" gorm:"type:varchar(255);not null;default:''"`
+	Author   string `json:"author" gorm:"type:varchar(64);not null;default:''"`
+	Reviewer string `json:"reviewer" gorm:"type:varchar(64);not null;default:''"`
+	// Comment 审批意见
+	Comment    string     `json:"comment" gorm:"type:varchar(255);not null;default:''"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	ReviewedAt *time.Time `json:"reviewed_at" gorm:"default:null"`
+	BaseModel  `json:"-" gorm:"-"`
+}
+
+func (r *TaskChangeRequest) Create() (int, error) {
+	r.Status = ChangeRequestPending
+	result := Db.Create(r)
+	return r.Id, result.Error
+}
+
+func (r *TaskChangeRequest) Detail(id int) (TaskChangeRequest, error) {
+	var request TaskChangeRequest
+	err := Db.Where("id = ?", id).First(&request).Error
+	return request, err
+}
+
+func (r *TaskChangeRequest) List(params CommonMap) ([]TaskChangeRequest, error) {
+	r.parsePageAndPageSize(params)
+	list := make([]TaskChangeRequest, 0)
+	query := Db.Model(&TaskChangeRequest{})
+	r.parseWhere(query, params)
+	err := query.Order("id DESC").Limit(r.PageSize).Offset(r.pageLimitOffset()).Find(&list).Error
+	return list, err
+}
+
+func (r *TaskChangeRequest) Total(params CommonMap) (int64, error) {
+	var count int64
+	query := Db.Model(&TaskChangeRequest{})
+	r.parseWhere(query, params)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (r *TaskChangeRequest) parseWhere(query *gorm.DB, params CommonMap) {
+	if status, ok := params["Status"]; ok && status.(string) != "" {
+		query.Where("status = ?", status)
+	}
+	if taskId, ok := params["TaskId"]; ok && taskId.(int) > 0 {
+		query.Where("task_id = ?", taskId)
+	}
+}
+
+// Masked 隐藏申请中任务定义的凭据，用于接口返回；数据库中保存原值，审批通过后才能应用
+func (r TaskChangeRequest) Masked() TaskChangeRequest {
+	if r.Base != nil {
+		base := r.Base.Masked()
+		r.Base = &base
+	}
+	if r.Definition != nil {
+		definition := r.Definition.Masked()
+		r.Definition = &definition
+	}
+	return r
+}
+
+// Changes 返回申请相对提交时定义的变更
+func (r TaskChangeRequest) Changes() []TaskFieldChange {
+	if r.Base == nil || r.Definition == nil {
+		return []TaskFieldChange{}
+	}
+	return r.Base.Diff(*r.Definition)
+}
+
+// Approve 审批通过。修改和回滚申请在同一事务中应用到任务并把旧定义保存为新版本；
+// 手动执行、启停和删除申请只更新状态，由调用方执行对应操作。
+func (r *TaskChangeRequest) Approve(reviewer, comment string) error {
+	if r.Author == reviewer {
+		return ErrChangeRequestSelfApproval
+	}
+
+	return Db.Transaction(func(tx *gorm.DB) error {
+		if err := r.finish(tx, ChangeRequestApproved, reviewer, comment); err != nil {
+			return err
+		}
+		if r.Type != ChangeRequestUpdate && r.Type != ChangeRequestRollback || r.Definition == nil {
+			return nil
+		}
+		if r.Definition.HasMasked() {
+			return ErrRollbackMasked
+		}
+
+		current := Task{}
+		if err := tx.Where("id = ?", r.TaskId).First(&current).Error; err != nil {
+			return err
+		}
+		hosts := make([]TaskHost, 0)
+		if err := tx.Where("task_id = ?", r.TaskId).Find(&hosts).Error; err != nil {
+			return err
+		}
+		for _, host := range hosts {
+			current.Hosts = append(current.Hosts, TaskHostDetail{TaskHost: host})
+		}
+		if err := tx.Where("task_id = ?", r.TaskId).Order("id").Find(&current.Notifications).Error; err != nil {
+			return err
+		}
+		currentDef := NewTaskDefinition(current)
+		if r.Base != nil && !currentDef.Equal(*r.Base) {
+			return ErrChangeRequestStale
+		}
+		if _, err := RecordTaskVersion(tx, r.TaskId, currentDef, reviewer, fmt.Sprintf("approved change request #%d", r.Id)); err != nil {
+			return err
+		}
+
+		return applyTaskDefinition(tx, current, *r.Definition)
+	})
+}
+
+// Reject 驳回申请
+func (r *TaskChangeRequest) Reject(reviewer, comment string) error {
+	if r.Author == reviewer {
+		return ErrChangeRequestSelfApproval
+	}
+	return r.finish(Db, ChangeRequestRejected, reviewer, comment)
+}
+
+// Cancel 作者撤回申请
+func (r *TaskChangeRequest) Cancel(username string) error {
+	if r.Author != username {
+		return ErrChangeRequestNotAuthor
+	}
+	return r.finish(Db, ChangeRequestCancelled, username, "")
+}
+
+// finish 仅当申请仍处于待审批状态时更新，避免重复审批
+func (r *TaskChangeRequest) finish(tx *gorm.DB, status, reviewer, comment string) error {
+	now := time.Now()
+	result := tx.Model(&TaskChangeRequest{}).
+		Where("id = ? AND status = ?", r.Id, ChangeRequestPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewer":    reviewer,
+			"comment":     comment,
+			"reviewed_at": &now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrChangeRequestNotPending
+	}
+	r.Status = status
+	r.Reviewer = reviewer
+	r.Comment = comment
+	r.ReviewedAt = &now
+
+	return nil
+}
+
+// IsProtectedTag 判断任务标签中是否包含受保护标签
+func IsProtectedTag(tag string, protectedTags []string) bool {
+	if len(protectedTags) == 0 || tag == "" {
+		return false
+	}
+	for _, item := range strings.Split(tag, ",") {
+		item = strings.TrimSpace(item)
+		for _, protected := range protectedTags {
+			if item != "" && strings.EqualFold(item, protected) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsProtectedTag(t *testing.T) {
+	protected := []string{"prod", "Billing"}
+	cases := map[string]bool{
+		"":              false,
+		"prod":          true,
+		"dev, PROD":     true,
+		"billing":       true,
+		"production":    false,
+		"dev,staging":   false,
+		" prod , other": true,
+	}
+	for tag, want := range cases {
+		if got := IsProtectedTag(tag, protected); got != want {
+			t.Errorf("IsProtectedTag(%q) = %v, want %v", tag, got, want)
+		}
+	}
+	if IsProtectedTag("prod", nil) {
+		t.Error("no protected tags configured should disable the gate")
+	}
+}
+
+func TestSetting_ProtectedTags(t *testing.T) {
+	cleanup := setupDefinitionTestDB(t)
+	defer cleanup()
+	if err := Db.AutoMigrate(&Setting{}); err != nil {
+		t.Fatal(err)
+	}
+
+	setting := new(Setting)
+	if len(setting.GetProtectedTags()) != 0 {
+		t.Fatal("expected no protected tags by default")
+	}
+	if err := setting.UpdateProtectedTags([]string{" prod ", "", "billing"}); err != nil {
+		t.Fatal(err)
+	}
+	tags := setting.GetProtectedTags()
+	if len(tags) != 2 || tags[0] != "prod" || tags[1] != "billing" {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+}
+
+func createChangeRequest(t *testing.T, task Task, mutate func(*TaskDefinition)) *TaskChangeRequest {
+	t.Helper()
+	base := NewTaskDefinition(task)
+	target := NewTaskDefinition(task)
+	mutate(&target)
+	request := &TaskChangeRequest{
+		TaskId:     task.Id,
+		TaskName:   task.Name,
+		Type:       ChangeRequestUpdate,
+		Base:       &base,
+		Definition: &target,
+		Author:     "alice",
+	}
+	if _, err := request.Create(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := request.Detail(request.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &loaded
+}
+
+func TestTaskChangeRequest_Approve(t *testing.T) {
+	cleanup := setupDefinitionTestDB(t)
+	defer cleanup()
+	if err := Db.AutoMigrate(&TaskChangeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	h1 := &Host{Name: "h1", Port: 5921}
+	h2 := &Host{Name: "h2", Port: 5921}
+	_, _ = h1.Create()
+	_, _ = h2.Create()
+	task := createDefinitionTestTask(t, h1.Id)
+
+	request := createChangeRequest(t, task, func(d *TaskDefinition) {
+		d.Spec = "0 0 4 * * *"
+		d.HostIds = []int{h2.Id}
+	})
+	if request.Status != ChangeRequestPending || len(request.Changes()) != 2 {
+		t.Fatalf("unexpected pending request: %+v changes=%+v", request, request.Changes())
+	}
+
+	// 提交后任务未变化
+	unchanged, _ := task.Detail(task.Id)
+	if unchanged.Spec != task.Spec {
+		t.Fatal("pending change must not be applied")
+	}
+
+	if err := request.Approve("alice", ""); !errors.Is(err, ErrChangeRequestSelfApproval) {
+		t.Fatalf("expected ErrChangeRequestSelfApproval, got %v", err)
+	}
+	if err := request.Approve("bob", "lgtm"); err != nil {
+		t.Fatalf("approve failed: %v", err)
+	}
+
+	applied, _ := task.Detail(task.Id)
+	def := NewTaskDefinition(applied)
+	if def.Spec != "0 0 4 * * *" || len(def.HostIds) != 1 || def.HostIds[0] != h2.Id {
+		t.Fatalf("change not applied: %+v", def)
+	}
+	saved := TaskScriptVersion{}
+	Db.Where("task_id = ?", task.Id).First(&saved)
+	if snapshot, _ := saved.Snapshot(); snapshot.Spec != task.Spec || saved.Username != "bob" {
+		t.Fatalf("previous definition should be saved as a version: %+v", saved)
+	}
+
+	reloaded, _ := request.Detail(request.Id)
+	if reloaded.Status != ChangeRequestApproved || reloaded.Reviewer != "bob" || reloaded.Comment != "lgtm" || reloaded.ReviewedAt == nil {
+		t.Fatalf("unexpected approved request: %+v", reloaded)
+	}
+	if err := reloaded.Approve("carol", ""); !errors.Is(err, ErrChangeRequestNotPending) {
+		t.Fatalf("expected ErrChangeRequestNotPending, got %v", err)
+	}
+
+	// 带通知规则的任务：规则参与冲突检测，保存的版本和应用后的任务都保留规则
+	rules := []TaskNotification{{Channel: NotifyChannelSlack, ReceiverIds: "1", Trigger: NotifyAlways}}
+	if err := SaveTaskNotifications(task.Id, rules); err != nil {
+		t.Fatal(err)
+	}
+	withRules, _ := task.Detail(task.Id)
+	request = createChangeRequest(t, withRules, func(d *TaskDefinition) { d.Timeout = 5 })
+	if err := request.Approve("bob", ""); err != nil {
+		t.Fatalf("task with notification rules should not be stale: %v", err)
+	}
+	applied, _ = task.Detail(task.Id)
+	if applied.Timeout != 5 || len(applied.Notifications) != 1 || applied.Notifications[0].ReceiverIds != "1" {
+		t.Fatalf("change not applied or rules lost: %+v", applied)
+	}
+	latest := TaskScriptVersion{}
+	Db.Where("task_id = ?", task.Id).Order("version DESC").First(&latest)
+	if snapshot, _ := latest.Snapshot(); len(snapshot.Notifications) != 1 {
+		t.Fatalf("saved version should keep the notification rules: %+v", snapshot)
+	}
+}
+
+func TestTaskChangeRequest_StaleRejectCancel(t *testing.T) {
+	cleanup := setupDefinitionTestDB(t)
+	defer cleanup()
+	if err := Db.AutoMigrate(&TaskChangeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	task := createDefinitionTestTask(t)
+	stale := createChangeRequest(t, task, func(d *TaskDefinition) { d.Timeout = 5 })
+
+	// 提交后任务被修改，审批失败且状态保持待审批
+	task.Command = "changed.sh"
+	_, _ = task.UpdateBean(task.Id)
+	if err := stale.Approve("bob", ""); !errors.Is(err, ErrChangeRequestStale) {
+		t.Fatalf("expected ErrChangeRequestStale, got %v", err)
+	}
+	reloaded, _ := stale.Detail(stale.Id)
+	if reloaded.Status != ChangeRequestPending {
+		t.Fatalf("failed approval must roll back status, got %s", reloaded.Status)
+	}
+
+	if err := reloaded.Reject("alice", ""); !errors.Is(err, ErrChangeRequestSelfApproval) {
+		t.Fatalf("expected ErrChangeRequestSelfApproval, got %v", err)
+	}
+	if err := reloaded.Reject("bob", "outdated"); err != nil {
+		t.Fatal(err)
+	}
+
+	current, _ := task.Detail(task.Id)
+	other := createChangeRequest(t, current, func(d *TaskDefinition) { d.Timeout = 5 })
+	if err := other.Cancel("bob"); !errors.Is(err, ErrChangeRequestNotAuthor) {
+		t.Fatalf("expected ErrChangeRequestNotAuthor, got %v", err)
+	}
+	if err := other.Cancel("alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	total, _ := new(TaskChangeRequest).Total(CommonMap{"Status": ChangeRequestPending})
+	if total != 0 {
+		t.Fatalf("expected no pending requests, got %d", total)
+	}
+	list, _ := new(TaskChangeRequest).List(CommonMap{"TaskId": task.Id, "Page": 1, "PageSize": 10})
+	if len(list) != 2 || list[0].Status != ChangeRequestCancelled || list[1].Status != ChangeRequestRejected {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+}
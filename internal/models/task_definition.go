@@ -0,0 +1,444 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+	"gorm.io/gorm"
+)
+
+// ErrRollbackHostsMissing 回滚版本绑定的主机均已删除
+var ErrRollbackHostsMissing = errors.New("hosts of the version no longer exist")
+
+// ErrRollbackNameExists 回滚版本的任务名已被其他任务使用
+var ErrRollbackNameExists = errors.New("task name of the version is used by another task")
+
+// ErrRollbackMasked 版本中被隐藏的凭据无法用当前任务的值还原
+var ErrRollbackMasked = errors.New("masked credentials of the version cannot be restored from the current task")
+
+// TaskDefinition 任务定义快照，包含主机绑定和依赖，用于版本历史、对比和回滚。
+// 任务级别(Level)创建后不可修改，不在快照中。
+type TaskDefinition struct {
+	Name                string               `json:"name"`
+	Spec                string               `json:"spec"`
+	Protocol            TaskProtocol         `json:"protocol"`
+	Command             string               `json:"command"`
+	HttpMethod          TaskHTTPMethod       `json:"http_method"`
+	HttpBody            string               `json:"http_body"`
+	HttpHeaders         string               `json:"http_headers"`
+	HttpBodyType        string               `json:"http_body_type,omitempty"`
+	HttpContentType     string               `json:"http_content_type,omitempty"`
+	SuccessPattern      string               `json:"success_pattern"`
+	HttpSuccessCodes    string               `json:"http_success_codes,omitempty"`
+	HttpAssertions      string               `json:"http_assertions,omitempty"`
+	HttpMaxResponseTime int                  `json:"http_max_response_time,omitempty"`
+	HttpAuthType        string               `json:"http_auth_type,omitempty"`
+	HttpAuth            string               `json:"http_auth,omitempty"`
+	HttpProfileId       int                  `json:"http_profile_id,omitempty"`
+	HttpAsyncMode       string               `json:"http_async_mode,omitempty"`
+	HttpAsync           string               `json:"http_async,omitempty"`
+	HttpMaxBodySize     int                  `json:"http_max_body_size,omitempty"`
+	SqlDataSourceId     int                  `json:"sql_data_source_id,omitempty"`
+	SqlTransaction      bool                 `json:"sql_transaction,omitempty"`
+	SqlMaxRows          int                  `json:"sql_max_rows,omitempty"`
+	SqlAssertions       string               `json:"sql_assertions,omitempty"`
+	GrpcMethod          string               `json:"grpc_method,omitempty"`
+	GrpcRequest         string               `json:"grpc_request,omitempty"`
+	GrpcMetadata        string               `json:"grpc_metadata,omitempty"`
+	GrpcTls             bool                 `json:"grpc_tls,omitempty"`
+	GrpcServerName      string               `json:"grpc_server_name,omitempty"`
+	GrpcDescriptorId    int                  `json:"grpc_descriptor_id,omitempty"`
+	Timeout             int                  `json:"timeout"`
+	Multi               int8                 `json:"multi"`
+	RetryTimes          int8                 `json:"retry_times"`
+	RetryInterval       int16                `json:"retry_interval"`
+	HostIds             []int                `json:"host_ids"`
+	DependencyTaskId    string               `json:"dependency_task_id"`
+	DependencyStatus    TaskDependencyStatus `json:"dependency_status"`
+	Notifications       []TaskNotification   `json:"notifications"`
+	Tag                 string               `json:"tag"`
+	LogRetentionDays    int                  `json:"log_retention_days"`
+	SlaMaxDuration      int                  `json:"sla_max_duration"`
+	SlaSuccessInterval  int                  `json:"sla_success_interval"`
+	HeartbeatGrace      int                  `json:"heartbeat_grace"`
+	Remark              string               `json:"remark"`
+}
+
+// TaskFieldChange 字段级变更
+type TaskFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// NewTaskDefinition 从任务生成定义快照，主机取自 task.Hosts
+func NewTaskDefinition(task Task) TaskDefinition {
+	hostIds := make([]int, 0, len(task.Hosts))
+	for _, host := range task.Hosts {
+		hostIds = append(hostIds, host.HostId)
+	}
+
+	return NewTaskDefinitionWithHosts(task, hostIds)
+}
+
+// NewTaskDefinitionWithHosts 从任务和指定的主机 ID 生成定义快照
+func NewTaskDefinitionWithHosts(task Task, hostIds []int) TaskDefinition {
+	hosts := make([]int, 0, len(hostIds))
+	for _, id := range hostIds {
+		if id > 0 {
+			hosts = append(hosts, id)
+		}
+	}
+	sort.Ints(hosts)
+
+	return TaskDefinition{
+		Name:                task.Name,
+		Spec:                task.Spec,
+		Protocol:            task.Protocol,
+		Command:             task.Command,
+		HttpMethod:          task.HttpMethod,
+		HttpBody:            task.HttpBody,
+		HttpHeaders:         task.HttpHeaders,
+		HttpBodyType:        task.HttpBodyType,
+		HttpContentType:     task.HttpContentType,
+		SuccessPattern:      task.SuccessPattern,
+		HttpSuccessCodes:    task.HttpSuccessCodes,
+		HttpAssertions:      task.HttpAssertions,
+		HttpMaxResponseTime: task.HttpMaxResponseTime,
+		HttpAuthType:        task.HttpAuthType,
+		HttpAuth:            task.HttpAuth,
+		HttpProfileId:       task.HttpProfileId,
+		HttpAsyncMode:       task.HttpAsyncMode,
+		HttpAsync:           task.HttpAsync,
+		HttpMaxBodySize:     task.HttpMaxBodySize,
+		SqlDataSourceId:     task.SqlDataSourceId,
+		SqlTransaction:      task.SqlTransaction,
+		SqlMaxRows:          task.SqlMaxRows,
+		SqlAssertions:       task.SqlAssertions,
+		GrpcMethod:          task.GrpcMethod,
+		GrpcRequest:         task.GrpcRequest,
+		GrpcMetadata:        task.GrpcMetadata,
+		GrpcTls:             task.GrpcTls,
+		GrpcServerName:      task.GrpcServerName,
+		GrpcDescriptorId:    task.GrpcDescriptorId,
+		Timeout:             task.Timeout,
+		Multi:               task.Multi,
+		RetryTimes:          task.RetryTimes,
+		RetryInterval:       task.RetryInterval,
+		HostIds:             hosts,
+		DependencyTaskId:    task.DependencyTaskId,
+		DependencyStatus:    task.DependencyStatus,
+		Notifications:       portableNotifications(task.Notifications),
+		Tag:                 task.Tag,
+		LogRetentionDays:    task.LogRetentionDays,
+		SlaMaxDuration:      task.SlaMaxDuration,
+		SlaSuccessInterval:  task.SlaSuccessInterval,
+		HeartbeatGrace:      task.HeartbeatGrace,
+		Remark:              task.Remark,
+	}
+}
+
+// portableNotifications 去掉规则的 Id 和 TaskId，快照只关心规则内容
+func portableNotifications(rules []TaskNotification) []TaskNotification {
+	result := make([]TaskNotification, len(rules))
+	for i, rule := range rules {
+		rule.Id = 0
+		rule.TaskId = 0
+		result[i] = rule
+	}
+	return result
+}
+
+// ApplyTo 把快照写回任务字段（不含主机绑定）
+func (d TaskDefinition) ApplyTo(task *Task) {
+	task.Name = d.Name
+	task.Spec = d.Spec
+	task.Protocol = d.Protocol
+	task.Command = d.Command
+	task.HttpMethod = d.HttpMethod
+	task.HttpBody = d.HttpBody
+	task.HttpHeaders = d.HttpHeaders
+	task.HttpBodyType = d.HttpBodyType
+	task.HttpContentType = d.HttpContentType
+	task.SuccessPattern = d.SuccessPattern
+	task.HttpSuccessCodes = d.HttpSuccessCodes
+	task.HttpAssertions = d.HttpAssertions
+	task.HttpMaxResponseTime = d.HttpMaxResponseTime
+	task.HttpAuthType = d.HttpAuthType
+	task.HttpAuth = d.HttpAuth
+	task.HttpProfileId = d.HttpProfileId
+	task.HttpAsyncMode = d.HttpAsyncMode
+	task.HttpAsync = d.HttpAsync
+	task.HttpMaxBodySize = d.HttpMaxBodySize
+	task.SqlDataSourceId = d.SqlDataSourceId
+	task.SqlTransaction = d.SqlTransaction
+	task.SqlMaxRows = d.SqlMaxRows
+	task.SqlAssertions = d.SqlAssertions
+	task.GrpcMethod = d.GrpcMethod
+	task.GrpcRequest = d.GrpcRequest
+	task.GrpcMetadata = d.GrpcMetadata
+	task.GrpcTls = d.GrpcTls
+	task.GrpcServerName = d.GrpcServerName
+	task.GrpcDescriptorId = d.GrpcDescriptorId
+	task.Timeout = d.Timeout
+	task.Multi = d.Multi
+	task.RetryTimes = d.RetryTimes
+	task.RetryInterval = d.RetryInterval
+	task.DependencyTaskId = d.DependencyTaskId
+	task.DependencyStatus = d.DependencyStatus
+	task.Notifications = portableNotifications(d.Notifications)
+	task.Tag = d.Tag
+	task.LogRetentionDays = d.LogRetentionDays
+	task.SlaMaxDuration = d.SlaMaxDuration
+	task.SlaSuccessInterval = d.SlaSuccessInterval
+	task.HeartbeatGrace = d.HeartbeatGrace
+	task.Remark = d.Remark
+}
+
+// Masked 隐藏 Header、gRPC 元数据和 HTTP 任务地址中的凭据，用于版本快照、审计和变更对比。
+// HTTP 认证配置只引用密钥名称，不需要隐藏
+func (d TaskDefinition) Masked() TaskDefinition {
+	d.HttpHeaders = httpclient.MaskHeaders(d.HttpHeaders)
+	d.GrpcMetadata = httpclient.MaskHeaders(d.GrpcMetadata)
+	if d.Protocol == TaskHTTP {
+		d.Command = httpclient.MaskURL(d.Command)
+	}
+	return d
+}
+
+// Unmask 用 current 中的值还原被隐藏的凭据，回滚到脱敏的快照时使用。
+// current 中没有对应的 Header 或查询参数时保留隐藏值，应用前用 HasMasked 检查
+func (d TaskDefinition) Unmask(current TaskDefinition) TaskDefinition {
+	d.HttpHeaders = httpclient.UnmaskHeaders(d.HttpHeaders, current.HttpHeaders)
+	d.GrpcMetadata = httpclient.UnmaskHeaders(d.GrpcMetadata, current.GrpcMetadata)
+	if d.Protocol == TaskHTTP {
+		d.Command = httpclient.UnmaskURL(d.Command, current.Command)
+	}
+	return d
+}
+
+// HasMasked 定义中是否仍有未还原的隐藏凭据，这样的定义不能应用到任务
+func (d TaskDefinition) HasMasked() bool {
+	if strings.Contains(d.HttpHeaders, httpclient.MaskedValue) || strings.Contains(d.GrpcMetadata, httpclient.MaskedValue) {
+		return true
+	}
+	return d.Protocol == TaskHTTP && strings.Contains(d.Command, httpclient.MaskedValue)
+}
+
+// Diff 返回从 d 到 newer 的字段级变更，字段名与 JSON 字段一致。
+// 按原值比较，变更中的值隐藏了凭据
+func (d TaskDefinition) Diff(newer TaskDefinition) []TaskFieldChange {
+	changes := make([]TaskFieldChange, 0)
+	oldValue := reflect.ValueOf(d)
+	newValue := reflect.ValueOf(newer)
+	oldMasked := reflect.ValueOf(d.Masked())
+	newMasked := reflect.ValueOf(newer.Masked())
+	typ := oldValue.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if formatDefinitionValue(oldValue.Field(i)) != formatDefinitionValue(newValue.Field(i)) {
+			changes = append(changes, TaskFieldChange{
+				Field: field,
+				Old:   formatDefinitionValue(oldMasked.Field(i)),
+				New:   formatDefinitionValue(newMasked.Field(i)),
+			})
+		}
+	}
+
+	return changes
+}
+
+// Equal 判断两个快照是否相同
+func (d TaskDefinition) Equal(other TaskDefinition) bool {
+	return len(d.Diff(other)) == 0
+}
+
+func formatDefinitionValue(v reflect.Value) string {
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		// 通知规则等结构体本身含逗号，用分号分隔
+		if v.Type().Elem().Kind() == reflect.Struct {
+			return strings.Join(items, "; ")
+		}
+		return strings.Join(items, ",")
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// FormatTaskChanges 把变更格式化为审计日志文本，每行 "field: old → new"
+func FormatTaskChanges(changes []TaskFieldChange) string {
+	var b strings.Builder
+	for i, ch := range changes {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(ch.Field)
+		b.WriteString(": ")
+		b.WriteString(ch.Old)
+		b.WriteString(" → ")
+		b.WriteString(ch.New)
+	}
+
+	return b.String()
+}
+
+func (d TaskDefinition) Value() (driver.Value, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (d *TaskDefinition) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported task definition type: %T", value)
+	}
+
+	// 早期快照保存的是单一通知配置，读取时转换为通知规则
+	var snapshot struct {
+		TaskDefinition
+		NotifyStatus     int8   `json:"notify_status"`
+		NotifyType       int8   `json:"notify_type"`
+		NotifyReceiverId string `json:"notify_receiver_id"`
+		NotifyKeyword    string `json:"notify_keyword"`
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+	*d = snapshot.TaskDefinition
+	if d.Notifications == nil {
+		d.Notifications = LegacyTaskNotifications(snapshot.NotifyStatus, snapshot.NotifyType, snapshot.NotifyReceiverId, snapshot.NotifyKeyword)
+	}
+
+	return nil
+}
+
+// RecordTaskVersion 在事务中把任务定义保存为新版本
+func RecordTaskVersion(tx *gorm.DB, taskId int, definition TaskDefinition, username, remark string) (TaskScriptVersion, error) {
+	var latest TaskScriptVersion
+	err := tx.Where("task_id = ?", taskId).Order("version DESC").Limit(1).Find(&latest).Error
+	if err != nil {
+		return latest, err
+	}
+	// 快照不保存凭据，回滚时用当前任务的值还原
+	definition = definition.Masked()
+	version := TaskScriptVersion{
+		TaskId:     taskId,
+		Command:    definition.Command,
+		Definition: &definition,
+		Remark:     remark,
+		Username:   username,
+		Version:    latest.Version + 1,
+	}
+	err = tx.Create(&version).Error
+
+	return version, err
+}
+
+// RollbackTarget 返回回滚到版本后的任务定义；旧版本只记录了命令时在当前定义上替换命令，
+// complete 为 false
+func RollbackTarget(current Task, version TaskScriptVersion) (TaskDefinition, bool) {
+	target, complete := version.Snapshot()
+	if !complete {
+		target = NewTaskDefinition(current)
+		target.Command = version.Command
+	}
+
+	return target.Unmask(NewTaskDefinition(current)), complete
+}
+
+// RollbackTask 把任务恢复到版本保存的完整定义（含主机绑定和依赖）。
+// 回滚前的当前定义会先保存为新版本；旧版本只记录了命令时仅回滚命令。
+// 返回从当前定义到回滚后定义的变更。
+func RollbackTask(current Task, version TaskScriptVersion, username string) ([]TaskFieldChange, error) {
+	currentDef := NewTaskDefinition(current)
+	target, complete := RollbackTarget(current, version)
+	if target.HasMasked() {
+		return nil, ErrRollbackMasked
+	}
+	changes := currentDef.Diff(target)
+	if len(changes) == 0 {
+		return changes, nil
+	}
+
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		if _, err := RecordTaskVersion(tx, current.Id, currentDef, username, "auto-save before rollback"); err != nil {
+			return err
+		}
+		if !complete {
+			return tx.Model(&Task{}).Where("id = ?", current.Id).UpdateColumn("command", target.Command).Error
+		}
+
+		return applyTaskDefinition(tx, current, target)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// applyTaskDefinition 在事务中把完整定义写回任务并替换主机绑定
+func applyTaskDefinition(tx *gorm.DB, current Task, target TaskDefinition) error {
+	// 与 Task.NameExist 一致：只与已启用的任务冲突
+	var count int64
+	err := tx.Model(&Task{}).Where("name = ? AND status = ? AND id != ?", target.Name, Enabled, current.Id).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRollbackNameExists
+	}
+
+	hostIds := make([]int, 0, len(target.HostIds))
+	if target.Protocol.UsesHosts() && len(target.HostIds) > 0 {
+		if err := tx.Model(&Host{}).Where("id IN ?", target.HostIds).Order("id").Pluck("id", &hostIds).Error; err != nil {
+			return err
+		}
+		if len(hostIds) == 0 {
+			return ErrRollbackHostsMissing
+		}
+	}
+
+	task := current
+	target.ApplyTo(&task)
+	if _, err := task.updateWith(tx, current.Id); err != nil {
+		return err
+	}
+	if err := replaceTaskNotifications(tx, current.Id, target.Notifications); err != nil {
+		return err
+	}
+	if err := tx.Where("task_id = ?", current.Id).Delete(&TaskHost{}).Error; err != nil {
+		return err
+	}
+	if len(hostIds) == 0 {
+		return nil
+	}
+	taskHosts := make([]TaskHost, len(hostIds))
+	for i, hostId := range hostIds {
+		taskHosts[i] = TaskHost{TaskId: current.Id, HostId: hostId}
+	}
+	return tx.Create(&taskHosts).Error
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// TaskHeartbeatCheck 心跳任务待检查的调度，调度时间到达时写入，
+// 由 leader 上的检查任务在 Deadline 之后检查并删除，重启或切换 leader 后不会丢失
+type TaskHeartbeatCheck struct {
+	Id          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId      int       `json:"task_id" gorm:"not null;uniqueIndex:idx_task_heartbeat_check"`
+	ScheduledAt time.Time `json:"scheduled_at" gorm:"not null;uniqueIndex:idx_task_heartbeat_check"`
+	// Deadline 调度时间加宽限时间
+	Deadline time.Time `json:"deadline" gorm:"not null;index"`
+}
+
+// Create 写入待检查的调度，同一任务同一调度时间只写入一次
+func (check *TaskHeartbeatCheck) Create() error {
+	return Db.Clauses(clause.OnConflict{DoNothing: true}).Create(check).Error
+}
+
+// DueHeartbeatChecks 截止时间不晚于 t 的检查，按截止时间排序
+func DueHeartbeatChecks(t time.Time) ([]TaskHeartbeatCheck, error) {
+	list := make([]TaskHeartbeatCheck, 0)
+	err := Db.Where("deadline <= ?", t).Order("deadline ASC, id ASC").Find(&list).Error
+
+	return list, err
+}
+
+// Claim 删除检查记录，返回 false 表示已被其他检查处理
+func (check TaskHeartbeatCheck) Claim() (bool, error) {
+	result := Db.Delete(&TaskHeartbeatCheck{}, check.Id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
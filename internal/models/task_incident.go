@@ -0,0 +1,119 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 告警事件状态
+const (
+	IncidentOpen     = "open"
+	IncidentResolved = "resolved"
+)
+
+// TaskIncident 任务在告警平台（PagerDuty、Opsgenie）上的事件状态，每个任务、渠道、接收服务一条记录。
+// 任务失败时打开事件，下一次执行成功时自动关闭；投递前会核对状态，避免重试晚到的触发请求重新打开已关闭的事件。
+type TaskIncident struct {
+	Id       int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId   int           `json:"task_id" gorm:"not null;uniqueIndex:idx_task_incident"`
+	TaskName string        `json:"task_name" gorm:"type:varchar(32);not null;default:''"`
+	Channel  NotifyChannel `json:"channel" gorm:"not null;uniqueIndex:idx_task_incident"`
+	// ServiceId 接收服务的配置ID
+	ServiceId int `json:"service_id" gorm:"not null;uniqueIndex:idx_task_incident"`
+	// DedupKey 告警平台的去重键，同一任务固定不变
+	DedupKey     string     `json:"dedup_key" gorm:"type:varchar(128);not null;default:''"`
+	Status       string     `json:"status" gorm:"type:varchar(16);not null;index"`
+	TriggerCount int        `json:"trigger_count" gorm:"not null;default:0"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ResolvedAt   *time.Time `json:"resolved_at" gorm:"default:null"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	BaseModel    `json:"-" gorm:"-"`
+}
+
+// IncidentDedupKey 任务的告警去重键
+func IncidentDedupKey(taskId int) string {
+	return fmt.Sprintf("gocron-task-%d", taskId)
+}
+
+// OpenIncident 打开事件，已打开时累加触发次数
+func OpenIncident(taskId int, taskName string, channel NotifyChannel, serviceId int) error {
+	return Db.Transaction(func(tx *gorm.DB) error {
+		var incident TaskIncident
+		err := tx.Where("task_id = ? AND channel = ? AND service_id = ?", taskId, channel, serviceId).First(&incident).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&TaskIncident{
+				TaskId:       taskId,
+				TaskName:     taskName,
+				Channel:      channel,
+				ServiceId:    serviceId,
+				DedupKey:     IncidentDedupKey(taskId),
+				Status:       IncidentOpen,
+				TriggerCount: 1,
+				OpenedAt:     time.Now(),
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"task_name":     taskName,
+			"trigger_count": gorm.Expr("trigger_count + 1"),
+		}
+		if incident.Status != IncidentOpen {
+			updates["status"] = IncidentOpen
+			updates["trigger_count"] = 1
+			updates["opened_at"] = time.Now()
+			updates["resolved_at"] = nil
+		}
+		return tx.Model(&TaskIncident{}).Where("id = ?", incident.Id).Updates(updates).Error
+	})
+}
+
+// ResolveIncident 关闭打开中的事件，返回事件此前是否处于打开状态
+func ResolveIncident(taskId int, channel NotifyChannel, serviceId int) (bool, error) {
+	result := Db.Model(&TaskIncident{}).
+		Where("task_id = ? AND channel = ? AND service_id = ? AND status = ?", taskId, channel, serviceId, IncidentOpen).
+		Updates(map[string]interface{}{"status": IncidentResolved, "resolved_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// IncidentIsOpen 判断事件当前是否处于打开状态
+func IncidentIsOpen(taskId int, channel NotifyChannel, serviceId int) (bool, error) {
+	var count int64
+	err := Db.Model(&TaskIncident{}).
+		Where("task_id = ? AND channel = ? AND service_id = ? AND status = ?", taskId, channel, serviceId, IncidentOpen).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (i *TaskIncident) List(params CommonMap) ([]TaskIncident, error) {
+	i.parsePageAndPageSize(params)
+	list := make([]TaskIncident, 0)
+	query := Db.Model(&TaskIncident{})
+	i.parseWhere(query, params)
+	err := query.Order("updated_at DESC, id DESC").Limit(i.PageSize).Offset(i.pageLimitOffset()).Find(&list).Error
+	return list, err
+}
+
+func (i *TaskIncident) Total(params CommonMap) (int64, error) {
+	var count int64
+	query := Db.Model(&TaskIncident{})
+	i.parseWhere(query, params)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (i *TaskIncident) parseWhere(query *gorm.DB, params CommonMap) {
+	if status, ok := params["Status"]; ok && status.(string) != "" {
+		query.Where("status = ?", status)
+	}
+	if taskId, ok := params["TaskId"]; ok && taskId.(int) > 0 {
+		query.Where("task_id = ?", taskId)
+	}
+	if channel, ok := params["Channel"]; ok && channel.(int) >= 0 {
+		query.Where("channel = ?", channel)
+	}
+}
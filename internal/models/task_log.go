@@ -58,8 +58,10 @@ type TaskLog struct {
 	EndTime    LocalTime    `json:"end_time" gorm:"column:end_time;autoUpdateTime"`
 	Status     Status       `json:"status" gorm:"not null;index;default:1"`
 	Result     string       `json:"result" gorm:"not null"`
-	TotalTime  int          `json:"total_time" gorm:"-"`
-	BaseModel  `json:"-" gorm:"-"`
+	// HTTP 任务的请求详情（JSON），包含请求、响应 Header 和各阶段耗时，凭据已脱敏
+	HttpExchange string `json:"http_exchange" gorm:"type:text"`
+	TotalTime    int    `json:"total_time" gorm:"-"`
+	BaseModel    `json:"-" gorm:"-"`
 }
 
 func (taskLog *TaskLog) Create() (insertId int64, err error) {
@@ -150,6 +152,67 @@ func (taskLog *TaskLog) RemoveByDays(days int) (int64, error) {
 	return result.RowsAffected, result.Error
 }
 
+// ConsecutiveFailuresBefore 统计指定日志之前任务的连续失败次数，运行中和已取消的记录不计入
+func (taskLog *TaskLog) ConsecutiveFailuresBefore(taskId int, id int64) (int, error) {
+	var lastSuccessId int64
+	err := Db.Model(&TaskLog{}).Select("COALESCE(MAX(id), 0)").
+		Where("task_id = ? AND id < ? AND status = ?", taskId, id, Finish).
+		Scan(&lastSuccessId).Error
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = Db.Model(&TaskLog{}).
+		Where("task_id = ? AND id > ? AND id < ? AND status = ?", taskId, lastSuccessId, id, Failure).
+		Count(&count).Error
+
+	return int(count), err
+}
+
+// RunningBefore 任务中开始时间早于 t 且仍在运行的日志
+func (taskLog *TaskLog) RunningBefore(taskId int, t time.Time) ([]TaskLog, error) {
+	list := make([]TaskLog, 0)
+	err := Db.Select("id", "task_id", "hostname", "start_time").
+		Where("task_id = ? AND status = ? AND start_time < ?", taskId, Running, t).
+		Order("id ASC").Find(&list).Error
+
+	return list, err
+}
+
+// LastSuccess 任务最近一次执行成功的日志，没有时返回零值
+func (taskLog *TaskLog) LastSuccess(taskId int) (TaskLog, error) {
+	list := make([]TaskLog, 0, 1)
+	err := Db.Select("id", "task_id", "start_time", "end_time").
+		Where("task_id = ? AND status = ?", taskId, Finish).
+		Order("id DESC").Limit(1).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return TaskLog{}, err
+	}
+
+	return list[0], nil
+}
+
+// LatestRunning 任务最近一条运行中的日志，没有时返回零值
+func (taskLog *TaskLog) LatestRunning(taskId int) (TaskLog, error) {
+	list := make([]TaskLog, 0, 1)
+	err := Db.Select("id", "task_id", "start_time").
+		Where("task_id = ? AND status = ?", taskId, Running).
+		Order("id DESC").Limit(1).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return TaskLog{}, err
+	}
+
+	return list[0], nil
+}
+
+// StartedSince 任务在 t 之后是否有开始的执行
+func (taskLog *TaskLog) StartedSince(taskId int, t time.Time) (bool, error) {
+	var count int64
+	err := Db.Model(&TaskLog{}).Where("task_id = ? AND start_time >= ?", taskId, t).Count(&count).Error
+
+	return count > 0, err
+}
+
 // 删除N天前的日志，排除有自定义保留策略的任务
 func (taskLog *TaskLog) RemoveByDaysExcludingCustomRetention(days int) (int64, error) {
 	if days <= 0 {
@@ -195,6 +258,11 @@ func (taskLog *TaskLog) parseWhere(query *gorm.DB, params CommonMap) {
 	if len(params) == 0 {
 		return
 	}
+	// 通知中的日志链接按 ID 定位单条日志
+	id, ok := params["Id"]
+	if ok && id.(int64) > 0 {
+		query.Where("id = ?", id)
+	}
 	taskId, ok := params["TaskId"]
 	if ok && taskId.(int) > 0 {
 		query.Where("task_id = ?", taskId)
@@ -0,0 +1,317 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NotifyChannel 通知渠道
+type NotifyChannel int8
+
+const (
+	NotifyChannelMail      NotifyChannel = 0  // 邮件
+	NotifyChannelSlack     NotifyChannel = 1  // Slack
+	NotifyChannelWebhook   NotifyChannel = 2  // WebHook
+	NotifyChannelDingTalk  NotifyChannel = 3  // 钉钉群机器人
+	NotifyChannelWeCom     NotifyChannel = 4  // 企业微信群机器人
+	NotifyChannelFeishu    NotifyChannel = 5  // 飞书群机器人
+	NotifyChannelTelegram  NotifyChannel = 6  // Telegram
+	NotifyChannelDiscord   NotifyChannel = 7  // Discord
+	NotifyChannelTeams     NotifyChannel = 8  // Microsoft Teams
+	NotifyChannelPagerDuty NotifyChannel = 9  // PagerDuty
+	NotifyChannelOpsgenie  NotifyChannel = 10 // Opsgenie
+)
+
+// NotifyTrigger 通知触发条件
+type NotifyTrigger int8
+
+const (
+	NotifyOnFailure NotifyTrigger = 1 // 执行失败
+	NotifyAlways    NotifyTrigger = 2 // 总是
+	NotifyOnKeyword NotifyTrigger = 3 // 输出匹配关键字
+	// 以下根据上一次执行结果判断，只在状态变化时通知
+	NotifyOnFirstFailure NotifyTrigger = 4 // 首次失败（上一次执行成功后的第一次失败）
+	NotifyOnRecovery     NotifyTrigger = 5 // 恢复（失败后的第一次成功）
+	NotifyOnStateChange  NotifyTrigger = 6 // 首次失败和恢复
+)
+
+// Legacy 旧版 notify_status 可以表示的触发条件，模板的通知设置只支持这些
+func (t NotifyTrigger) Legacy() bool {
+	return t >= NotifyOnFailure && t <= NotifyOnKeyword
+}
+
+// 通知规则参数上限
+const (
+	maxNotifyRepeatEvery = 1000
+	maxNotifyMinInterval = 7 * 24 * 3600
+	maxNotifyTemplateLen = 2048
+)
+
+var notifyChannelNames = map[NotifyChannel]string{
+	NotifyChannelMail:      "mail",
+	NotifyChannelSlack:     "slack",
+	NotifyChannelWebhook:   "webhook",
+	NotifyChannelDingTalk:  "dingtalk",
+	NotifyChannelWeCom:     "wecom",
+	NotifyChannelFeishu:    "feishu",
+	NotifyChannelTelegram:  "telegram",
+	NotifyChannelDiscord:   "discord",
+	NotifyChannelTeams:     "teams",
+	NotifyChannelPagerDuty: "pagerduty",
+	NotifyChannelOpsgenie:  "opsgenie",
+}
+
+// IsIncident 告警平台渠道，任务失败时打开事件，恢复成功时自动关闭
+func (c NotifyChannel) IsIncident() bool {
+	return c == NotifyChannelPagerDuty || c == NotifyChannelOpsgenie
+}
+
+var notifyTriggerNames = map[NotifyTrigger]string{
+	NotifyOnFailure:      "failure",
+	NotifyAlways:         "always",
+	NotifyOnKeyword:      "keyword",
+	NotifyOnFirstFailure: "first-failure",
+	NotifyOnRecovery:     "recovery",
+	NotifyOnStateChange:  "state-change",
+}
+
+// TaskNotification 任务通知规则，一个任务可配置多条，分别发送到不同渠道
+type TaskNotification struct {
+	Id     int `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId int `json:"task_id" gorm:"not null;index"`
+	// Channel 0:邮件 1:Slack 2:WebHook 3:钉钉 4:企业微信 5:飞书 6:Telegram 7:Discord 8:Teams 9:PagerDuty 10:Opsgenie
+	Channel NotifyChannel `json:"channel" gorm:"not null;default:0"`
+	// ReceiverIds 接收者ID，逗号分隔，含义取决于渠道（邮件用户/Slack频道/WebHook地址/群机器人/聊天接收者/告警服务）
+	ReceiverIds string `json:"receiver_ids" gorm:"type:varchar(256);not null;default:''"`
+	// Trigger 1:执行失败 2:总是 3:关键字匹配
+	Trigger NotifyTrigger `json:"trigger" gorm:"not null;default:1"`
+	Keyword string        `json:"keyword" gorm:"type:varchar(128);not null;default:''"`
+	// RepeatEvery 持续失败时每隔 N 次失败再通知一次，0 表示只通知首次失败，仅用于首次失败和状态变化
+	RepeatEvery int `json:"repeat_every" gorm:"not null;default:0"`
+	// MinInterval 该规则两次通知的最小间隔（秒），0 表示不限制，恢复通知不受限制
+	MinInterval int `json:"min_interval" gorm:"not null;default:0"`
+	// Template 覆盖渠道的通知模板，为空时使用渠道模板，仅对使用模板的渠道（邮件/Slack/WebHook/群机器人）生效
+	Template string `json:"template" gorm:"type:varchar(2048);not null;default:''"`
+}
+
+// Validate 校验通知规则
+func (n TaskNotification) Validate() error {
+	if _, ok := notifyChannelNames[n.Channel]; !ok {
+		return fmt.Errorf("unknown notify channel %d", n.Channel)
+	}
+	if _, ok := notifyTriggerNames[n.Trigger]; !ok {
+		return fmt.Errorf("unknown notify trigger %d", n.Trigger)
+	}
+	if n.Trigger == NotifyOnKeyword && strings.TrimSpace(n.Keyword) == "" {
+		return errors.New("keyword is required for keyword trigger")
+	}
+	if n.RepeatEvery < 0 || n.RepeatEvery > maxNotifyRepeatEvery {
+		return fmt.Errorf("repeat_every must be between 0 and %d", maxNotifyRepeatEvery)
+	}
+	if n.MinInterval < 0 || n.MinInterval > maxNotifyMinInterval {
+		return fmt.Errorf("min_interval must be between 0 and %d seconds", maxNotifyMinInterval)
+	}
+	if len(n.Template) > maxNotifyTemplateLen {
+		return fmt.Errorf("template must be at most %d bytes", maxNotifyTemplateLen)
+	}
+	// WebHook 地址未选择时沿用旧逻辑，不强制要求接收者
+	if n.Channel != NotifyChannelWebhook && strings.Trim(n.ReceiverIds, ", ") == "" {
+		return fmt.Errorf("receivers are required for %s channel", notifyChannelNames[n.Channel])
+	}
+
+	return nil
+}
+
+// NeedsRunHistory 触发条件是否依赖之前的执行结果
+func (n TaskNotification) NeedsRunHistory() bool {
+	return n.Trigger == NotifyOnFirstFailure || n.Trigger == NotifyOnRecovery || n.Trigger == NotifyOnStateChange
+}
+
+// Matches 判断本次执行结果是否触发该规则，previousFailures 为本次执行之前的连续失败次数
+func (n TaskNotification) Matches(output string, failed bool, previousFailures int) bool {
+	switch n.Trigger {
+	case NotifyOnFailure:
+		return failed
+	case NotifyAlways:
+		return true
+	case NotifyOnKeyword:
+		return n.Keyword != "" && strings.Contains(output, n.Keyword)
+	case NotifyOnFirstFailure:
+		return failed && n.isFailureToNotify(previousFailures+1)
+	case NotifyOnRecovery:
+		return !failed && previousFailures > 0
+	case NotifyOnStateChange:
+		if failed {
+			return n.isFailureToNotify(previousFailures + 1)
+		}
+		return previousFailures > 0
+	}
+
+	return false
+}
+
+// MatchesSlaBreach 规则是否接收 SLA 违约通知：关注失败的规则都会收到，仅关键字和仅恢复的规则不接收
+func (n TaskNotification) MatchesSlaBreach() bool {
+	return n.Trigger != NotifyOnKeyword && n.Trigger != NotifyOnRecovery
+}
+
+// isFailureToNotify 第 streak 次连续失败是否需要通知：首次失败，之后每隔 RepeatEvery 次
+func (n TaskNotification) isFailureToNotify(streak int) bool {
+	if streak == 1 {
+		return true
+	}
+	return n.RepeatEvery > 0 && (streak-1)%n.RepeatEvery == 0
+}
+
+// String 用于版本对比和审计，例如 "slack[1,2] on failure"
+func (n TaskNotification) String() string {
+	s := fmt.Sprintf("%s[%s] on %s", notifyChannelNames[n.Channel], n.ReceiverIds, notifyTriggerNames[n.Trigger])
+	if n.Trigger == NotifyOnKeyword {
+		s += fmt.Sprintf("(%s)", n.Keyword)
+	}
+	if n.RepeatEvery > 0 {
+		s += fmt.Sprintf(", repeat every %d", n.RepeatEvery)
+	}
+	if n.MinInterval > 0 {
+		s += fmt.Sprintf(", min interval %ds", n.MinInterval)
+	}
+	if n.Template != "" {
+		s += ", custom template"
+	}
+	return s
+}
+
+// ParseTaskNotifications 解析表单提交的 JSON 规则列表
+func ParseTaskNotifications(data string) ([]TaskNotification, error) {
+	rules := make([]TaskNotification, 0)
+	if strings.TrimSpace(data) == "" {
+		return rules, nil
+	}
+	if err := json.Unmarshal([]byte(data), &rules); err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		rules[i].Id = 0
+		rules[i].TaskId = 0
+		rules[i].ReceiverIds = strings.Trim(rules[i].ReceiverIds, ", ")
+		if err := rules[i].Validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+
+	return rules, nil
+}
+
+// LegacyTaskNotifications 把旧版 notify_status/notify_type/notify_receiver_id/notify_keyword
+// 转换为通知规则，notify_status 为 0 时返回空列表
+func LegacyTaskNotifications(status, channel int8, receiverIds, keyword string) []TaskNotification {
+	rules := make([]TaskNotification, 0, 1)
+	if status <= 0 {
+		return rules
+	}
+
+	return append(rules, TaskNotification{
+		Channel:     NotifyChannel(channel),
+		ReceiverIds: receiverIds,
+		Trigger:     NotifyTrigger(status),
+		Keyword:     keyword,
+	})
+}
+
+// ListByTaskIds 批量查询多个任务的通知规则
+func (n *TaskNotification) ListByTaskIds(taskIds []int) (map[int][]TaskNotification, error) {
+	result := make(map[int][]TaskNotification)
+	if len(taskIds) == 0 {
+		return result, nil
+	}
+	list := make([]TaskNotification, 0)
+	err := Db.Where("task_id IN ?", taskIds).Order("id").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range list {
+		result[item.TaskId] = append(result[item.TaskId], item)
+	}
+
+	return result, nil
+}
+
+// Remove 删除任务的全部通知规则
+func (n *TaskNotification) Remove(taskId int) error {
+	return Db.Where("task_id = ?", taskId).Delete(&TaskNotification{}).Error
+}
+
+// replaceTaskNotifications 在事务中替换任务的通知规则
+func replaceTaskNotifications(tx *gorm.DB, taskId int, rules []TaskNotification) error {
+	if err := tx.Where("task_id = ?", taskId).Delete(&TaskNotification{}).Error; err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	rows := make([]TaskNotification, len(rules))
+	for i, rule := range rules {
+		rows[i] = rule
+		rows[i].Id = 0
+		rows[i].TaskId = taskId
+	}
+
+	return tx.Create(&rows).Error
+}
+
+// SaveTaskNotifications 替换任务的通知规则
+func SaveTaskNotifications(taskId int, rules []TaskNotification) error {
+	return Db.Transaction(func(tx *gorm.DB) error {
+		return replaceTaskNotifications(tx, taskId, rules)
+	})
+}
+
+// legacyTaskNotify 旧版 task 表中的通知字段，已由 TaskNotification 取代，仅用于升级迁移
+type legacyTaskNotify struct {
+	Id               int    `gorm:"primaryKey"`
+	NotifyStatus     int8   `gorm:"not null;default:0"`
+	NotifyType       int8   `gorm:"not null;default:0"`
+	NotifyReceiverId string `gorm:"type:varchar(256);not null;default:''"`
+	NotifyKeyword    string `gorm:"type:varchar(128);not null;default:''"`
+}
+
+func (legacyTaskNotify) TableName() string {
+	return TablePrefix + "task"
+}
+
+// migrateLegacyTaskNotifications 把 task 表中旧的通知配置转换为通知规则，
+// 转换后清零 notify_status，已有规则的任务跳过，可重复执行
+func migrateLegacyTaskNotifications(tx *gorm.DB) (int, error) {
+	if !tx.Migrator().HasColumn(&legacyTaskNotify{}, "notify_status") {
+		return 0, nil
+	}
+	rows := make([]legacyTaskNotify, 0)
+	if err := tx.Where("notify_status > 0").Find(&rows).Error; err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, row := range rows {
+		var count int64
+		if err := tx.Model(&TaskNotification{}).Where("task_id = ?", row.Id).Count(&count).Error; err != nil {
+			return migrated, err
+		}
+		if count > 0 {
+			continue
+		}
+		rules := LegacyTaskNotifications(row.NotifyStatus, row.NotifyType, row.NotifyReceiverId, row.NotifyKeyword)
+		if err := replaceTaskNotifications(tx, row.Id, rules); err != nil {
+			return migrated, err
+		}
+		if err := tx.Model(&legacyTaskNotify{}).Where("id = ?", row.Id).UpdateColumn("notify_status", 0).Error; err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
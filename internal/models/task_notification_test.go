@@ -0,0 +1,150 @@
+package models
+
+import "testing"
+
+func TestParseTaskNotifications(t *testing.T) {
+	rules, err := ParseTaskNotifications(`[
+		{"id": 9, "task_id": 3, "channel": 0, "receiver_ids": "1,2,", "trigger": 1},
+		{"channel": 2, "trigger": 3, "keyword": "ERROR"}
+	]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules[0].Id != 0 || rules[0].TaskId != 0 || rules[0].ReceiverIds != "1,2" {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+
+	invalid := []string{
+		`[{"channel": 99, "receiver_ids": "1", "trigger": 1}]`,
+		`[{"channel": 0, "receiver_ids": "1", "trigger": 0}]`,
+		`[{"channel": 1, "receiver_ids": "", "trigger": 1}]`,
+		`[{"channel": 2, "trigger": 3}]`,
+		`{"channel": 0}`,
+	}
+	for _, data := range invalid {
+		if _, err := ParseTaskNotifications(data); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+	if rules, err := ParseTaskNotifications(""); err != nil || len(rules) != 0 {
+		t.Fatalf("empty input should produce no rules, got %+v %v", rules, err)
+	}
+}
+
+func TestTaskNotification_Matches(t *testing.T) {
+	failure := TaskNotification{Trigger: NotifyOnFailure}
+	always := TaskNotification{Trigger: NotifyAlways}
+	keyword := TaskNotification{Trigger: NotifyOnKeyword, Keyword: "WARN"}
+
+	if failure.Matches("", false, 0) || !failure.Matches("", true, 0) {
+		t.Error("failure trigger should only match failed runs")
+	}
+	if !always.Matches("", false, 0) || !always.Matches("", true, 0) {
+		t.Error("always trigger should match every run")
+	}
+	if keyword.Matches("all good", true, 0) || !keyword.Matches("disk WARN", false, 0) {
+		t.Error("keyword trigger should match output only")
+	}
+
+	firstFailure := TaskNotification{Trigger: NotifyOnFirstFailure}
+	if !firstFailure.Matches("", true, 0) || firstFailure.Matches("", true, 1) || firstFailure.Matches("", false, 0) {
+		t.Error("first-failure trigger should only match the first failure of a streak")
+	}
+	recovery := TaskNotification{Trigger: NotifyOnRecovery}
+	if !recovery.Matches("", false, 2) || recovery.Matches("", false, 0) || recovery.Matches("", true, 2) {
+		t.Error("recovery trigger should only match a success after failures")
+	}
+	// 每 2 次连续失败重复通知：第 1、3、5 次失败
+	repeat := TaskNotification{Trigger: NotifyOnStateChange, RepeatEvery: 2}
+	for previous, want := range []bool{true, false, true, false, true} {
+		if got := repeat.Matches("", true, previous); got != want {
+			t.Errorf("failure #%d: got %v, want %v", previous+1, got, want)
+		}
+	}
+	if !repeat.Matches("", false, 4) {
+		t.Error("state-change trigger should match recovery")
+	}
+}
+
+func TestConsecutiveFailuresBefore(t *testing.T) {
+	cleanup := setupDefinitionTestDB(t)
+	defer cleanup()
+	if err := Db.AutoMigrate(&TaskLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// sqlite 中 bigint 主键不会自增，显式指定 ID；日志以运行中状态创建，执行结束后更新状态
+	ids := []int64{}
+	for i, status := range []Status{Failure, Finish, Failure, Cancel, Failure, Running} {
+		log := TaskLog{Id: int64(i + 1), TaskId: 1}
+		Db.Create(&log)
+		Db.Model(&TaskLog{}).Where("id = ?", log.Id).UpdateColumn("status", status)
+		ids = append(ids, log.Id)
+	}
+	Db.Create(&TaskLog{Id: 100, TaskId: 2})
+	Db.Model(&TaskLog{}).Where("id = ?", 100).UpdateColumn("status", Failure)
+
+	logModel := new(TaskLog)
+	tests := []struct {
+		before int64
+		want   int
+	}{
+		{ids[0], 0},
+		{ids[2], 0},
+		{ids[4], 1},
+		{ids[5], 2},
+	}
+	for _, tt := range tests {
+		if got, err := logModel.ConsecutiveFailuresBefore(1, tt.before); err != nil || got != tt.want {
+			t.Errorf("before %d: got %d (%v), want %d", tt.before, got, err, tt.want)
+		}
+	}
+}
+
+func TestMigrateLegacyTaskNotifications(t *testing.T) {
+	cleanup := setupDefinitionTestDB(t)
+	defer cleanup()
+	if err := Db.AutoMigrate(&legacyTaskNotify{}); err != nil {
+		t.Fatal(err)
+	}
+
+	mail := createDefinitionTestTask(t)
+	Db.Model(&legacyTaskNotify{}).Where("id = ?", mail.Id).Updates(map[string]interface{}{
+		"notify_status": 1, "notify_type": 0, "notify_receiver_id": "1,2",
+	})
+	other := Task{Name: "other", Level: TaskLevelParent, Spec: "* * * * * *", Protocol: TaskHTTP, Command: "http://a", Status: Enabled}
+	_, _ = other.Create()
+	Db.Model(&legacyTaskNotify{}).Where("id = ?", other.Id).Updates(map[string]interface{}{
+		"notify_status": 3, "notify_type": 2, "notify_keyword": "ERROR",
+	})
+	disabled := Task{Name: "disabled", Level: TaskLevelParent, Spec: "* * * * * *", Protocol: TaskHTTP, Command: "http://b", Status: Enabled}
+	_, _ = disabled.Create()
+
+	migrated, err := migrateLegacyTaskNotifications(Db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated != 2 {
+		t.Fatalf("expected 2 migrated tasks, got %d", migrated)
+	}
+
+	detail, _ := mail.Detail(mail.Id)
+	if len(detail.Notifications) != 1 || detail.Notifications[0].Channel != NotifyChannelMail ||
+		detail.Notifications[0].ReceiverIds != "1,2" || detail.Notifications[0].Trigger != NotifyOnFailure {
+		t.Fatalf("unexpected mail rules: %+v", detail.Notifications)
+	}
+	detail, _ = other.Detail(other.Id)
+	if len(detail.Notifications) != 1 || detail.Notifications[0].Keyword != "ERROR" {
+		t.Fatalf("unexpected webhook rules: %+v", detail.Notifications)
+	}
+	detail, _ = disabled.Detail(disabled.Id)
+	if len(detail.Notifications) != 0 {
+		t.Fatalf("disabled task should have no rules: %+v", detail.Notifications)
+	}
+
+	// 再次执行不会重复迁移
+	migrated, _ = migrateLegacyTaskNotifications(Db)
+	if migrated != 0 {
+		t.Fatalf("expected idempotent migration, got %d", migrated)
+	}
+}
@@ -4,18 +4,21 @@ import (
 	"errors"
 	"time"
 
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
 	"gorm.io/gorm"
 )
 
 type TaskScriptVersion struct {
-	Id        int       `json:"id" gorm:"primaryKey;autoIncrement"`
-	TaskId    int       `json:"task_id" gorm:"type:int;not null;index;uniqueIndex:idx_task_version"`
-	Command   string    `json:"command" gorm:"type:text;not null"`
-	Remark    string    `json:"remark" gorm:"type:varchar(200);not null;default:''"`
-	Username  string    `json:"username" gorm:"type:varchar(64);not null;default:''"`
-	Version   int       `json:"version" gorm:"type:int;not null;uniqueIndex:idx_task_version"`
-	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
-	BaseModel `json:"-" gorm:"-"`
+	Id      int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId  int    `json:"task_id" gorm:"type:int;not null;index;uniqueIndex:idx_task_version"`
+	Command string `json:"command" gorm:"type:text;not null"`
+	// Definition 完整任务定义快照，v1.7.0 之前的版本为空，只记录了命令
+	Definition *TaskDefinition `json:"definition" gorm:"type:text"`
+	Remark     string          `json:"remark" gorm:"type:varchar(200);not null;default:''"`
+	Username   string          `json:"username" gorm:"type:varchar(64);not null;default:''"`
+	Version    int             `json:"version" gorm:"type:int;not null;uniqueIndex:idx_task_version"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	BaseModel  `json:"-" gorm:"-"`
 }
 
 func (v *TaskScriptVersion) Create() (int, error) {
@@ -45,6 +48,26 @@ func (v *TaskScriptVersion) Detail(id int) (TaskScriptVersion, error) {
 	return version, err
 }
 
+// Snapshot 返回版本保存的任务定义，complete 为 false 表示旧版本只记录了命令
+func (v TaskScriptVersion) Snapshot() (definition TaskDefinition, complete bool) {
+	if v.Definition == nil {
+		return TaskDefinition{Command: v.Command}, false
+	}
+	return *v.Definition, true
+}
+
+// Masked 隐藏版本中的凭据，升级前保存的版本可能包含明文
+func (v TaskScriptVersion) Masked() TaskScriptVersion {
+	if v.Definition != nil {
+		definition := v.Definition.Masked()
+		v.Definition = &definition
+		v.Command = definition.Command
+	} else {
+		v.Command = httpclient.MaskURL(v.Command)
+	}
+	return v
+}
+
 func (v *TaskScriptVersion) GetLatestVersion(taskId int) (int, error) {
 	var version TaskScriptVersion
 	err := Db.Where("task_id = ?", taskId).Order("version DESC").First(&version).Error
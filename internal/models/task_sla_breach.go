@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// SLA 违约类型
+const (
+	// SlaKindDuration 单次执行超过最长预期耗时
+	SlaKindDuration = "duration"
+	// SlaKindSuccess 超过规定间隔没有成功执行
+	SlaKindSuccess = "success"
+)
+
+// TaskSlaBreach 任务 SLA 违约记录，用于去重，同一次违约只通知一次。
+// RefId 为违约对应的任务日志ID：耗时违约是运行中的日志，成功间隔违约是最近一次成功的日志（从未成功时为 0），
+// 直到出现新的成功执行前不会重复通知
+type TaskSlaBreach struct {
+	Id       int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId   int    `json:"task_id" gorm:"not null;uniqueIndex:idx_task_sla_breach"`
+	TaskName string `json:"task_name" gorm:"type:varchar(32);not null;default:''"`
+	Kind     string `json:"kind" gorm:"type:varchar(16);not null;uniqueIndex:idx_task_sla_breach"`
+	RefId    int64  `json:"ref_id" gorm:"type:bigint;not null;default:0;uniqueIndex:idx_task_sla_breach"`
+	// Threshold 违约时的阈值（秒）
+	Threshold int       `json:"threshold" gorm:"not null;default:0"`
+	Detail    string    `json:"detail" gorm:"type:varchar(255);not null;default:''"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime;index"`
+}
+
+// Record 记录违约，已记录过时返回 false
+func (breach *TaskSlaBreach) Record() (bool, error) {
+	result := Db.Clauses(clause.OnConflict{DoNothing: true}).Create(breach)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RemoveSlaBreachesBefore 删除 days 天前的违约记录
+func RemoveSlaBreachesBefore(days int) (int64, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	t := time.Now().AddDate(0, 0, -days)
+	result := Db.Where("created_at < ?", t).Delete(&TaskSlaBreach{})
+
+	return result.RowsAffected, result.Error
+}
@@ -9,32 +9,35 @@ import (
 )
 
 type TaskTemplate struct {
-	Id               int       `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name             string    `json:"name" gorm:"type:varchar(64);not null"`
-	Description      string    `json:"description" gorm:"type:varchar(500);not null;default:''"`
-	Category         string    `json:"category" gorm:"type:varchar(32);not null;default:'custom';index"`
-	Protocol         int8      `json:"protocol" gorm:"not null;default:2"`
-	Command          string    `json:"command" gorm:"type:text;not null"`
-	HttpMethod       int8      `json:"http_method" gorm:"not null;default:1"`
-	HttpBody         string    `json:"http_body" gorm:"type:text"`
-	HttpHeaders      string    `json:"http_headers" gorm:"type:text"`
-	SuccessPattern   string    `json:"success_pattern" gorm:"type:varchar(512);not null;default:''"`
-	Tag              string    `json:"tag" gorm:"type:varchar(255);not null;default:''"`
-	Spec             string    `json:"spec" gorm:"type:varchar(64);not null;default:''"`
-	Timeout          int       `json:"timeout" gorm:"type:int;not null;default:0"`
-	Multi            int8      `json:"multi" gorm:"not null;default:1"`
-	RetryTimes       int8      `json:"retry_times" gorm:"not null;default:0"`
-	RetryInterval    int16     `json:"retry_interval" gorm:"type:smallint;not null;default:0"`
-	Timezone         string    `json:"timezone" gorm:"type:varchar(64);not null;default:''"`
-	NotifyStatus     int8      `json:"notify_status" gorm:"not null;default:0"`
-	NotifyType       int8      `json:"notify_type" gorm:"not null;default:0"`
-	NotifyKeyword    string    `json:"notify_keyword" gorm:"type:varchar(128);not null;default:''"`
-	LogRetentionDays int       `json:"log_retention_days" gorm:"type:smallint;not null;default:0"`
-	IsBuiltin        int8      `json:"is_builtin" gorm:"not null;default:0"`
-	UsageCount       int       `json:"usage_count" gorm:"type:int;not null;default:0"`
-	CreatedBy        string    `json:"created_by" gorm:"type:varchar(64);not null;default:''"`
-	CreatedAt        time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt        time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	Id               int                `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name             string             `json:"name" gorm:"type:varchar(64);not null"`
+	Description      string             `json:"description" gorm:"type:varchar(500);not null;default:''"`
+	Category         string             `json:"category" gorm:"type:varchar(32);not null;default:'custom';index"`
+	Protocol         int8               `json:"protocol" gorm:"not null;default:2"`
+	Command          string             `json:"command" gorm:"type:text;not null"`
+	HttpMethod       int8               `json:"http_method" gorm:"not null;default:1"`
+	HttpBody         string             `json:"http_body" gorm:"type:text"`
+	HttpHeaders      string             `json:"http_headers" gorm:"type:text"`
+	HttpBodyType     string             `json:"http_body_type" gorm:"type:varchar(16);not null;default:''"`
+	HttpContentType  string             `json:"http_content_type" gorm:"type:varchar(128);not null;default:''"`
+	SuccessPattern   string             `json:"success_pattern" gorm:"type:varchar(512);not null;default:''"`
+	Tag              string             `json:"tag" gorm:"type:varchar(255);not null;default:''"`
+	Spec             string             `json:"spec" gorm:"type:varchar(64);not null;default:''"`
+	Timeout          int                `json:"timeout" gorm:"type:int;not null;default:0"`
+	Multi            int8               `json:"multi" gorm:"not null;default:1"`
+	RetryTimes       int8               `json:"retry_times" gorm:"not null;default:0"`
+	RetryInterval    int16              `json:"retry_interval" gorm:"type:smallint;not null;default:0"`
+	Timezone         string             `json:"timezone" gorm:"type:varchar(64);not null;default:''"`
+	NotifyStatus     int8               `json:"notify_status" gorm:"not null;default:0"`
+	NotifyType       int8               `json:"notify_type" gorm:"not null;default:0"`
+	NotifyKeyword    string             `json:"notify_keyword" gorm:"type:varchar(128);not null;default:''"`
+	LogRetentionDays int                `json:"log_retention_days" gorm:"type:smallint;not null;default:0"`
+	Parameters       TemplateParameters `json:"parameters" gorm:"type:text"`
+	IsBuiltin        int8               `json:"is_builtin" gorm:"not null;default:0"`
+	UsageCount       int                `json:"usage_count" gorm:"type:int;not null;default:0"`
+	CreatedBy        string             `json:"created_by" gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt        time.Time          `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time          `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 	BaseModel        `json:"-" gorm:"-"`
 }
 
@@ -44,11 +47,15 @@ func (t *TaskTemplate) Create() (int, error) {
 }
 
 func (t *TaskTemplate) UpdateBean(id int) (int64, error) {
-	result := Db.Model(&TaskTemplate{}).Where("id = ?", id).
+	return t.updateWith(Db, id)
+}
+
+func (t *TaskTemplate) updateWith(db *gorm.DB, id int) (int64, error) {
+	result := db.Model(&TaskTemplate{}).Where("id = ?", id).
 		Select("name", "description", "category", "protocol", "command",
-			"http_method", "http_body", "http_headers", "success_pattern",
+			"http_method", "http_body", "http_headers", "http_body_type", "http_content_type", "success_pattern",
 			"tag", "spec", "timeout", "multi", "retry_times", "retry_interval",
-			"timezone", "notify_status", "notify_type", "notify_keyword", "log_retention_days").
+			"timezone", "notify_status", "notify_type", "notify_keyword", "log_retention_days", "parameters").
 		UpdateColumns(map[string]interface{}{
 			"name":               t.Name,
 			"description":        t.Description,
@@ -58,6 +65,8 @@ func (t *TaskTemplate) UpdateBean(id int) (int64, error) {
 			"http_method":        t.HttpMethod,
 			"http_body":          t.HttpBody,
 			"http_headers":       t.HttpHeaders,
+			"http_body_type":     t.HttpBodyType,
+			"http_content_type":  t.HttpContentType,
 			"success_pattern":    t.SuccessPattern,
 			"tag":                t.Tag,
 			"spec":               t.Spec,
@@ -70,6 +79,7 @@ func (t *TaskTemplate) UpdateBean(id int) (int64, error) {
 			"notify_type":        t.NotifyType,
 			"notify_keyword":     t.NotifyKeyword,
 			"log_retention_days": t.LogRetentionDays,
+			"parameters":         t.Parameters,
 		})
 	return result.RowsAffected, result.Error
 }
@@ -140,30 +150,92 @@ func (t *TaskTemplate) GetCategories() ([]string, error) {
 
 // seedBuiltinTemplates 初始化内置模板
 func seedBuiltinTemplates(tx *gorm.DB) {
-	templates := []TaskTemplate{
+	templates := builtinTemplates()
+
+	for i := range templates {
+		var count int64
+		tx.Model(&TaskTemplate{}).Where("name = ?", templates[i].Name).Count(&count)
+		if count > 0 {
+			continue
+		}
+		if err := tx.Create(&templates[i]).Error; err != nil {
+			logger.Warnf("初始化内置模板 [%s] 失败: %v", templates[i].Name, err)
+		}
+	}
+}
+
+// backfillBuiltinTemplateParameters 为升级前已创建的内置模板补充参数声明
+func backfillBuiltinTemplateParameters(tx *gorm.DB) error {
+	for _, tmpl := range builtinTemplates() {
+		err := tx.Model(&TaskTemplate{}).
+			Where("name = ? AND is_builtin = ? AND (parameters IS NULL OR parameters = '')", tmpl.Name, 1).
+			UpdateColumn("parameters", tmpl.Parameters).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// templatePathPattern 内置模板的目录参数：绝对路径，只允许字母、数字和 _ . - /
+const templatePathPattern = `^/[A-Za-z0-9_./-]*$`
+
+// updateBuiltinTemplates 内置模板不能编辑，升级时同步命令、调度规则和参数声明
+func updateBuiltinTemplates(tx *gorm.DB) error {
+	for _, tmpl := range builtinTemplates() {
+		err := tx.Model(&TaskTemplate{}).
+			Where("name = ? AND is_builtin = ?", tmpl.Name, 1).
+			UpdateColumns(map[string]interface{}{
+				"command":    tmpl.Command,
+				"spec":       tmpl.Spec,
+				"parameters": tmpl.Parameters,
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func builtinTemplates() []TaskTemplate {
+	return []TaskTemplate{
 		{
 			Name:        "MySQL Database Backup",
 			Description: "Backup MySQL database to compressed file",
 			Category:    "backup",
 			Protocol:    2,
-			Command:     `mysqldump -h {{db_host}} -u {{db_user}} -p'{{db_pass}}' {{db_name}} | gzip > /backup/{{db_name}}_$(date +%Y%m%d_%H%M%S).sql.gz`,
+			Command:     `mysqldump -h {{db_host}} -u {{db_user}} -p{{db_pass}} {{db_name}} | gzip > /backup/{{db_name}}_$(date +%Y%m%d_%H%M%S).sql.gz`,
 			Tag:         "backup,database",
 			Spec:        "0 0 2 * * *",
 			Timeout:     3600,
 			Multi:       0,
 			IsBuiltin:   1,
+			Parameters: []TemplateParameter{
+				{Name: "db_host", Type: TemplateParamString, Default: "127.0.0.1", Required: true, Description: "Database host"},
+				{Name: "db_user", Type: TemplateParamString, Default: "root", Required: true, Description: "Database user"},
+				{Name: "db_pass", Type: TemplateParamString, Required: true, Description: "Database password"},
+				{Name: "db_name", Type: TemplateParamString, Required: true, Pattern: `^[A-Za-z0-9_$-]+$`, Description: "Database name"},
+			},
 		},
 		{
 			Name:        "PostgreSQL Database Backup",
 			Description: "Backup PostgreSQL database to compressed file",
 			Category:    "backup",
 			Protocol:    2,
-			Command:     `PGPASSWORD='{{db_pass}}' pg_dump -h {{db_host}} -U {{db_user}} {{db_name}} | gzip > /backup/{{db_name}}_$(date +%Y%m%d_%H%M%S).sql.gz`,
+			Command:     `PGPASSWORD={{db_pass}} pg_dump -h {{db_host}} -U {{db_user}} {{db_name}} | gzip > /backup/{{db_name}}_$(date +%Y%m%d_%H%M%S).sql.gz`,
 			Tag:         "backup,database",
 			Spec:        "0 0 2 * * *",
 			Timeout:     3600,
 			Multi:       0,
 			IsBuiltin:   1,
+			Parameters: []TemplateParameter{
+				{Name: "db_pass", Type: TemplateParamString, Required: true, Description: "Database password"},
+				{Name: "db_host", Type: TemplateParamString, Default: "127.0.0.1", Required: true, Description: "Database host"},
+				{Name: "db_user", Type: TemplateParamString, Default: "postgres", Required: true, Description: "Database user"},
+				{Name: "db_name", Type: TemplateParamString, Required: true, Pattern: `^[A-Za-z0-9_$-]+$`, Description: "Database name"},
+			},
 		},
 		{
 			Name:        "Clean Log Files",
@@ -176,6 +248,10 @@ func seedBuiltinTemplates(tx *gorm.DB) {
 			Timeout:     300,
 			Multi:       0,
 			IsBuiltin:   1,
+			Parameters: []TemplateParameter{
+				{Name: "log_dir", Type: TemplateParamString, Required: true, Pattern: templatePathPattern, Description: "Absolute path of the log directory"},
+				{Name: "retain_days", Type: TemplateParamInt, Default: "7", Required: true, Description: "Keep files modified within this many days"},
+			},
 		},
 		{
 			Name:        "Clean Temp Files",
@@ -188,6 +264,10 @@ func seedBuiltinTemplates(tx *gorm.DB) {
 			Timeout:     300,
 			Multi:       0,
 			IsBuiltin:   1,
+			Parameters: []TemplateParameter{
+				{Name: "temp_dir", Type: TemplateParamString, Default: "/tmp", Required: true, Pattern: templatePathPattern, Description: "Absolute path of the temp directory"},
+				{Name: "retain_days", Type: TemplateParamInt, Default: "3", Required: true, Description: "Keep files modified within this many days"},
+			},
 		},
 		{
 			Name:          "HTTP Health Check",
@@ -201,17 +281,24 @@ func seedBuiltinTemplates(tx *gorm.DB) {
 			RetryTimes:    3,
 			RetryInterval: 30,
 			IsBuiltin:     1,
+			Parameters: []TemplateParameter{
+				{Name: "check_url", Type: TemplateParamString, Required: true, Pattern: `^https?://\S+$`, Description: "URL to check"},
+			},
 		},
 		{
 			Name:        "Disk Usage Alert",
 			Description: "Alert when disk usage exceeds threshold",
 			Category:    "monitor",
 			Protocol:    2,
-			Command:     `usage=$(df {{mount_point}} | awk 'NR==2{print $5}' | tr -d '%%') && [ "$usage" -lt {{threshold}} ] && echo "OK: ${usage}%% used" || (echo "WARN: ${usage}%% used, exceeds {{threshold}}%%" && exit 1)`,
+			Command:     `threshold={{threshold}}; usage=$(df {{mount_point}} | awk 'NR==2{print $5}' | tr -d '%%') && [ "$usage" -lt "$threshold" ] && echo "OK: ${usage}%% used" || (echo "WARN: ${usage}%% used, exceeds ${threshold}%%" && exit 1)`,
 			Tag:         "monitor,disk",
 			Spec:        "0 */30 * * * *",
 			Timeout:     30,
 			IsBuiltin:   1,
+			Parameters: []TemplateParameter{
+				{Name: "mount_point", Type: TemplateParamString, Default: "/", Required: true, Pattern: templatePathPattern, Description: "Mount point to check"},
+				{Name: "threshold", Type: TemplateParamInt, Default: "80", Required: true, Pattern: `^([1-9][0-9]?|100)$`, Description: "Usage percentage that triggers the alert"},
+			},
 		},
 		{
 			Name:        "Docker Container Restart",
@@ -220,9 +307,13 @@ func seedBuiltinTemplates(tx *gorm.DB) {
 			Protocol:    2,
 			Command:     `docker restart {{container_name}} && sleep 3 && docker ps | grep {{container_name}}`,
 			Tag:         "deploy,docker",
+			Spec:        "0 0 4 * * *",
 			Timeout:     120,
 			Multi:       0,
 			IsBuiltin:   1,
+			Parameters: []TemplateParameter{
+				{Name: "container_name", Type: TemplateParamString, Required: true, Pattern: `^[A-Za-z0-9][A-Za-z0-9_.-]*$`, Description: "Container name or ID"},
+			},
 		},
 		{
 			Name:          "HTTP API Call (GET)",
@@ -232,10 +323,14 @@ func seedBuiltinTemplates(tx *gorm.DB) {
 			Command:       `{{api_url}}`,
 			HttpMethod:    1,
 			Tag:           "api,http",
+			Spec:          "0 */10 * * * *",
 			Timeout:       30,
 			RetryTimes:    2,
 			RetryInterval: 10,
 			IsBuiltin:     1,
+			Parameters: []TemplateParameter{
+				{Name: "api_url", Type: TemplateParamString, Required: true, Pattern: `^https?://\S+$`, Description: "API URL"},
+			},
 		},
 		{
 			Name:          "HTTP API Call (POST)",
@@ -247,21 +342,15 @@ func seedBuiltinTemplates(tx *gorm.DB) {
 			HttpBody:      `{{json_body}}`,
 			HttpHeaders:   `{"Content-Type": "application/json"}`,
 			Tag:           "api,http",
+			Spec:          "0 */10 * * * *",
 			Timeout:       30,
 			RetryTimes:    2,
 			RetryInterval: 10,
 			IsBuiltin:     1,
+			Parameters: []TemplateParameter{
+				{Name: "api_url", Type: TemplateParamString, Required: true, Pattern: `^https?://\S+$`, Description: "API URL"},
+				{Name: "json_body", Type: TemplateParamString, Default: "{}", Description: "JSON request body"},
+			},
 		},
 	}
-
-	for i := range templates {
-		var count int64
-		tx.Model(&TaskTemplate{}).Where("name = ?", templates[i].Name).Count(&count)
-		if count > 0 {
-			continue
-		}
-		if err := tx.Create(&templates[i]).Error; err != nil {
-			logger.Warnf("初始化内置模板 [%s] 失败: %v", templates[i].Name, err)
-		}
-	}
 }
@@ -0,0 +1,450 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goccy/go-yaml"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"gorm.io/gorm"
+)
+
+// TemplateBundleVersion 模板包格式版本，格式不兼容变更时递增
+const TemplateBundleVersion = 1
+
+// 模板包格式
+const (
+	TemplateBundleJSON = "json"
+	TemplateBundleYAML = "yaml"
+)
+
+// 导入时名称冲突的处理方式
+const (
+	TemplateConflictSkip      = "skip"
+	TemplateConflictOverwrite = "overwrite"
+	TemplateConflictRename    = "rename"
+)
+
+// TemplateBundle 可在实例之间迁移的模板包
+type TemplateBundle struct {
+	Version    int                  `json:"version"`
+	ExportedAt string               `json:"exported_at,omitempty"`
+	Templates  []TemplateBundleItem `json:"templates"`
+}
+
+// TemplateBundleItem 模板包中的单个模板，不包含 id、使用次数等实例相关字段
+type TemplateBundleItem struct {
+	Name             string             `json:"name"`
+	Description      string             `json:"description,omitempty"`
+	Category         string             `json:"category,omitempty"`
+	Protocol         int8               `json:"protocol"`
+	Command          string             `json:"command"`
+	HttpMethod       int8               `json:"http_method,omitempty"`
+	HttpBody         string             `json:"http_body,omitempty"`
+	HttpHeaders      string             `json:"http_headers,omitempty"`
+	HttpBodyType     string             `json:"http_body_type,omitempty"`
+	HttpContentType  string             `json:"http_content_type,omitempty"`
+	SuccessPattern   string             `json:"success_pattern,omitempty"`
+	Tag              string             `json:"tag,omitempty"`
+	Spec             string             `json:"spec,omitempty"`
+	Timeout          int                `json:"timeout,omitempty"`
+	Multi            int8               `json:"multi"`
+	RetryTimes       int8               `json:"retry_times,omitempty"`
+	RetryInterval    int16              `json:"retry_interval,omitempty"`
+	Timezone         string             `json:"timezone,omitempty"`
+	NotifyStatus     int8               `json:"notify_status,omitempty"`
+	NotifyType       int8               `json:"notify_type,omitempty"`
+	NotifyKeyword    string             `json:"notify_keyword,omitempty"`
+	LogRetentionDays int                `json:"log_retention_days,omitempty"`
+	Parameters       TemplateParameters `json:"parameters,omitempty"`
+}
+
+// TemplateRename 导入时被重命名的模板
+type TemplateRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// TemplateImportFailure 校验失败未导入的模板
+type TemplateImportFailure struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// TemplateImportResult 导入结果
+type TemplateImportResult struct {
+	Created     []string                `json:"created"`
+	Overwritten []string                `json:"overwritten"`
+	Renamed     []TemplateRename        `json:"renamed"`
+	Skipped     []string                `json:"skipped"`
+	Failed      []TemplateImportFailure `json:"failed"`
+}
+
+// NewTemplateImportResult 返回各列表均已初始化的导入结果，便于 JSON 输出空数组
+func NewTemplateImportResult() TemplateImportResult {
+	return TemplateImportResult{
+		Created:     []string{},
+		Overwritten: []string{},
+		Renamed:     []TemplateRename{},
+		Skipped:     []string{},
+		Failed:      []TemplateImportFailure{},
+	}
+}
+
+// Merge 合并另一次导入的结果
+func (r *TemplateImportResult) Merge(other TemplateImportResult) {
+	r.Created = append(r.Created, other.Created...)
+	r.Overwritten = append(r.Overwritten, other.Overwritten...)
+	r.Renamed = append(r.Renamed, other.Renamed...)
+	r.Skipped = append(r.Skipped, other.Skipped...)
+	r.Failed = append(r.Failed, other.Failed...)
+}
+
+// ValidTemplateConflictMode 检查冲突处理方式是否合法
+func ValidTemplateConflictMode(mode string) bool {
+	switch mode {
+	case TemplateConflictSkip, TemplateConflictOverwrite, TemplateConflictRename:
+		return true
+	}
+	return false
+}
+
+// NewTemplateBundleItem 由模板生成模板包条目，未声明参数的模板导出推断出的占位符参数
+func NewTemplateBundleItem(t TaskTemplate) TemplateBundleItem {
+	return TemplateBundleItem{
+		Name:             t.Name,
+		Description:      t.Description,
+		Category:         t.Category,
+		Protocol:         t.Protocol,
+		Command:          t.Command,
+		HttpMethod:       t.HttpMethod,
+		HttpBody:         t.HttpBody,
+		HttpHeaders:      t.HttpHeaders,
+		HttpBodyType:     t.HttpBodyType,
+		HttpContentType:  t.HttpContentType,
+		SuccessPattern:   t.SuccessPattern,
+		Tag:              t.Tag,
+		Spec:             t.Spec,
+		Timeout:          t.Timeout,
+		Multi:            t.Multi,
+		RetryTimes:       t.RetryTimes,
+		RetryInterval:    t.RetryInterval,
+		Timezone:         t.Timezone,
+		NotifyStatus:     t.NotifyStatus,
+		NotifyType:       t.NotifyType,
+		NotifyKeyword:    t.NotifyKeyword,
+		LogRetentionDays: t.LogRetentionDays,
+		Parameters:       t.EffectiveParameters(),
+	}
+}
+
+func (item TemplateBundleItem) toTemplate() TaskTemplate {
+	return TaskTemplate{
+		Name:             item.Name,
+		Description:      item.Description,
+		Category:         item.Category,
+		Protocol:         item.Protocol,
+		Command:          item.Command,
+		HttpMethod:       item.HttpMethod,
+		HttpBody:         item.HttpBody,
+		HttpHeaders:      item.HttpHeaders,
+		HttpBodyType:     item.HttpBodyType,
+		HttpContentType:  item.HttpContentType,
+		SuccessPattern:   item.SuccessPattern,
+		Tag:              item.Tag,
+		Spec:             item.Spec,
+		Timeout:          item.Timeout,
+		Multi:            item.Multi,
+		RetryTimes:       item.RetryTimes,
+		RetryInterval:    item.RetryInterval,
+		Timezone:         item.Timezone,
+		NotifyStatus:     item.NotifyStatus,
+		NotifyType:       item.NotifyType,
+		NotifyKeyword:    item.NotifyKeyword,
+		LogRetentionDays: item.LogRetentionDays,
+		Parameters:       item.Parameters,
+	}
+}
+
+// normalize 补全默认值并校验，与模板表单的校验规则保持一致
+func (item *TemplateBundleItem) normalize() error {
+	item.Name = strings.TrimSpace(item.Name)
+	item.Category = strings.TrimSpace(item.Category)
+	if item.Name == "" {
+		return errors.New("name is required")
+	}
+	if utf8.RuneCountInString(item.Name) > 64 {
+		return errors.New("name exceeds 64 characters")
+	}
+	if item.Category == "" {
+		item.Category = "custom"
+	}
+	if utf8.RuneCountInString(item.Category) > 32 {
+		return errors.New("category exceeds 32 characters")
+	}
+	if item.Protocol == 0 {
+		item.Protocol = int8(TaskRPC)
+	}
+	if item.Protocol != int8(TaskHTTP) && item.Protocol != int8(TaskRPC) {
+		return fmt.Errorf("unsupported protocol %d", item.Protocol)
+	}
+	if strings.TrimSpace(item.Command) == "" {
+		return errors.New("command is required")
+	}
+	if item.HttpMethod == 0 {
+		item.HttpMethod = 1
+	}
+	if _, ok := taskHTTPMethodNames[TaskHTTPMethod(item.HttpMethod)]; !ok {
+		return fmt.Errorf("unsupported http_method %d", item.HttpMethod)
+	}
+	if item.HttpBodyType != httpclient.BodyTypeAuto && !utils.InStringSlice(httpclient.BodyTypes, item.HttpBodyType) {
+		return fmt.Errorf("unsupported http_body_type %q", item.HttpBodyType)
+	}
+	if utf8.RuneCountInString(item.HttpContentType) > 128 {
+		return errors.New("http_content_type exceeds 128 characters")
+	}
+	if item.Timeout < 0 || item.Timeout > 86400 {
+		return errors.New("timeout must be between 0 and 86400")
+	}
+	if item.Multi != 0 && item.Multi != 1 {
+		return errors.New("multi must be 0 or 1")
+	}
+	if utf8.RuneCountInString(item.SuccessPattern) > 512 {
+		return errors.New("success_pattern exceeds 512 characters")
+	}
+	if item.LogRetentionDays < 0 || item.LogRetentionDays > 3650 {
+		return errors.New("log_retention_days must be between 0 and 3650")
+	}
+	for i := range item.Parameters {
+		item.Parameters[i].Name = strings.TrimSpace(item.Parameters[i].Name)
+		if item.Parameters[i].Type == "" {
+			item.Parameters[i].Type = TemplateParamString
+		}
+	}
+
+	return item.Parameters.Validate()
+}
+
+// ExportTemplates 导出指定模板，ids 为空时导出全部模板
+func ExportTemplates(ids []int) (TemplateBundle, error) {
+	list := make([]TaskTemplate, 0)
+	query := Db.Model(&TaskTemplate{})
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	if err := query.Order("category ASC, name ASC").Find(&list).Error; err != nil {
+		return TemplateBundle{}, err
+	}
+
+	bundle := TemplateBundle{
+		Version:    TemplateBundleVersion,
+		ExportedAt: time.Now().Format(time.RFC3339),
+		Templates:  make([]TemplateBundleItem, 0, len(list)),
+	}
+	for _, tmpl := range list {
+		bundle.Templates = append(bundle.Templates, NewTemplateBundleItem(tmpl))
+	}
+
+	return bundle, nil
+}
+
+// MarshalTemplateBundle 按格式序列化模板包
+func MarshalTemplateBundle(bundle TemplateBundle, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "", TemplateBundleJSON:
+		return data, nil
+	case TemplateBundleYAML:
+		// 经 JSON 中转，字段名只由 json tag 决定，两种格式保持一致
+		return yaml.JSONToYAML(data)
+	}
+
+	return nil, fmt.Errorf("unsupported bundle format %q", format)
+}
+
+// ParseTemplateBundle 解析 JSON 或 YAML 格式的模板包
+func ParseTemplateBundle(data []byte) (TemplateBundle, error) {
+	var bundle TemplateBundle
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return bundle, errors.New("empty template bundle")
+	}
+	if data[0] != '{' {
+		converted, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return bundle, fmt.Errorf("invalid template bundle: %s", err)
+		}
+		data = converted
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return bundle, fmt.Errorf("invalid template bundle: %s", err)
+	}
+	if bundle.Version > TemplateBundleVersion {
+		return bundle, fmt.Errorf("unsupported template bundle version %d", bundle.Version)
+	}
+	if len(bundle.Templates) == 0 {
+		return bundle, errors.New("template bundle contains no templates")
+	}
+
+	return bundle, nil
+}
+
+// ImportTemplates 导入模板包。校验失败的模板记录在 Failed 中，其余模板在同一事务中写入。
+// 内置模板不会被覆盖，overwrite 模式下与内置模板同名时跳过。
+func ImportTemplates(bundle TemplateBundle, mode string, createdBy string) (TemplateImportResult, error) {
+	result := NewTemplateImportResult()
+	if mode == "" {
+		mode = TemplateConflictSkip
+	}
+	if !ValidTemplateConflictMode(mode) {
+		return result, fmt.Errorf("unsupported conflict mode %q", mode)
+	}
+
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		seen := make(map[string]bool, len(bundle.Templates))
+		for _, item := range bundle.Templates {
+			if err := item.normalize(); err != nil {
+				result.Failed = append(result.Failed, TemplateImportFailure{Name: item.Name, Error: err.Error()})
+				continue
+			}
+			if seen[item.Name] {
+				result.Failed = append(result.Failed, TemplateImportFailure{Name: item.Name, Error: "duplicate name in bundle"})
+				continue
+			}
+			seen[item.Name] = true
+
+			tmpl := item.toTemplate()
+			tmpl.CreatedBy = createdBy
+
+			var existing TaskTemplate
+			err := tx.Where("name = ?", item.Name).Limit(1).Find(&existing).Error
+			if err != nil {
+				return err
+			}
+			if existing.Id == 0 {
+				if err := tx.Create(&tmpl).Error; err != nil {
+					return err
+				}
+				result.Created = append(result.Created, tmpl.Name)
+				continue
+			}
+
+			switch {
+			case mode == TemplateConflictOverwrite && existing.IsBuiltin == 0:
+				if _, err := tmpl.updateWith(tx, existing.Id); err != nil {
+					return err
+				}
+				result.Overwritten = append(result.Overwritten, tmpl.Name)
+			case mode == TemplateConflictRename:
+				name, err := availableTemplateName(tx, item.Name)
+				if err != nil {
+					return err
+				}
+				tmpl.Name = name
+				if err := tx.Create(&tmpl).Error; err != nil {
+					return err
+				}
+				result.Renamed = append(result.Renamed, TemplateRename{From: item.Name, To: name})
+			default:
+				result.Skipped = append(result.Skipped, item.Name)
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// availableTemplateName 生成 "name (2)" 形式的不冲突名称，总长度不超过 64 个字符
+func availableTemplateName(tx *gorm.DB, name string) (string, error) {
+	for i := 2; i < 1000; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		base := []rune(name)
+		if max := 64 - utf8.RuneCountInString(suffix); len(base) > max {
+			base = base[:max]
+		}
+		candidate := string(base) + suffix
+		var count int64
+		if err := tx.Model(&TaskTemplate{}).Where("name = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("no available name for template %s", name)
+}
+
+// ImportTemplateDir 导入目录下的全部模板包（*.json / *.yaml / *.yml），按文件名顺序处理。
+// 单个文件解析失败不影响其他文件。
+func ImportTemplateDir(dir string, mode string, createdBy string) (TemplateImportResult, error) {
+	result := NewTemplateImportResult()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result, err
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		fileResult, err := ImportTemplateFile(file, mode, createdBy)
+		if err != nil {
+			result.Failed = append(result.Failed, TemplateImportFailure{Name: filepath.Base(file), Error: err.Error()})
+			continue
+		}
+		result.Merge(fileResult)
+	}
+
+	return result, nil
+}
+
+// ImportTemplateFile 导入单个模板包文件
+func ImportTemplateFile(file string, mode string, createdBy string) (TemplateImportResult, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return NewTemplateImportResult(), err
+	}
+	bundle, err := ParseTemplateBundle(data)
+	if err != nil {
+		return NewTemplateImportResult(), err
+	}
+
+	return ImportTemplates(bundle, mode, createdBy)
+}
+
+// LoadTemplateDir 启动时加载模板目录，结果只写日志
+func LoadTemplateDir(dir string, mode string) {
+	result, err := ImportTemplateDir(dir, mode, "system")
+	if err != nil {
+		logger.Warnf("加载模板目录 [%s] 失败: %v", dir, err)
+		return
+	}
+	logger.Infof("加载模板目录 [%s]: 新增 %d, 覆盖 %d, 重命名 %d, 跳过 %d, 失败 %d",
+		dir, len(result.Created), len(result.Overwritten), len(result.Renamed), len(result.Skipped), len(result.Failed))
+	for _, failure := range result.Failed {
+		logger.Warnf("导入模板 [%s] 失败: %s", failure.Name, failure.Error)
+	}
+}
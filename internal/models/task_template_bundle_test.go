@@ -0,0 +1,195 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateBundle_ExportParseRoundTrip(t *testing.T) {
+	cleanup := setupTemplateTestDB(t)
+	defer cleanup()
+
+	tmpl := &TaskTemplate{
+		Name:          "Disk Check",
+		Category:      "monitor",
+		Protocol:      2,
+		Command:       "df -h {{mount}}",
+		Multi:         1,
+		NotifyStatus:  2,
+		NotifyType:    3,
+		NotifyKeyword: "FULL",
+		UsageCount:    7,
+	}
+	if _, err := tmpl.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := ExportTemplates([]int{tmpl.Id})
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	for _, format := range []string{TemplateBundleJSON, TemplateBundleYAML} {
+		data, err := MarshalTemplateBundle(bundle, format)
+		if err != nil {
+			t.Fatalf("marshal %s failed: %v", format, err)
+		}
+		if strings.Contains(string(data), "usage_count") {
+			t.Fatalf("%s bundle must not contain instance fields", format)
+		}
+		parsed, err := ParseTemplateBundle(data)
+		if err != nil {
+			t.Fatalf("parse %s failed: %v\n%s", format, err, data)
+		}
+		item := parsed.Templates[0]
+		if item.Name != "Disk Check" || item.Category != "monitor" || item.NotifyKeyword != "FULL" || item.NotifyType != 3 {
+			t.Fatalf("%s round trip lost fields: %+v", format, item)
+		}
+		// 未声明参数的模板导出推断出的占位符
+		if len(item.Parameters) != 1 || item.Parameters[0].Name != "mount" {
+			t.Fatalf("%s bundle should carry placeholders, got %+v", format, item.Parameters)
+		}
+	}
+
+	if _, err := ParseTemplateBundle([]byte(`{"version": 99, "templates": [{"name": "x"}]}`)); err == nil {
+		t.Fatal("expected error for newer bundle version")
+	}
+	if _, err := ParseTemplateBundle([]byte("version: 1\ntemplates: []\n")); err == nil {
+		t.Fatal("expected error for empty bundle")
+	}
+}
+
+func TestTemplateBundle_HttpMethodAndBodyType(t *testing.T) {
+	bundle, err := ParseTemplateBundle([]byte(`{"version": 1, "templates": [{"name": "upload", "protocol": 1, "command": "https://example.com",
+		"http_method": 3, "http_body_type": "multipart", "http_body": "{\"a\": \"1\"}"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := bundle.Templates[0]
+	if err := item.normalize(); err != nil || item.HttpMethod != 3 || item.HttpBodyType != "multipart" {
+		t.Fatalf("unexpected item: %+v %v", item, err)
+	}
+	for _, invalid := range []TemplateBundleItem{
+		{Name: "x", Protocol: 1, Command: "https://example.com", HttpMethod: 7},
+		{Name: "x", Protocol: 1, Command: "https://example.com", HttpBodyType: "xml"},
+	} {
+		if err := invalid.normalize(); err == nil {
+			t.Fatalf("expected error for %+v", invalid)
+		}
+	}
+}
+
+func TestImportTemplates_ConflictModes(t *testing.T) {
+	cleanup := setupTemplateTestDB(t)
+	defer cleanup()
+
+	existing := &TaskTemplate{Name: "Cleanup", Category: "custom", Protocol: 2, Command: "old"}
+	builtin := &TaskTemplate{Name: "Builtin", Category: "system", Protocol: 2, Command: "builtin", IsBuiltin: 1}
+	_, _ = existing.Create()
+	_, _ = builtin.Create()
+
+	bundle := TemplateBundle{Version: 1, Templates: []TemplateBundleItem{
+		{Name: "Cleanup", Command: "new"},
+		{Name: "Builtin", Command: "changed"},
+		{Name: "Fresh", Command: "echo fresh", Protocol: 1},
+		{Name: "", Command: "missing name"},
+		{Name: "Bad Param", Command: "echo", Parameters: TemplateParameters{{Name: "1x"}}},
+	}}
+
+	result, err := ImportTemplates(bundle, TemplateConflictSkip, "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Created) != 1 || len(result.Skipped) != 2 || len(result.Failed) != 2 {
+		t.Fatalf("unexpected skip result: %+v", result)
+	}
+	fresh := TaskTemplate{}
+	Db.Where("name = ?", "Fresh").First(&fresh)
+	if fresh.CreatedBy != "tester" || fresh.Category != "custom" || fresh.HttpMethod != 1 {
+		t.Fatalf("expected defaults to be filled, got %+v", fresh)
+	}
+
+	result, err = ImportTemplates(bundle, TemplateConflictOverwrite, "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fresh 已存在会被覆盖；内置模板仍然跳过
+	if len(result.Overwritten) != 2 || len(result.Skipped) != 1 || result.Skipped[0] != "Builtin" {
+		t.Fatalf("unexpected overwrite result: %+v", result)
+	}
+	updated, _ := existing.Detail(existing.Id)
+	if updated.Command != "new" {
+		t.Fatalf("expected command to be overwritten, got %q", updated.Command)
+	}
+	unchanged, _ := builtin.Detail(builtin.Id)
+	if unchanged.Command != "builtin" {
+		t.Fatal("builtin template must not be overwritten")
+	}
+
+	result, err = ImportTemplates(TemplateBundle{Templates: bundle.Templates[:1]}, TemplateConflictRename, "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Renamed) != 1 || result.Renamed[0].To != "Cleanup (2)" {
+		t.Fatalf("unexpected rename result: %+v", result)
+	}
+	result, _ = ImportTemplates(TemplateBundle{Templates: bundle.Templates[:1]}, TemplateConflictRename, "tester")
+	if result.Renamed[0].To != "Cleanup (3)" {
+		t.Fatalf("expected next free name, got %+v", result.Renamed)
+	}
+
+	if _, err := ImportTemplates(bundle, "merge", "tester"); err == nil {
+		t.Fatal("expected error for unknown conflict mode")
+	}
+}
+
+func TestAvailableTemplateName_Truncates(t *testing.T) {
+	cleanup := setupTemplateTestDB(t)
+	defer cleanup()
+
+	name, err := availableTemplateName(Db, strings.Repeat("名", 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len([]rune(name)) != 64 || !strings.HasSuffix(name, " (2)") {
+		t.Fatalf("unexpected name %q", name)
+	}
+}
+
+func TestImportTemplateDir(t *testing.T) {
+	cleanup := setupTemplateTestDB(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(`version: 1
+templates:
+  - name: From YAML
+    command: echo {{msg}}
+    parameters:
+      - name: msg
+        default: hello
+`), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"version":1,"templates":[{"name":"From JSON","command":"uptime"}]}`), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "broken.yml"), []byte("templates: [oops"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0644)
+
+	result, err := ImportTemplateDir(dir, TemplateConflictSkip, "system")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Created) != 2 || len(result.Failed) != 1 || result.Failed[0].Name != "broken.yml" {
+		t.Fatalf("unexpected dir import result: %+v", result)
+	}
+	tmpl := TaskTemplate{}
+	Db.Where("name = ?", "From YAML").First(&tmpl)
+	if len(tmpl.Parameters) != 1 || tmpl.Parameters[0].Type != TemplateParamString || tmpl.Parameters[0].Default != "hello" {
+		t.Fatalf("yaml parameters not imported: %+v", tmpl.Parameters)
+	}
+
+	// 再次加载不会产生重复模板
+	result, _ = ImportTemplateDir(dir, TemplateConflictSkip, "system")
+	if len(result.Created) != 0 || len(result.Skipped) != 2 {
+		t.Fatalf("expected second load to skip, got %+v", result)
+	}
+}
@@ -0,0 +1,260 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 模板参数类型
+const (
+	TemplateParamString = "string"
+	TemplateParamInt    = "int"
+	TemplateParamBool   = "bool"
+	TemplateParamEnum   = "enum"
+)
+
+// 模板参数校验失败原因
+const (
+	TemplateParamErrRequired = "required"
+	TemplateParamErrType     = "type"
+	TemplateParamErrPattern  = "pattern"
+	TemplateParamErrOption   = "option"
+)
+
+// 模板占位符 {{name}}，与通知模板的 {{.Field}} 语法区分
+var templatePlaceholderRegexp = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+var templateParamNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// TemplateParameter 模板声明的参数
+type TemplateParameter struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Default     string   `json:"default"`
+	Required    bool     `json:"required"`
+	Pattern     string   `json:"pattern"`
+	Description string   `json:"description"`
+	Options     []string `json:"options,omitempty"`
+}
+
+// TemplateParameters 以 JSON 文本存储的参数列表
+type TemplateParameters []TemplateParameter
+
+func (p TemplateParameters) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (p *TemplateParameters) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported template parameters type: %T", value)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		*p = nil
+		return nil
+	}
+	return json.Unmarshal(data, p)
+}
+
+// TemplateParamError 单个参数的校验错误
+type TemplateParamError struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Validate 校验参数声明本身是否合法（保存模板时调用）
+func (p TemplateParameters) Validate() error {
+	seen := make(map[string]bool, len(p))
+	for _, param := range p {
+		if !templateParamNameRegexp.MatchString(param.Name) {
+			return fmt.Errorf("invalid parameter name: %q", param.Name)
+		}
+		if seen[param.Name] {
+			return fmt.Errorf("duplicate parameter: %s", param.Name)
+		}
+		seen[param.Name] = true
+		switch param.Type {
+		case TemplateParamString, TemplateParamInt, TemplateParamBool:
+		case TemplateParamEnum:
+			if len(param.Options) == 0 {
+				return fmt.Errorf("parameter %s: enum requires options", param.Name)
+			}
+		default:
+			return fmt.Errorf("parameter %s: unsupported type %q", param.Name, param.Type)
+		}
+		if param.Pattern != "" {
+			if _, err := regexp.Compile(param.Pattern); err != nil {
+				return fmt.Errorf("parameter %s: invalid pattern: %s", param.Name, err)
+			}
+		}
+		if param.Default != "" {
+			if reason := param.check(param.Default); reason != "" {
+				return fmt.Errorf("parameter %s: default value fails %s check", param.Name, reason)
+			}
+		}
+	}
+
+	return nil
+}
+
+// check 校验单个值，返回失败原因，空字符串表示通过
+func (param TemplateParameter) check(value string) string {
+	switch param.Type {
+	case TemplateParamInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return TemplateParamErrType
+		}
+	case TemplateParamBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return TemplateParamErrType
+		}
+	case TemplateParamEnum:
+		found := false
+		for _, option := range param.Options {
+			if option == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return TemplateParamErrOption
+		}
+	}
+	if param.Pattern != "" {
+		re, err := regexp.Compile(param.Pattern)
+		if err != nil || !re.MatchString(value) {
+			return TemplateParamErrPattern
+		}
+	}
+
+	return ""
+}
+
+// EffectiveParameters 返回模板参数；未声明参数的旧模板从占位符推断为必填字符串参数
+func (t *TaskTemplate) EffectiveParameters() TemplateParameters {
+	if len(t.Parameters) > 0 {
+		return t.Parameters
+	}
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, field := range []string{t.Command, t.HttpBody, t.HttpHeaders, t.SuccessPattern} {
+		for _, match := range templatePlaceholderRegexp.FindAllStringSubmatch(field, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	params := make(TemplateParameters, 0, len(names))
+	for _, name := range names {
+		params = append(params, TemplateParameter{Name: name, Type: TemplateParamString, Required: true})
+	}
+
+	return params
+}
+
+// Render 使用参数值替换模板中的占位符，返回渲染后的模板副本和校验错误。
+// 未提供的值使用默认值，bool 统一规范为 true/false。
+// Shell 模板命令中的值用单引号包裹，占位符不需要也不应该再加引号
+func (t *TaskTemplate) Render(values map[string]string) (TaskTemplate, []TemplateParamError) {
+	rendered := *t
+	resolved := make(map[string]string)
+	errs := make([]TemplateParamError, 0)
+
+	for _, param := range t.EffectiveParameters() {
+		value, ok := values[param.Name]
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			value = param.Default
+		}
+		if value == "" {
+			if param.Required {
+				errs = append(errs, TemplateParamError{Name: param.Name, Reason: TemplateParamErrRequired})
+			}
+			resolved[param.Name] = ""
+			continue
+		}
+		if reason := param.check(value); reason != "" {
+			errs = append(errs, TemplateParamError{Name: param.Name, Reason: reason})
+			continue
+		}
+		if param.Type == TemplateParamBool {
+			b, _ := strconv.ParseBool(value)
+			value = strconv.FormatBool(b)
+		}
+		resolved[param.Name] = value
+	}
+	if len(errs) > 0 {
+		return rendered, errs
+	}
+
+	replace := func(s string, quote bool) string {
+		return templatePlaceholderRegexp.ReplaceAllStringFunc(s, func(match string) string {
+			name := templatePlaceholderRegexp.FindStringSubmatch(match)[1]
+			value, ok := resolved[name]
+			if !ok {
+				// 未声明的占位符原样保留
+				return match
+			}
+			if quote {
+				return shellQuote(value)
+			}
+			return value
+		})
+	}
+	// 参数值中的引号、分号、$()、反引号等不能被 Shell 解释
+	rendered.Command = replace(t.Command, t.Protocol == int8(TaskRPC))
+	rendered.HttpBody = replace(t.HttpBody, false)
+	rendered.HttpHeaders = replace(t.HttpHeaders, false)
+	rendered.SuccessPattern = replace(t.SuccessPattern, false)
+
+	return rendered, nil
+}
+
+// shellQuote 用单引号包裹值，值中的单引号先结束引号、转义后再重新开始引号
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// ParseTemplateParameters 解析前端提交的参数声明 JSON
+func ParseTemplateParameters(raw string) (TemplateParameters, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var params TemplateParameters
+	if err := json.Unmarshal([]byte(raw), &params); err != nil {
+		return nil, errors.New("parameters must be a JSON array")
+	}
+	for i := range params {
+		params[i].Name = strings.TrimSpace(params[i].Name)
+		if params[i].Type == "" {
+			params[i].Type = TemplateParamString
+		}
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
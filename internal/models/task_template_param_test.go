@@ -0,0 +1,173 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestTaskTemplate_RenderWithParameters(t *testing.T) {
+	tmpl := &TaskTemplate{
+		Command:     "backup {{ db_name }} --port={{port}} --gzip={{gzip}} --mode={{mode}} {{unknown}}",
+		HttpHeaders: `{"X-Db": "{{db_name}}"}`,
+		Parameters: TemplateParameters{
+			{Name: "db_name", Type: TemplateParamString, Required: true, Pattern: `^[a-z_]+$`},
+			{Name: "port", Type: TemplateParamInt, Default: "3306"},
+			{Name: "gzip", Type: TemplateParamBool, Default: "1"},
+			{Name: "mode", Type: TemplateParamEnum, Default: "full", Options: []string{"full", "incr"}},
+		},
+	}
+
+	rendered, errs := tmpl.Render(map[string]string{"db_name": "orders", "mode": "incr"})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+	want := "backup orders --port=3306 --gzip=true --mode=incr {{unknown}}"
+	if rendered.Command != want {
+		t.Fatalf("command = %q, want %q", rendered.Command, want)
+	}
+	if rendered.HttpHeaders != `{"X-Db": "orders"}` {
+		t.Fatalf("headers not rendered: %q", rendered.HttpHeaders)
+	}
+	// 原模板不应被修改
+	if tmpl.Command == rendered.Command {
+		t.Fatal("render must not mutate the template")
+	}
+
+	_, errs = tmpl.Render(map[string]string{"db_name": "Orders-1", "port": "abc", "mode": "diff"})
+	reasons := make(map[string]string)
+	for _, e := range errs {
+		reasons[e.Name] = e.Reason
+	}
+	if reasons["db_name"] != TemplateParamErrPattern || reasons["port"] != TemplateParamErrType || reasons["mode"] != TemplateParamErrOption {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+
+	_, errs = tmpl.Render(nil)
+	if len(errs) != 1 || errs[0].Name != "db_name" || errs[0].Reason != TemplateParamErrRequired {
+		t.Fatalf("expected required error for db_name, got %+v", errs)
+	}
+}
+
+func TestTaskTemplate_EffectiveParametersInferred(t *testing.T) {
+	tmpl := &TaskTemplate{Command: "curl {{url}} && echo {{url}} {{ token }}"}
+
+	params := tmpl.EffectiveParameters()
+	if len(params) != 2 || params[0].Name != "url" || params[1].Name != "token" || !params[0].Required {
+		t.Fatalf("unexpected inferred parameters: %+v", params)
+	}
+	if _, errs := tmpl.Render(map[string]string{"url": "http://a"}); len(errs) != 1 {
+		t.Fatalf("expected missing token to fail, got %+v", errs)
+	}
+}
+
+func TestParseTemplateParameters(t *testing.T) {
+	params, err := ParseTemplateParameters(`[{"name":" host "},{"name":"level","type":"enum","options":["a","b"],"default":"a"}]`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if params[0].Name != "host" || params[0].Type != TemplateParamString {
+		t.Fatalf("expected trimmed name and default type, got %+v", params[0])
+	}
+
+	invalid := []string{
+		`{"name":"x"}`,
+		`[{"name":"1bad"}]`,
+		`[{"name":"a"},{"name":"a"}]`,
+		`[{"name":"a","type":"float"}]`,
+		`[{"name":"a","type":"enum"}]`,
+		`[{"name":"a","pattern":"("}]`,
+		`[{"name":"a","type":"int","default":"x"}]`,
+	}
+	for _, raw := range invalid {
+		if _, err := ParseTemplateParameters(raw); err == nil {
+			t.Errorf("expected error for %s", raw)
+		}
+	}
+}
+
+func TestTaskTemplate_ParametersPersisted(t *testing.T) {
+	cleanup := setupTemplateTestDB(t)
+	defer cleanup()
+
+	tmpl := &TaskTemplate{
+		Name:       "Param Template",
+		Command:    "echo {{msg}}",
+		Parameters: TemplateParameters{{Name: "msg", Type: TemplateParamString, Default: "hi"}},
+	}
+	id, err := tmpl.Create()
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	loaded, err := tmpl.Detail(id)
+	if err != nil {
+		t.Fatalf("detail failed: %v", err)
+	}
+	if len(loaded.Parameters) != 1 || loaded.Parameters[0].Default != "hi" {
+		t.Fatalf("parameters not round-tripped: %+v", loaded.Parameters)
+	}
+}
+
+func TestBackfillBuiltinTemplateParameters(t *testing.T) {
+	cleanup := setupTemplateTestDB(t)
+	defer cleanup()
+
+	builtin := builtinTemplates()[0]
+	builtin.Parameters = nil
+	if _, err := builtin.Create(); err != nil {
+		t.Fatal(err)
+	}
+	custom := &TaskTemplate{Name: "custom", Command: "echo {{x}}"}
+	if _, err := custom.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backfillBuiltinTemplateParameters(Db); err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+
+	loaded, _ := builtin.Detail(builtin.Id)
+	if len(loaded.Parameters) == 0 {
+		t.Fatal("expected builtin template parameters to be backfilled")
+	}
+	loaded, _ = custom.Detail(custom.Id)
+	if len(loaded.Parameters) != 0 {
+		t.Fatalf("custom template must not be touched, got %+v", loaded.Parameters)
+	}
+}
+
+func TestTaskTemplate_RenderQuotesShellValues(t *testing.T) {
+	var mysql TaskTemplate
+	for _, tmpl := range builtinTemplates() {
+		if tmpl.Spec == "" {
+			t.Errorf("builtin template %s has no spec", tmpl.Name)
+		}
+		if tmpl.Name == "MySQL Database Backup" {
+			mysql = tmpl
+		}
+	}
+
+	rendered, errs := mysql.Render(map[string]string{"db_pass": `p'a;$(id)`, "db_name": "orders"})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+	want := `mysqldump -h '127.0.0.1' -u 'root' -p'p'\''a;$(id)' 'orders' | gzip > /backup/'orders'_$(date +%Y%m%d_%H%M%S).sql.gz`
+	if rendered.Command != want {
+		t.Fatalf("command = %q, want %q", rendered.Command, want)
+	}
+
+	// HTTP 模板的地址和请求体不是 Shell 命令，不加引号
+	httpTmpl := &TaskTemplate{Protocol: int8(TaskHTTP), Command: "{{url}}", HttpBody: `{"id":"{{id}}"}`}
+	rendered, _ = httpTmpl.Render(map[string]string{"url": "https://example.com/a", "id": "1"})
+	if rendered.Command != "https://example.com/a" || rendered.HttpBody != `{"id":"1"}` {
+		t.Fatalf("http template should not be quoted: %q %q", rendered.Command, rendered.HttpBody)
+	}
+
+	path := TemplateParameter{Name: "dir", Type: TemplateParamString, Pattern: templatePathPattern}
+	for _, value := range []string{"/var/log;id", "/tmp/$(id)", "/tmp/`id`", "relative/path", "/tmp/a b"} {
+		if path.check(value) != TemplateParamErrPattern {
+			t.Errorf("path %q should be rejected", value)
+		}
+	}
+	if path.check("/var/log/app-1.d") != "" {
+		t.Error("plain absolute path should be accepted")
+	}
+}
@@ -92,6 +92,49 @@ func TestSetting_CreateWebhookUrl(t *testing.T) {
 	}
 }
 
+// TestSetting_UpdateWebhookUrl 测试带认证和签名配置的webhook地址的添加、查询和修改
+func TestSetting_UpdateWebhookUrl(t *testing.T) {
+	Db = setupTestDB(t)
+
+	setting := &Setting{}
+	id, err := setting.AddWebhookUrl(WebhookUrl{
+		Id:       99,
+		Name:     "Signed",
+		Url:      "https://test.example.com/hook",
+		Method:   "PUT",
+		Headers:  map[string]string{"X-Source": "gocron"},
+		AuthType: WebhookAuthBearer,
+		Token:    "t0ken",
+		Secret:   "s3cret",
+		Timeout:  10,
+	})
+	if err != nil || id == 0 {
+		t.Fatalf("failed to add webhook url: %d %v", id, err)
+	}
+
+	saved, err := setting.WebhookUrlById(id)
+	if err != nil {
+		t.Fatalf("failed to load webhook url: %v", err)
+	}
+	if saved.Id != id || saved.Method != "PUT" || saved.Headers["X-Source"] != "gocron" || saved.Token != "t0ken" || saved.Secret != "s3cret" || saved.Timeout != 10 {
+		t.Fatalf("unexpected webhook url: %+v", saved)
+	}
+
+	saved.Name = "Renamed"
+	saved.Secret = ""
+	if err := setting.UpdateWebhookUrl(saved); err != nil {
+		t.Fatalf("failed to update webhook url: %v", err)
+	}
+	webHook, _ := setting.Webhook()
+	if len(webHook.WebhookUrls) != 1 || webHook.WebhookUrls[0].Name != "Renamed" || webHook.WebhookUrls[0].Secret != "" {
+		t.Fatalf("unexpected webhook urls after update: %+v", webHook.WebhookUrls)
+	}
+
+	if _, err := setting.WebhookUrlById(id + 1); err == nil {
+		t.Fatal("expected error for missing webhook url")
+	}
+}
+
 // TestSetting_RemoveWebhookUrl 测试删除webhook地址
 func TestSetting_RemoveWebhookUrl(t *testing.T) {
 	db := setupTestDB(t)
@@ -0,0 +1,78 @@
+package artifact
+
+// 任务附件：服务端保存随任务分发的脚本、二进制和配置文件，
+// 执行 Shell 任务时把附件清单随命令发给节点，节点下载并校验后放在工作目录中执行命令
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+// Command 带附件的任务命令前缀，后面是 JSON 编码的 Job
+const Command = "__ARTIFACT__"
+
+const (
+	// MaxFileSize 单个附件的最大字节数
+	MaxFileSize = 32 << 20
+	// MaxFiles 每个任务的附件数量上限
+	MaxFiles = 20
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// File 节点执行前需要准备的附件
+type File struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	Sha256  string `json:"sha256"`
+	Size    int64  `json:"size"`
+	// Url 下载地址，带有效期的签名令牌
+	Url string `json:"url"`
+}
+
+// Job 节点执行的命令和附件，命令在放置附件的工作目录中执行
+type Job struct {
+	Files   []File `json:"files"`
+	Command string `json:"command"`
+}
+
+// ValidateName 附件只能是工作目录下的文件名，不能包含路径
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid file name %q, use letters, digits, dot, underscore and hyphen", name)
+	}
+	return nil
+}
+
+// EncodeCommand 生成发给节点的命令
+func EncodeCommand(job Job) (string, error) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+	return Command + "\n" + string(data), nil
+}
+
+// RunCommand 节点收到带附件的命令后下载附件并执行，payload 为去掉前缀的内容
+func RunCommand(ctx context.Context, cache *Cache, payload string) (string, error) {
+	var job Job
+	if err := json.Unmarshal([]byte(strings.TrimSpace(payload)), &job); err != nil {
+		return "", fmt.Errorf("invalid artifact job: %w", err)
+	}
+	if len(job.Files) == 0 {
+		return "", errors.New("invalid artifact job: no files")
+	}
+	dir, cleanup, err := cache.Prepare(ctx, job.Files)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	return utils.ExecShellInDir(ctx, dir, job.Command)
+}
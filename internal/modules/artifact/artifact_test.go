@@ -0,0 +1,144 @@
+package artifact
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// serveFiles 按路径返回文件内容，记录下载次数
+func serveFiles(t *testing.T, files map[string]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		hits.Add(1)
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestCacheFetch(t *testing.T) {
+	content := "#!/bin/bash\necho deployed\n"
+	server, hits := serveFiles(t, map[string]string{"/deploy.sh": content, "/other": "tampered"})
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := File{Name: "deploy.sh", Version: 1, Sha256: checksum(content), Size: int64(len(content)), Url: server.URL + "/deploy.sh"}
+
+	path, err := cache.Fetch(context.Background(), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Fatalf("unexpected cached content %q", data)
+	}
+	// 内容相同时使用缓存
+	if _, err := cache.Fetch(context.Background(), file); err != nil || hits.Load() != 1 {
+		t.Fatalf("expected cached file, downloads %d %v", hits.Load(), err)
+	}
+	// 缓存文件被修改时重新下载
+	if err := os.WriteFile(path, []byte("corrupted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Fetch(context.Background(), file); err != nil || hits.Load() != 2 {
+		t.Fatalf("expected download after corruption, downloads %d %v", hits.Load(), err)
+	}
+
+	for _, tt := range []struct {
+		file File
+		want string
+	}{
+		{File{Sha256: checksum(content), Size: int64(len(content)), Url: server.URL + "/other"}, "size mismatch"},
+		{File{Sha256: checksum(content), Size: 8, Url: server.URL + "/other"}, "checksum mismatch"},
+		{File{Sha256: checksum(content), Size: 1, Url: server.URL + "/missing"}, "HTTP 404"},
+		{File{Sha256: "abc", Url: server.URL + "/deploy.sh"}, "invalid checksum"},
+	} {
+		// 使用独立缓存，避免命中上面已缓存的文件
+		fresh, _ := NewCache(t.TempDir())
+		if _, err := fresh.Fetch(context.Background(), tt.file); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("expected %q, got %v", tt.want, err)
+		}
+		if entries, _ := os.ReadDir(fresh.Dir); len(entries) != 0 {
+			t.Errorf("failed download should not be cached: %v", entries)
+		}
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses bash")
+	}
+	// 结果写入文件，不依赖输出管道
+	script := "#!/bin/bash\necho \"deploying $1 from $(basename \"$PWD\" | cut -c1-16)\" > \"$2\"\ncat config.ini >> \"$2\"\n"
+	result := filepath.Join(t.TempDir(), "result.txt")
+	config := "env=prod\n"
+	server, _ := serveFiles(t, map[string]string{"/1": script, "/2": config})
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	command, err := EncodeCommand(Job{Command: "./deploy.sh v2 " + result, Files: []File{
+		{Name: "deploy.sh", Version: 3, Sha256: checksum(script), Size: int64(len(script)), Url: server.URL + "/1"},
+		{Name: "config.ini", Version: 1, Sha256: checksum(config), Size: int64(len(config)), Url: server.URL + "/2"},
+	}})
+	if err != nil || !strings.HasPrefix(command, Command+"\n") {
+		t.Fatalf("unexpected command %q %v", command, err)
+	}
+
+	if _, err := RunCommand(context.Background(), cache, strings.TrimPrefix(command, Command)); err != nil {
+		t.Fatal(err)
+	}
+	if output, _ := os.ReadFile(result); !strings.Contains(string(output), "deploying v2 from gocron_artifact_") || !strings.Contains(string(output), "env=prod") {
+		t.Fatalf("unexpected result %s", output)
+	}
+	// 工作目录中的附件可以执行，清理后删除
+	dir, cleanup, err := cache.Prepare(context.Background(), []File{{Name: "deploy.sh", Version: 3, Sha256: checksum(script), Size: int64(len(script))}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "deploy.sh")); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Fatalf("artifact should be executable: %v %v", info, err)
+	}
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("work dir not removed: %v", err)
+	}
+
+	bad, _ := EncodeCommand(Job{Command: "ls", Files: []File{{Name: "../escape.sh", Sha256: checksum(config), Size: 9, Url: server.URL + "/2"}}})
+	if _, err := RunCommand(context.Background(), cache, strings.TrimPrefix(bad, Command)); err == nil || !strings.Contains(err.Error(), "invalid file name") {
+		t.Fatalf("expected invalid name error, got %v", err)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"deploy.sh", "app-v1.2.tar.gz", "config_prod.ini", "Makefile"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", ".env", "..", "../deploy.sh", "dir/deploy.sh", `dir\deploy.sh`, "a b.sh", strings.Repeat("a", 129)} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("expected error for %q", name)
+		}
+	}
+}
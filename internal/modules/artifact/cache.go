@@ -0,0 +1,180 @@
+package artifact
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Cache 节点上的附件缓存，文件按 SHA-256 保存，内容相同的版本只下载一次
+type Cache struct {
+	Dir    string
+	Client *http.Client
+}
+
+// NewCache 创建缓存目录
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Cache{Dir: dir, Client: &http.Client{}}, nil
+}
+
+// DefaultDir 默认缓存目录
+func DefaultDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "gocron-node", "artifacts")
+	}
+	return filepath.Join(os.TempDir(), "gocron-node-artifacts")
+}
+
+// Prepare 准备附件并复制到新建的工作目录，返回目录和清理函数
+func (c *Cache) Prepare(ctx context.Context, files []File) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "gocron_artifact_*")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	for _, f := range files {
+		if err := ValidateName(f.Name); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+		path, err := c.Fetch(ctx, f)
+		if err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("artifact %s v%d: %w", f.Name, f.Version, err)
+		}
+		if err := copyFile(path, filepath.Join(dir, f.Name)); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("artifact %s v%d: %w", f.Name, f.Version, err)
+		}
+	}
+	return dir, cleanup, nil
+}
+
+// Fetch 返回校验通过的缓存文件，缓存不存在或校验失败时重新下载
+func (c *Cache) Fetch(ctx context.Context, f File) (string, error) {
+	if !sha256Pattern.MatchString(f.Sha256) {
+		return "", fmt.Errorf("invalid checksum %q", f.Sha256)
+	}
+	path := filepath.Join(c.Dir, f.Sha256)
+	if sum, err := fileSha256(path); err == nil {
+		if sum == f.Sha256 {
+			// 更新修改时间，Prune 按最近使用时间清理
+			now := time.Now()
+			_ = os.Chtimes(path, now, now)
+			return path, nil
+		}
+		// 缓存文件被修改或损坏
+		_ = os.Remove(path)
+	}
+	if err := c.download(ctx, f, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// download 下载到临时文件，校验大小和 SHA-256 后移动到缓存
+func (c *Cache) download(ctx context.Context, f File, path string) error {
+	if f.Url == "" {
+		return fmt.Errorf("download url is empty")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.Url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "gocron-node")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".download_*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(resp.Body, f.Size+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	if n != f.Size {
+		return fmt.Errorf("size mismatch: expected %d bytes, got %d", f.Size, n)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != f.Sha256 {
+		return fmt.Errorf("checksum mismatch: expected sha256 %s, got %s", f.Sha256, sum)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Prune 删除超过 maxAge 未使用的缓存文件
+func (c *Cache) Prune(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		// 中断的下载留下的临时文件同样清理
+		if sha256Pattern.MatchString(entry.Name()) || strings.HasPrefix(entry.Name(), ".download_") {
+			if os.Remove(filepath.Join(c.Dir, entry.Name())) == nil {
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+func fileSha256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// copyFile 复制到工作目录并设置执行权限，任务命令可以直接运行脚本
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0700)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,214 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// maxReflectionFiles 通过反射获取的文件数量上限
+const maxReflectionFiles = 500
+
+var errNoFiles = errors.New("descriptor set contains no files")
+
+// ParseDescriptorSet 解析描述文件集，必须包含所有依赖（protoc --include_imports）
+func ParseDescriptorSet(data []byte) (*protoregistry.Files, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	if len(set.GetFile()) == 0 {
+		return nil, errNoFiles
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set (build with --include_imports): %w", err)
+	}
+	return files, nil
+}
+
+// Services 描述文件中定义的服务全名
+func Services(files *protoregistry.Files) []string {
+	services := make([]string, 0)
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := 0; i < fd.Services().Len(); i++ {
+			services = append(services, string(fd.Services().Get(i).FullName()))
+		}
+		return true
+	})
+	sort.Strings(services)
+	return services
+}
+
+// reflectionStream 服务端反射 v1 和 v1alpha 的公共操作，返回序列化的 FileDescriptorProto
+type reflectionStream interface {
+	fileContainingSymbol(symbol string) ([][]byte, error)
+	fileByFilename(name string) ([][]byte, error)
+}
+
+// resolveByReflection 通过服务端反射获取服务所在文件及其依赖，服务端只支持 v1alpha 时自动降级
+func resolveByReflection(ctx context.Context, conn *grpc.ClientConn, serviceName string) (*protoregistry.Files, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	v1, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection: %w", err)
+	}
+	files, err := resolveFiles(v1Stream{v1}, serviceName)
+	if status.Code(err) != codes.Unimplemented {
+		return files, err
+	}
+	v1alpha, err := reflectionv1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection: %w", err)
+	}
+	files, err = resolveFiles(v1alphaStream{v1alpha}, serviceName)
+	if status.Code(err) == codes.Unimplemented {
+		return nil, fmt.Errorf("server reflection is not enabled on the target, upload a descriptor set instead: %w", err)
+	}
+	return files, err
+}
+
+func resolveFiles(stream reflectionStream, serviceName string) (*protoregistry.Files, error) {
+	protos := make(map[string]*descriptorpb.FileDescriptorProto)
+	add := func(raw [][]byte) error {
+		for _, data := range raw {
+			fd := new(descriptorpb.FileDescriptorProto)
+			if err := proto.Unmarshal(data, fd); err != nil {
+				return fmt.Errorf("invalid file descriptor from server reflection: %w", err)
+			}
+			protos[fd.GetName()] = fd
+		}
+		return nil
+	}
+	raw, err := stream.fileContainingSymbol(serviceName)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("service %s not found by server reflection", serviceName)
+		}
+		return nil, err
+	}
+	if err := add(raw); err != nil {
+		return nil, err
+	}
+
+	// 服务端通常会一起返回依赖，缺少的依赖逐个获取，内置的公共类型（google/protobuf 等）兜底
+	for len(protos) <= maxReflectionFiles {
+		missing := missingDependency(protos)
+		if missing == "" {
+			break
+		}
+		raw, err := stream.fileByFilename(missing)
+		if err == nil {
+			err = add(raw)
+		}
+		if protos[missing] == nil {
+			global, globalErr := protoregistry.GlobalFiles.FindFileByPath(missing)
+			if globalErr != nil {
+				if err == nil {
+					err = fmt.Errorf("not returned by server")
+				}
+				return nil, fmt.Errorf("dependency %s: %w", missing, err)
+			}
+			protos[missing] = protodesc.ToFileDescriptorProto(global)
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range protos {
+		set.File = append(set.File, fd)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptors from server reflection: %w", err)
+	}
+	return files, nil
+}
+
+func missingDependency(protos map[string]*descriptorpb.FileDescriptorProto) string {
+	names := make([]string, 0, len(protos))
+	for name := range protos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, dep := range protos[name].GetDependency() {
+			if protos[dep] == nil {
+				return dep
+			}
+		}
+	}
+	return ""
+}
+
+type v1Stream struct {
+	stream reflectionv1.ServerReflection_ServerReflectionInfoClient
+}
+
+func (s v1Stream) request(req *reflectionv1.ServerReflectionRequest) ([][]byte, error) {
+	if err := s.stream.Send(req); err != nil {
+		return nil, err
+	}
+	resp, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+	}
+	return resp.GetFileDescriptorResponse().GetFileDescriptorProto(), nil
+}
+
+func (s v1Stream) fileContainingSymbol(symbol string) ([][]byte, error) {
+	return s.request(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+}
+
+func (s v1Stream) fileByFilename(name string) ([][]byte, error) {
+	return s.request(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+	})
+}
+
+type v1alphaStream struct {
+	stream reflectionv1alpha.ServerReflection_ServerReflectionInfoClient
+}
+
+func (s v1alphaStream) request(req *reflectionv1alpha.ServerReflectionRequest) ([][]byte, error) {
+	if err := s.stream.Send(req); err != nil {
+		return nil, err
+	}
+	resp, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+	}
+	return resp.GetFileDescriptorResponse().GetFileDescriptorProto(), nil
+}
+
+func (s v1alphaStream) fileContainingSymbol(symbol string) ([][]byte, error) {
+	return s.request(&reflectionv1alpha.ServerReflectionRequest{
+		MessageRequest: &reflectionv1alpha.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+}
+
+func (s v1alphaStream) fileByFilename(name string) ([][]byte, error) {
+	return s.request(&reflectionv1alpha.ServerReflectionRequest{
+		MessageRequest: &reflectionv1alpha.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+	})
+}
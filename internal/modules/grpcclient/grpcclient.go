@@ -0,0 +1,239 @@
+package grpcclient
+
+// gRPC 任务：按方法全名调用任意 gRPC 服务。方法定义通过服务端反射或上传的描述文件集获取，
+// 请求和响应使用 JSON 编码，非 OK 状态码视为失败
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	// MaxOutputSize 写入日志的响应长度上限（字节），超出部分截断
+	MaxOutputSize = 64 * 1024
+	maxMetadata   = 50
+)
+
+// Request 一次调用
+type Request struct {
+	// Target 服务地址，如 orders.internal:50051、dns:///orders:443
+	Target string
+	// Method 方法全名，如 package.Service/Method
+	Method string
+	// Body 请求消息的 JSON，为空时发送空消息
+	Body     string
+	Metadata metadata.MD
+	// TLS 为 nil 时使用明文连接
+	TLS *tls.Config
+	// Descriptor 上传的描述文件集（protoc --include_imports --descriptor_set_out），为空时使用服务端反射
+	Descriptor []byte
+}
+
+// Call 调用方法，返回 JSON 编码的响应；状态码不是 OK 时返回状态、消息和详情
+func Call(ctx context.Context, req Request) (string, error) {
+	serviceName, methodName, err := ParseMethod(req.Method)
+	if err != nil {
+		return "", err
+	}
+	var creds credentials.TransportCredentials = insecure.NewCredentials()
+	if req.TLS != nil {
+		creds = credentials.NewTLS(req.TLS)
+	}
+	conn, err := grpc.NewClient(strings.TrimSpace(req.Target),
+		grpc.WithTransportCredentials(creds), grpc.WithUserAgent("gocron"))
+	if err != nil {
+		return "", fmt.Errorf("invalid target: %w", err)
+	}
+	defer conn.Close()
+
+	var files *protoregistry.Files
+	if len(req.Descriptor) > 0 {
+		files, err = ParseDescriptorSet(req.Descriptor)
+	} else {
+		files, err = resolveByReflection(ctx, conn, serviceName)
+	}
+	if err != nil {
+		return "", err
+	}
+	method, err := findMethod(files, serviceName, methodName)
+	if err != nil {
+		return "", err
+	}
+
+	types := dynamicpb.NewTypes(files)
+	input := dynamicpb.NewMessage(method.Input())
+	if strings.TrimSpace(req.Body) != "" {
+		if err := (protojson.UnmarshalOptions{Resolver: types}).Unmarshal([]byte(req.Body), input); err != nil {
+			return "", fmt.Errorf("invalid request body for %s: %w", method.Input().FullName(), err)
+		}
+	}
+	output := dynamicpb.NewMessage(method.Output())
+	if len(req.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, req.Metadata)
+	}
+	fullMethod := "/" + serviceName + "/" + methodName
+	if err := conn.Invoke(ctx, fullMethod, input, output); err != nil {
+		st := status.Convert(err)
+		return truncate(renderStatus(st, types)), fmt.Errorf("gRPC status %s: %s", st.Code(), st.Message())
+	}
+
+	data, err := (protojson.MarshalOptions{Multiline: true, Indent: "  ", Resolver: types}).Marshal(output)
+	if err != nil {
+		return "", fmt.Errorf("encode response: %w", err)
+	}
+	return truncate(string(data)), nil
+}
+
+// ParseMethod 解析方法全名，支持 package.Service/Method、/package.Service/Method 和 package.Service.Method
+func ParseMethod(s string) (string, string, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "/")
+	var serviceName, methodName string
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		serviceName, methodName = s[:i], s[i+1:]
+	} else if i := strings.LastIndex(s, "."); i >= 0 {
+		serviceName, methodName = s[:i], s[i+1:]
+	}
+	if !protoreflect.FullName(serviceName).IsValid() || !protoreflect.Name(methodName).IsValid() {
+		return "", "", fmt.Errorf("invalid method %q, expected package.Service/Method", s)
+	}
+	return serviceName, methodName, nil
+}
+
+func findMethod(files *protoregistry.Files, serviceName, methodName string) (protoreflect.MethodDescriptor, error) {
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found", serviceName)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("method %s not found in service %s", methodName, serviceName)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("streaming method %s is not supported", method.FullName())
+	}
+	return method, nil
+}
+
+var metadataKeyPattern = regexp.MustCompile(`^[0-9a-z_.-]+$`)
+
+// ParseMetadata 解析 JSON 对象形式的元数据，键以 -bin 结尾时值为 base64 编码的二进制
+func ParseMetadata(raw string) (metadata.MD, error) {
+	md := metadata.MD{}
+	if strings.TrimSpace(raw) == "" {
+		return md, nil
+	}
+	values := make(map[string]string)
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, fmt.Errorf("metadata must be a JSON object of strings: %w", err)
+	}
+	if len(values) > maxMetadata {
+		return nil, fmt.Errorf("at most %d metadata entries are allowed", maxMetadata)
+	}
+	for key, value := range values {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !metadataKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid metadata key %q", key)
+		}
+		if strings.HasPrefix(key, "grpc-") {
+			return nil, fmt.Errorf("metadata key %q is reserved", key)
+		}
+		if strings.HasSuffix(key, "-bin") {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("metadata %q: binary value must be base64 encoded", key)
+			}
+			value = string(decoded)
+		} else if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("metadata %q: value must not contain line breaks", key)
+		}
+		md.Append(key, value)
+	}
+	return md, nil
+}
+
+// renderStatus 非 OK 状态写入日志的内容
+func renderStatus(st *status.Status, types *dynamicpb.Types) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Status: %s\nMessage: %s\n", st.Code(), st.Message())
+	details := st.Proto().GetDetails()
+	if len(details) == 0 {
+		return b.String()
+	}
+	b.WriteString("Details:\n")
+	resolver := chainResolver{types}
+	for _, detail := range details {
+		data, err := (protojson.MarshalOptions{Multiline: true, Indent: "  ", Resolver: resolver}).Marshal(detail)
+		if err != nil {
+			// 无法解析的详情只输出类型
+			fmt.Fprintf(&b, "%s\n", detail.GetTypeUrl())
+			continue
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// chainResolver 先从描述文件中查找类型，再查找程序内置的类型（如 google.rpc 错误详情）
+type chainResolver struct {
+	types *dynamicpb.Types
+}
+
+func (r chainResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	if mt, err := r.types.FindMessageByName(name); err == nil {
+		return mt, nil
+	}
+	return protoregistry.GlobalTypes.FindMessageByName(name)
+}
+
+func (r chainResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	if mt, err := r.types.FindMessageByURL(url); err == nil {
+		return mt, nil
+	}
+	return protoregistry.GlobalTypes.FindMessageByURL(url)
+}
+
+func (r chainResolver) FindExtensionByName(name protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	if xt, err := r.types.FindExtensionByName(name); err == nil {
+		return xt, nil
+	}
+	return protoregistry.GlobalTypes.FindExtensionByName(name)
+}
+
+func (r chainResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	if xt, err := r.types.FindExtensionByNumber(message, field); err == nil {
+		return xt, nil
+	}
+	return protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+}
+
+func truncate(s string) string {
+	if len(s) <= MaxOutputSize {
+		return s
+	}
+	cut := MaxOutputSize
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "\n...(truncated)"
+}
@@ -0,0 +1,197 @@
+package grpcclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// startHealthServer 启动只提供健康检查服务的 gRPC 服务，返回地址和收到的元数据
+func startHealthServer(t *testing.T, withReflection bool, opts ...grpc.ServerOption) (string, chan metadata.MD) {
+	t.Helper()
+	received := make(chan metadata.MD, 10)
+	opts = append(opts, grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		received <- md
+		return handler(ctx, req)
+	}))
+	server := grpc.NewServer(opts...)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	if withReflection {
+		reflection.Register(server)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String(), received
+}
+
+func callContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestCallWithReflection(t *testing.T) {
+	addr, received := startHealthServer(t, true)
+	md, err := ParseMetadata(`{"X-Request-Source":"gocron","trace-bin":"AAEC"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := Call(callContext(t), Request{Target: addr, Method: "grpc.health.v1.Health/Check", Body: `{"service":"orders"}`, Metadata: md})
+	if err != nil || !strings.Contains(output, `"status":`) || !strings.Contains(output, "NOT_SERVING") {
+		t.Fatalf("unexpected result %v\n%s", err, output)
+	}
+	got := <-received
+	if v := got.Get("x-request-source"); len(v) != 1 || v[0] != "gocron" {
+		t.Errorf("metadata not sent: %v", got)
+	}
+	if v := got.Get("trace-bin"); len(v) != 1 || v[0] != "\x00\x01\x02" {
+		t.Errorf("binary metadata not decoded: %v", got)
+	}
+
+	// 非 OK 状态码视为失败
+	output, err = Call(callContext(t), Request{Target: addr, Method: "/grpc.health.v1.Health/Check", Body: `{"service":"missing"}`})
+	if err == nil || !strings.Contains(err.Error(), "NotFound") || !strings.HasPrefix(output, "Status: NotFound\nMessage: unknown service") {
+		t.Fatalf("expected NotFound, got %v\n%s", err, output)
+	}
+
+	for _, tt := range []struct{ method, body, want string }{
+		{"grpc.health.v1.Health/Watch", "", "streaming method"},
+		{"grpc.health.v1.Health/Missing", "", "method Missing not found"},
+		{"orders.Orders/Sync", "", "service orders.Orders not found"},
+		{"grpc.health.v1.Health/Check", `{"unknown":1}`, "invalid request body"},
+	} {
+		if _, err := Call(callContext(t), Request{Target: addr, Method: tt.method, Body: tt.body}); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s %s: expected %q, got %v", tt.method, tt.body, tt.want, err)
+		}
+	}
+}
+
+func TestCallWithDescriptorSet(t *testing.T) {
+	addr, _ := startHealthServer(t, false)
+	if _, err := Call(callContext(t), Request{Target: addr, Method: "grpc.health.v1.Health/Check"}); err == nil || !strings.Contains(err.Error(), "server reflection is not enabled") {
+		t.Fatalf("expected reflection error, got %v", err)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+	}}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := ParseDescriptorSet(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if services := Services(files); len(services) != 1 || services[0] != "grpc.health.v1.Health" {
+		t.Fatalf("unexpected services %v", services)
+	}
+	output, err := Call(callContext(t), Request{Target: addr, Method: "grpc.health.v1.Health.Check", Descriptor: data})
+	if err != nil || !strings.Contains(output, "SERVING") {
+		t.Fatalf("unexpected result %v\n%s", err, output)
+	}
+
+	if _, err := ParseDescriptorSet([]byte("not a descriptor")); err == nil {
+		t.Fatal("expected error for invalid descriptor set")
+	}
+}
+
+func TestCallWithTLS(t *testing.T) {
+	serverCert, pool := selfSignedCert(t)
+	addr, _ := startHealthServer(t, true, grpc.Creds(credentials.NewServerTLSFromCert(&serverCert)))
+
+	output, err := Call(callContext(t), Request{Target: addr, Method: "grpc.health.v1.Health/Check", TLS: &tls.Config{RootCAs: pool}})
+	if err != nil || !strings.Contains(output, "SERVING") {
+		t.Fatalf("unexpected result %v\n%s", err, output)
+	}
+	// 未信任的证书
+	if _, err := Call(callContext(t), Request{Target: addr, Method: "grpc.health.v1.Health/Check", TLS: &tls.Config{}}); err == nil {
+		t.Fatal("expected certificate error")
+	}
+	// 明文连接 TLS 服务
+	if _, err := Call(callContext(t), Request{Target: addr, Method: "grpc.health.v1.Health/Check"}); err == nil {
+		t.Fatal("expected error for plaintext connection")
+	}
+}
+
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "grpc-test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+func TestParseMethod(t *testing.T) {
+	for _, s := range []string{"pkg.v1.Orders/Sync", "/pkg.v1.Orders/Sync", "pkg.v1.Orders.Sync", " pkg.v1.Orders/Sync "} {
+		service, method, err := ParseMethod(s)
+		if err != nil || service != "pkg.v1.Orders" || method != "Sync" {
+			t.Errorf("ParseMethod(%q) = %s %s %v", s, service, method, err)
+		}
+	}
+	for _, s := range []string{"", "Sync", "pkg.Orders/", "pkg..Orders/Sync", "pkg.Orders/Sync/Extra"} {
+		if _, _, err := ParseMethod(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	md, err := ParseMetadata(`{"Authorization":"Bearer x","tenant":"a"}`)
+	if err != nil || md.Get("authorization")[0] != "Bearer x" || md.Get("tenant")[0] != "a" {
+		t.Fatalf("unexpected metadata %v %v", md, err)
+	}
+	for _, s := range []string{`[]`, `{"a b":"x"}`, `{"grpc-timeout":"1S"}`, `{"trace-bin":"%%"}`, `{"x":"a\nb"}`, `{"x":1}`} {
+		if _, err := ParseMetadata(s); err == nil {
+			t.Errorf("expected error for %s", s)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package httpclient
+
+// 响应断言：可接受的状态码、JSONPath 断言和响应头断言
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 断言对象
+const (
+	AssertSourceJson   = "json"
+	AssertSourceHeader = "header"
+)
+
+// 断言比较符
+const (
+	AssertEqual       = "=="
+	AssertNotEqual    = "!="
+	AssertGreater     = ">"
+	AssertGreaterEq   = ">="
+	AssertLess        = "<"
+	AssertLessEq      = "<="
+	AssertContains    = "contains"
+	AssertNotContains = "not_contains"
+	AssertMatches     = "matches"
+	AssertExists      = "exists"
+	AssertNotExists   = "not_exists"
+)
+
+var assertOps = map[string]bool{
+	AssertEqual: true, AssertNotEqual: true, AssertGreater: true, AssertGreaterEq: true,
+	AssertLess: true, AssertLessEq: true, AssertContains: true, AssertNotContains: true,
+	AssertMatches: true, AssertExists: true, AssertNotExists: true,
+}
+
+const maxAssertions = 20
+
+// Assertion 单条断言。Source 为 json 时 Target 是 JSONPath，为 header 时是响应头名称
+type Assertion struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Op     string `json:"op"`
+	Value  string `json:"value"`
+}
+
+func (a Assertion) String() string {
+	if a.Op == AssertExists || a.Op == AssertNotExists {
+		return fmt.Sprintf("%s %s %s", a.Source, a.Target, a.Op)
+	}
+	return fmt.Sprintf("%s %s %s %q", a.Source, a.Target, a.Op, a.Value)
+}
+
+// ParseAssertions 解析并校验断言列表（JSON 数组）
+func ParseAssertions(s string) ([]Assertion, error) {
+	assertions := make([]Assertion, 0)
+	if strings.TrimSpace(s) == "" {
+		return assertions, nil
+	}
+	if err := json.Unmarshal([]byte(s), &assertions); err != nil {
+		return nil, fmt.Errorf("invalid assertions JSON: %w", err)
+	}
+	if len(assertions) > maxAssertions {
+		return nil, fmt.Errorf("at most %d assertions are allowed", maxAssertions)
+	}
+	for i, a := range assertions {
+		if err := a.validate(); err != nil {
+			return nil, fmt.Errorf("assertion %d: %w", i+1, err)
+		}
+	}
+
+	return assertions, nil
+}
+
+func (a Assertion) validate() error {
+	switch a.Source {
+	case AssertSourceJson:
+		if _, err := parseJSONPath(a.Target); err != nil {
+			return err
+		}
+	case AssertSourceHeader:
+		if strings.TrimSpace(a.Target) == "" {
+			return errors.New("header name is required")
+		}
+	default:
+		return fmt.Errorf("unsupported source %q", a.Source)
+	}
+	if !assertOps[a.Op] {
+		return fmt.Errorf("unsupported operator %q", a.Op)
+	}
+	switch a.Op {
+	case AssertGreater, AssertGreaterEq, AssertLess, AssertLessEq:
+		if _, err := strconv.ParseFloat(strings.TrimSpace(a.Value), 64); err != nil {
+			return fmt.Errorf("operator %s requires a number", a.Op)
+		}
+	case AssertMatches:
+		if _, err := regexp.Compile(a.Value); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Check 检查响应，通过时返回 nil，失败时返回包含实际值的说明
+func (a Assertion) Check(resp ResponseWrapper) error {
+	var actual interface{}
+	var found bool
+	switch a.Source {
+	case AssertSourceHeader:
+		values := resp.Header.Values(a.Target)
+		found = len(values) > 0
+		actual = strings.Join(values, ", ")
+	default:
+		var doc interface{}
+		decoder := json.NewDecoder(bytes.NewReader([]byte(resp.Body)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return fmt.Errorf("%s: response body is not JSON", a)
+		}
+		var err error
+		actual, found, err = lookupJSONPath(normalizeNumbers(doc), a.Target)
+		if err != nil {
+			return fmt.Errorf("%s: %s", a, err)
+		}
+	}
+
+	switch a.Op {
+	case AssertExists:
+		if !found {
+			return fmt.Errorf("%s: not found", a)
+		}
+		return nil
+	case AssertNotExists:
+		if found {
+			return fmt.Errorf("%s: found %s", a, formatAssertValue(actual))
+		}
+		return nil
+	}
+	if !found {
+		return fmt.Errorf("%s: not found", a)
+	}
+	if !compareAssertValue(actual, a.Op, a.Value) {
+		return fmt.Errorf("%s: actual %s", a, formatAssertValue(actual))
+	}
+
+	return nil
+}
+
+// normalizeNumbers json.Number 转为 float64，JSONPath length() 返回的也是 float64
+func normalizeNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if f, err := value.Float64(); err == nil {
+			return f
+		}
+		return value.String()
+	case []interface{}:
+		for i := range value {
+			value[i] = normalizeNumbers(value[i])
+		}
+	case map[string]interface{}:
+		for k := range value {
+			value[k] = normalizeNumbers(value[k])
+		}
+	}
+	return v
+}
+
+// formatAssertValue 字符串原样输出，其他值按 JSON 输出
+func formatAssertValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func assertString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// compareAssertValue 两边都是数字时按数值比较，否则按字符串比较；大小比较要求实际值为数字
+func compareAssertValue(actual interface{}, op, expected string) bool {
+	actualStr := assertString(actual)
+	actualNum, actualIsNum := actual.(float64)
+	if !actualIsNum {
+		if f, err := strconv.ParseFloat(actualStr, 64); err == nil && op != AssertEqual && op != AssertNotEqual {
+			actualNum, actualIsNum = f, true
+		}
+	}
+	expectedNum, expectedErr := strconv.ParseFloat(strings.TrimSpace(expected), 64)
+	numeric := actualIsNum && expectedErr == nil
+
+	switch op {
+	case AssertEqual, AssertNotEqual:
+		equal := actualStr == expected
+		if numeric {
+			equal = actualNum == expectedNum
+		}
+		return equal == (op == AssertEqual)
+	case AssertGreater:
+		return numeric && actualNum > expectedNum
+	case AssertGreaterEq:
+		return numeric && actualNum >= expectedNum
+	case AssertLess:
+		return numeric && actualNum < expectedNum
+	case AssertLessEq:
+		return numeric && actualNum <= expectedNum
+	case AssertContains:
+		return strings.Contains(actualStr, expected)
+	case AssertNotContains:
+		return !strings.Contains(actualStr, expected)
+	case AssertMatches:
+		re, err := regexp.Compile(expected)
+		return err == nil && re.MatchString(actualStr)
+	}
+
+	return false
+}
+
+// StatusCodes 可接受的状态码，例如 200-299,304
+type StatusCodes [][2]int
+
+// ParseStatusCodes 解析逗号分隔的状态码和范围，空字符串表示只接受 200
+func ParseStatusCodes(s string) (StatusCodes, error) {
+	if strings.TrimSpace(s) == "" {
+		return StatusCodes{{200, 200}}, nil
+	}
+	codes := make(StatusCodes, 0)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		low, high, isRange := strings.Cut(part, "-")
+		from, err := parseStatusCode(low)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = parseStatusCode(high); err != nil {
+				return nil, err
+			}
+			if to < from {
+				return nil, fmt.Errorf("invalid status code range %q", part)
+			}
+		}
+		codes = append(codes, [2]int{from, to})
+	}
+	if len(codes) == 0 {
+		return nil, errors.New("no status code")
+	}
+
+	return codes, nil
+}
+
+func parseStatusCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", s)
+	}
+	return code, nil
+}
+
+// Accept 状态码是否可接受
+func (codes StatusCodes) Accept(code int) bool {
+	for _, r := range codes {
+		if code >= r[0] && code <= r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseStatusCodes(t *testing.T) {
+	codes, err := ParseStatusCodes("200-299, 304")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for code, want := range map[int]bool{200: true, 204: true, 299: true, 304: true, 300: false, 404: false} {
+		if codes.Accept(code) != want {
+			t.Errorf("Accept(%d) = %v, want %v", code, !want, want)
+		}
+	}
+	if codes, _ := ParseStatusCodes(""); !codes.Accept(200) || codes.Accept(201) {
+		t.Fatal("empty codes should only accept 200")
+	}
+	for _, invalid := range []string{"abc", "99", "600", "300-200", "2xx", ","} {
+		if _, err := ParseStatusCodes(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"code": float64(0),
+		"data": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"id": float64(1)},
+				map[string]interface{}{"id": float64(2)},
+			},
+			"a.b": "dotted",
+		},
+	}
+	tests := map[string]interface{}{
+		"$.code":                 float64(0),
+		"$.data.items[1].id":     float64(2),
+		"$.data.items[-1].id":    float64(2),
+		"$.data['a.b']":          "dotted",
+		`$["data"]["items"][0]`:  map[string]interface{}{"id": float64(1)},
+		"$.data.items.length()":  float64(2),
+		"$.data['a.b'].length()": float64(6),
+	}
+	for path, want := range tests {
+		got, found, err := lookupJSONPath(doc, path)
+		if err != nil || !found || formatAssertValue(got) != formatAssertValue(want) {
+			t.Errorf("%s: got %v %v %v", path, got, found, err)
+		}
+	}
+	for _, missing := range []string{"$.missing", "$.data.items[5]", "$.code.x", "$.code[0]"} {
+		if _, found, err := lookupJSONPath(doc, missing); err != nil || found {
+			t.Errorf("%s should not be found: %v", missing, err)
+		}
+	}
+	for _, invalid := range []string{"code", "$.", "$[abc]", "$.a[0", "$.length().x"} {
+		if _, err := parseJSONPath(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestAssertionCheck(t *testing.T) {
+	resp := ResponseWrapper{
+		StatusCode: 200,
+		Body:       `{"code": 0, "msg": "ok", "data": {"total": 15, "items": [1, 2, 3], "big": 12345678901234567890}}`,
+		Header:     http.Header{"Content-Type": {"application/json; charset=utf-8"}, "X-Rate-Remaining": {"42"}},
+	}
+	pass := []Assertion{
+		{Source: AssertSourceJson, Target: "$.code", Op: AssertEqual, Value: "0"},
+		{Source: AssertSourceJson, Target: "$.code", Op: AssertEqual, Value: "0.0"},
+		{Source: AssertSourceJson, Target: "$.msg", Op: AssertEqual, Value: "ok"},
+		{Source: AssertSourceJson, Target: "$.msg", Op: AssertNotEqual, Value: "error"},
+		{Source: AssertSourceJson, Target: "$.data.total", Op: AssertGreaterEq, Value: "10"},
+		{Source: AssertSourceJson, Target: "$.data.items.length()", Op: AssertLess, Value: "5"},
+		{Source: AssertSourceJson, Target: "$.data.items", Op: AssertContains, Value: "2,3"},
+		{Source: AssertSourceJson, Target: "$.msg", Op: AssertMatches, Value: "^o"},
+		{Source: AssertSourceJson, Target: "$.data", Op: AssertExists},
+		{Source: AssertSourceJson, Target: "$.error", Op: AssertNotExists},
+		{Source: AssertSourceJson, Target: "$.data.big", Op: AssertGreater, Value: "1e19"},
+		{Source: AssertSourceHeader, Target: "content-type", Op: AssertContains, Value: "json"},
+		{Source: AssertSourceHeader, Target: "X-Rate-Remaining", Op: AssertGreater, Value: "10"},
+		{Source: AssertSourceHeader, Target: "X-Missing", Op: AssertNotExists},
+	}
+	for _, a := range pass {
+		if err := a.Check(resp); err != nil {
+			t.Errorf("%s should pass: %v", a, err)
+		}
+	}
+
+	fail := map[Assertion]string{
+		{Source: AssertSourceJson, Target: "$.code", Op: AssertEqual, Value: "1"}:                `json $.code == "1": actual 0`,
+		{Source: AssertSourceJson, Target: "$.msg", Op: AssertGreater, Value: "1"}:               `actual "ok"`,
+		{Source: AssertSourceJson, Target: "$.missing", Op: AssertEqual, Value: "1"}:             "not found",
+		{Source: AssertSourceJson, Target: "$.code", Op: AssertNotExists}:                        "found 0",
+		{Source: AssertSourceHeader, Target: "Content-Type", Op: AssertEqual, Value: "text/xml"}: `actual "application/json; charset=utf-8"`,
+		{Source: AssertSourceHeader, Target: "X-Missing", Op: AssertExists}:                      "not found",
+	}
+	for a, want := range fail {
+		err := a.Check(resp)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected failure containing %q, got %v", a, want, err)
+		}
+	}
+
+	notJson := ResponseWrapper{StatusCode: 200, Body: "<html/>", Header: http.Header{}}
+	if err := (Assertion{Source: AssertSourceJson, Target: "$.code", Op: AssertExists}).Check(notJson); err == nil || !strings.Contains(err.Error(), "not JSON") {
+		t.Fatalf("expected not JSON failure, got %v", err)
+	}
+}
+
+func TestParseAssertions(t *testing.T) {
+	assertions, err := ParseAssertions(`[{"source":"json","target":"$.code","op":"==","value":"0"},{"source":"header","target":"X-Id","op":"exists"}]`)
+	if err != nil || len(assertions) != 2 {
+		t.Fatalf("unexpected result: %+v %v", assertions, err)
+	}
+	if assertions, err := ParseAssertions(" "); err != nil || len(assertions) != 0 {
+		t.Fatalf("empty assertions should be valid: %v", err)
+	}
+	invalid := []string{
+		`{}`,
+		`[{"source":"body","target":"x","op":"=="}]`,
+		`[{"source":"json","target":"code","op":"=="}]`,
+		`[{"source":"json","target":"$.code","op":"~="}]`,
+		`[{"source":"json","target":"$.code","op":">","value":"abc"}]`,
+		`[{"source":"json","target":"$.code","op":"matches","value":"["}]`,
+		`[{"source":"header","target":" ","op":"exists"}]`,
+	}
+	for _, s := range invalid {
+		if _, err := ParseAssertions(s); err == nil {
+			t.Errorf("expected error for %s", s)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package httpclient
+
+// 异步任务：触发请求返回 202 和任务 id 后，轮询状态地址直到成功或失败条件满足，
+// 或者等待被调用方回调 gocron 生成的地址。
+// 状态地址、请求 Header 和请求体中的 {{.name}} 替换为从触发响应中提取的值，
+// {{.callback_url}} 替换为本次执行的回调地址
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// 异步模式
+const (
+	AsyncNone     = ""
+	AsyncPoll     = "poll"
+	AsyncCallback = "callback"
+)
+
+// AsyncCallbackVar 回调地址变量名
+const AsyncCallbackVar = "callback_url"
+
+const (
+	AsyncDefaultInterval = 10
+	AsyncDefaultTimeout  = 3600
+	asyncMaxTimeout      = 7 * 24 * 3600
+	asyncMaxExtract      = 10
+)
+
+// AsyncConfig 异步任务配置
+type AsyncConfig struct {
+	// Extract 变量名 => 取值位置，JSONPath（$.data.id）或 header:名称
+	Extract map[string]string `json:"extract,omitempty"`
+	// StatusUrl 轮询地址，可以是相对触发地址的路径
+	StatusUrl string `json:"status_url,omitempty"`
+	// Interval 轮询间隔（秒）
+	Interval int `json:"interval,omitempty"`
+	// Timeout 等待任务完成的最长时间（秒），不包含触发请求
+	Timeout int `json:"timeout,omitempty"`
+	// Success 成功条件，全部满足时任务成功；回调模式下为空表示收到回调即成功
+	Success []Assertion `json:"success,omitempty"`
+	// Failure 失败条件，全部满足时任务失败
+	Failure []Assertion `json:"failure,omitempty"`
+}
+
+// AsyncState 状态响应的判断结果
+type AsyncState int
+
+const (
+	AsyncPending AsyncState = iota
+	AsyncSucceeded
+	AsyncFailed
+)
+
+var (
+	asyncVarNamePattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	asyncPlaceholderPattern = regexp.MustCompile(`\{\{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+)
+
+// ParseAsyncConfig 解析并校验异步配置，异步模式为空时返回空配置。未设置的间隔和超时取默认值
+func ParseAsyncConfig(mode, s string) (AsyncConfig, error) {
+	var config AsyncConfig
+	if mode == AsyncNone {
+		return config, nil
+	}
+	if mode != AsyncPoll && mode != AsyncCallback {
+		return config, fmt.Errorf("unsupported async mode %q", mode)
+	}
+	if strings.TrimSpace(s) != "" {
+		if err := json.Unmarshal([]byte(s), &config); err != nil {
+			return config, fmt.Errorf("invalid async config JSON: %w", err)
+		}
+	}
+	if config.Interval == 0 {
+		config.Interval = AsyncDefaultInterval
+	}
+	if config.Timeout == 0 {
+		config.Timeout = AsyncDefaultTimeout
+	}
+	if config.Interval < 1 || config.Timeout < 1 || config.Timeout > asyncMaxTimeout {
+		return config, fmt.Errorf("interval must be at least 1 and timeout between 1 and %d seconds", asyncMaxTimeout)
+	}
+	if config.Interval > config.Timeout {
+		return config, errors.New("interval must not exceed timeout")
+	}
+	if len(config.Extract) > asyncMaxExtract {
+		return config, fmt.Errorf("at most %d variables can be extracted", asyncMaxExtract)
+	}
+	for name, source := range config.Extract {
+		if !asyncVarNamePattern.MatchString(name) || name == AsyncCallbackVar {
+			return config, fmt.Errorf("invalid variable name %q", name)
+		}
+		if header, ok := strings.CutPrefix(source, "header:"); ok {
+			if strings.TrimSpace(header) == "" {
+				return config, fmt.Errorf("variable %s: header name is required", name)
+			}
+		} else if _, err := parseJSONPath(source); err != nil {
+			return config, fmt.Errorf("variable %s: %w", name, err)
+		}
+	}
+	for _, group := range []struct {
+		name       string
+		assertions []Assertion
+	}{{"success", config.Success}, {"failure", config.Failure}} {
+		if len(group.assertions) > maxAssertions {
+			return config, fmt.Errorf("at most %d %s conditions are allowed", maxAssertions, group.name)
+		}
+		for i, a := range group.assertions {
+			if err := a.validate(); err != nil {
+				return config, fmt.Errorf("%s condition %d: %w", group.name, i+1, err)
+			}
+		}
+	}
+
+	if mode == AsyncPoll {
+		if strings.TrimSpace(config.StatusUrl) == "" {
+			return config, errors.New("status_url is required for polling")
+		}
+		if len(config.Success) == 0 {
+			return config, errors.New("at least one success condition is required for polling")
+		}
+		for _, match := range asyncPlaceholderPattern.FindAllStringSubmatch(config.StatusUrl, -1) {
+			if _, ok := config.Extract[match[1]]; !ok {
+				return config, fmt.Errorf("status_url uses undefined variable %q", match[1])
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// ExtractValues 从触发请求的响应中提取变量
+func (c AsyncConfig) ExtractValues(resp ResponseWrapper) (map[string]string, error) {
+	values := make(map[string]string, len(c.Extract))
+	if len(c.Extract) == 0 {
+		return values, nil
+	}
+	var doc interface{}
+	var docErr error
+	decoded := false
+	for name, source := range c.Extract {
+		if header, ok := strings.CutPrefix(source, "header:"); ok {
+			value := resp.Header.Get(strings.TrimSpace(header))
+			if value == "" {
+				return nil, fmt.Errorf("variable %s: header %s not found", name, header)
+			}
+			values[name] = value
+			continue
+		}
+		if !decoded {
+			decoder := json.NewDecoder(bytes.NewReader([]byte(resp.Body)))
+			decoder.UseNumber()
+			// 保留 json.Number，较大的数字 id 不会丢失精度
+			docErr = decoder.Decode(&doc)
+			decoded = true
+		}
+		if docErr != nil {
+			return nil, fmt.Errorf("variable %s: response body is not JSON", name)
+		}
+		value, found, err := lookupJSONPath(doc, source)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", name, err)
+		}
+		if !found || value == nil {
+			return nil, fmt.Errorf("variable %s: %s not found", name, source)
+		}
+		values[name] = assertString(value)
+	}
+
+	return values, nil
+}
+
+// Expand 替换 {{.name}} 占位符，未定义的变量保持原样
+func Expand(s string, values map[string]string) string {
+	return expand(s, values, func(v string) string { return v })
+}
+
+// ExpandStatusUrl 替换状态地址中的变量（按路径转义），相对地址按触发地址解析
+func ExpandStatusUrl(statusUrl, triggerUrl string, values map[string]string) (string, error) {
+	expanded := expand(strings.TrimSpace(statusUrl), values, url.PathEscape)
+	ref, err := url.Parse(expanded)
+	if err != nil {
+		return "", fmt.Errorf("invalid status_url: %w", err)
+	}
+	if !ref.IsAbs() {
+		base, err := url.Parse(triggerUrl)
+		if err != nil {
+			return "", fmt.Errorf("invalid status_url: %w", err)
+		}
+		ref = base.ResolveReference(ref)
+	}
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return "", fmt.Errorf("status_url must be an http(s) URL: %s", expanded)
+	}
+
+	return ref.String(), nil
+}
+
+func expand(s string, values map[string]string, escape func(string) string) string {
+	if len(values) == 0 || !strings.Contains(s, "{{") {
+		return s
+	}
+	return asyncPlaceholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		name := asyncPlaceholderPattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := values[name]; ok {
+			return escape(value)
+		}
+		return placeholder
+	})
+}
+
+// Evaluate 判断状态响应：先检查失败条件，再检查成功条件。
+// 返回 AsyncPending 时 error 说明未满足的成功条件
+func (c AsyncConfig) Evaluate(resp ResponseWrapper) (AsyncState, error) {
+	if len(c.Failure) > 0 && checkAll(c.Failure, resp) == nil {
+		return AsyncFailed, nil
+	}
+	if err := checkAll(c.Success, resp); err != nil {
+		return AsyncPending, err
+	}
+
+	return AsyncSucceeded, nil
+}
+
+// checkAll 全部断言通过时返回 nil，否则返回第一条失败的断言
+func checkAll(assertions []Assertion, resp ResponseWrapper) error {
+	for _, a := range assertions {
+		if err := a.Check(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
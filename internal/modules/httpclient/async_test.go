@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseAsyncConfig(t *testing.T) {
+	config, err := ParseAsyncConfig(AsyncPoll, `{"extract":{"job_id":"$.data.id"},"status_url":"/jobs/{{.job_id}}","success":[{"source":"json","target":"$.state","op":"==","value":"done"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Interval != AsyncDefaultInterval || config.Timeout != AsyncDefaultTimeout {
+		t.Fatalf("defaults should be applied, got %+v", config)
+	}
+	if _, err := ParseAsyncConfig(AsyncCallback, ""); err != nil {
+		t.Fatalf("callback mode without conditions should be valid, got %v", err)
+	}
+	if config, err := ParseAsyncConfig(AsyncNone, "garbage"); err != nil || config.StatusUrl != "" {
+		t.Fatalf("no async mode should ignore the config, got %+v %v", config, err)
+	}
+
+	success := `"success":[{"source":"json","target":"$.state","op":"==","value":"done"}]`
+	invalid := map[string]struct{ mode, config string }{
+		"unsupported async mode": {"webhook", `{}`},
+		"status_url is required": {AsyncPoll, `{` + success + `}`},
+		"success condition":      {AsyncPoll, `{"status_url":"/jobs"}`},
+		"undefined variable":     {AsyncPoll, `{"status_url":"/jobs/{{.id}}",` + success + `}`},
+		"invalid variable name":  {AsyncPoll, `{"extract":{"callback_url":"$.id"},"status_url":"/jobs",` + success + `}`},
+		"must start with $":      {AsyncPoll, `{"extract":{"id":"data.id"},"status_url":"/jobs",` + success + `}`},
+		"must not exceed":        {AsyncCallback, `{"interval":60,"timeout":30}`},
+		"unsupported operator":   {AsyncCallback, `{"failure":[{"source":"json","target":"$.state","op":"~"}]}`},
+		"invalid async config":   {AsyncCallback, `not json`},
+	}
+	for want, tt := range invalid {
+		if _, err := ParseAsyncConfig(tt.mode, tt.config); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestAsyncExtractAndExpand(t *testing.T) {
+	config := AsyncConfig{Extract: map[string]string{"job_id": "$.data.id", "location": "header:Location"}}
+	resp := ResponseWrapper{
+		StatusCode: http.StatusAccepted,
+		Body:       `{"data":{"id":9007199254740993}}`,
+		Header:     http.Header{"Location": {"/jobs/a b"}},
+	}
+	values, err := config.ExtractValues(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["job_id"] != "9007199254740993" || values["location"] != "/jobs/a b" {
+		t.Fatalf("unexpected values %v", values)
+	}
+	if _, err := config.ExtractValues(ResponseWrapper{Body: `{}`, Header: resp.Header}); err == nil || !strings.Contains(err.Error(), "$.data.id not found") {
+		t.Fatalf("missing value should be reported, got %v", err)
+	}
+
+	// 相对地址按触发地址解析，变量按路径转义
+	statusUrl, err := ExpandStatusUrl("/jobs/{{.job_id}}/{{ .location }}", "https://api.example.com/v1/export?x=1", values)
+	if err != nil || statusUrl != "https://api.example.com/jobs/9007199254740993/%2Fjobs%2Fa%20b" {
+		t.Fatalf("unexpected status url %q %v", statusUrl, err)
+	}
+	if _, err := ExpandStatusUrl("ftp://files/{{.job_id}}", "https://api.example.com", values); err == nil {
+		t.Fatal("non-http status url should be rejected")
+	}
+	if got := Expand(`{"callback":"{{.callback_url}}","other":"{{.unknown}}"}`, map[string]string{"callback_url": "https://cron/callback/t"}); got != `{"callback":"https://cron/callback/t","other":"{{.unknown}}"}` {
+		t.Fatalf("unexpected expansion %s", got)
+	}
+}
+
+func TestAsyncEvaluate(t *testing.T) {
+	config := AsyncConfig{
+		Success: []Assertion{{Source: AssertSourceJson, Target: "$.state", Op: AssertEqual, Value: "done"}},
+		Failure: []Assertion{{Source: AssertSourceJson, Target: "$.state", Op: AssertEqual, Value: "failed"}},
+	}
+	cases := map[string]AsyncState{
+		`{"state":"running"}`: AsyncPending,
+		`{"state":"done"}`:    AsyncSucceeded,
+		`{"state":"failed"}`:  AsyncFailed,
+		`<html>502</html>`:    AsyncPending,
+	}
+	for body, want := range cases {
+		state, _ := config.Evaluate(ResponseWrapper{StatusCode: http.StatusOK, Body: body})
+		if state != want {
+			t.Errorf("%s: expected state %d, got %d", body, want, state)
+		}
+	}
+	if _, err := config.Evaluate(ResponseWrapper{Body: `{"state":"running"}`}); err == nil || !strings.Contains(err.Error(), `actual "running"`) {
+		t.Fatalf("pending state should explain the unmet condition, got %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package httpclient
+
+// HTTP 任务认证：Basic、Bearer 和 OAuth2 client credentials。
+// 任务只保存用户名、client_id 等非敏感信息和密钥库中的密钥名称，执行时再取出凭据；
+// OAuth2 access token 按 token 地址、client_id、client secret 和 scope 缓存，
+// 过期或目标地址返回 401 时重新获取
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 认证方式
+const (
+	AuthNone   = ""
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+	AuthOAuth2 = "oauth2"
+)
+
+// AuthConfig 任务保存的认证配置，*Secret 字段是密钥库中的密钥名称，不是凭据本身
+type AuthConfig struct {
+	Username       string `json:"username,omitempty"`
+	PasswordSecret string `json:"password_secret,omitempty"`
+	TokenSecret    string `json:"token_secret,omitempty"`
+	TokenUrl       string `json:"token_url,omitempty"`
+	ClientId       string `json:"client_id,omitempty"`
+	ClientSecret   string `json:"client_secret,omitempty"`
+	Scope          string `json:"scope,omitempty"`
+}
+
+// ParseAuthConfig 解析并校验认证配置，认证方式为空时返回空配置
+func ParseAuthConfig(authType, s string) (AuthConfig, error) {
+	var config AuthConfig
+	if authType == AuthNone {
+		return config, nil
+	}
+	if strings.TrimSpace(s) == "" {
+		return config, errors.New("auth config is required")
+	}
+	if err := json.Unmarshal([]byte(s), &config); err != nil {
+		return config, fmt.Errorf("invalid auth config JSON: %w", err)
+	}
+	switch authType {
+	case AuthBasic:
+		if config.Username == "" || config.PasswordSecret == "" {
+			return config, errors.New("basic auth requires username and password_secret")
+		}
+	case AuthBearer:
+		if config.TokenSecret == "" {
+			return config, errors.New("bearer auth requires token_secret")
+		}
+	case AuthOAuth2:
+		tokenUrl := strings.ToLower(config.TokenUrl)
+		if !strings.HasPrefix(tokenUrl, "http://") && !strings.HasPrefix(tokenUrl, "https://") {
+			return config, errors.New("oauth2 token_url must be an http(s) URL")
+		}
+		if config.ClientId == "" || config.ClientSecret == "" {
+			return config, errors.New("oauth2 requires client_id and client_secret")
+		}
+	default:
+		return config, fmt.Errorf("unsupported auth type %q", authType)
+	}
+
+	return config, nil
+}
+
+// References 是否引用了指定名称的密钥
+func (c AuthConfig) References(name string) bool {
+	return name != "" && (c.PasswordSecret == name || c.TokenSecret == name || c.ClientSecret == name)
+}
+
+// Resolve 从密钥库取出凭据，lookup 按名称返回密钥的值
+func (c AuthConfig) Resolve(authType string, lookup func(name string) (string, error)) (*Auth, error) {
+	auth := &Auth{Type: authType, Username: c.Username, TokenUrl: c.TokenUrl, ClientId: c.ClientId, Scope: c.Scope}
+	var err error
+	switch authType {
+	case AuthBasic:
+		auth.Password, err = lookupSecret(lookup, c.PasswordSecret)
+	case AuthBearer:
+		auth.Token, err = lookupSecret(lookup, c.TokenSecret)
+	case AuthOAuth2:
+		auth.ClientSecret, err = lookupSecret(lookup, c.ClientSecret)
+	default:
+		return nil, fmt.Errorf("unsupported auth type %q", authType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
+func lookupSecret(lookup func(name string) (string, error), name string) (string, error) {
+	value, err := lookup(name)
+	if err != nil {
+		return "", fmt.Errorf("secret %q: %w", name, err)
+	}
+	return value, nil
+}
+
+// Auth 发送请求时使用的认证信息，凭据已从密钥库取出
+type Auth struct {
+	Type         string
+	Username     string
+	Password     string
+	Token        string
+	TokenUrl     string
+	ClientId     string
+	ClientSecret string
+	Scope        string
+	// credentials 本次执行实际发送过的凭据（Basic 编码值、access token），用于从结果中去掉
+	credentials []string
+}
+
+// apply 设置 Authorization 头，refresh 为 true 时不使用缓存的 OAuth2 token
+func (a *Auth) apply(req *http.Request, client httpDoer, refresh bool) error {
+	switch a.Type {
+	case AuthBasic:
+		encoded := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password))
+		a.credentials = append(a.credentials, encoded)
+		req.Header.Set("Authorization", "Basic "+encoded)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case AuthOAuth2:
+		token, err := a.oauth2Token(client, refresh)
+		if err != nil {
+			return err
+		}
+		a.credentials = append(a.credentials, token)
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return nil
+}
+
+// minRedactLength 过短的凭据不做替换，避免把结果中的普通字符都替换掉
+const minRedactLength = 4
+
+// Redact 把结果中出现的凭据替换为 MaskedValue
+func (a *Auth) Redact(s string) string {
+	if a == nil {
+		return s
+	}
+	values := append([]string{a.Password, a.Token, a.ClientSecret}, a.credentials...)
+	for _, value := range values {
+		if len(value) >= minRedactLength {
+			s = strings.ReplaceAll(s, value, MaskedValue)
+		}
+	}
+	return s
+}
+
+// oauth2TokenExpiryMargin 提前过期时间，避免 token 在请求途中过期
+const oauth2TokenExpiryMargin = 30 * time.Second
+
+type oauth2CachedToken struct {
+	accessToken string
+	// expiresAt 为零值表示服务端没有返回有效期，直到目标地址返回 401 才重新获取
+	expiresAt time.Time
+}
+
+var oauth2Tokens = struct {
+	sync.Mutex
+	tokens map[string]oauth2CachedToken
+}{tokens: make(map[string]oauth2CachedToken)}
+
+// oauth2CacheKey client secret 参与计算，密钥轮换后不会继续使用旧 token
+func (a *Auth) oauth2CacheKey() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{a.TokenUrl, a.ClientId, a.ClientSecret, a.Scope}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// oauth2Token 返回缓存中未过期的 token，没有或 refresh 为 true 时重新获取
+func (a *Auth) oauth2Token(client httpDoer, refresh bool) (string, error) {
+	key := a.oauth2CacheKey()
+	oauth2Tokens.Lock()
+	cached, ok := oauth2Tokens.tokens[key]
+	oauth2Tokens.Unlock()
+	if ok && !refresh && (cached.expiresAt.IsZero() || time.Now().Before(cached.expiresAt)) {
+		return cached.accessToken, nil
+	}
+
+	token, err := a.fetchOAuth2Token(client)
+	if err != nil {
+		return "", err
+	}
+	oauth2Tokens.Lock()
+	oauth2Tokens.tokens[key] = token
+	oauth2Tokens.Unlock()
+
+	return token.accessToken, nil
+}
+
+// fetchOAuth2Token 按 RFC 6749 4.4 获取 token，client 凭据通过 Basic 认证发送。
+// token 地址与目标地址使用同一个客户端，自定义 CA 和代理同样生效
+func (a *Auth) fetchOAuth2Token(client httpDoer) (oauth2CachedToken, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if a.Scope != "" {
+		form.Set("scope", a.Scope)
+	}
+	req, err := http.NewRequest(http.MethodPost, a.TokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return oauth2CachedToken{}, fmt.Errorf("oauth2 token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.ClientId), url.QueryEscape(a.ClientSecret))
+
+	resp := doRequest(client, req, 0)
+	if resp.StatusCode == 0 {
+		return oauth2CachedToken{}, fmt.Errorf("oauth2 token request failed: %s", resp.Body)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return oauth2CachedToken{}, fmt.Errorf("oauth2 token request failed: HTTP %d: %s", resp.StatusCode, truncate(resp.Body, 512))
+	}
+	var body struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &body); err != nil || body.AccessToken == "" {
+		return oauth2CachedToken{}, errors.New("oauth2 token response has no access_token")
+	}
+	token := oauth2CachedToken{accessToken: body.AccessToken}
+	if seconds, err := body.ExpiresIn.Int64(); err == nil && seconds > 0 {
+		expiresIn := time.Duration(seconds) * time.Second
+		if expiresIn > 2*oauth2TokenExpiryMargin {
+			expiresIn -= oauth2TokenExpiryMargin
+		}
+		token.expiresAt = time.Now().Add(expiresIn)
+	}
+
+	return token, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
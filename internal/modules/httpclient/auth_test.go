@@ -0,0 +1,163 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseAuthConfig(t *testing.T) {
+	valid := map[string]string{
+		AuthBasic:  `{"username":"admin","password_secret":"api-password"}`,
+		AuthBearer: `{"token_secret":"api-token"}`,
+		AuthOAuth2: `{"token_url":"https://auth.example.com/token","client_id":"gocron","client_secret":"oauth-secret","scope":"jobs"}`,
+	}
+	for authType, config := range valid {
+		if _, err := ParseAuthConfig(authType, config); err != nil {
+			t.Errorf("%s: unexpected error %v", authType, err)
+		}
+	}
+	if config, err := ParseAuthConfig(AuthNone, "garbage"); err != nil || config != (AuthConfig{}) {
+		t.Fatalf("no auth should ignore the config, got %+v %v", config, err)
+	}
+
+	invalid := []struct{ authType, config string }{
+		{AuthBasic, `{"username":"admin"}`},
+		{AuthBearer, `{}`},
+		{AuthBearer, ``},
+		{AuthOAuth2, `{"token_url":"ftp://x","client_id":"a","client_secret":"b"}`},
+		{AuthOAuth2, `{"token_url":"https://x","client_id":"a"}`},
+		{"digest", `{"username":"a"}`},
+		{AuthBasic, `not json`},
+	}
+	for _, tt := range invalid {
+		if _, err := ParseAuthConfig(tt.authType, tt.config); err == nil {
+			t.Errorf("%s %s: expected error", tt.authType, tt.config)
+		}
+	}
+}
+
+func TestAuthConfigResolve(t *testing.T) {
+	secrets := map[string]string{"api-password": "s3cret"}
+	lookup := func(name string) (string, error) {
+		if value, ok := secrets[name]; ok {
+			return value, nil
+		}
+		return "", errors.New("not found")
+	}
+	config := AuthConfig{Username: "admin", PasswordSecret: "api-password"}
+	auth, err := config.Resolve(AuthBasic, lookup)
+	if err != nil || auth.Username != "admin" || auth.Password != "s3cret" {
+		t.Fatalf("unexpected auth %+v %v", auth, err)
+	}
+	if _, err := (AuthConfig{TokenSecret: "missing"}).Resolve(AuthBearer, lookup); err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Fatalf("missing secret should be reported by name, got %v", err)
+	}
+	if !config.References("api-password") || config.References("api-token") || config.References("") {
+		t.Fatal("unexpected References result")
+	}
+}
+
+func TestSendBasicAndBearerAuth(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("echo " + authorization))
+	}))
+	defer server.Close()
+
+	auth := &Auth{Type: AuthBasic, Username: "admin", Password: "s3cret"}
+	resp := Send(RequestSpec{Method: http.MethodGet, Url: server.URL, Headers: `{"Authorization":"Bearer pasted"}`, Auth: auth}, 5)
+	if authorization != "Basic YWRtaW46czNjcmV0" {
+		t.Fatalf("auth should override the custom header, got %q", authorization)
+	}
+	if got := auth.Redact(resp.Body); got != "echo Basic "+MaskedValue {
+		t.Fatalf("encoded credentials should be redacted, got %q", got)
+	}
+
+	auth = &Auth{Type: AuthBearer, Token: "token-123"}
+	resp = Send(RequestSpec{Method: http.MethodGet, Url: server.URL, Auth: auth}, 5)
+	if authorization != "Bearer token-123" {
+		t.Fatalf("unexpected authorization %q", authorization)
+	}
+	if got := auth.Redact(resp.Body); got != "echo Bearer "+MaskedValue {
+		t.Fatalf("token should be redacted, got %q", got)
+	}
+}
+
+func TestSendOAuth2ClientCredentials(t *testing.T) {
+	var fetches int32
+	var revoked atomic.Value
+	revoked.Store("")
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientId, clientSecret, ok := r.BasicAuth()
+		if !ok || clientId != "gocron" || clientSecret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("scope") != "jobs" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n := atomic.AddInt32(&fetches, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token-` + string(rune('0'+n)) + `","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == revoked.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("ok " + token))
+	}))
+	defer api.Close()
+
+	newAuth := func() *Auth {
+		return &Auth{Type: AuthOAuth2, TokenUrl: tokenServer.URL, ClientId: "gocron", ClientSecret: "client-secret", Scope: "jobs"}
+	}
+	send := func() ResponseWrapper {
+		return Send(RequestSpec{Method: http.MethodPost, Url: api.URL, Auth: newAuth()}, 5)
+	}
+
+	// 第二次请求使用缓存的 token
+	if resp := send(); resp.Body != "ok token-1" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if resp := send(); resp.Body != "ok token-1" || atomic.LoadInt32(&fetches) != 1 {
+		t.Fatalf("token should be cached, fetches=%d body=%q", fetches, resp.Body)
+	}
+
+	// 目标返回 401 时重新获取 token 并重试一次
+	revoked.Store("token-1")
+	if resp := send(); resp.Body != "ok token-2" || atomic.LoadInt32(&fetches) != 2 {
+		t.Fatalf("token should be refreshed on 401, fetches=%d resp=%+v", fetches, resp)
+	}
+
+	// 过期的 token 重新获取
+	key := newAuth().oauth2CacheKey()
+	oauth2Tokens.Lock()
+	oauth2Tokens.tokens[key] = oauth2CachedToken{accessToken: "token-2", expiresAt: time.Now().Add(-time.Second)}
+	oauth2Tokens.Unlock()
+	auth := newAuth()
+	resp := Send(RequestSpec{Method: http.MethodGet, Url: api.URL, Auth: auth}, 5)
+	if resp.Body != "ok token-3" {
+		t.Fatalf("expired token should be refreshed, got %+v", resp)
+	}
+	if got := auth.Redact(resp.Body); got != "ok "+MaskedValue {
+		t.Fatalf("access token should be redacted, got %q", got)
+	}
+
+	// 错误的 client secret
+	bad := newAuth()
+	bad.ClientSecret = "wrong-secret"
+	resp = Send(RequestSpec{Method: http.MethodGet, Url: api.URL, Auth: bad}, 5)
+	if resp.StatusCode != 0 || !strings.Contains(resp.Body, "oauth2 token request failed: HTTP 401") {
+		t.Fatalf("token errors should fail the request, got %+v", resp)
+	}
+}
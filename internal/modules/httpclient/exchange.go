@@ -0,0 +1,158 @@
+package httpclient
+
+// 请求详情：记录请求方法、地址、Header，响应状态、Header、响应体大小，
+// 以及通过 httptrace 采集的 DNS、建连、TLS、首字节耗时，凭据已脱敏
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBodySize 默认读取的响应体大小上限，超出部分丢弃
+const DefaultMaxBodySize = 1 << 20
+
+// Exchange 一次 HTTP 请求的详情
+type Exchange struct {
+	Method          string            `json:"method"`
+	Url             string            `json:"url"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	RequestBodySize int64             `json:"request_body_size"`
+	Proto           string            `json:"proto,omitempty"`
+	StatusCode      int               `json:"status_code"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	// BodySize 响应体大小，BodyTruncated 为 true 时只读取了前 BodyLimit 字节，
+	// 大小取 Content-Length，没有时为已读取的字节数
+	BodySize      int64  `json:"body_size"`
+	BodyLimit     int64  `json:"body_limit"`
+	BodyTruncated bool   `json:"body_truncated,omitempty"`
+	RemoteAddr    string `json:"remote_addr,omitempty"`
+	ConnReused    bool   `json:"conn_reused,omitempty"`
+	TlsVersion    string `json:"tls_version,omitempty"`
+	// Error 请求失败原因
+	Error     string         `json:"error,omitempty"`
+	StartedAt time.Time      `json:"started_at"`
+	Timing    ExchangeTiming `json:"timing"`
+}
+
+// ExchangeTiming 各阶段耗时（毫秒），复用连接时 DNS、建连、TLS 为 0
+type ExchangeTiming struct {
+	Dns     float64 `json:"dns_ms"`
+	Connect float64 `json:"connect_ms"`
+	Tls     float64 `json:"tls_ms"`
+	// Ttfb 从开始请求到收到响应首字节
+	Ttfb  float64 `json:"ttfb_ms"`
+	Total float64 `json:"total_ms"`
+}
+
+// newExchange 记录请求信息，Header 和地址中的凭据已脱敏
+func newExchange(req *http.Request, limit int64) *Exchange {
+	return &Exchange{
+		Method:          req.Method,
+		Url:             MaskURL(req.URL.String()),
+		RequestHeaders:  maskHeaderValues(req.Header),
+		RequestBodySize: req.ContentLength,
+		BodyLimit:       limit,
+		StartedAt:       time.Now(),
+	}
+}
+
+// failedExchange 请求未发出时的详情
+func failedExchange(spec RequestSpec, err error) *Exchange {
+	method := strings.ToUpper(strings.TrimSpace(spec.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	return &Exchange{Method: method, Url: MaskURL(spec.Url), Error: err.Error(), StartedAt: time.Now()}
+}
+
+// setResponse 记录响应信息
+func (e *Exchange) setResponse(resp *http.Response) {
+	e.StatusCode = resp.StatusCode
+	e.Proto = resp.Proto
+	e.ResponseHeaders = maskHeaderValues(resp.Header)
+	if resp.TLS != nil {
+		e.TlsVersion = tls.VersionName(resp.TLS.Version)
+	}
+}
+
+// maskHeaderValues 多个值以逗号连接，携带凭据的 Header 只显示脱敏值
+func maskHeaderValues(header http.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(header))
+	for name, v := range header {
+		if IsSensitiveName(name) {
+			values[name] = MaskedValue
+			continue
+		}
+		values[name] = strings.Join(v, ", ")
+	}
+	return values
+}
+
+// exchangeTracer 采集各阶段耗时。并发拨号时只记录最先开始和最先完成的连接
+type exchangeTracer struct {
+	sync.Mutex
+	start                     time.Time
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	firstByte                 time.Time
+	remoteAddr                string
+	reused                    bool
+}
+
+func (t *exchangeTracer) clientTrace() *httptrace.ClientTrace {
+	mark := func(field *time.Time) {
+		t.Lock()
+		if field.IsZero() {
+			*field = time.Now()
+		}
+		t.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { mark(&t.dnsDone) },
+		ConnectStart:         func(string, string) { mark(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { mark(&t.connectDone) },
+		TLSHandshakeStart:    func() { mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { mark(&t.tlsDone) },
+		GotFirstResponseByte: func() { mark(&t.firstByte) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.Lock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+			t.Unlock()
+		},
+	}
+}
+
+// finish 请求结束后写入耗时
+func (t *exchangeTracer) finish(e *Exchange) {
+	t.Lock()
+	defer t.Unlock()
+	e.RemoteAddr = t.remoteAddr
+	e.ConnReused = t.reused
+	e.Timing = ExchangeTiming{
+		Dns:     elapsedMs(t.dnsStart, t.dnsDone),
+		Connect: elapsedMs(t.connectStart, t.connectDone),
+		Tls:     elapsedMs(t.tlsStart, t.tlsDone),
+		Ttfb:    elapsedMs(t.start, t.firstByte),
+		Total:   elapsedMs(t.start, time.Now()),
+	}
+}
+
+// elapsedMs 毫秒，保留两位小数，任一时间点缺失时为 0
+func elapsedMs(start, end time.Time) float64 {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return float64(end.Sub(start).Microseconds()/10) / 100
+}
@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendRecordsExchange(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Header().Set("X-Request-Id", "r-1")
+		w.Header().Set("Content-Length", "3000")
+		_, _ = w.Write([]byte(strings.Repeat("x", 3000)))
+	}))
+	defer server.Close()
+	profile := &Profile{Name: "exchange-test", InsecureSkipVerify: true}
+	defer CloseProfile("exchange-test")
+
+	resp := Send(RequestSpec{
+		Method:      http.MethodPost,
+		Url:         server.URL + "/jobs?token=t0ps3cret&page=1",
+		Headers:     `{"X-Api-Key":"k3y","X-Trace":"on"}`,
+		BodyType:    BodyTypeJson,
+		Body:        `{"a":1}`,
+		Auth:        &Auth{Type: AuthBearer, Token: "bearer-token"},
+		Profile:     profile,
+		MaxBodySize: 1024,
+	}, 5)
+	exchange := resp.Exchange
+	if resp.StatusCode != http.StatusOK || exchange == nil {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	// 响应体只保留前 1024 字节，但记录实际大小
+	if len(resp.Body) != 1024 || exchange.BodySize != 3000 || !exchange.BodyTruncated || exchange.BodyLimit != 1024 {
+		t.Fatalf("unexpected body size %d %+v", len(resp.Body), exchange)
+	}
+	if exchange.Method != http.MethodPost || strings.Contains(exchange.Url, "t0ps3cret") || !strings.Contains(exchange.Url, "page=1") {
+		t.Fatalf("unexpected request line %s %s", exchange.Method, exchange.Url)
+	}
+	if exchange.RequestHeaders["Authorization"] != MaskedValue || exchange.RequestHeaders["X-Api-Key"] != MaskedValue ||
+		exchange.RequestHeaders["X-Trace"] != "on" || exchange.RequestBodySize != 7 {
+		t.Fatalf("unexpected request headers %+v", exchange.RequestHeaders)
+	}
+	if exchange.ResponseHeaders["Set-Cookie"] != MaskedValue || exchange.ResponseHeaders["X-Request-Id"] != "r-1" {
+		t.Fatalf("unexpected response headers %+v", exchange.ResponseHeaders)
+	}
+	if exchange.Proto != "HTTP/1.1" || exchange.TlsVersion == "" || exchange.RemoteAddr == "" {
+		t.Fatalf("unexpected connection info %+v", exchange)
+	}
+	timing := exchange.Timing
+	if timing.Connect <= 0 || timing.Tls <= 0 || timing.Ttfb <= 0 || timing.Total < timing.Ttfb {
+		t.Fatalf("unexpected timing %+v", timing)
+	}
+
+	// 复用连接时没有建连和 TLS 耗时
+	resp = Send(RequestSpec{Method: http.MethodGet, Url: server.URL, Profile: profile}, 5)
+	if !resp.Exchange.ConnReused || resp.Exchange.Timing.Tls != 0 || resp.Exchange.BodyTruncated {
+		t.Fatalf("expected reused connection, got %+v", resp.Exchange)
+	}
+}
+
+func TestSendStopsReadingAtBodyLimit(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 2048)))
+		w.(http.Flusher).Flush()
+		// 流式响应一直不结束
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	started := time.Now()
+	resp := Send(RequestSpec{Method: http.MethodGet, Url: server.URL, MaxBodySize: 1024}, 10)
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Fatalf("reading should stop at the body limit, took %s", elapsed)
+	}
+	if resp.StatusCode != http.StatusOK || len(resp.Body) != 1024 || !resp.Exchange.BodyTruncated || resp.Exchange.BodySize != 1024 {
+		t.Fatalf("unexpected truncated response %d %+v", len(resp.Body), resp.Exchange)
+	}
+}
+
+func TestSendRecordsFailedExchange(t *testing.T) {
+	withMockClient(t, func(req *http.Request) (*http.Response, error) {
+		return nil, http.ErrHandlerTimeout
+	})
+	resp := Get("http://example.com/ping", 1)
+	if resp.Exchange == nil || resp.Exchange.Error != http.ErrHandlerTimeout.Error() || resp.Exchange.StatusCode != 0 {
+		t.Fatalf("expected failed exchange, got %+v", resp.Exchange)
+	}
+
+	resp = Send(RequestSpec{Method: http.MethodPost, Url: "http://example.com", BodyType: BodyTypeJson, Body: "{"}, 1)
+	if resp.Exchange == nil || resp.Exchange.Method != http.MethodPost || resp.Exchange.Error == "" {
+		t.Fatalf("expected failed exchange for invalid request, got %+v", resp.Exchange)
+	}
+}
@@ -3,12 +3,12 @@ package httpclient
 // http-client
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
 	"time"
 )
@@ -17,6 +17,8 @@ type ResponseWrapper struct {
 	StatusCode int
 	Body       string
 	Header     http.Header
+	// Exchange 请求详情，凭据已脱敏
+	Exchange *Exchange
 }
 
 type httpDoer interface {
@@ -50,34 +52,15 @@ var clientFactory = func(timeout int) httpDoer {
 }
 
 func Get(url string, timeout int) ResponseWrapper {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return createRequestError(err)
-	}
-
-	return request(req, timeout)
+	return Send(RequestSpec{Method: http.MethodGet, Url: url}, timeout)
 }
 
 func PostParams(url string, params string, timeout int) ResponseWrapper {
-	buf := bytes.NewBufferString(params)
-	req, err := http.NewRequest("POST", url, buf)
-	if err != nil {
-		return createRequestError(err)
-	}
-	req.Header.Set("Content-type", "application/x-www-form-urlencoded")
-
-	return request(req, timeout)
+	return Send(RequestSpec{Method: http.MethodPost, Url: url, BodyType: BodyTypeForm, Body: params}, timeout)
 }
 
 func PostJson(url string, body string, timeout int) ResponseWrapper {
-	buf := bytes.NewBufferString(body)
-	req, err := http.NewRequest("POST", url, buf)
-	if err != nil {
-		return createRequestError(err)
-	}
-	req.Header.Set("Content-type", "application/json")
-
-	return request(req, timeout)
+	return Send(RequestSpec{Method: http.MethodPost, Url: url, BodyType: BodyTypeJson, Body: body}, timeout)
 }
 
 // blockedHeaders 禁止用户设置的危险 Header
@@ -137,51 +120,62 @@ func SetCustomHeaders(req *http.Request, headersJSON string) {
 
 // GetWithHeaders 带自定义 Header 的 GET 请求
 func GetWithHeaders(url string, headersJSON string, timeout int) ResponseWrapper {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return createRequestError(err)
-	}
-	SetCustomHeaders(req, headersJSON)
-	return request(req, timeout)
+	return Send(RequestSpec{Method: http.MethodGet, Url: url, Headers: headersJSON}, timeout)
 }
 
 // PostJsonWithHeaders 带自定义 Header 的 POST JSON 请求
 func PostJsonWithHeaders(url string, body string, headersJSON string, timeout int) ResponseWrapper {
-	buf := bytes.NewBufferString(body)
-	req, err := http.NewRequest("POST", url, buf)
-	if err != nil {
-		return createRequestError(err)
-	}
-	req.Header.Set("Content-type", "application/json")
-	SetCustomHeaders(req, headersJSON)
-	return request(req, timeout)
+	return Send(RequestSpec{Method: http.MethodPost, Url: url, Headers: headersJSON, BodyType: BodyTypeJson, Body: body}, timeout)
 }
 
 // PostParamsWithHeaders 带自定义 Header 的 POST 表单请求
 func PostParamsWithHeaders(url string, params string, headersJSON string, timeout int) ResponseWrapper {
-	buf := bytes.NewBufferString(params)
-	req, err := http.NewRequest("POST", url, buf)
-	if err != nil {
-		return createRequestError(err)
-	}
-	req.Header.Set("Content-type", "application/x-www-form-urlencoded")
-	SetCustomHeaders(req, headersJSON)
+	return Send(RequestSpec{Method: http.MethodPost, Url: url, Headers: headersJSON, BodyType: BodyTypeForm, Body: params}, timeout)
+}
+
+// Do 发送调用方构造的请求，用于需要自定义请求方法、认证的场景
+func Do(req *http.Request, timeout int) ResponseWrapper {
 	return request(req, timeout)
 }
 
 func request(req *http.Request, timeout int) ResponseWrapper {
+	return doRequest(clientFactory(timeout), req, 0)
+}
+
+// doRequest 发送请求并记录请求详情，响应体只读取前 limit 字节，超出时不再读取剩余部分，limit <= 0 时取默认值
+func doRequest(client httpDoer, req *http.Request, limit int64) ResponseWrapper {
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
 	wrapper := ResponseWrapper{StatusCode: 0, Body: "", Header: make(http.Header)}
-	client := clientFactory(timeout)
 	setRequestHeader(req)
+	exchange := newExchange(req, limit)
+	wrapper.Exchange = exchange
+	tracer := &exchangeTracer{start: exchange.StartedAt}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), tracer.clientTrace()))
 	resp, err := client.Do(req)
 	if err != nil {
+		tracer.finish(exchange)
+		exchange.Error = err.Error()
 		wrapper.Body = fmt.Sprintf("执行HTTP请求错误-%s", err.Error())
 		return wrapper
 	}
 	defer resp.Body.Close()
-	// 限制响应体最大 1MB，防止 OOM
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	exchange.setResponse(resp)
+	// 限制响应体大小，防止 OOM；多读 1 字节判断是否超出，超出后直接关闭，不等待大响应或流式响应读完
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	tracer.finish(exchange)
+	exchange.BodySize = int64(len(body))
+	if int64(len(body)) > limit {
+		body = body[:limit]
+		exchange.BodyTruncated = true
+		exchange.BodySize = limit
+		if resp.ContentLength > limit {
+			exchange.BodySize = resp.ContentLength
+		}
+	}
 	if err != nil {
+		exchange.Error = fmt.Sprintf("read response body: %s", err)
 		wrapper.Body = fmt.Sprintf("读取HTTP请求返回值失败-%s", err.Error())
 		return wrapper
 	}
@@ -198,5 +192,5 @@ func setRequestHeader(req *http.Request) {
 
 func createRequestError(err error) ResponseWrapper {
 	errorMessage := fmt.Sprintf("创建HTTP请求错误-%s", err.Error())
-	return ResponseWrapper{0, errorMessage, make(http.Header)}
+	return ResponseWrapper{StatusCode: 0, Body: errorMessage, Header: make(http.Header)}
 }
@@ -0,0 +1,122 @@
+package httpclient
+
+// JSONPath 子集，用于响应断言：
+//   $.data.items[0].id    对象字段和数组下标，负数下标从末尾计数
+//   $['a.b']["c"]         带特殊字符的字段名
+//   $.items.length()      数组、对象的元素个数或字符串长度
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type jsonPathStep struct {
+	key    string
+	index  int
+	isIdx  bool
+	length bool
+}
+
+// parseJSONPath 解析路径，返回各级访问步骤
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, errors.New("JSONPath must start with $")
+	}
+	rest := path[1:]
+	steps := make([]jsonPathStep, 0)
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("empty field name in %q", path)
+			}
+			rest = rest[end:]
+			if key == "length()" {
+				if rest != "" {
+					return nil, fmt.Errorf("length() must be the last step in %q", path)
+				}
+				steps = append(steps, jsonPathStep{length: true})
+				continue
+			}
+			steps = append(steps, jsonPathStep{key: key})
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, jsonPathStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index [%s] in %q", inner, path)
+			}
+			steps = append(steps, jsonPathStep{index: index, isIdx: true})
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", rest[0], path)
+		}
+	}
+
+	return steps, nil
+}
+
+// lookupJSONPath 在 json.Unmarshal 得到的数据中查找路径，路径不存在时 found 为 false
+func lookupJSONPath(doc interface{}, path string) (value interface{}, found bool, err error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	value = doc
+	for _, step := range steps {
+		switch {
+		case step.length:
+			switch v := value.(type) {
+			case []interface{}:
+				value = float64(len(v))
+			case map[string]interface{}:
+				value = float64(len(v))
+			case string:
+				value = float64(utf8.RuneCountInString(v))
+			default:
+				return nil, false, nil
+			}
+		case step.isIdx:
+			list, ok := value.([]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+			index := step.index
+			if index < 0 {
+				index += len(list)
+			}
+			if index < 0 || index >= len(list) {
+				return nil, false, nil
+			}
+			value = list[index]
+		default:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+			value, ok = object[step.key]
+			if !ok {
+				return nil, false, nil
+			}
+		}
+	}
+
+	return value, true, nil
+}
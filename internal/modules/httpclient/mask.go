@@ -0,0 +1,157 @@
+package httpclient
+
+// 凭据脱敏：任务版本快照和审计 diff 中隐藏 Header、URL 里的凭据，
+// 回滚时再用当前任务的值还原被隐藏的部分
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// MaskedValue 凭据脱敏后的显示值
+const MaskedValue = "******"
+
+var sensitiveNames = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+var sensitiveNameParts = []string{"token", "secret", "password", "passwd", "apikey", "api-key", "api_key", "signature"}
+
+// IsSensitiveName Header 或查询参数名是否可能携带凭据
+func IsSensitiveName(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if sensitiveNames[name] {
+		return true
+	}
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskHeaders 隐藏 Header JSON 中携带凭据的值，没有需要隐藏的值或格式错误时原样返回
+func MaskHeaders(headersJSON string) string {
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(headersJSON), &headers); err != nil {
+		return headersJSON
+	}
+	masked := false
+	for name, value := range headers {
+		if value != "" && value != MaskedValue && IsSensitiveName(name) {
+			headers[name] = MaskedValue
+			masked = true
+		}
+	}
+	if !masked {
+		return headersJSON
+	}
+	data, _ := json.Marshal(headers)
+
+	return string(data)
+}
+
+// UnmaskHeaders 用 current 中同名 Header 的值替换 masked 中被隐藏的值
+func UnmaskHeaders(masked, current string) string {
+	if !strings.Contains(masked, MaskedValue) {
+		return masked
+	}
+	var headers, currentHeaders map[string]string
+	if json.Unmarshal([]byte(masked), &headers) != nil || json.Unmarshal([]byte(current), &currentHeaders) != nil {
+		return masked
+	}
+	restored := false
+	for name, value := range headers {
+		if value != MaskedValue {
+			continue
+		}
+		for currentName, currentValue := range currentHeaders {
+			if strings.EqualFold(name, currentName) {
+				headers[name] = currentValue
+				restored = true
+				break
+			}
+		}
+	}
+	if !restored {
+		return masked
+	}
+	data, _ := json.Marshal(headers)
+
+	return string(data)
+}
+
+// MaskURL 隐藏 URL 中的密码和携带凭据的查询参数，没有需要隐藏的值时原样返回
+func MaskURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return rawURL
+	}
+	masked := false
+	if password, ok := u.User.Password(); ok && password != MaskedValue {
+		u.User = url.UserPassword(u.User.Username(), MaskedValue)
+		masked = true
+	}
+	query := u.Query()
+	for name, values := range query {
+		if !IsSensitiveName(name) {
+			continue
+		}
+		for i, value := range values {
+			if value != "" && value != MaskedValue {
+				values[i] = MaskedValue
+				masked = true
+			}
+		}
+	}
+	if !masked {
+		return rawURL
+	}
+	u.RawQuery = query.Encode()
+
+	// 保持 ****** 可读，不编码为 %2A
+	return strings.ReplaceAll(u.String(), url.QueryEscape(MaskedValue), MaskedValue)
+}
+
+// UnmaskURL 用 current 中的密码和同名查询参数还原 masked 中被隐藏的值
+func UnmaskURL(masked, current string) string {
+	if !strings.Contains(masked, MaskedValue) {
+		return masked
+	}
+	u, err := url.Parse(masked)
+	if err != nil {
+		return masked
+	}
+	currentURL, err := url.Parse(current)
+	if err != nil {
+		return masked
+	}
+	restored := false
+	if password, ok := u.User.Password(); ok && password == MaskedValue {
+		if currentPassword, ok := currentURL.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), currentPassword)
+			restored = true
+		}
+	}
+	query := u.Query()
+	currentQuery := currentURL.Query()
+	for name, values := range query {
+		for i, value := range values {
+			if value == MaskedValue && i < len(currentQuery[name]) {
+				values[i] = currentQuery[name][i]
+				restored = true
+			}
+		}
+	}
+	if !restored {
+		return masked
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
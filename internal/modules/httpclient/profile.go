@@ -0,0 +1,195 @@
+package httpclient
+
+// HTTP 配置：自定义 CA、客户端证书（mTLS）、跳过证书校验和代理。
+// 每个配置使用独立的 Transport 和连接池，配置修改后旧连接池关闭空闲连接并重建
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Profile 发送请求时使用的 HTTP 配置，客户端私钥和代理密码已从密钥库取出
+type Profile struct {
+	// Name 连接池按名称区分，同名配置内容变化时重建连接池
+	Name string
+	// CaCert 额外信任的 CA 证书（PEM），在系统根证书之外追加
+	CaCert string
+	// ClientCert、ClientKey 客户端证书和私钥（PEM），用于 mTLS
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	// ProxyUrl 代理地址，支持 http、https、socks5
+	ProxyUrl      string
+	ProxyUsername string
+	ProxyPassword string
+}
+
+// ProxySchemes 支持的代理协议
+var ProxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ValidateProxyUrl 校验代理地址，密码不允许写在地址中，需要通过密钥库配置
+func ValidateProxyUrl(raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	supported := false
+	for _, scheme := range ProxySchemes {
+		if u.Scheme == scheme {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("proxy URL has no host")
+	}
+	if _, ok := u.User.Password(); ok {
+		return errors.New("proxy password must not be included in the proxy URL")
+	}
+
+	return nil
+}
+
+// Validate 校验证书、私钥和代理地址
+func (p *Profile) Validate() error {
+	_, err := p.newTransport()
+	return err
+}
+
+// TLSConfig 配置中的 CA、客户端证书和证书校验设置，gRPC 任务也使用
+func (p *Profile) TLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: p.InsecureSkipVerify}
+	if strings.TrimSpace(p.CaCert) != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(p.CaCert)) {
+			return nil, errors.New("CA certificate contains no valid PEM certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	hasCert, hasKey := strings.TrimSpace(p.ClientCert) != "", strings.TrimSpace(p.ClientKey) != ""
+	if hasCert != hasKey {
+		return nil, errors.New("client certificate and client key must be configured together")
+	}
+	if hasCert {
+		cert, err := tls.X509KeyPair([]byte(p.ClientCert), []byte(p.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate or key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// newTransport 生成配置对应的 Transport，连接池参数与默认客户端一致
+func (p *Profile) newTransport() (*http.Transport, error) {
+	tlsConfig, err := p.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := &http.Transport{
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+	}
+	if strings.TrimSpace(p.ProxyUrl) != "" {
+		if err := ValidateProxyUrl(p.ProxyUrl); err != nil {
+			return nil, err
+		}
+		proxy, _ := url.Parse(strings.TrimSpace(p.ProxyUrl))
+		if p.ProxyUsername != "" {
+			proxy.User = url.UserPassword(p.ProxyUsername, p.ProxyPassword)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return transport, nil
+}
+
+// fingerprint 配置内容摘要，私钥和代理密码参与计算，轮换后重建连接池
+func (p *Profile) fingerprint() string {
+	parts := []string{p.CaCert, p.ClientCert, p.ClientKey, fmt.Sprint(p.InsecureSkipVerify), p.ProxyUrl, p.ProxyUsername, p.ProxyPassword}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+type profileTransport struct {
+	fingerprint string
+	transport   *http.Transport
+}
+
+var profileTransports = struct {
+	sync.Mutex
+	transports map[string]profileTransport
+}{transports: make(map[string]profileTransport)}
+
+// transport 返回配置的 Transport，同一配置复用连接池
+func (p *Profile) transport() (*http.Transport, error) {
+	fingerprint := p.fingerprint()
+	profileTransports.Lock()
+	defer profileTransports.Unlock()
+	cached, ok := profileTransports.transports[p.Name]
+	if ok && cached.fingerprint == fingerprint {
+		return cached.transport, nil
+	}
+	transport, err := p.newTransport()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		cached.transport.CloseIdleConnections()
+	}
+	profileTransports.transports[p.Name] = profileTransport{fingerprint: fingerprint, transport: transport}
+
+	return transport, nil
+}
+
+// CloseProfile 配置删除后关闭对应连接池的空闲连接
+func CloseProfile(name string) {
+	profileTransports.Lock()
+	defer profileTransports.Unlock()
+	if cached, ok := profileTransports.transports[name]; ok {
+		cached.transport.CloseIdleConnections()
+		delete(profileTransports.transports, name)
+	}
+}
+
+// profileClient 使用配置的连接池生成客户端，未指定配置时使用默认客户端
+func profileClient(p *Profile, timeout int) (httpDoer, error) {
+	if p == nil {
+		return clientFactory(timeout), nil
+	}
+	transport, err := p.transport()
+	if err != nil {
+		return nil, fmt.Errorf("HTTP profile %s: %w", p.Name, err)
+	}
+	if timeout <= 0 {
+		timeout = 300
+	}
+
+	return &http.Client{Timeout: time.Duration(timeout) * time.Second, Transport: transport}, nil
+}
@@ -0,0 +1,255 @@
+package httpclient
+
+// 通用请求构造：按请求方法、请求体类型和自定义 Header 生成请求
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// 请求体类型
+const (
+	// BodyTypeAuto 兼容升级前的任务：有请求体时按 JSON 发送
+	BodyTypeAuto      = ""
+	BodyTypeJson      = "json"
+	BodyTypeForm      = "form"
+	BodyTypeMultipart = "multipart"
+	BodyTypeRaw       = "raw"
+)
+
+// BodyTypes 支持的请求体类型
+var BodyTypes = []string{BodyTypeJson, BodyTypeForm, BodyTypeMultipart, BodyTypeRaw}
+
+const defaultRawContentType = "text/plain; charset=utf-8"
+
+// RequestSpec 请求描述
+type RequestSpec struct {
+	Method string
+	Url    string
+	// Headers 自定义 Header，JSON 对象
+	Headers  string
+	BodyType string
+	// Body 请求体：
+	//   json      原样发送
+	//   form      JSON 对象或 a=1&b=2 格式
+	//   multipart JSON 对象，字符串为普通字段，对象为文件字段 {"filename", "content", "content_type", "encoding": "base64"}
+	//   raw       原样发送，Content-Type 由 ContentType 指定
+	Body        string
+	ContentType string
+	// Auth 认证信息，为空时不认证
+	Auth *Auth
+	// Profile HTTP 配置（CA、客户端证书、代理），为空时使用默认客户端
+	Profile *Profile
+	// MaxBodySize 读取的响应体大小上限（字节），0 表示 DefaultMaxBodySize
+	MaxBodySize int64
+}
+
+// multipartFile multipart 请求体中的文件字段
+type multipartFile struct {
+	Filename    string `json:"filename"`
+	Content     string `json:"content"`
+	ContentType string `json:"content_type"`
+	Encoding    string `json:"encoding"`
+}
+
+// NewRequest 生成请求。GET、HEAD 请求不发送请求体；自定义 Header 可以覆盖 Content-Type，multipart 除外
+func NewRequest(spec RequestSpec) (*http.Request, error) {
+	method := strings.ToUpper(strings.TrimSpace(spec.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	var contentType string
+	if method != http.MethodGet && method != http.MethodHead {
+		data, ct, err := encodeBody(spec)
+		if err != nil {
+			return nil, err
+		}
+		if data != nil {
+			body = bytes.NewReader(data)
+		}
+		contentType = ct
+	}
+	req, err := http.NewRequest(method, spec.Url, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	SetCustomHeaders(req, spec.Headers)
+	if spec.BodyType == BodyTypeMultipart && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
+
+// Send 生成并发送请求。OAuth2 认证的请求返回 401 时，重新获取 token 后重试一次
+func Send(spec RequestSpec, timeout int) ResponseWrapper {
+	resp := send(spec, timeout, false)
+	if spec.Auth != nil && spec.Auth.Type == AuthOAuth2 && resp.StatusCode == http.StatusUnauthorized {
+		resp = send(spec, timeout, true)
+	}
+
+	return resp
+}
+
+func send(spec RequestSpec, timeout int, refreshToken bool) ResponseWrapper {
+	req, err := NewRequest(spec)
+	if err != nil {
+		resp := createRequestError(err)
+		resp.Exchange = failedExchange(spec, err)
+		return resp
+	}
+	client, err := profileClient(spec.Profile, timeout)
+	if err != nil {
+		return ResponseWrapper{StatusCode: 0, Body: err.Error(), Header: make(http.Header), Exchange: failedExchange(spec, err)}
+	}
+	if spec.Auth != nil {
+		if err := spec.Auth.apply(req, client, refreshToken); err != nil {
+			return ResponseWrapper{StatusCode: 0, Body: err.Error(), Header: make(http.Header), Exchange: failedExchange(spec, err)}
+		}
+	}
+
+	return doRequest(client, req, spec.MaxBodySize)
+}
+
+// ValidateBody 校验请求体类型和请求体格式
+func ValidateBody(bodyType, body string) error {
+	switch bodyType {
+	case BodyTypeAuto, BodyTypeJson, BodyTypeForm, BodyTypeMultipart, BodyTypeRaw:
+	default:
+		return fmt.Errorf("unsupported body type %q", bodyType)
+	}
+	_, _, err := encodeBody(RequestSpec{BodyType: bodyType, Body: body})
+
+	return err
+}
+
+// encodeBody 返回请求体和 Content-Type，没有请求体时返回 nil
+func encodeBody(spec RequestSpec) ([]byte, string, error) {
+	switch spec.BodyType {
+	case BodyTypeAuto:
+		if strings.TrimSpace(spec.Body) == "" {
+			return nil, "", nil
+		}
+		return []byte(spec.Body), "application/json", nil
+	case BodyTypeJson:
+		return []byte(spec.Body), "application/json", nil
+	case BodyTypeForm:
+		return encodeForm(spec.Body)
+	case BodyTypeMultipart:
+		return encodeMultipart(spec.Body)
+	case BodyTypeRaw:
+		contentType := strings.TrimSpace(spec.ContentType)
+		if contentType == "" {
+			contentType = defaultRawContentType
+		}
+		return []byte(spec.Body), contentType, nil
+	}
+
+	return nil, "", fmt.Errorf("unsupported body type %q", spec.BodyType)
+}
+
+// encodeForm JSON 对象按字段编码，其他内容视为已编码的 a=1&b=2 原样发送
+func encodeForm(body string) ([]byte, string, error) {
+	const contentType = "application/x-www-form-urlencoded"
+	trimmed := strings.TrimSpace(body)
+	if !strings.HasPrefix(trimmed, "{") {
+		return []byte(body), contentType, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(trimmed), &fields); err != nil {
+		return nil, "", fmt.Errorf("invalid form body: %w", err)
+	}
+	values := url.Values{}
+	for key, value := range fields {
+		if _, ok := value.(map[string]interface{}); ok {
+			return nil, "", fmt.Errorf("form field %q must be a scalar", key)
+		}
+		values.Set(key, fmt.Sprint(value))
+	}
+
+	return []byte(values.Encode()), contentType, nil
+}
+
+// encodeMultipart 按字段名排序写入，保证同一配置生成的请求体相同（边界除外）
+func encodeMultipart(body string) ([]byte, string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(strings.TrimSpace(body)), &fields); err != nil {
+		return nil, "", fmt.Errorf("multipart body must be a JSON object: %w", err)
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, key := range keys {
+		raw := fields[key]
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil {
+			if err := writer.WriteField(key, value); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+		var file multipartFile
+		if err := json.Unmarshal(raw, &file); err != nil || file.Filename == "" {
+			return nil, "", fmt.Errorf("multipart field %q must be a string or a file object with filename", key)
+		}
+		content := []byte(file.Content)
+		switch file.Encoding {
+		case "":
+		case "base64":
+			decoded, err := base64.StdEncoding.DecodeString(file.Content)
+			if err != nil {
+				return nil, "", fmt.Errorf("multipart file %q: invalid base64 content", key)
+			}
+			content = decoded
+		default:
+			return nil, "", fmt.Errorf("multipart file %q: unsupported encoding %q", key, file.Encoding)
+		}
+		part, err := writer.CreatePart(filePartHeader(key, file))
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	if len(keys) == 0 {
+		return nil, "", errors.New("multipart body is empty")
+	}
+
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+func filePartHeader(field string, file multipartFile) textproto.MIMEHeader {
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	quote := strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quote.Replace(field), quote.Replace(file.Filename)))
+	header.Set("Content-Type", contentType)
+
+	return header
+}
@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewRequestBodyTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        RequestSpec
+		contentType string
+		body        string
+	}{
+		{"auto json", RequestSpec{Method: "PUT", Body: `{"a":1}`}, "application/json", `{"a":1}`},
+		{"auto empty", RequestSpec{Method: "DELETE"}, "", ""},
+		{"json", RequestSpec{Method: "PATCH", BodyType: BodyTypeJson, Body: `[1]`}, "application/json", `[1]`},
+		{"form object", RequestSpec{Method: "POST", BodyType: BodyTypeForm, Body: `{"b":"x y","a":1}`}, "application/x-www-form-urlencoded", "a=1&b=x+y"},
+		{"form encoded", RequestSpec{Method: "POST", BodyType: BodyTypeForm, Body: "a=1&b=2"}, "application/x-www-form-urlencoded", "a=1&b=2"},
+		{"raw xml", RequestSpec{Method: "POST", BodyType: BodyTypeRaw, Body: "<a/>", ContentType: "application/xml"}, "application/xml", "<a/>"},
+		{"raw default", RequestSpec{Method: "POST", BodyType: BodyTypeRaw, Body: "hi"}, defaultRawContentType, "hi"},
+		{"get ignores body", RequestSpec{Method: "GET", BodyType: BodyTypeJson, Body: `{"a":1}`}, "", ""},
+		{"head ignores body", RequestSpec{Method: "head", Body: `{"a":1}`}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Url = "http://example.com"
+			req, err := NewRequest(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.Method != strings.ToUpper(tt.spec.Method) {
+				t.Fatalf("unexpected method %s", req.Method)
+			}
+			if got := req.Header.Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("unexpected content-type %q", got)
+			}
+			var body []byte
+			if req.Body != nil {
+				body, _ = io.ReadAll(req.Body)
+			}
+			if string(body) != tt.body {
+				t.Fatalf("unexpected body %q", body)
+			}
+		})
+	}
+}
+
+func TestNewRequestMultipart(t *testing.T) {
+	req, err := NewRequest(RequestSpec{
+		Method:   "POST",
+		Url:      "http://example.com/upload",
+		BodyType: BodyTypeMultipart,
+		Headers:  `{"Content-Type": "text/plain", "X-Trace": "1"}`,
+		Body:     `{"name": "report", "file": {"filename": "a.csv", "content": "aWQsbmFtZQo=", "encoding": "base64", "content_type": "text/csv"}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("custom header should not override multipart content-type: %q", req.Header.Get("Content-Type"))
+	}
+	if req.Header.Get("X-Trace") != "1" {
+		t.Fatal("custom headers should be applied")
+	}
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+	if req.FormValue("name") != "report" {
+		t.Fatalf("unexpected field: %v", req.MultipartForm.Value)
+	}
+	files := req.MultipartForm.File["file"]
+	if len(files) != 1 || files[0].Filename != "a.csv" || files[0].Header.Get("Content-Type") != "text/csv" {
+		t.Fatalf("unexpected file: %+v", files)
+	}
+	f, _ := files[0].Open()
+	content, _ := io.ReadAll(f)
+	if string(content) != "id,name\n" {
+		t.Fatalf("unexpected file content %q", content)
+	}
+}
+
+func TestValidateBody(t *testing.T) {
+	valid := map[string]string{
+		"":          "",
+		"json":      `{"a":1}`,
+		"form":      `{"a":"1"}`,
+		"multipart": `{"a":"1","f":{"filename":"x.txt","content":"hi"}}`,
+		"raw":       "anything",
+	}
+	for bodyType, body := range valid {
+		if err := ValidateBody(bodyType, body); err != nil {
+			t.Errorf("%s: unexpected error %v", bodyType, err)
+		}
+	}
+	invalid := [][2]string{
+		{"xml", "<a/>"},
+		{"form", `{"a":`},
+		{"form", `{"a":{"b":1}}`},
+		{"multipart", "a=1"},
+		{"multipart", "{}"},
+		{"multipart", `{"f":{"content":"x"}}`},
+		{"multipart", `{"f":{"filename":"x","content":"!!","encoding":"base64"}}`},
+	}
+	for _, tt := range invalid {
+		if err := ValidateBody(tt[0], tt[1]); err == nil {
+			t.Errorf("%s %s: expected error", tt[0], tt[1])
+		}
+	}
+}
+
+func TestSendUsesMethod(t *testing.T) {
+	withMockClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodDelete || req.Header.Get("Authorization") != "Bearer x" {
+			t.Fatalf("unexpected request %s %v", req.Method, req.Header)
+		}
+		return &http.Response{StatusCode: 204, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}}, nil
+	})
+
+	resp := Send(RequestSpec{Method: "DELETE", Url: "http://example.com/items/1", Headers: `{"Authorization": "Bearer x"}`}, 0)
+	if resp.StatusCode != 204 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp := Send(RequestSpec{Method: "POST", Url: "http://example.com", BodyType: BodyTypeMultipart, Body: "bad"}, 0); resp.StatusCode != 0 || !strings.Contains(resp.Body, "创建HTTP请求错误") {
+		t.Fatalf("expected request error, got %+v", resp)
+	}
+}
@@ -47,7 +47,7 @@ var enUS = map[string]string{
 	"invalid_log_id":                         "Invalid log ID",
 	"invalid_task_id":                        "Invalid task ID",
 	"get_task_info_failed":                   "Failed to get task information",
-	"only_shell_task_can_stop":               "Only SHELL tasks can be stopped manually",
+	"only_shell_task_can_stop":               "This type of task cannot be stopped manually",
 	"task_node_list_empty":                   "Task node list is empty",
 	"stop_task_sent":                         "Stop command sent, please wait for task to exit",
 	"param_range_1_12":                       "Parameter value range: 1-12",
@@ -95,4 +95,74 @@ var enUS = map[string]string{
 	"builtin_template_readonly":              "Built-in template is read-only",
 	"builtin_template_no_delete":             "Built-in template cannot be deleted",
 	"task_not_found":                         "Task not found",
+	"internal_ca_disabled":                   "Internal CA is not enabled",
+	"cert_rotate_success":                    "Certificate rotated",
+	"cert_rotate_failed":                     "Certificate rotation failed",
+	"cert_revoke_success":                    "Certificate revoked",
+	"template_params_invalid":                "Invalid template parameters",
+	"template_param_required":                "Parameter %s is required",
+	"template_param_type":                    "Parameter %s has an invalid type",
+	"template_param_pattern":                 "Parameter %s does not match the required format",
+	"template_param_option":                  "Parameter %s is not one of the allowed options",
+	"template_task_name_too_long":            "Task name must be at most 32 characters",
+	"template_bundle_invalid":                "Invalid template bundle: %s",
+	"template_bundle_too_large":              "Template bundle exceeds 2MB",
+	"template_import_failed":                 "Failed to import templates",
+	"template_import_success":                "Templates imported",
+	"rollback_hosts_missing":                 "Hosts bound in this version no longer exist",
+	"task_change_pending":                    "Task is protected, change request #%d is waiting for approval",
+	"task_run_pending":                       "Task is protected, run request #%d is waiting for approval",
+	"change_request_not_found":               "Change request not found",
+	"change_request_not_pending":             "Change request has already been processed",
+	"change_request_self_review":             "You cannot review your own change request",
+	"change_request_not_author":              "Only the author can cancel the change request",
+	"change_request_stale":                   "The task has been modified since the request was submitted, please submit it again",
+	"change_request_approved":                "Change request approved",
+	"change_request_rejected":                "Change request rejected",
+	"change_request_cancelled":               "Change request cancelled",
+	"notify_rules_invalid":                   "Invalid notification rules: %s",
+	"notification_not_found":                 "Notification not found",
+	"notification_not_finished":              "Notification is still being delivered",
+	"notification_resend_queued":             "Notification queued for resend",
+	"command_required":                       "Please enter the command",
+	"notify_template_invalid":                "Invalid notification template: %s",
+	"notify_test_failed":                     "Failed to render the notification: %s",
+	"notify_test_no_target":                  "No receiver selected for the test notification",
+	"secret_name_invalid":                    "Secret name may only contain letters, digits, _ . - (max 64 characters)",
+	"secret_name_exists":                     "Secret name already exists",
+	"secret_value_required":                  "Please enter the secret value",
+	"secret_in_use":                          "Secret is used by tasks: %s",
+	"http_profile_not_found":                 "HTTP profile does not exist",
+	"http_profile_name_exists":               "HTTP profile name already exists",
+	"http_profile_invalid":                   "Invalid HTTP profile: %s",
+	"http_profile_in_use":                    "HTTP profile is used by tasks: %s",
+	"secret_in_use_by_profile":               "Secret is used by HTTP profiles: %s",
+	"http_async_site_url_required":           "Callback mode requires the site URL to be configured in system settings",
+	"secret_in_use_by_host":                  "Secret is used by host SSH settings: %s",
+	"ssh_config_invalid":                     "Invalid SSH settings: %s",
+	"data_source_name_exists":                "Data source name already exists",
+	"data_source_invalid":                    "Invalid data source: %s",
+	"data_source_in_use":                     "Data source is used by tasks: %s",
+	"data_source_connect_failed":             "Connection failed: %s",
+	"data_source_connect_success":            "Connection succeeded",
+	"data_source_not_found":                  "Data source does not exist",
+	"grpc_descriptor_name_exists":            "Descriptor set name already exists",
+	"grpc_descriptor_file_required":          "Please upload a descriptor set file",
+	"grpc_descriptor_too_large":              "Descriptor set file exceeds 4MB",
+	"grpc_descriptor_invalid":                "Invalid descriptor set: %s",
+	"grpc_descriptor_in_use":                 "Descriptor set is used by tasks: %s",
+	"grpc_descriptor_not_found":              "Descriptor set does not exist",
+	"artifact_shell_only":                    "Artifacts can only be attached to Shell (RPC) tasks",
+	"artifact_file_required":                 "Please select a file",
+	"artifact_invalid":                       "Invalid artifact: %s",
+	"artifact_too_large":                     "Artifact exceeds 32MB",
+	"artifact_too_many":                      "A task can have at most %d artifacts",
+	"artifact_not_found":                     "Artifact does not exist",
+	"command_reserved":                       "The command cannot start with %s, it is reserved for gocron-node internal instructions",
+	"task_enable_pending":                    "Task saved as disabled, enabling a protected task needs approval: change request #%d is waiting for review",
+	"task_batch_pending":                     "Operation done, %d protected tasks are waiting for approval of their change requests",
+	"template_protocol_unsupported":          "Only HTTP and Shell tasks can be saved as templates",
+	"rollback_masked":                        "The version contains hidden credentials that the current task no longer has, edit the task and enter them instead of rolling back",
+	"artifact_protected":                     "Attachments of protected tasks cannot be changed directly, remove the protected tag through an approved change first",
+	"sql_node_requires_tls":                  "SQL tasks can only run on nodes when agent TLS is enabled (enable_tls or internal_ca), remove the hosts to run the task on the server",
 }
@@ -47,7 +47,7 @@ var zhCN = map[string]string{
 	"invalid_log_id":                         "参数错误: 无效的日志ID",
 	"invalid_task_id":                        "参数错误: 无效的任务ID",
 	"get_task_info_failed":                   "获取任务信息失败",
-	"only_shell_task_can_stop":               "仅支持SHELL任务手动停止",
+	"only_shell_task_can_stop":               "该类型的任务不支持手动停止",
 	"task_node_list_empty":                   "任务节点列表为空",
 	"stop_task_sent":                         "已执行停止操作, 请等待任务退出",
 	"param_range_1_12":                       "参数取值范围1-12",
@@ -95,4 +95,74 @@ var zhCN = map[string]string{
 	"builtin_template_readonly":              "内置模板不可修改",
 	"builtin_template_no_delete":             "内置模板不可删除",
 	"task_not_found":                         "任务不存在",
+	"internal_ca_disabled":                   "未启用内置CA",
+	"cert_rotate_success":                    "证书已轮换",
+	"cert_rotate_failed":                     "证书轮换失败",
+	"cert_revoke_success":                    "证书已吊销",
+	"template_params_invalid":                "模板参数声明不合法",
+	"template_param_required":                "参数 %s 不能为空",
+	"template_param_type":                    "参数 %s 类型不正确",
+	"template_param_pattern":                 "参数 %s 格式不正确",
+	"template_param_option":                  "参数 %s 不在可选值范围内",
+	"template_task_name_too_long":            "任务名称不能超过32个字符",
+	"template_bundle_invalid":                "模板包格式错误: %s",
+	"template_bundle_too_large":              "模板包不能超过 2MB",
+	"template_import_failed":                 "导入模板失败",
+	"template_import_success":                "模板导入完成",
+	"rollback_hosts_missing":                 "该版本绑定的主机已全部删除",
+	"task_change_pending":                    "任务受保护，变更申请 #%d 等待审批",
+	"task_run_pending":                       "任务受保护，执行申请 #%d 等待审批",
+	"change_request_not_found":               "变更申请不存在",
+	"change_request_not_pending":             "变更申请已处理",
+	"change_request_self_review":             "不能审批自己提交的变更申请",
+	"change_request_not_author":              "只有提交人可以撤回变更申请",
+	"change_request_stale":                   "申请提交后任务已被修改，请重新提交",
+	"change_request_approved":                "变更申请已通过",
+	"change_request_rejected":                "变更申请已驳回",
+	"change_request_cancelled":               "变更申请已撤回",
+	"notify_rules_invalid":                   "通知规则无效: %s",
+	"notification_not_found":                 "通知不存在",
+	"notification_not_finished":              "通知仍在投递中",
+	"notification_resend_queued":             "通知已重新加入投递队列",
+	"command_required":                       "请输入命令",
+	"notify_template_invalid":                "通知模板无效: %s",
+	"notify_test_failed":                     "生成通知失败: %s",
+	"notify_test_no_target":                  "测试通知没有选择接收者",
+	"secret_name_invalid":                    "密钥名称只能包含字母、数字和 _ . -，最多 64 个字符",
+	"secret_name_exists":                     "密钥名称已存在",
+	"secret_value_required":                  "请输入密钥的值",
+	"secret_in_use":                          "密钥正在被任务使用: %s",
+	"http_profile_not_found":                 "HTTP 配置不存在",
+	"http_profile_name_exists":               "HTTP 配置名称已存在",
+	"http_profile_invalid":                   "HTTP 配置无效: %s",
+	"http_profile_in_use":                    "HTTP 配置正在被任务使用: %s",
+	"secret_in_use_by_profile":               "密钥正在被 HTTP 配置使用: %s",
+	"http_async_site_url_required":           "回调模式需要先在系统设置中配置站点地址",
+	"secret_in_use_by_host":                  "密钥正在被主机 SSH 设置使用: %s",
+	"ssh_config_invalid":                     "SSH 设置错误: %s",
+	"data_source_name_exists":                "数据源名称已存在",
+	"data_source_invalid":                    "数据源无效: %s",
+	"data_source_in_use":                     "数据源正在被任务使用: %s",
+	"data_source_connect_failed":             "连接失败: %s",
+	"data_source_connect_success":            "连接成功",
+	"data_source_not_found":                  "数据源不存在",
+	"grpc_descriptor_name_exists":            "描述文件集名称已存在",
+	"grpc_descriptor_file_required":          "请上传描述文件集",
+	"grpc_descriptor_too_large":              "描述文件集超过 4MB",
+	"grpc_descriptor_invalid":                "描述文件集无效: %s",
+	"grpc_descriptor_in_use":                 "描述文件集正在被任务使用: %s",
+	"grpc_descriptor_not_found":              "描述文件集不存在",
+	"artifact_shell_only":                    "只有 Shell (RPC) 任务可以使用附件",
+	"artifact_file_required":                 "请选择文件",
+	"artifact_invalid":                       "附件无效: %s",
+	"artifact_too_large":                     "附件超过 32MB",
+	"artifact_too_many":                      "每个任务最多 %d 个附件",
+	"artifact_not_found":                     "附件不存在",
+	"command_reserved":                       "命令不能以 %s 开头，该前缀保留给 gocron-node 内部指令",
+	"task_enable_pending":                    "任务已保存为停用状态，启用受保护任务需要审批，变更申请 #%d 等待审批",
+	"task_batch_pending":                     "操作完成，%d 个受保护任务已提交变更申请，等待审批",
+	"template_protocol_unsupported":          "只有 HTTP 和 Shell 任务可以保存为模板",
+	"rollback_masked":                        "该版本包含已隐藏的凭据，当前任务中已没有对应的值，请编辑任务重新填写，不能直接回滚",
+	"artifact_protected":                     "受保护任务的附件不能直接修改，请先通过审批移除受保护标签",
+	"sql_node_requires_tls":                  "SQL 任务只能在启用节点 TLS（enable_tls 或 internal_ca）时发给节点执行，去掉关联主机可由服务端执行",
 }
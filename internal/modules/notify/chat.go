@@ -0,0 +1,135 @@
+package notify
+
+// 聊天应用通知（Telegram、Discord、Microsoft Teams）
+// 各平台消息格式固定，包含任务名称、状态、耗时和截断后的输出，写入 outbox 的内容为渠道无关的 chatMessage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+// chatOutputLimit 消息中保留的输出字符数，三个平台的单条消息上限都远大于此值
+const chatOutputLimit = 1500
+
+// chatMessage 聊天应用消息
+type chatMessage struct {
+	TaskId   int    `json:"task_id"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+	Output   string `json:"output"`
+	Remark   string `json:"remark"`
+}
+
+func (m chatMessage) failed() bool {
+	return isAlert(m.Status)
+}
+
+// title 消息标题，例如 "❌ backup - Failed"
+func (m chatMessage) title() string {
+	icon := "✅"
+	switch m.Status {
+	case StatusFailed:
+		icon = "❌"
+	case StatusSlaBreached:
+		icon = "⏰"
+	}
+	return fmt.Sprintf("%s %s - %s", icon, m.Name, m.Status)
+}
+
+// prepareChat 生成消息内容，每个接收者一个投递目标，格式为 "接收者ID:名称"
+func prepareChat(code string, msg Message) (string, []string, error) {
+	chatSetting, err := new(models.Setting).Chat(code)
+	if err != nil {
+		return "", nil, fmt.Errorf("从数据库获取%s配置失败-%w", code, err)
+	}
+	if len(chatSetting.Receivers) == 0 {
+		return "", nil, fmt.Errorf("%s接收者列表为空", code)
+	}
+
+	message := chatMessage{
+		Name:     fmt.Sprint(msg["name"]),
+		Status:   fmt.Sprint(msg["status"]),
+		Duration: "-",
+		Output:   truncateOutput(fmt.Sprint(msg["output"]), chatOutputLimit),
+	}
+	message.TaskId, _ = msg["task_id"].(int)
+	message.Remark, _ = msg["remark"].(string)
+	if duration, ok := msg["duration"].(time.Duration); ok {
+		message.Duration = formatDuration(duration)
+	}
+	content, err := json.Marshal(message)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(content), getActiveChatReceivers(chatSetting, msg), nil
+}
+
+func getActiveChatReceivers(chatSetting models.ChatSetting, msg Message) []string {
+	taskReceiverIds := strings.Split(msg["task_receiver_id"].(string), ",")
+	targets := []string{}
+	for _, v := range chatSetting.Receivers {
+		if utils.InStringSlice(taskReceiverIds, strconv.Itoa(v.Id)) {
+			targets = append(targets, fmt.Sprintf("%d:%s", v.Id, v.Name))
+		}
+	}
+
+	return targets
+}
+
+// loadChatDelivery 解析 outbox 内容并读取渠道配置和目标接收者
+func loadChatDelivery(code, content, target string) (models.ChatSetting, models.ChatReceiver, chatMessage, error) {
+	message := chatMessage{}
+	if err := json.Unmarshal([]byte(content), &message); err != nil {
+		return models.ChatSetting{}, models.ChatReceiver{}, message, fmt.Errorf("解析通知内容失败-%w", err)
+	}
+	settingModel := new(models.Setting)
+	chatSetting, err := settingModel.Chat(code)
+	if err != nil {
+		return chatSetting, models.ChatReceiver{}, message, fmt.Errorf("从数据库获取%s配置失败-%w", code, err)
+	}
+	idStr, _, _ := strings.Cut(target, ":")
+	id, _ := strconv.Atoi(idStr)
+	receiver, err := settingModel.ChatReceiver(code, id)
+	if err != nil {
+		return chatSetting, receiver, message, fmt.Errorf("%s接收者#%s不存在-%w", code, target, err)
+	}
+
+	return chatSetting, receiver, message, nil
+}
+
+// postChat 以 JSON 发送消息
+func postChat(url string, body interface{}) (int, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+
+	return checkResponse(httpclient.PostJson(url, string(payload), notifyHttpTimeout))
+}
+
+// truncateOutput 截断输出，保留开头部分并标注原始长度
+func truncateOutput(output string, limit int) string {
+	output = strings.TrimSpace(output)
+	runes := []rune(output)
+	if len(runes) <= limit {
+		return output
+	}
+
+	return fmt.Sprintf("%s\n...(truncated, %d chars total)", string(runes[:limit]), len(runes))
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
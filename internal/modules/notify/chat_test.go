@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	if got := truncateOutput("  short  ", 10); got != "short" {
+		t.Fatalf("short output should be kept, got %q", got)
+	}
+	got := truncateOutput(strings.Repeat("错", 12), 10)
+	if !strings.HasPrefix(got, strings.Repeat("错", 10)+"\n") || !strings.Contains(got, "12 chars total") {
+		t.Fatalf("unexpected truncated output: %q", got)
+	}
+}
+
+func TestPrepareChat(t *testing.T) {
+	defer setupNotifyTestDB(t)()
+
+	settingModel := new(models.Setting)
+	id, _ := settingModel.CreateChatReceiver(models.DiscordCode, models.ChatReceiver{Name: "ops", Target: "https://discord.test/hook"})
+	_, _ = settingModel.CreateChatReceiver(models.DiscordCode, models.ChatReceiver{Name: "dev", Target: "https://discord.test/other"})
+
+	msg := Message{
+		"task_receiver_id": strconv.Itoa(id),
+		"name":             "backup",
+		"output":           strings.Repeat("x", chatOutputLimit+10),
+		"status":           "Failed",
+		"task_id":          3,
+		"duration":         1500 * time.Millisecond,
+	}
+	content, targets, err := new(Discord).Prepare(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0] != strconv.Itoa(id)+":ops" {
+		t.Fatalf("unexpected targets: %v", targets)
+	}
+	message := chatMessage{}
+	_ = json.Unmarshal([]byte(content), &message)
+	if message.TaskId != 3 || message.Duration != "2s" || !message.failed() ||
+		!strings.Contains(message.Output, "truncated") {
+		t.Fatalf("unexpected message: %+v", message)
+	}
+
+	if _, _, err := new(Teams).Prepare(msg); err == nil {
+		t.Fatal("expected error when no teams receiver is configured")
+	}
+}
+
+func TestTelegramDeliver(t *testing.T) {
+	defer setupNotifyTestDB(t)()
+
+	var path string
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &received)
+		if received["chat_id"] == "blocked" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Forbidden: bot was blocked by the user"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	original := telegramApi
+	telegramApi = server.URL
+	defer func() { telegramApi = original }()
+
+	settingModel := new(models.Setting)
+	id, _ := settingModel.CreateChatReceiver(models.TelegramCode, models.ChatReceiver{Name: "ops", Target: "-100123"})
+	content, _ := json.Marshal(chatMessage{TaskId: 1, Name: "a<b>", Status: "Failed", Duration: "3s", Output: "exit 1"})
+	target := strconv.Itoa(id) + ":ops"
+
+	telegram := &Telegram{}
+	if _, err := telegram.Deliver(string(content), target); err == nil {
+		t.Fatal("expected error without bot token")
+	}
+
+	_ = settingModel.UpdateChatToken(models.TelegramCode, "123:abc")
+	if _, err := telegram.Deliver(string(content), target); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/bot123:abc/sendMessage" || received["chat_id"] != "-100123" || received["parse_mode"] != "HTML" {
+		t.Fatalf("unexpected request: %s %+v", path, received)
+	}
+	text := received["text"].(string)
+	if !strings.Contains(text, "a&lt;b&gt;") || !strings.Contains(text, "Duration: 3s") || !strings.Contains(text, "<pre>exit 1</pre>") {
+		t.Fatalf("unexpected text: %s", text)
+	}
+
+	blockedId, _ := settingModel.CreateChatReceiver(models.TelegramCode, models.ChatReceiver{Name: "blocked", Target: "blocked"})
+	statusCode, err := telegram.Deliver(string(content), strconv.Itoa(blockedId)+":blocked")
+	if statusCode != http.StatusForbidden || err == nil || !strings.Contains(err.Error(), "bot was blocked") ||
+		strings.Contains(err.Error(), "123:abc") {
+		t.Fatalf("unexpected error: %d %v", statusCode, err)
+	}
+}
+
+func TestDiscordAndTeamsRequest(t *testing.T) {
+	message := chatMessage{TaskId: 9, Name: "sync", Status: "Success", Duration: "12s", Output: "done", Remark: "nightly"}
+
+	discordBody := discordRequest(message, time.Unix(0, 0))
+	embed := discordBody["embeds"].([]map[string]interface{})[0]
+	if embed["color"] != discordColorSuccess || embed["description"] != "```\ndone\n```" || len(embed["fields"].([]map[string]interface{})) != 4 {
+		t.Fatalf("unexpected discord embed: %+v", embed)
+	}
+
+	message.Status = "Failed"
+	teamsBody := teamsRequest(message)
+	card := teamsBody["attachments"].([]map[string]interface{})[0]["content"].(map[string]interface{})
+	blocks := card["body"].([]map[string]interface{})
+	if blocks[0]["color"] != "Attention" || blocks[0]["text"] != "❌ sync - Failed" || len(blocks) != 3 {
+		t.Fatalf("unexpected teams card: %+v", card)
+	}
+}
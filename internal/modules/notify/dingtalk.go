@@ -0,0 +1,67 @@
+package notify
+
+// 发送消息到钉钉群机器人
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+type DingTalk struct{}
+
+func (dingTalk *DingTalk) Prepare(msg Message) (string, []string, error) {
+	return prepareIM(models.DingTalkCode, msg)
+}
+
+func (dingTalk *DingTalk) Deliver(content string, target string) (int, error) {
+	bot, message, err := loadIMDelivery(models.DingTalkCode, content, target)
+	if err != nil {
+		return 0, err
+	}
+	requestUrl, body := dingTalkRequest(bot, message, time.Now())
+
+	return postIM(requestUrl, body, "errcode", "errmsg")
+}
+
+// dingTalkRequest 生成 markdown 消息。配置了加签密钥时在 URL 上附加 timestamp 和 sign
+func dingTalkRequest(bot models.IMBot, message imMessage, now time.Time) (string, map[string]interface{}) {
+	requestUrl := bot.Webhook
+	if bot.Secret != "" {
+		timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+		sign := hmacSign(bot.Secret, timestamp+"\n"+bot.Secret)
+		separator := "?"
+		if strings.Contains(requestUrl, "?") {
+			separator = "&"
+		}
+		requestUrl += separator + "timestamp=" + timestamp + "&sign=" + url.QueryEscape(sign)
+	}
+
+	text := "### " + message.Title + "\n\n" + message.Text
+	at := map[string]interface{}{"isAtAll": false}
+	if message.Failed {
+		mobiles := splitList(bot.AtMobiles)
+		userIds := splitList(bot.AtUserIds)
+		// markdown 消息正文中包含 @手机号 / @userId 才会提醒到人
+		for _, mention := range append(mobiles, userIds...) {
+			text += " @" + mention
+		}
+		at = map[string]interface{}{
+			"atMobiles": mobiles,
+			"atUserIds": userIds,
+			"isAtAll":   bot.AtAll,
+		}
+	}
+
+	return requestUrl, map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": message.Title,
+			"text":  text,
+		},
+		"at": at,
+	}
+}
@@ -0,0 +1,60 @@
+package notify
+
+// 发送消息到 Discord 频道的 Webhook，使用 embed 展示任务信息
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+const (
+	discordColorSuccess = 0x2EB67D
+	discordColorFailure = 0xE01E5A
+)
+
+type Discord struct{}
+
+// Prepare 每个 Webhook 一个投递目标
+func (discord *Discord) Prepare(msg Message) (string, []string, error) {
+	return prepareChat(models.DiscordCode, msg)
+}
+
+func (discord *Discord) Deliver(content string, target string) (int, error) {
+	_, receiver, message, err := loadChatDelivery(models.DiscordCode, content, target)
+	if err != nil {
+		return 0, err
+	}
+
+	return postChat(receiver.Target, discordRequest(message, time.Now()))
+}
+
+func discordRequest(message chatMessage, now time.Time) map[string]interface{} {
+	color := discordColorSuccess
+	if message.failed() {
+		color = discordColorFailure
+	}
+	fields := []map[string]interface{}{
+		{"name": "Task ID", "value": fmt.Sprint(message.TaskId), "inline": true},
+		{"name": "Status", "value": message.Status, "inline": true},
+		{"name": "Duration", "value": message.Duration, "inline": true},
+	}
+	if message.Remark != "" {
+		fields = append(fields, map[string]interface{}{"name": "Remark", "value": message.Remark})
+	}
+	embed := map[string]interface{}{
+		"title":     message.title(),
+		"color":     color,
+		"fields":    fields,
+		"timestamp": now.UTC().Format(time.RFC3339),
+	}
+	if message.Output != "" {
+		embed["description"] = "```\n" + message.Output + "\n```"
+	}
+
+	return map[string]interface{}{
+		"username": "gocron",
+		"embeds":   []map[string]interface{}{embed},
+	}
+}
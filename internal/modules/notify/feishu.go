@@ -0,0 +1,63 @@
+package notify
+
+// 发送消息到飞书群机器人
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+type Feishu struct{}
+
+func (feishu *Feishu) Prepare(msg Message) (string, []string, error) {
+	return prepareIM(models.FeishuCode, msg)
+}
+
+func (feishu *Feishu) Deliver(content string, target string) (int, error) {
+	bot, message, err := loadIMDelivery(models.FeishuCode, content, target)
+	if err != nil {
+		return 0, err
+	}
+
+	return postIM(bot.Webhook, feishuRequest(bot, message, time.Now()), "code", "msg")
+}
+
+// feishuRequest 生成消息卡片，失败为红色标题并 @ 配置的成员。
+// 配置了签名校验时在请求体中附加 timestamp 和 sign
+func feishuRequest(bot models.IMBot, message imMessage, now time.Time) map[string]interface{} {
+	text := message.Text
+	template := "green"
+	if message.Failed {
+		template = "red"
+		for _, userId := range splitList(bot.AtUserIds) {
+			text += " <at id=" + userId + "></at>"
+		}
+		if bot.AtAll {
+			text += " <at id=all></at>"
+		}
+	}
+
+	body := map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"config": map[string]bool{"wide_screen_mode": true},
+			"header": map[string]interface{}{
+				"template": template,
+				"title":    map[string]string{"tag": "plain_text", "content": message.Title},
+			},
+			"elements": []map[string]string{
+				{"tag": "markdown", "content": text},
+			},
+		},
+	}
+	if bot.Secret != "" {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		body["timestamp"] = timestamp
+		// 飞书签名以 timestamp + "\n" + secret 作为密钥，对空字符串计算 HMAC-SHA256
+		body["sign"] = hmacSign(timestamp+"\n"+bot.Secret, "")
+	}
+
+	return body
+}
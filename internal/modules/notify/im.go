@@ -0,0 +1,115 @@
+package notify
+
+// 即时通讯群机器人通知（钉钉、企业微信、飞书）
+// 写入 outbox 的内容为渠道无关的 imMessage，投递时再读取机器人配置生成各平台的请求，
+// 这样加签时间戳在每次重试时都是最新的，修改机器人的 @ 成员也会对未投递的通知生效。
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+// imMessage 群机器人消息
+type imMessage struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	// Failed 任务执行失败或违反 SLA，需要 @ 机器人配置的成员
+	Failed bool `json:"failed"`
+}
+
+// prepareIM 渲染群机器人消息，每个机器人一个投递目标，格式为 "机器人ID:名称"
+func prepareIM(code string, msg Message) (string, []string, error) {
+	imSetting, err := new(models.Setting).IM(code)
+	if err != nil {
+		return "", nil, fmt.Errorf("从数据库获取%s配置失败-%w", code, err)
+	}
+	if len(imSetting.Bots) == 0 {
+		return "", nil, fmt.Errorf("%s机器人列表为空", code)
+	}
+	status, _ := msg["status"].(string)
+	message := imMessage{
+		Title:  fmt.Sprintf("[%s] %s", status, msg["name"]),
+		Text:   html.UnescapeString(parseNotifyTemplate(imSetting.Template, msg)),
+		Failed: isAlert(status),
+	}
+	content, err := json.Marshal(message)
+	if err != nil {
+		return "", nil, err
+	}
+
+	taskReceiverIds := strings.Split(msg["task_receiver_id"].(string), ",")
+	targets := []string{}
+	for _, bot := range imSetting.Bots {
+		if utils.InStringSlice(taskReceiverIds, strconv.Itoa(bot.Id)) {
+			targets = append(targets, fmt.Sprintf("%d:%s", bot.Id, bot.Name))
+		}
+	}
+
+	return string(content), targets, nil
+}
+
+// loadIMDelivery 解析 outbox 内容并读取目标机器人
+func loadIMDelivery(code, content, target string) (models.IMBot, imMessage, error) {
+	message := imMessage{}
+	if err := json.Unmarshal([]byte(content), &message); err != nil {
+		return models.IMBot{}, message, fmt.Errorf("解析通知内容失败-%w", err)
+	}
+	idStr, _, _ := strings.Cut(target, ":")
+	id, _ := strconv.Atoi(idStr)
+	bot, err := new(models.Setting).IMBot(code, id)
+	if err != nil {
+		return bot, message, fmt.Errorf("%s机器人#%s不存在-%w", code, target, err)
+	}
+
+	return bot, message, nil
+}
+
+// postIM 发送请求，平台在 HTTP 200 的响应体中用错误码表示失败
+func postIM(url string, body interface{}, codeField, msgField string) (int, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+	resp := httpclient.PostJson(url, string(payload), notifyHttpTimeout)
+	statusCode, err := checkResponse(resp)
+	if err != nil {
+		return statusCode, err
+	}
+
+	result := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(resp.Body), &result); err != nil {
+		return statusCode, fmt.Errorf("无法解析响应: %s", resp.Body)
+	}
+	if code, ok := result[codeField].(float64); ok && code != 0 {
+		return statusCode, fmt.Errorf("%s %v: %v", codeField, code, result[msgField])
+	}
+
+	return statusCode, nil
+}
+
+// hmacSign 计算 HMAC-SHA256 并做 base64 编码
+func hmacSign(key, data string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
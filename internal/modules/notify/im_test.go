@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func TestDingTalkRequest(t *testing.T) {
+	bot := models.IMBot{
+		Webhook:   "https://oapi.dingtalk.com/robot/send?access_token=abc",
+		Secret:    "SECtest",
+		AtMobiles: "13800000000, 13900000000",
+		AtAll:     true,
+	}
+	now := time.UnixMilli(1700000000000)
+	requestUrl, body := dingTalkRequest(bot, imMessage{Title: "[Failed] backup", Text: "disk full", Failed: true}, now)
+
+	parsed, err := url.Parse(requestUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("access_token") != "abc" || query.Get("timestamp") != "1700000000000" {
+		t.Fatalf("unexpected query: %s", parsed.RawQuery)
+	}
+	if want := hmacSign("SECtest", "1700000000000\nSECtest"); query.Get("sign") != want {
+		t.Fatalf("sign = %s, want %s", query.Get("sign"), want)
+	}
+
+	text := body["markdown"].(map[string]string)["text"]
+	if !strings.Contains(text, "@13800000000") || !strings.Contains(text, "@13900000000") {
+		t.Fatalf("failure text should mention mobiles: %s", text)
+	}
+	at := body["at"].(map[string]interface{})
+	if at["isAtAll"] != true || len(at["atMobiles"].([]string)) != 2 {
+		t.Fatalf("unexpected at: %+v", at)
+	}
+
+	// 成功不 @ 任何人，未配置密钥不加签
+	bot.Secret = ""
+	requestUrl, body = dingTalkRequest(bot, imMessage{Title: "[Success] backup", Text: "ok"}, now)
+	if strings.Contains(requestUrl, "sign=") || strings.Contains(body["markdown"].(map[string]string)["text"], "@") {
+		t.Fatalf("success message should be unsigned and without mentions: %s %+v", requestUrl, body)
+	}
+}
+
+func TestWeComRequest(t *testing.T) {
+	bot := models.IMBot{AtMobiles: "13800000000", AtUserIds: "zhangsan"}
+
+	body := weComRequest(bot, imMessage{Title: "[Failed] backup", Text: "disk full", Failed: true})
+	if body["msgtype"] != "text" {
+		t.Fatalf("failure with mentions should use text message: %+v", body)
+	}
+	textBody := body["text"].(map[string]interface{})
+	if textBody["mentioned_list"].([]string)[0] != "zhangsan" || textBody["mentioned_mobile_list"].([]string)[0] != "13800000000" {
+		t.Fatalf("unexpected mentions: %+v", textBody)
+	}
+
+	body = weComRequest(bot, imMessage{Title: "[Success] backup", Text: "ok"})
+	if body["msgtype"] != "markdown" || !strings.Contains(body["markdown"].(map[string]string)["content"], `color="info"`) {
+		t.Fatalf("success should use markdown: %+v", body)
+	}
+}
+
+func TestFeishuRequest(t *testing.T) {
+	bot := models.IMBot{Secret: "feishu-secret", AtUserIds: "ou_1", AtAll: true}
+	body := feishuRequest(bot, imMessage{Title: "[Failed] backup", Text: "disk full", Failed: true}, time.Unix(1700000000, 0))
+
+	if body["timestamp"] != "1700000000" || body["sign"] != hmacSign("1700000000\nfeishu-secret", "") {
+		t.Fatalf("unexpected signature: %v %v", body["timestamp"], body["sign"])
+	}
+	card := body["card"].(map[string]interface{})
+	if card["header"].(map[string]interface{})["template"] != "red" {
+		t.Fatalf("failure card should be red: %+v", card["header"])
+	}
+	content := card["elements"].([]map[string]string)[0]["content"]
+	if !strings.Contains(content, "<at id=ou_1></at>") || !strings.Contains(content, "<at id=all></at>") {
+		t.Fatalf("failure card should mention users: %s", content)
+	}
+}
+
+func TestDingTalkDeliver(t *testing.T) {
+	defer setupNotifyTestDB(t)()
+
+	var received map[string]interface{}
+	var errcode int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &received)
+		if r.URL.Query().Get("sign") == "" {
+			t.Errorf("request should be signed: %s", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"errcode": errcode, "errmsg": "sign not match"})
+	}))
+	defer server.Close()
+
+	settingModel := new(models.Setting)
+	botId, _ := settingModel.CreateIMBot(models.DingTalkCode, models.IMBot{Name: "ops", Webhook: server.URL + "/robot/send?access_token=x", Secret: "s"})
+	_, _ = settingModel.CreateIMBot(models.DingTalkCode, models.IMBot{Name: "other", Webhook: server.URL})
+
+	dingTalk := &DingTalk{}
+	msg := Message{"task_receiver_id": "999," + strconv.Itoa(botId), "name": "backup", "output": "done", "status": "Success", "task_id": 1}
+	content, targets, err := dingTalk.Prepare(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0] != strconv.Itoa(botId)+":ops" {
+		t.Fatalf("unexpected targets: %v", targets)
+	}
+
+	statusCode, err := dingTalk.Deliver(content, targets[0])
+	if err != nil || statusCode != http.StatusOK {
+		t.Fatalf("deliver failed: %d %v", statusCode, err)
+	}
+	markdown := received["markdown"].(map[string]interface{})
+	if markdown["title"] != "[Success] backup" || !strings.Contains(markdown["text"].(string), "backup") {
+		t.Fatalf("unexpected body: %+v", received)
+	}
+
+	// 钉钉在 HTTP 200 的响应中返回错误码
+	errcode = 310000
+	if _, err := dingTalk.Deliver(content, targets[0]); err == nil || !strings.Contains(err.Error(), "310000") {
+		t.Fatalf("expected errcode error, got %v", err)
+	}
+	if _, err := dingTalk.Deliver(content, "12345:removed"); err == nil {
+		t.Fatal("expected error for removed bot")
+	}
+}
@@ -0,0 +1,120 @@
+package notify
+
+// 告警平台通知（PagerDuty、Opsgenie）
+// 任务失败时以固定的去重键触发事件，同一任务下一次执行成功时自动关闭。
+// 事件状态记录在 task_incident 表，入队时更新，投递时核对：
+// 触发请求重试到达前事件已被关闭，或关闭请求到达前任务再次失败，都会跳过过期的请求。
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+const (
+	incidentTrigger = "trigger"
+	incidentResolve = "resolve"
+	// incidentOutputLimit 事件详情中保留的输出字符数
+	incidentOutputLimit = 1000
+)
+
+// incidentMessage 告警事件
+type incidentMessage struct {
+	Action   string `json:"action"`
+	TaskId   int    `json:"task_id"`
+	Name     string `json:"name"`
+	Output   string `json:"output"`
+	Remark   string `json:"remark"`
+	DedupKey string `json:"dedup_key"`
+}
+
+// summary 事件标题
+func (m incidentMessage) summary() string {
+	return fmt.Sprintf("gocron task %s (#%d) failed", m.Name, m.TaskId)
+}
+
+// prepareIncident 任务失败或违反 SLA 时为每个选中的服务打开事件，成功时只为事件打开中的服务生成关闭请求。
+// 投递目标格式为 "服务ID:名称"
+func prepareIncident(code string, channel models.NotifyChannel, msg Message) (string, []string, error) {
+	taskId, _ := msg["task_id"].(int)
+	if taskId <= 0 {
+		return "", nil, errors.New("告警通知缺少任务ID")
+	}
+	services, err := new(models.Setting).IncidentServices(code)
+	if err != nil {
+		return "", nil, fmt.Errorf("从数据库获取%s配置失败-%w", code, err)
+	}
+	if len(services) == 0 {
+		return "", nil, fmt.Errorf("%s服务列表为空", code)
+	}
+
+	message := incidentMessage{
+		Action:   incidentResolve,
+		TaskId:   taskId,
+		Name:     fmt.Sprint(msg["name"]),
+		Output:   truncateOutput(fmt.Sprint(msg["output"]), incidentOutputLimit),
+		DedupKey: models.IncidentDedupKey(taskId),
+	}
+	message.Remark, _ = msg["remark"].(string)
+	if isAlert(msg["status"]) {
+		message.Action = incidentTrigger
+	}
+
+	taskReceiverIds := strings.Split(msg["task_receiver_id"].(string), ",")
+	targets := []string{}
+	for _, service := range services {
+		if !utils.InStringSlice(taskReceiverIds, strconv.Itoa(service.Id)) {
+			continue
+		}
+		if message.Action == incidentTrigger {
+			if err := models.OpenIncident(taskId, message.Name, channel, service.Id); err != nil {
+				return "", nil, fmt.Errorf("记录告警事件失败-%w", err)
+			}
+		} else {
+			resolved, err := models.ResolveIncident(taskId, channel, service.Id)
+			if err != nil {
+				return "", nil, fmt.Errorf("记录告警事件失败-%w", err)
+			}
+			if !resolved {
+				continue
+			}
+		}
+		targets = append(targets, fmt.Sprintf("%d:%s", service.Id, service.Name))
+	}
+	content, err := json.Marshal(message)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(content), targets, nil
+}
+
+// loadIncidentDelivery 解析 outbox 内容并读取目标服务。
+// 事件状态已与请求不一致时 stale 为 true，调用方应跳过投递
+func loadIncidentDelivery(code string, channel models.NotifyChannel, content, target string) (service models.IncidentService, message incidentMessage, stale bool, err error) {
+	if err = json.Unmarshal([]byte(content), &message); err != nil {
+		return service, message, false, fmt.Errorf("解析通知内容失败-%w", err)
+	}
+	idStr, _, _ := strings.Cut(target, ":")
+	id, _ := strconv.Atoi(idStr)
+	service, err = new(models.Setting).IncidentService(code, id)
+	if err != nil {
+		return service, message, false, fmt.Errorf("%s服务#%s不存在-%w", code, target, err)
+	}
+	open, err := models.IncidentIsOpen(message.TaskId, channel, service.Id)
+	if err != nil {
+		return service, message, false, err
+	}
+	if open != (message.Action == incidentTrigger) {
+		logger.Infof("#notify#%s#跳过过期的%s请求#任务ID-%d#%s", code, message.Action, message.TaskId, target)
+		return service, message, true, nil
+	}
+
+	return service, message, false, nil
+}
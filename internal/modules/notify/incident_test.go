@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+// incidentStandIn 模拟告警平台，记录收到的请求
+type incidentStandIn struct {
+	mu       sync.Mutex
+	requests []incidentRequest
+}
+
+type incidentRequest struct {
+	Path          string
+	Authorization string
+	Body          map[string]interface{}
+}
+
+func (s *incidentStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	body := map[string]interface{}{}
+	_ = json.Unmarshal(data, &body)
+	s.mu.Lock()
+	s.requests = append(s.requests, incidentRequest{Path: r.URL.RequestURI(), Authorization: r.Header.Get("Authorization"), Body: body})
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write([]byte(`{"status":"success"}`))
+}
+
+// deliverAll 投递 outbox 中全部到期的通知
+func deliverAll(t *testing.T) int {
+	t.Helper()
+	items, err := models.ClaimDueNotifications(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		deliver(item)
+	}
+	return len(items)
+}
+
+func incidentMessageFor(channel models.NotifyChannel, receiverId int, status string) Message {
+	return Message{
+		"task_type":        int8(channel),
+		"task_receiver_id": strconv.Itoa(receiverId),
+		"name":             "backup",
+		"output":           "disk full",
+		"status":           status,
+		"task_id":          42,
+	}
+}
+
+func TestPagerDutyTriggerAndAutoResolve(t *testing.T) {
+	defer setupNotifyTestDB(t)()
+	standIn := &incidentStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	serviceId, _ := new(models.Setting).CreateIncidentService(models.PagerDutyCode, models.IncidentService{Name: "ops", Key: "routing-key", ApiUrl: server.URL})
+	channel := models.NotifyChannelPagerDuty
+
+	// 连续两次失败只对应一个事件
+	for i := 0; i < 2; i++ {
+		if err := enqueue(incidentMessageFor(channel, serviceId, "Failed")); err != nil {
+			t.Fatal(err)
+		}
+		deliverAll(t)
+	}
+	incident := models.TaskIncident{}
+	models.Db.First(&incident)
+	if incident.Status != models.IncidentOpen || incident.TriggerCount != 2 || incident.DedupKey != "gocron-task-42" {
+		t.Fatalf("unexpected incident: %+v", incident)
+	}
+	first := standIn.requests[0].Body
+	if first["routing_key"] != "routing-key" || first["event_action"] != "trigger" || first["dedup_key"] != "gocron-task-42" {
+		t.Fatalf("unexpected trigger event: %+v", first)
+	}
+	if summary := first["payload"].(map[string]interface{})["summary"]; summary != "gocron task backup (#42) failed" {
+		t.Fatalf("unexpected summary: %v", summary)
+	}
+
+	// 恢复成功后自动关闭
+	_ = enqueue(incidentMessageFor(channel, serviceId, "Success"))
+	if n := deliverAll(t); n != 1 {
+		t.Fatalf("expected 1 resolve delivery, got %d", n)
+	}
+	last := standIn.requests[len(standIn.requests)-1].Body
+	if last["event_action"] != "resolve" || last["dedup_key"] != "gocron-task-42" || last["payload"] != nil {
+		t.Fatalf("unexpected resolve event: %+v", last)
+	}
+	models.Db.First(&incident)
+	if incident.Status != models.IncidentResolved || incident.ResolvedAt == nil {
+		t.Fatalf("incident should be resolved: %+v", incident)
+	}
+
+	// 没有打开中的事件时，成功不产生任何请求
+	_ = enqueue(incidentMessageFor(channel, serviceId, "Success"))
+	if n := deliverAll(t); n != 0 || len(standIn.requests) != 3 {
+		t.Fatalf("expected no delivery, got %d deliveries and %d requests", n, len(standIn.requests))
+	}
+}
+
+func TestIncidentSkipsStaleTrigger(t *testing.T) {
+	defer setupNotifyTestDB(t)()
+	standIn := &incidentStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	serviceId, _ := new(models.Setting).CreateIncidentService(models.PagerDutyCode, models.IncidentService{Name: "ops", Key: "k", ApiUrl: server.URL})
+	channel := models.NotifyChannelPagerDuty
+
+	// 触发请求尚未投递时任务已恢复，过期的触发请求不应再打开事件
+	_ = enqueue(incidentMessageFor(channel, serviceId, "Failed"))
+	_ = enqueue(incidentMessageFor(channel, serviceId, "Success"))
+	deliverAll(t)
+
+	if len(standIn.requests) != 1 || standIn.requests[0].Body["event_action"] != "resolve" {
+		t.Fatalf("expected only the resolve event, got %+v", standIn.requests)
+	}
+}
+
+func TestOpsgenieTriggerAndClose(t *testing.T) {
+	defer setupNotifyTestDB(t)()
+	standIn := &incidentStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	serviceId, _ := new(models.Setting).CreateIncidentService(models.OpsgenieCode, models.IncidentService{Name: "ops", Key: "genie", ApiUrl: server.URL + "/"})
+	channel := models.NotifyChannelOpsgenie
+
+	_ = enqueue(incidentMessageFor(channel, serviceId, "Failed"))
+	deliverAll(t)
+	_ = enqueue(incidentMessageFor(channel, serviceId, "Success"))
+	deliverAll(t)
+
+	if len(standIn.requests) != 2 {
+		t.Fatalf("expected 2 requests, got %+v", standIn.requests)
+	}
+	create, closeReq := standIn.requests[0], standIn.requests[1]
+	if create.Path != "/v2/alerts" || create.Authorization != "GenieKey genie" ||
+		create.Body["alias"] != "gocron-task-42" || create.Body["description"] != "disk full" {
+		t.Fatalf("unexpected create request: %+v", create)
+	}
+	if closeReq.Path != "/v2/alerts/gocron-task-42/close?identifierType=alias" || closeReq.Authorization != "GenieKey genie" {
+		t.Fatalf("unexpected close request: %+v", closeReq)
+	}
+}
@@ -1,9 +1,10 @@
 package notify
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/go-gomail/gomail"
 	"github.com/gocronx-team/gocron/internal/models"
@@ -17,58 +18,59 @@ import (
 type Mail struct {
 }
 
-func (mail *Mail) Send(msg Message) {
+// Prepare 一封邮件发送给全部接收者，目标为逗号分隔的邮件地址
+func (mail *Mail) Prepare(msg Message) (string, []string, error) {
+	mailSetting, err := mail.setting()
+	if err != nil {
+		return "", nil, err
+	}
+	content := parseNotifyTemplate(mailSetting.Template, msg)
+	toUsers := mail.getActiveMailUsers(mailSetting, msg)
+	if len(toUsers) == 0 {
+		return content, nil, nil
+	}
+
+	return content, []string{strings.Join(toUsers, ",")}, nil
+}
+
+func (mail *Mail) Deliver(content string, target string) (int, error) {
+	mailSetting, err := mail.setting()
+	if err != nil {
+		return 0, err
+	}
+	body := strings.Replace(content, "\n", "<br>", -1)
+	gomailMessage := gomail.NewMessage()
+	gomailMessage.SetHeader("From", mailSetting.User)
+	gomailMessage.SetHeader("To", strings.Split(target, ",")...)
+	gomailMessage.SetHeader("Subject", "gocron-定时任务通知")
+	gomailMessage.SetBody("text/html", body)
+	mailer := gomail.NewDialer(mailSetting.Host, mailSetting.Port,
+		mailSetting.User, mailSetting.Password)
+
+	return 0, mailer.DialAndSend(gomailMessage)
+}
+
+func (mail *Mail) setting() (models.Mail, error) {
 	model := new(models.Setting)
 	mailSetting, err := model.Mail()
 	logger.Debugf("%+v", mailSetting)
 	if err != nil {
-		logger.Error("#mail#从数据库获取mail配置失败", err)
-		return
+		return mailSetting, fmt.Errorf("从数据库获取mail配置失败-%w", err)
 	}
 	if mailSetting.Host == "" {
-		logger.Error("#mail#Host为空")
-		return
+		return mailSetting, errors.New("mail Host为空")
 	}
 	if mailSetting.Port == 0 {
-		logger.Error("#mail#Port为空")
-		return
+		return mailSetting, errors.New("mail Port为空")
 	}
 	if mailSetting.User == "" {
-		logger.Error("#mail#User为空")
-		return
+		return mailSetting, errors.New("mail User为空")
 	}
 	if mailSetting.Password == "" {
-		logger.Error("#mail#Password为空")
-		return
+		return mailSetting, errors.New("mail Password为空")
 	}
-	msg["content"] = parseNotifyTemplate(mailSetting.Template, msg)
-	toUsers := mail.getActiveMailUsers(mailSetting, msg)
-	mail.send(mailSetting, toUsers, msg)
-}
 
-func (mail *Mail) send(mailSetting models.Mail, toUsers []string, msg Message) {
-	body := msg["content"].(string)
-	body = strings.Replace(body, "\n", "<br>", -1)
-	gomailMessage := gomail.NewMessage()
-	gomailMessage.SetHeader("From", mailSetting.User)
-	gomailMessage.SetHeader("To", toUsers...)
-	gomailMessage.SetHeader("Subject", "gocron-定时任务通知")
-	gomailMessage.SetBody("text/html", body)
-	mailer := gomail.NewDialer(mailSetting.Host, mailSetting.Port,
-		mailSetting.User, mailSetting.Password)
-	maxTimes := 3
-	i := 0
-	for i < maxTimes {
-		err := mailer.DialAndSend(gomailMessage)
-		if err == nil {
-			break
-		}
-		i += 1
-		time.Sleep(2 * time.Second)
-		if i < maxTimes {
-			logger.Errorf("mail#发送消息失败#%s#消息内容-%s", err.Error(), msg["content"])
-		}
-	}
+	return mailSetting, nil
 }
 
 func (mail *Mail) getActiveMailUsers(mailSetting models.Mail, msg Message) []string {
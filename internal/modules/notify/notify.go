@@ -1,78 +1,105 @@
 package notify
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"html/template"
-	"time"
 
+	"github.com/gocronx-team/gocron/internal/models"
 	"github.com/gocronx-team/gocron/internal/modules/logger"
 )
 
 type Message map[string]interface{}
 
-type Notifiable interface {
-	Send(msg Message)
+// 消息中的任务状态
+const (
+	StatusSuccess     = "Success"
+	StatusFailed      = "Failed"
+	StatusSlaBreached = "SLA breached"
+)
+
+// isAlert 需要提醒处理的状态：执行失败或违反 SLA
+func isAlert(status interface{}) bool {
+	return status == StatusFailed || status == StatusSlaBreached
 }
 
-var queue = make(chan Message, 100)
+// Notifiable 通知渠道。消息先由 Prepare 渲染并写入 outbox，再由后台 worker 调用 Deliver 逐个目标投递
+type Notifiable interface {
+	// Prepare 读取渠道配置，渲染通知内容并解析出发送目标
+	Prepare(msg Message) (content string, targets []string, err error)
+	// Deliver 向单个目标投递一次，返回 HTTP 状态码，非 HTTP 渠道返回 0
+	Deliver(content string, target string) (statusCode int, err error)
+}
 
-func init() {
-	go run()
+var notifiers = map[models.NotifyChannel]Notifiable{
+	models.NotifyChannelMail:      &Mail{},
+	models.NotifyChannelSlack:     &Slack{},
+	models.NotifyChannelWebhook:   &WebHook{},
+	models.NotifyChannelDingTalk:  &DingTalk{},
+	models.NotifyChannelWeCom:     &WeCom{},
+	models.NotifyChannelFeishu:    &Feishu{},
+	models.NotifyChannelTelegram:  &Telegram{},
+	models.NotifyChannelDiscord:   &Discord{},
+	models.NotifyChannelTeams:     &Teams{},
+	models.NotifyChannelPagerDuty: &PagerDuty{},
+	models.NotifyChannelOpsgenie:  &Opsgenie{},
 }
 
-// 把消息推入队列
+// Push 把消息写入 outbox，由后台 worker 异步投递
 func Push(msg Message) {
-	queue <- msg
+	if err := enqueue(msg); err != nil {
+		logger.Errorf("#notify#%s#%+v", err, msg)
+	}
 }
 
-func run() {
-	for msg := range queue {
-		// 根据任务配置发送通知
-		taskType, taskTypeOk := msg["task_type"]
-		_, taskReceiverIdOk := msg["task_receiver_id"]
-		_, nameOk := msg["name"]
-		_, outputOk := msg["output"]
-		_, statusOk := msg["status"]
-		if !taskTypeOk || !taskReceiverIdOk || !nameOk || !outputOk || !statusOk {
-			logger.Errorf("#notify#参数不完整#%+v", msg)
-			continue
+func enqueue(msg Message) error {
+	channel, notifier, err := notifierFor(msg)
+	if err != nil {
+		return err
+	}
+	taskId, _ := msg["task_id"].(int)
+	ruleId, _ := msg["rule_id"].(int)
+	taskName := msg["name"].(string)
+
+	content, targets, err := notifier.Prepare(msg)
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		item := &models.NotificationOutbox{
+			TaskId:      taskId,
+			TaskName:    taskName,
+			Channel:     channel,
+			RuleId:      ruleId,
+			Target:      target,
+			Content:     content,
+			MaxAttempts: maxAttempts,
 		}
-		msg["content"] = fmt.Sprintf("============\n============\n============\n任务名称: %s\n状态: %s\n输出:\n %s\n", msg["name"], msg["status"], msg["output"])
-		logger.Debugf("%+v", msg)
-		switch taskType.(int8) {
-		case 0:
-			// 邮件
-			mail := Mail{}
-			go mail.Send(msg)
-		case 1:
-			// Slack
-			slack := Slack{}
-			go slack.Send(msg)
-		case 2:
-			// WebHook
-			webHook := WebHook{}
-			go webHook.Send(msg)
+		if _, err := item.Create(); err != nil {
+			return fmt.Errorf("写入通知队列失败-%w", err)
 		}
-		time.Sleep(1 * time.Second)
 	}
+	if len(targets) > 0 {
+		Wake()
+	}
+
+	return nil
 }
 
-func parseNotifyTemplate(notifyTemplate string, msg Message) string {
-	tmpl, err := template.New("notify").Parse(notifyTemplate)
-	if err != nil {
-		return fmt.Sprintf("解析通知模板失败: %s", err)
+// notifierFor 校验消息的必填字段并返回对应的通知渠道
+func notifierFor(msg Message) (models.NotifyChannel, Notifiable, error) {
+	taskType, taskTypeOk := msg["task_type"].(int8)
+	_, taskReceiverIdOk := msg["task_receiver_id"]
+	_, nameOk := msg["name"]
+	_, outputOk := msg["output"]
+	_, statusOk := msg["status"]
+	if !taskTypeOk || !taskReceiverIdOk || !nameOk || !outputOk || !statusOk {
+		return 0, nil, errors.New("参数不完整")
 	}
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, map[string]interface{}{
-		"TaskId":   msg["task_id"],
-		"TaskName": msg["name"],
-		"Status":   msg["status"],
-		"Result":   msg["output"],
-		"Remark":   msg["remark"],
-	}); err != nil {
-		return fmt.Sprintf("执行模板失败: %s", err)
+	channel := models.NotifyChannel(taskType)
+	notifier, ok := notifiers[channel]
+	if !ok {
+		return channel, nil, fmt.Errorf("未知的通知渠道-%d", channel)
 	}
 
-	return buf.String()
+	return channel, notifier, nil
 }
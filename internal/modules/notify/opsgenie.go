@@ -0,0 +1,65 @@
+package notify
+
+// 发送告警到 Opsgenie Alert API，以任务的去重键作为 alias
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+)
+
+// opsgenieMessageLimit Opsgenie 告警标题最大长度
+const opsgenieMessageLimit = 130
+
+type Opsgenie struct{}
+
+func (opsgenie *Opsgenie) Prepare(msg Message) (string, []string, error) {
+	return prepareIncident(models.OpsgenieCode, models.NotifyChannelOpsgenie, msg)
+}
+
+func (opsgenie *Opsgenie) Deliver(content string, target string) (int, error) {
+	service, message, stale, err := loadIncidentDelivery(models.OpsgenieCode, models.NotifyChannelOpsgenie, content, target)
+	if err != nil || stale {
+		return 0, err
+	}
+	requestUrl, body := opsgenieRequest(service.Endpoint(models.OpsgenieCode), message)
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+	headers, _ := json.Marshal(map[string]string{"Authorization": "GenieKey " + service.Key})
+
+	return checkResponse(httpclient.PostJsonWithHeaders(requestUrl, string(payload), string(headers), notifyHttpTimeout))
+}
+
+// opsgenieRequest 触发时创建告警，关闭时按 alias 关闭告警
+func opsgenieRequest(endpoint string, message incidentMessage) (string, map[string]interface{}) {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if message.Action == incidentResolve {
+		requestUrl := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", endpoint, url.PathEscape(message.DedupKey))
+		return requestUrl, map[string]interface{}{
+			"source": "gocron",
+			"note":   "Task recovered: the latest run succeeded",
+		}
+	}
+
+	summary := []rune(message.summary())
+	if len(summary) > opsgenieMessageLimit {
+		summary = summary[:opsgenieMessageLimit]
+	}
+	return endpoint + "/v2/alerts", map[string]interface{}{
+		"message":     string(summary),
+		"alias":       message.DedupKey,
+		"description": message.Output,
+		"source":      "gocron",
+		"priority":    "P2",
+		"details": map[string]string{
+			"task_id": fmt.Sprint(message.TaskId),
+			"remark":  message.Remark,
+		},
+	}
+}
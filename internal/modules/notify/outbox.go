@@ -0,0 +1,172 @@
+package notify
+
+// 通知 outbox 投递：从数据库领取到期的通知，交给 worker 池投递，失败后按指数退避重试
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+const (
+	// 每条通知最多投递次数
+	maxAttempts = 8
+	// 第一次重试的等待时间，之后每次翻倍
+	retryBaseDelay = 15 * time.Second
+	// 重试等待时间上限
+	retryMaxDelay = 30 * time.Minute
+	// 没有唤醒信号时轮询数据库的间隔，用于处理到期的重试和其他实例写入的通知
+	pollInterval = 5 * time.Second
+	// 停留在 sending 超过该时间的通知视为投递中进程退出，重新放回队列
+	staleSending = 5 * time.Minute
+)
+
+var (
+	startOnce sync.Once
+	wake      = make(chan struct{}, 1)
+)
+
+// Start 启动通知投递 worker，workers 为并发投递数
+func Start(workers int) {
+	startOnce.Do(func() {
+		if workers <= 0 {
+			workers = 1
+		}
+		jobs := make(chan models.NotificationOutbox, workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				for item := range jobs {
+					deliver(item)
+				}
+			}()
+		}
+		go dispatch(jobs, workers)
+		logger.Infof("Notification outbox started with %d workers", workers)
+	})
+}
+
+// Wake 通知 dispatcher 立即检查待投递的通知，不必等到下一次轮询
+func Wake() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+func dispatch(jobs chan<- models.NotificationOutbox, batch int) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	lastRecover := time.Time{}
+	for {
+		if time.Since(lastRecover) >= staleSending {
+			if n, err := models.RecoverStaleNotifications(time.Now().Add(-staleSending)); err != nil {
+				logger.Error("#notify#恢复投递中的通知失败", err)
+			} else if n > 0 {
+				logger.Infof("#notify#重新投递 %d 条中断的通知", n)
+			}
+			lastRecover = time.Now()
+		}
+		for {
+			items, err := models.ClaimDueNotifications(batch)
+			if err != nil {
+				logger.Error("#notify#领取待投递通知失败", err)
+				break
+			}
+			for _, item := range items {
+				jobs <- item
+			}
+			if len(items) < batch {
+				break
+			}
+		}
+		select {
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver 投递一次并记录结果
+func deliver(item models.NotificationOutbox) {
+	attempt := models.NotificationAttempt{}
+	notifier, ok := notifiers[item.Channel]
+	start := time.Now()
+	if !ok {
+		attempt.Error = fmt.Sprintf("未知的通知渠道-%d", item.Channel)
+	} else {
+		statusCode, response, err := safeDeliver(notifier, item)
+		attempt.StatusCode = statusCode
+		attempt.Response = truncateResponse(response)
+		attempt.Success = err == nil
+		if err != nil {
+			attempt.Error = truncateError(err.Error())
+		}
+	}
+	attempt.LatencyMs = time.Since(start).Milliseconds()
+
+	if err := item.Complete(attempt, time.Now().Add(retryDelay(item.Attempts+1))); err != nil {
+		logger.Errorf("#notify#记录投递结果失败#id-%d#%s", item.Id, err)
+		return
+	}
+	if !attempt.Success {
+		logger.Errorf("#notify#投递失败#id-%d#第%d次#%s", item.Id, item.Attempts, attempt.Error)
+	}
+}
+
+func safeDeliver(notifier Notifiable, item models.NotificationOutbox) (statusCode int, response string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return deliverOnce(notifier, item.Content, item.Target)
+}
+
+// responseRecorder 需要在投递记录中保存响应内容的渠道
+type responseRecorder interface {
+	DeliverWithResponse(content string, target string) (statusCode int, response string, err error)
+}
+
+// deliverOnce 投递一次，渠道实现 responseRecorder 时同时返回响应内容
+func deliverOnce(notifier Notifiable, content, target string) (int, string, error) {
+	if recorder, ok := notifier.(responseRecorder); ok {
+		return recorder.DeliverWithResponse(content, target)
+	}
+	statusCode, err := notifier.Deliver(content, target)
+
+	return statusCode, "", err
+}
+
+// retryDelay 第 attempt 次投递失败后的等待时间
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+// truncateResponse 投递记录中保留的响应内容长度与字段长度一致
+func truncateResponse(s string) string {
+	const maxLen = 1024
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen])
+}
+
+func truncateError(s string) string {
+	const maxLen = 500
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen])
+}
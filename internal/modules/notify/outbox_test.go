@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/ncruces/go-sqlite3/gormlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func TestMain(m *testing.M) {
+	_ = os.MkdirAll("log", 0o755)
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+// fakeNotifier 记录投递的目标，按顺序返回预设结果
+type fakeNotifier struct {
+	targets   []string
+	delivered []string
+	results   []error
+}
+
+func (f *fakeNotifier) Prepare(msg Message) (string, []string, error) {
+	return "content of " + msg["name"].(string), f.targets, nil
+}
+
+func (f *fakeNotifier) Deliver(content string, target string) (int, error) {
+	f.delivered = append(f.delivered, target)
+	var err error
+	if len(f.results) > 0 {
+		err, f.results = f.results[0], f.results[1:]
+	}
+	if err != nil {
+		return 502, err
+	}
+	return 200, nil
+}
+
+const fakeChannel = models.NotifyChannel(99)
+
+func setupNotifyTestDB(t *testing.T) func() {
+	t.Helper()
+	db, err := gorm.Open(gormlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Setting{}, &models.NotificationOutbox{}, &models.NotificationAttempt{}, &models.TaskIncident{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	original := models.Db
+	models.Db = db
+
+	return func() {
+		models.Db = original
+	}
+}
+
+func setupOutboxTest(t *testing.T, notifier Notifiable) func() {
+	t.Helper()
+	cleanup := setupNotifyTestDB(t)
+	notifiers[fakeChannel] = notifier
+
+	return func() {
+		cleanup()
+		delete(notifiers, fakeChannel)
+	}
+}
+
+func fakeMessage() Message {
+	return Message{
+		"task_type":        int8(fakeChannel),
+		"task_receiver_id": "1,2",
+		"name":             "backup",
+		"output":           "disk full",
+		"status":           "Failed",
+		"task_id":          7,
+	}
+}
+
+func TestEnqueueWritesOneOutboxRowPerTarget(t *testing.T) {
+	notifier := &fakeNotifier{targets: []string{"a", "b"}}
+	defer setupOutboxTest(t, notifier)()
+
+	msg := fakeMessage()
+	msg["rule_id"] = 3
+	if err := enqueue(msg); err != nil {
+		t.Fatal(err)
+	}
+	items, _ := models.ClaimDueNotifications(10)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 outbox rows, got %d", len(items))
+	}
+	for _, item := range items {
+		if item.TaskId != 7 || item.TaskName != "backup" || item.Content != "content of backup" ||
+			item.MaxAttempts != maxAttempts || item.RuleId != 3 {
+			t.Fatalf("unexpected outbox row: %+v", item)
+		}
+	}
+
+	msg = fakeMessage()
+	delete(msg, "status")
+	if err := enqueue(msg); err == nil {
+		t.Fatal("incomplete message should be rejected")
+	}
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	notifier := &fakeNotifier{targets: []string{"a"}, results: []error{errors.New("bad gateway")}}
+	defer setupOutboxTest(t, notifier)()
+
+	_ = enqueue(fakeMessage())
+	items, _ := models.ClaimDueNotifications(10)
+	before := time.Now()
+	deliver(items[0])
+
+	outboxModel := new(models.NotificationOutbox)
+	detail, _ := outboxModel.Detail(items[0].Id)
+	if detail.Status != models.NotificationPending || detail.Attempts != 1 || detail.LastError != "bad gateway" {
+		t.Fatalf("unexpected state after failed delivery: %+v", detail)
+	}
+	if wait := detail.NextAttemptAt.Sub(before); wait < retryBaseDelay-time.Second || wait > retryBaseDelay+time.Second {
+		t.Fatalf("expected retry in about %s, got %s", retryBaseDelay, wait)
+	}
+
+	// 到期后重试成功
+	models.Db.Model(&models.NotificationOutbox{}).Where("id = ?", detail.Id).
+		UpdateColumn("next_attempt_at", time.Now().Add(-time.Second))
+	items, _ = models.ClaimDueNotifications(10)
+	deliver(items[0])
+	detail, _ = outboxModel.Detail(detail.Id)
+	if detail.Status != models.NotificationSucceeded || detail.Attempts != 2 {
+		t.Fatalf("expected succeeded on retry: %+v", detail)
+	}
+
+	attempts, _ := outboxModel.AttemptList(detail.Id)
+	if len(attempts) != 2 || attempts[0].StatusCode != 502 || attempts[0].Success ||
+		attempts[1].StatusCode != 200 || !attempts[1].Success {
+		t.Fatalf("unexpected attempts: %+v", attempts)
+	}
+	if len(notifier.delivered) != 2 {
+		t.Fatalf("expected 2 deliveries, got %v", notifier.delivered)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, retryBaseDelay},
+		{2, 2 * retryBaseDelay},
+		{4, 8 * retryBaseDelay},
+		{20, retryMaxDelay},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempt); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package notify
+
+// 发送事件到 PagerDuty Events API v2
+
+import (
+	"encoding/json"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+)
+
+type PagerDuty struct{}
+
+func (pagerDuty *PagerDuty) Prepare(msg Message) (string, []string, error) {
+	return prepareIncident(models.PagerDutyCode, models.NotifyChannelPagerDuty, msg)
+}
+
+func (pagerDuty *PagerDuty) Deliver(content string, target string) (int, error) {
+	service, message, stale, err := loadIncidentDelivery(models.PagerDutyCode, models.NotifyChannelPagerDuty, content, target)
+	if err != nil || stale {
+		return 0, err
+	}
+	payload, err := json.Marshal(pagerDutyRequest(service.Key, message))
+	if err != nil {
+		return 0, err
+	}
+
+	return checkResponse(httpclient.PostJson(service.Endpoint(models.PagerDutyCode), string(payload), notifyHttpTimeout))
+}
+
+// pagerDutyRequest 以任务的去重键触发或关闭事件
+func pagerDutyRequest(routingKey string, message incidentMessage) map[string]interface{} {
+	body := map[string]interface{}{
+		"routing_key":  routingKey,
+		"event_action": message.Action,
+		"dedup_key":    message.DedupKey,
+	}
+	if message.Action == incidentTrigger {
+		body["payload"] = map[string]interface{}{
+			"summary":   message.summary(),
+			"source":    "gocron",
+			"severity":  "error",
+			"component": message.Name,
+			"custom_details": map[string]interface{}{
+				"task_id": message.TaskId,
+				"output":  message.Output,
+				"remark":  message.Remark,
+			},
+		}
+	}
+
+	return body
+}
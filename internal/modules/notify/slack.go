@@ -3,11 +3,11 @@ package notify
 // 发送消息到slack
 
 import (
+	"errors"
 	"fmt"
 	"html"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gocronx-team/gocron/internal/models"
 	"github.com/gocronx-team/gocron/internal/modules/httpclient"
@@ -17,47 +17,45 @@ import (
 
 type Slack struct{}
 
-func (slack *Slack) Send(msg Message) {
-	model := new(models.Setting)
-	slackSetting, err := model.Slack()
+// Prepare 每个频道一个投递目标
+func (slack *Slack) Prepare(msg Message) (string, []string, error) {
+	slackSetting, err := slack.setting()
 	if err != nil {
-		logger.Error("#slack#从数据库获取slack配置失败", err)
-		return
-	}
-	if slackSetting.Url == "" {
-		logger.Error("#slack#webhook-url为空")
-		return
+		return "", nil, err
 	}
 	if len(slackSetting.Channels) == 0 {
-		logger.Error("#slack#channels配置为空")
-		return
+		return "", nil, errors.New("slack channels配置为空")
 	}
 	logger.Debugf("%+v", slackSetting)
 	channels := slack.getActiveSlackChannels(slackSetting, msg)
 	logger.Debugf("%+v", channels)
-	msg["content"] = parseNotifyTemplate(slackSetting.Template, msg)
-	msg["content"] = html.UnescapeString(msg["content"].(string))
-	for _, channel := range channels {
-		slack.send(msg, slackSetting.Url, channel)
+	content := parseNotifyTemplate(slackSetting.Template, msg)
+	content = html.UnescapeString(content)
+
+	return content, channels, nil
+}
+
+func (slack *Slack) Deliver(content string, channel string) (int, error) {
+	slackSetting, err := slack.setting()
+	if err != nil {
+		return 0, err
 	}
+	resp := httpclient.PostJson(slackSetting.Url, slack.format(content, channel), notifyHttpTimeout)
+
+	return checkResponse(resp)
 }
 
-func (slack *Slack) send(msg Message, slackUrl string, channel string) {
-	formatBody := slack.format(msg["content"].(string), channel)
-	timeout := 30
-	maxTimes := 3
-	i := 0
-	for i < maxTimes {
-		resp := httpclient.PostJson(slackUrl, formatBody, timeout)
-		if resp.StatusCode == 200 {
-			break
-		}
-		i += 1
-		time.Sleep(2 * time.Second)
-		if i < maxTimes {
-			logger.Errorf("slack#发送消息失败#%s#消息内容-%s", resp.Body, msg["content"])
-		}
+func (slack *Slack) setting() (models.Slack, error) {
+	model := new(models.Setting)
+	slackSetting, err := model.Slack()
+	if err != nil {
+		return slackSetting, fmt.Errorf("从数据库获取slack配置失败-%w", err)
+	}
+	if slackSetting.Url == "" {
+		return slackSetting, errors.New("slack webhook-url为空")
 	}
+
+	return slackSetting, nil
 }
 
 func (slack *Slack) getActiveSlackChannels(slackSetting models.Slack, msg Message) []string {
@@ -0,0 +1,62 @@
+package notify
+
+// 发送消息到 Microsoft Teams 频道的 Webhook，使用 Adaptive Card 展示任务信息
+
+import (
+	"fmt"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+type Teams struct{}
+
+// Prepare 每个 Webhook 一个投递目标
+func (teams *Teams) Prepare(msg Message) (string, []string, error) {
+	return prepareChat(models.TeamsCode, msg)
+}
+
+func (teams *Teams) Deliver(content string, target string) (int, error) {
+	_, receiver, message, err := loadChatDelivery(models.TeamsCode, content, target)
+	if err != nil {
+		return 0, err
+	}
+
+	return postChat(receiver.Target, teamsRequest(message))
+}
+
+func teamsRequest(message chatMessage) map[string]interface{} {
+	color := "Good"
+	if message.failed() {
+		color = "Attention"
+	}
+	facts := []map[string]string{
+		{"title": "Task ID", "value": fmt.Sprint(message.TaskId)},
+		{"title": "Status", "value": message.Status},
+		{"title": "Duration", "value": message.Duration},
+	}
+	if message.Remark != "" {
+		facts = append(facts, map[string]string{"title": "Remark", "value": message.Remark})
+	}
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": message.title(), "weight": "Bolder", "size": "Medium", "color": color, "wrap": true},
+		{"type": "FactSet", "facts": facts},
+	}
+	if message.Output != "" {
+		body = append(body, map[string]interface{}{"type": "TextBlock", "text": message.Output, "fontType": "Monospace", "wrap": true})
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package notify
+
+// 发送消息到 Telegram，使用机器人 token 调用 sendMessage 接口
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+)
+
+// telegramApi Bot API 地址，测试时替换为本地服务
+var telegramApi = "https://api.telegram.org"
+
+type Telegram struct{}
+
+// Prepare 每个 chat 一个投递目标
+func (telegram *Telegram) Prepare(msg Message) (string, []string, error) {
+	return prepareChat(models.TelegramCode, msg)
+}
+
+func (telegram *Telegram) Deliver(content string, target string) (int, error) {
+	chatSetting, receiver, message, err := loadChatDelivery(models.TelegramCode, content, target)
+	if err != nil {
+		return 0, err
+	}
+	if chatSetting.Token == "" {
+		return 0, errors.New("telegram bot token为空")
+	}
+	payload, err := json.Marshal(telegramRequest(receiver.Target, message))
+	if err != nil {
+		return 0, err
+	}
+	resp := httpclient.PostJson(fmt.Sprintf("%s/bot%s/sendMessage", telegramApi, chatSetting.Token), string(payload), notifyHttpTimeout)
+	statusCode, err := checkResponse(resp)
+	if err != nil {
+		// 错误信息中可能包含 token，只保留接口返回的描述
+		return statusCode, telegramError(statusCode, resp.Body)
+	}
+
+	return statusCode, nil
+}
+
+// telegramRequest 生成 HTML 格式的消息
+func telegramRequest(chatId string, message chatMessage) map[string]interface{} {
+	lines := []string{
+		"<b>" + html.EscapeString(message.title()) + "</b>",
+		fmt.Sprintf("Task ID: %d", message.TaskId),
+		"Duration: " + html.EscapeString(message.Duration),
+	}
+	if message.Remark != "" {
+		lines = append(lines, "Remark: "+html.EscapeString(message.Remark))
+	}
+	if message.Output != "" {
+		lines = append(lines, "<pre>"+html.EscapeString(message.Output)+"</pre>")
+	}
+
+	return map[string]interface{}{
+		"chat_id":                  chatId,
+		"text":                     strings.Join(lines, "\n"),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+}
+
+func telegramError(statusCode int, body string) error {
+	result := struct {
+		Description string `json:"description"`
+	}{}
+	if json.Unmarshal([]byte(body), &result) == nil && result.Description != "" {
+		return fmt.Errorf("HTTP %d: %s", statusCode, result.Description)
+	}
+	if statusCode == 0 {
+		return errors.New("请求telegram失败")
+	}
+	return fmt.Errorf("HTTP %d", statusCode)
+}
@@ -0,0 +1,192 @@
+package notify
+
+// 通知模板：邮件、Slack、WebHook 和群机器人使用渠道配置的模板渲染通知内容，
+// 任务的通知规则可以设置自己的模板覆盖渠道模板
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+// 模板辅助函数，参数顺序便于在管道中使用，例如 {{.Result | tail 20 | truncate 500}}
+var templateFuncs = template.FuncMap{
+	// truncate 保留前 n 个字符
+	"truncate": func(n int, s interface{}) string {
+		runes := []rune(fmt.Sprint(s))
+		if n < 0 || len(runes) <= n {
+			return string(runes)
+		}
+		return string(runes[:n]) + "..."
+	},
+	// jsonEscape 转义为 JSON 字符串内容，用于在模板中拼接 JSON
+	"jsonEscape": func(s interface{}) string {
+		return utils.EscapeJson(fmt.Sprint(s))
+	},
+	// tail 保留最后 n 行
+	"tail": func(n int, s interface{}) string {
+		lines := strings.Split(strings.TrimRight(fmt.Sprint(s), "\n"), "\n")
+		if n < 0 || len(lines) <= n {
+			return strings.Join(lines, "\n")
+		}
+		return strings.Join(lines[len(lines)-n:], "\n")
+	},
+}
+
+// templateData 模板变量
+func templateData(msg Message) map[string]interface{} {
+	data := map[string]interface{}{
+		"TaskId":     msg["task_id"],
+		"TaskName":   msg["name"],
+		"Status":     msg["status"],
+		"Result":     msg["output"],
+		"Remark":     msg["remark"],
+		"Duration":   "",
+		"StartTime":  "",
+		"EndTime":    "",
+		"RetryTimes": 0,
+		"Hosts":      msg["hosts"],
+		"ExitCode":   "",
+		"Tags":       msg["tags"],
+		"LogId":      0,
+		"LogUrl":     msg["log_url"],
+	}
+	if duration, ok := msg["duration"].(time.Duration); ok {
+		data["Duration"] = formatDuration(duration)
+	}
+	for key, field := range map[string]string{"start_time": "StartTime", "end_time": "EndTime"} {
+		if t, ok := msg[key].(time.Time); ok && !t.IsZero() {
+			data[field] = t.Format(models.DefaultTimeFormat)
+		}
+	}
+	if retryTimes, ok := msg["retry_times"]; ok {
+		data["RetryTimes"] = retryTimes
+	}
+	if exitCode, ok := msg["exit_code"]; ok {
+		data["ExitCode"] = exitCode
+	}
+	if logId, ok := msg["log_id"]; ok {
+		data["LogId"] = logId
+	}
+	for key, value := range data {
+		if value == nil {
+			data[key] = ""
+		}
+	}
+
+	return data
+}
+
+// renderTemplate 渲染模板，返回解析或执行错误
+func renderTemplate(notifyTemplate string, msg Message) (string, error) {
+	tmpl, err := template.New("notify").Funcs(templateFuncs).Parse(notifyTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateData(msg)); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// parseNotifyTemplate 渲染通知内容，消息中带有规则的自定义模板时优先使用
+func parseNotifyTemplate(notifyTemplate string, msg Message) string {
+	if custom, ok := msg["template"].(string); ok && strings.TrimSpace(custom) != "" {
+		notifyTemplate = custom
+	}
+	content, err := renderTemplate(notifyTemplate, msg)
+	if err != nil {
+		return fmt.Sprintf("解析通知模板失败: %s", err)
+	}
+
+	return content
+}
+
+// CheckTemplate 用示例消息渲染一次模板，检查语法和辅助函数的参数
+func CheckTemplate(notifyTemplate string) error {
+	if strings.TrimSpace(notifyTemplate) == "" {
+		return nil
+	}
+	_, err := renderTemplate(notifyTemplate, SampleMessage(models.NotifyChannelWebhook, "", "", true))
+
+	return err
+}
+
+// SampleMessage 预览和测试发送使用的示例消息
+func SampleMessage(channel models.NotifyChannel, receiverIds, notifyTemplate string, failed bool) Message {
+	end := time.Now()
+	msg := Message{
+		"task_type":        int8(channel),
+		"task_receiver_id": receiverIds,
+		"template":         notifyTemplate,
+		"name":             "gocron-test",
+		"task_id":          0,
+		"remark":           "This is a test notification",
+		"tags":             "test",
+		"hosts":            "127.0.0.1:5921",
+		"output":           "Hello from gocron",
+		"status":           StatusSuccess,
+		"exit_code":        0,
+		"retry_times":      int8(0),
+		"duration":         3 * time.Second,
+		"start_time":       end.Add(-3 * time.Second),
+		"end_time":         end,
+		"log_id":           int64(0),
+	}
+	if failed {
+		msg["output"] = "Hello from gocron\nexit status 1"
+		msg["status"] = StatusFailed
+		msg["exit_code"] = 1
+	}
+
+	return msg
+}
+
+// TestResult 测试发送到单个目标的结果
+type TestResult struct {
+	Target     string `json:"target"`
+	StatusCode int    `json:"status_code"`
+	Response   string `json:"response"`
+	Error      string `json:"error"`
+}
+
+// Preview 按渠道配置渲染消息，返回通知内容和发送目标，不投递
+func Preview(msg Message) (string, []string, error) {
+	_, notifier, err := notifierFor(msg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return notifier.Prepare(msg)
+}
+
+// SendTest 渲染消息并立即投递到每个目标，不写入 outbox，也不重试
+func SendTest(msg Message) ([]TestResult, error) {
+	_, notifier, err := notifierFor(msg)
+	if err != nil {
+		return nil, err
+	}
+	content, targets, err := notifier.Prepare(msg)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]TestResult, 0, len(targets))
+	for _, target := range targets {
+		result := TestResult{Target: target}
+		result.StatusCode, result.Response, err = deliverOnce(notifier, content, target)
+		result.Response = truncateResponse(result.Response)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
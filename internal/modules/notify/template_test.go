@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func TestParseNotifyTemplateVariables(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+	msg := Message{
+		"task_id":     3,
+		"name":        "backup",
+		"status":      StatusFailed,
+		"output":      "line1\nline2\nline3",
+		"duration":    90 * time.Second,
+		"start_time":  start,
+		"end_time":    start.Add(90 * time.Second),
+		"retry_times": int8(2),
+		"hosts":       "10.0.0.1:5921",
+		"exit_code":   1,
+		"tags":        "db",
+		"log_id":      int64(42),
+		"log_url":     "https://cron.example.com/#/task/log?task_id=3&log_id=42",
+	}
+	tpl := "{{.TaskName}}|{{.Duration}}|{{.StartTime}}|{{.EndTime}}|{{.RetryTimes}}|{{.Hosts}}|{{.ExitCode}}|{{.Tags}}|{{.LogId}}|{{.LogUrl}}"
+	got := parseNotifyTemplate(tpl, msg)
+	want := "backup|1m30s|2024-05-01 10:00:00|2024-05-01 10:01:30|2|10.0.0.1:5921|1|db|42|https://cron.example.com/#/task/log?task_id=3&amp;log_id=42"
+	if got != want {
+		t.Fatalf("unexpected content:\n%s\nwant:\n%s", got, want)
+	}
+
+	// 缺少的变量渲染为空
+	if got := parseNotifyTemplate("[{{.Hosts}}][{{.StartTime}}][{{.ExitCode}}]", Message{"name": "x"}); got != "[][][]" {
+		t.Fatalf("missing variables should be empty, got %q", got)
+	}
+}
+
+func TestParseNotifyTemplateHelpers(t *testing.T) {
+	msg := Message{"name": "job", "output": "a\nb\n\"c\"\n", "remark": "0123456789"}
+	tests := map[string]string{
+		"{{.Result | tail 2}}":                  "b\n&#34;c&#34;",
+		"{{.Remark | truncate 4}}":              "0123...",
+		"{{.Remark | truncate 20}}":             "0123456789",
+		"{{.Result | jsonEscape}}":              `a\nb\n\&#34;c\&#34;\n`,
+		"{{.Result | tail 1 | truncate 2}}":     "&#34;c...",
+		"{{if eq .Remark \"\"}}none{{end}}done": "done",
+	}
+	for tpl, want := range tests {
+		if got := parseNotifyTemplate(tpl, msg); got != want {
+			t.Errorf("%s: got %q, want %q", tpl, got, want)
+		}
+	}
+}
+
+func TestParseNotifyTemplateOverride(t *testing.T) {
+	msg := Message{"name": "job", "template": "custom {{.TaskName}}"}
+	if got := parseNotifyTemplate("channel {{.TaskName}}", msg); got != "custom job" {
+		t.Fatalf("rule template should override the channel template, got %q", got)
+	}
+	msg["template"] = "  "
+	if got := parseNotifyTemplate("channel {{.TaskName}}", msg); got != "channel job" {
+		t.Fatalf("blank rule template should fall back to the channel template, got %q", got)
+	}
+}
+
+func TestCheckTemplate(t *testing.T) {
+	valid := []string{"", "{{.TaskName}} {{.Result | tail 5 | truncate 100}}", "{{.LogUrl}}"}
+	for _, tpl := range valid {
+		if err := CheckTemplate(tpl); err != nil {
+			t.Errorf("expected %q to be valid, got %v", tpl, err)
+		}
+	}
+	invalid := []string{"{{.TaskName", "{{.Result | unknown}}", "{{truncate .Result}}", "{{.TaskName | tail \"x\"}}"}
+	for _, tpl := range invalid {
+		if err := CheckTemplate(tpl); err == nil {
+			t.Errorf("expected %q to be rejected", tpl)
+		}
+	}
+}
+
+func TestSampleMessage(t *testing.T) {
+	msg := SampleMessage(models.NotifyChannelSlack, "1,2", "{{.Status}}", true)
+	if _, notifier, err := notifierFor(msg); err != nil || notifier == nil {
+		t.Fatalf("sample message should be complete: %v", err)
+	}
+	if msg["status"] != StatusFailed || msg["exit_code"] != 1 || !strings.Contains(msg["output"].(string), "exit status 1") {
+		t.Fatalf("unexpected failed sample: %+v", msg)
+	}
+	if got := parseNotifyTemplate("", msg); got != StatusFailed {
+		t.Fatalf("sample should render with the custom template, got %q", got)
+	}
+}
@@ -1,7 +1,13 @@
 package notify
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"html"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -12,32 +18,116 @@ import (
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 )
 
+// 通知渠道 HTTP 请求超时时间（秒）
+const notifyHttpTimeout = 30
+
 type WebHook struct{}
 
-func (webHook *WebHook) Send(msg Message) {
+// Prepare 每个 webhook 地址一个投递目标
+func (webHook *WebHook) Prepare(msg Message) (string, []string, error) {
 	model := new(models.Setting)
 	webHookSetting, err := model.Webhook()
 	if err != nil {
-		logger.Error("#webHook#从数据库获取webHook配置失败", err)
-		return
+		return "", nil, fmt.Errorf("从数据库获取webHook配置失败-%w", err)
 	}
 	if len(webHookSetting.WebhookUrls) == 0 {
-		logger.Error("#webHook#webhook地址列表为空")
-		return
+		return "", nil, errors.New("webhook地址列表为空")
 	}
 	logger.Debugf("%+v", webHookSetting)
 	msg["name"] = utils.EscapeJson(msg["name"].(string))
 	msg["output"] = utils.EscapeJson(msg["output"].(string))
-	msg["content"] = parseNotifyTemplate(webHookSetting.Template, msg)
-	msg["content"] = html.UnescapeString(msg["content"].(string))
+	content := parseNotifyTemplate(webHookSetting.Template, msg)
+	content = html.UnescapeString(content)
 
 	// 获取任务配置的接收者ID列表
 	activeUrls := webHook.getActiveWebhookUrls(webHookSetting, msg)
-
-	// 向所有激活的webhook地址发送
+	targets := make([]string, 0, len(activeUrls))
 	for _, webhookUrl := range activeUrls {
-		go webHook.send(msg, webhookUrl.Url)
+		targets = append(targets, fmt.Sprintf("%d:%s", webhookUrl.Id, webhookUrl.Name))
+	}
+
+	return content, targets, nil
+}
+
+func (webHook *WebHook) Deliver(content string, target string) (int, error) {
+	statusCode, _, err := webHook.DeliverWithResponse(content, target)
+
+	return statusCode, err
+}
+
+// DeliverWithResponse 投递时读取地址配置，签名时间戳在每次重试时都是最新的。
+// 升级前写入 outbox 的目标为 webhook 地址，按原方式直接 POST
+func (webHook *WebHook) DeliverWithResponse(content string, target string) (int, string, error) {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		resp := httpclient.PostJson(target, content, notifyHttpTimeout)
+		statusCode, err := checkResponse(resp)
+		return statusCode, resp.Body, err
+	}
+	idStr, _, _ := strings.Cut(target, ":")
+	id, _ := strconv.Atoi(idStr)
+	webhookUrl, err := new(models.Setting).WebhookUrlById(id)
+	if err != nil {
+		return 0, "", fmt.Errorf("webhook地址#%s不存在-%w", target, err)
+	}
+	req, err := newWebhookRequest(webhookUrl, content, time.Now())
+	if err != nil {
+		return 0, "", err
+	}
+	timeout := webhookUrl.Timeout
+	if timeout <= 0 {
+		timeout = notifyHttpTimeout
+	}
+	resp := httpclient.Do(req, timeout)
+	statusCode, err := checkResponse(resp)
+
+	return statusCode, resp.Body, err
+}
+
+// newWebhookRequest 按地址配置生成请求：请求方法、自定义请求头、认证和签名
+func newWebhookRequest(webhookUrl models.WebhookUrl, content string, now time.Time) (*http.Request, error) {
+	method := webhookUrl.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, webhookUrl.Url, strings.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range webhookUrl.Headers {
+		if httpclient.IsBlockedHeader(key) {
+			continue
+		}
+		req.Header.Set(key, value)
 	}
+	switch webhookUrl.AuthType {
+	case models.WebhookAuthBearer:
+		req.Header.Set("Authorization", "Bearer "+webhookUrl.Token)
+	case models.WebhookAuthBasic:
+		req.SetBasicAuth(webhookUrl.Username, webhookUrl.Password)
+	}
+	if webhookUrl.Secret != "" {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(webhookUrl.Secret, timestamp, content))
+	}
+
+	return req, nil
+}
+
+// 签名请求头
+const (
+	WebhookSignatureHeader = "X-Gocron-Signature"
+	WebhookTimestampHeader = "X-Gocron-Timestamp"
+)
+
+// SignWebhook 对 "时间戳.请求体" 计算 HMAC-SHA256，返回十六进制字符串。
+// 接收方用同一密钥重新计算并比较，同时校验时间戳以拒绝重放的请求
+func SignWebhook(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + body))
+
+	return hex.EncodeToString(mac.Sum(nil))
 }
 
 func (webHook *WebHook) getActiveWebhookUrls(webHookSetting models.WebHook, msg Message) []models.WebhookUrl {
@@ -51,20 +141,14 @@ func (webHook *WebHook) getActiveWebhookUrls(webHookSetting models.WebHook, msg
 	return urls
 }
 
-func (webHook *WebHook) send(msg Message, url string) {
-	content := msg["content"].(string)
-	timeout := 30
-	maxTimes := 3
-	i := 0
-	for i < maxTimes {
-		resp := httpclient.PostJson(url, content, timeout)
-		if resp.StatusCode == 200 {
-			break
-		}
-		i += 1
-		time.Sleep(2 * time.Second)
-		if i < maxTimes {
-			logger.Errorf("webHook#发送消息失败#%s#消息内容-%s", resp.Body, msg["content"])
-		}
+// checkResponse 非 2xx 响应视为投递失败
+func checkResponse(resp httpclient.ResponseWrapper) (int, error) {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
 	}
+	if resp.StatusCode == 0 {
+		return 0, errors.New(resp.Body)
+	}
+
+	return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Body)
 }
@@ -1,7 +1,15 @@
 package notify
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gocronx-team/gocron/internal/models"
 )
@@ -258,3 +266,108 @@ func TestMessage_Type(t *testing.T) {
 		t.Error("failed to get task_receiver_id")
 	}
 }
+
+func TestSignWebhook(t *testing.T) {
+	got := SignWebhook("secret", "1700000000", `{"a":1}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(`1700000000.{"a":1}`))
+	if want := hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if SignWebhook("other", "1700000000", `{"a":1}`) == got {
+		t.Fatal("signature should depend on the secret")
+	}
+}
+
+func TestNewWebhookRequest(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	req, err := newWebhookRequest(models.WebhookUrl{
+		Url:      "https://example.com/hook",
+		Method:   "PATCH",
+		Headers:  map[string]string{"X-Source": "gocron", "Host": "evil.example.com"},
+		AuthType: models.WebhookAuthBasic,
+		Username: "u",
+		Password: "p",
+		Secret:   "s",
+	}, `{"a":1}`, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "PATCH" || req.Header.Get("X-Source") != "gocron" || req.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected request: %s %v", req.Method, req.Header)
+	}
+	if req.Header.Get("Host") != "" {
+		t.Fatal("blocked header should be skipped")
+	}
+	if user, pass, ok := req.BasicAuth(); !ok || user != "u" || pass != "p" {
+		t.Fatalf("unexpected basic auth: %s %s", user, pass)
+	}
+	if req.Header.Get(WebhookTimestampHeader) != "1700000000" ||
+		req.Header.Get(WebhookSignatureHeader) != "sha256="+SignWebhook("s", "1700000000", `{"a":1}`) {
+		t.Fatalf("unexpected signature headers: %v", req.Header)
+	}
+
+	// 默认 POST，未配置密钥时不签名
+	req, _ = newWebhookRequest(models.WebhookUrl{Url: "https://example.com", AuthType: models.WebhookAuthBearer, Token: "t"}, "{}", now)
+	if req.Method != http.MethodPost || req.Header.Get("Authorization") != "Bearer t" || req.Header.Get(WebhookSignatureHeader) != "" {
+		t.Fatalf("unexpected request: %s %v", req.Method, req.Header)
+	}
+}
+
+func TestWebHookDeliver(t *testing.T) {
+	defer setupNotifyTestDB(t)()
+
+	var received *http.Request
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received, body = r, string(data)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		_, _ = w.Write([]byte("accepted"))
+	}))
+	defer server.Close()
+
+	settingModel := new(models.Setting)
+	_ = models.Db.Create(&models.Setting{Code: models.WebhookCode, Key: models.WebhookTemplateKey, Value: `{"task":"{{.TaskName}}"}`}).Error
+	id, err := settingModel.AddWebhookUrl(models.WebhookUrl{Name: "signed", Url: server.URL + "/ok", Method: "PUT", Secret: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failId, _ := settingModel.AddWebhookUrl(models.WebhookUrl{Name: "fail", Url: server.URL + "/fail"})
+
+	webHook := &WebHook{}
+	msg := Message{"task_receiver_id": strconv.Itoa(id) + "," + strconv.Itoa(failId), "name": "backup", "output": "done", "status": StatusSuccess, "task_id": 1}
+	content, targets, err := webHook.Prepare(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 || targets[0] != strconv.Itoa(id)+":signed" {
+		t.Fatalf("unexpected targets: %v", targets)
+	}
+
+	statusCode, response, err := webHook.DeliverWithResponse(content, targets[0])
+	if err != nil || statusCode != http.StatusOK || response != "accepted" {
+		t.Fatalf("deliver failed: %d %q %v", statusCode, response, err)
+	}
+	if received.Method != "PUT" || body != `{"task":"backup"}` {
+		t.Fatalf("unexpected request: %s %s", received.Method, body)
+	}
+	timestamp := received.Header.Get(WebhookTimestampHeader)
+	if received.Header.Get(WebhookSignatureHeader) != "sha256="+SignWebhook("s", timestamp, body) {
+		t.Fatalf("invalid signature: %v", received.Header)
+	}
+
+	if statusCode, err := webHook.Deliver(content, targets[1]); err == nil || statusCode != http.StatusBadRequest {
+		t.Fatalf("expected HTTP 400 error, got %d %v", statusCode, err)
+	}
+	if _, err := webHook.Deliver(content, "12345:removed"); err == nil {
+		t.Fatal("expected error for removed webhook url")
+	}
+
+	// 升级前写入 outbox 的目标为地址本身
+	if statusCode, err := webHook.Deliver(content, server.URL+"/legacy"); err != nil || statusCode != http.StatusOK || received.Method != http.MethodPost {
+		t.Fatalf("legacy target failed: %d %v", statusCode, err)
+	}
+}
@@ -0,0 +1,53 @@
+package notify
+
+// 发送消息到企业微信群机器人
+
+import (
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+type WeCom struct{}
+
+func (weCom *WeCom) Prepare(msg Message) (string, []string, error) {
+	return prepareIM(models.WeComCode, msg)
+}
+
+func (weCom *WeCom) Deliver(content string, target string) (int, error) {
+	bot, message, err := loadIMDelivery(models.WeComCode, content, target)
+	if err != nil {
+		return 0, err
+	}
+
+	return postIM(bot.Webhook, weComRequest(bot, message), "errcode", "errmsg")
+}
+
+// weComRequest 默认发送 markdown 消息。markdown 消息不支持 @手机号 和 @所有人，
+// 任务失败且配置了提醒成员时改用文本消息
+func weComRequest(bot models.IMBot, message imMessage) map[string]interface{} {
+	mobiles := splitList(bot.AtMobiles)
+	userIds := splitList(bot.AtUserIds)
+	if bot.AtAll {
+		userIds = append(userIds, "@all")
+	}
+	if message.Failed && len(mobiles)+len(userIds) > 0 {
+		return map[string]interface{}{
+			"msgtype": "text",
+			"text": map[string]interface{}{
+				"content":               message.Title + "\n\n" + message.Text,
+				"mentioned_list":        userIds,
+				"mentioned_mobile_list": mobiles,
+			},
+		}
+	}
+
+	color := "info"
+	if message.Failed {
+		color = "warning"
+	}
+	return map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": `### <font color="` + color + `">` + message.Title + "</font>\n" + message.Text,
+		},
+	}
+}
@@ -3,9 +3,12 @@ package auth
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc/credentials"
 )
@@ -15,62 +18,223 @@ type Certificate struct {
 	CertFile   string
 	KeyFile    string
 	ServerName string
+	// CRLFile 证书吊销列表，为空或文件不存在时不做吊销检查
+	CRLFile string
 }
 
 func (c Certificate) GetTLSConfigForServer() (*tls.Config, error) {
-	certificate, err := tls.LoadX509KeyPair(
-		c.CertFile,
-		c.KeyFile,
-	)
-	if err != nil {
+	loader := &keyPairLoader{certFile: c.CertFile, keyFile: c.KeyFile}
+	if _, err := loader.load(); err != nil {
 		return nil, err
 	}
 
-	certPool := x509.NewCertPool()
-	bs, err := os.ReadFile(c.CAFile)
+	certPool, caCerts, err := loadCAPool(c.CAFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read client ca cert: %s", err)
 	}
 
-	ok := certPool.AppendCertsFromPEM(bs)
-	if !ok {
-		return nil, errors.New("failed to append client certs")
-	}
-
+	revocation := &revocationChecker{crlFile: c.CRLFile, issuers: caCerts}
 	tlsConfig := &tls.Config{
-		ClientAuth:   tls.RequireAndVerifyClientCert,
-		Certificates: []tls.Certificate{certificate},
-		ClientCAs:    certPool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  certPool,
+		// 证书轮换后直接覆盖文件，新连接即可使用新证书，无需重启节点
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return loader.load()
+		},
+		VerifyPeerCertificate: revocation.verify,
 	}
 
 	return tlsConfig, nil
 }
 
 func (c Certificate) GetTransportCredsForClient() (credentials.TransportCredentials, error) {
-	certificate, err := tls.LoadX509KeyPair(
-		c.CertFile,
-		c.KeyFile,
-	)
-	if err != nil {
+	loader := &keyPairLoader{certFile: c.CertFile, keyFile: c.KeyFile}
+	if _, err := loader.load(); err != nil {
 		return nil, err
 	}
 
-	certPool := x509.NewCertPool()
-	bs, err := os.ReadFile(c.CAFile)
+	certPool, caCerts, err := loadCAPool(c.CAFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read ca cert: %s", err)
 	}
 
+	revocation := &revocationChecker{crlFile: c.CRLFile, issuers: caCerts}
+	transportCreds := credentials.NewTLS(&tls.Config{
+		ServerName: c.ServerName,
+		RootCAs:    certPool,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return loader.load()
+		},
+		VerifyPeerCertificate: revocation.verify,
+	})
+
+	return transportCreds, nil
+}
+
+func loadCAPool(caFile string) (*x509.CertPool, []*x509.Certificate, error) {
+	bs, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPool := x509.NewCertPool()
 	ok := certPool.AppendCertsFromPEM(bs)
 	if !ok {
-		return nil, errors.New("failed to append certs")
+		return nil, nil, errors.New("failed to append certs")
 	}
 
-	transportCreds := credentials.NewTLS(&tls.Config{
-		ServerName:   c.ServerName,
-		Certificates: []tls.Certificate{certificate},
-		RootCAs:      certPool,
-	})
+	caCerts := make([]*x509.Certificate, 0, 1)
+	for rest := bs; len(rest) > 0; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != pemTypeCertificate {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			caCerts = append(caCerts, cert)
+		}
+	}
 
-	return transportCreds, nil
+	return certPool, caCerts, nil
+}
+
+// keyPairLoader 按文件修改时间缓存证书，文件变化时重新加载
+type keyPairLoader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	modTime time.Time
+	cert    *tls.Certificate
+}
+
+func (l *keyPairLoader) load() (*tls.Certificate, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	modTime := latestModTime(l.certFile, l.keyFile)
+	if l.cert != nil && !modTime.After(l.modTime) {
+		return l.cert, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		// 轮换过程中证书和私钥可能短暂不匹配，继续使用旧证书
+		if l.cert != nil {
+			return l.cert, nil
+		}
+		return nil, err
+	}
+	l.cert = &certificate
+	l.modTime = modTime
+
+	return l.cert, nil
+}
+
+// revocationChecker 在握手时检查对端证书是否已被吊销
+type revocationChecker struct {
+	crlFile string
+	issuers []*x509.Certificate
+
+	mu      sync.Mutex
+	modTime time.Time
+	revoked map[string]struct{}
+}
+
+func (r *revocationChecker) verify(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if r.crlFile == "" || len(rawCerts) == 0 {
+		return nil
+	}
+	revoked, err := r.load()
+	if err != nil {
+		return err
+	}
+	if len(revoked) == 0 {
+		return nil
+	}
+	leaf, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return err
+	}
+	if _, ok := revoked[FormatSerial(leaf.SerialNumber)]; ok {
+		return fmt.Errorf("certificate %s has been revoked", FormatSerial(leaf.SerialNumber))
+	}
+
+	return nil
+}
+
+func (r *revocationChecker) load() (map[string]struct{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := os.Stat(r.crlFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.revoked != nil && !info.ModTime().After(r.modTime) {
+		return r.revoked, nil
+	}
+
+	revoked, err := ReadCRLFile(r.crlFile, r.issuers)
+	if err != nil {
+		return nil, err
+	}
+	r.revoked = revoked
+	r.modTime = info.ModTime()
+
+	return r.revoked, nil
+}
+
+// ReadCRLFile 读取并校验 CRL，返回已吊销证书序列号集合
+func ReadCRLFile(crlFile string, issuers []*x509.Certificate) (map[string]struct{}, error) {
+	data, err := os.ReadFile(crlFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read crl: %s", err)
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse crl: %s", err)
+	}
+
+	verified := len(issuers) == 0
+	for _, issuer := range issuers {
+		if crl.CheckSignatureFrom(issuer) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("crl is not signed by a trusted ca")
+	}
+
+	revoked := make(map[string]struct{}, len(crl.RevokedCertificateEntries))
+	for _, entry := range crl.RevokedCertificateEntries {
+		revoked[FormatSerial(entry.SerialNumber)] = struct{}{}
+	}
+
+	return revoked, nil
+}
+
+func latestModTime(files ...string) time.Time {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest
 }
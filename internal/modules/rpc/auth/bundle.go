@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// CertificateBundle 下发给节点的证书文件内容（PEM）
+type CertificateBundle struct {
+	CACert string `json:"ca_cert"`
+	Cert   string `json:"cert"`
+	Key    string `json:"key"`
+	CRL    string `json:"crl"`
+}
+
+// WriteTo 把证书写入 Certificate 指定的路径，先写临时文件再 rename，
+// 避免正在握手的连接读到不完整的文件
+func (b CertificateBundle) WriteTo(c Certificate) error {
+	if b.Cert == "" || b.Key == "" {
+		return errors.New("certificate bundle is empty")
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("certificate path is not configured")
+	}
+	if _, err := ParseCertificatePEM([]byte(b.Cert)); err != nil {
+		return err
+	}
+
+	files := []struct {
+		path    string
+		content string
+		perm    os.FileMode
+	}{
+		{c.CAFile, b.CACert, 0644},
+		{c.CRLFile, b.CRL, 0644},
+		{c.KeyFile, b.Key, 0600},
+		{c.CertFile, b.Cert, 0644},
+	}
+	for _, file := range files {
+		if file.path == "" || file.content == "" {
+			continue
+		}
+		if err := WriteFileAtomic(file.path, []byte(file.content), file.perm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteFileAtomic 先写入同目录下的临时文件再 rename 覆盖目标文件
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpName, path)
+}
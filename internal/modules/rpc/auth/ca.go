@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// 内置 CA：服务端可选地充当节点证书的签发机构，节点注册时下发证书，
+// 到期前轮换，吊销后写入 CRL 供双方握手时校验。
+
+const (
+	pemTypeCertificate = "CERTIFICATE"
+	pemTypePrivateKey  = "EC PRIVATE KEY"
+	pemTypeCRL         = "X509 CRL"
+)
+
+type CertificateAuthority struct {
+	Cert    *x509.Certificate
+	CertPEM []byte
+	key     crypto.Signer
+}
+
+// IssueRequest 签发证书参数，Hosts 可以是 IP 或域名，会写入 SAN
+type IssueRequest struct {
+	CommonName string
+	Hosts      []string
+	Validity   time.Duration
+	// Client 为 true 时签发客户端证书（服务端连接节点时使用），否则签发节点的服务端证书
+	Client bool
+}
+
+// IssuedCertificate 签发结果，Serial 为十六进制序列号
+type IssuedCertificate struct {
+	Serial    string
+	NotBefore time.Time
+	NotAfter  time.Time
+	CertPEM   []byte
+	KeyPEM    []byte
+}
+
+// RevokedCertificate CRL 中的吊销条目
+type RevokedCertificate struct {
+	Serial    string
+	RevokedAt time.Time
+}
+
+// NewCertificateAuthority 生成自签名 CA，返回 CA 与 PEM 编码的私钥
+func NewCertificateAuthority(commonName string, validity time.Duration) (*CertificateAuthority, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"gocron"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := encodePrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ca := &CertificateAuthority{
+		Cert:    cert,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: pemTypeCertificate, Bytes: der}),
+		key:     key,
+	}
+
+	return ca, keyPEM, nil
+}
+
+// LoadCertificateAuthority 从 PEM 文件加载 CA 证书和私钥
+func LoadCertificateAuthority(certFile, keyFile string) (*CertificateAuthority, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca cert: %s", err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca key: %s", err)
+	}
+
+	return ParseCertificateAuthority(certPEM, keyPEM)
+}
+
+// ParseCertificateAuthority 从 PEM 编码的 CA 证书和私钥创建 CA
+func ParseCertificateAuthority(certPEM, keyPEM []byte) (*CertificateAuthority, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != pemTypeCertificate {
+		return nil, errors.New("invalid ca cert pem")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA {
+		return nil, errors.New("ca cert is not a certificate authority")
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, errors.New("invalid ca key pem")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertificateAuthority{Cert: cert, CertPEM: certPEM, key: key}, nil
+}
+
+// Issue 签发节点或客户端证书，每张证书使用独立生成的私钥
+func (ca *CertificateAuthority) Issue(req IssueRequest) (*IssuedCertificate, error) {
+	if req.Validity <= 0 {
+		return nil, errors.New("certificate validity must be positive")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	notAfter := now.Add(req.Validity)
+	// 签发的证书有效期不能超过 CA 本身
+	if notAfter.After(ca.Cert.NotAfter) {
+		notAfter = ca.Cert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: req.CommonName, Organization: []string{"gocron"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	if req.Client {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+	for _, host := range req.Hosts {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.key)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := encodePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IssuedCertificate{
+		Serial:    FormatSerial(serial),
+		NotBefore: template.NotBefore,
+		NotAfter:  notAfter,
+		CertPEM:   pem.EncodeToMemory(&pem.Block{Type: pemTypeCertificate, Bytes: der}),
+		KeyPEM:    keyPEM,
+	}, nil
+}
+
+// CreateCRL 生成 PEM 编码的证书吊销列表
+func (ca *CertificateAuthority) CreateCRL(revoked []RevokedCertificate, number int64, validity time.Duration) ([]byte, error) {
+	entries := make([]x509.RevocationListEntry, 0, len(revoked))
+	for _, item := range revoked {
+		serial, ok := new(big.Int).SetString(item.Serial, 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid certificate serial: %s", item.Serial)
+		}
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   serial,
+			RevocationTime: item.RevokedAt,
+		})
+	}
+	now := time.Now()
+	template := &x509.RevocationList{
+		Number:                    big.NewInt(number),
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(validity),
+		RevokedCertificateEntries: entries,
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, template, ca.Cert, ca.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeCRL, Bytes: der}), nil
+}
+
+// FormatSerial 证书序列号统一使用小写十六进制表示
+func FormatSerial(serial *big.Int) string {
+	return strings.ToLower(serial.Text(16))
+}
+
+// ParseCertificatePEM 解析 PEM 中的第一张证书
+func ParseCertificatePEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemTypeCertificate {
+		return nil, errors.New("invalid cert pem")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func randomSerial() (*big.Int, error) {
+	limit := new(big.Int).Lsh(big.NewInt(1), 128)
+	return rand.Int(rand.Reader, limit)
+}
+
+func encodePrivateKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePrivateKey, Bytes: der}), nil
+}
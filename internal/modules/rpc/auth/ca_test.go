@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto/x509"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+func newTestCA(t *testing.T) *CertificateAuthority {
+	t.Helper()
+	ca, _, err := NewCertificateAuthority("test ca", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("create ca failed: %v", err)
+	}
+	return ca
+}
+
+// writeBundle 把签发结果写入临时目录，返回对应的 Certificate
+func writeBundle(t *testing.T, ca *CertificateAuthority, issued *IssuedCertificate, crl []byte) Certificate {
+	t.Helper()
+	dir := t.TempDir()
+	c := Certificate{
+		CAFile:   filepath.Join(dir, "ca.crt"),
+		CertFile: filepath.Join(dir, "node.crt"),
+		KeyFile:  filepath.Join(dir, "node.key"),
+		CRLFile:  filepath.Join(dir, "crl.pem"),
+	}
+	bundle := CertificateBundle{CACert: string(ca.CertPEM), Cert: string(issued.CertPEM), Key: string(issued.KeyPEM), CRL: string(crl)}
+	if err := bundle.WriteTo(c); err != nil {
+		t.Fatalf("write bundle failed: %v", err)
+	}
+	return c
+}
+
+func TestCertificateAuthority_IssueAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	ca, keyPEM, err := NewCertificateAuthority("test ca", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("create ca failed: %v", err)
+	}
+	certFile := filepath.Join(dir, "ca.crt")
+	keyFile := filepath.Join(dir, "ca.key")
+	_ = os.WriteFile(certFile, ca.CertPEM, 0644)
+	_ = os.WriteFile(keyFile, keyPEM, 0600)
+
+	loaded, err := LoadCertificateAuthority(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("load ca failed: %v", err)
+	}
+
+	issued, err := loaded.Issue(IssueRequest{CommonName: "node1", Hosts: []string{"10.0.0.1", "node1.local"}, Validity: 48 * time.Hour})
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	cert, err := ParseCertificatePEM(issued.CertPEM)
+	if err != nil {
+		t.Fatalf("parse issued cert failed: %v", err)
+	}
+	if err := cert.CheckSignatureFrom(ca.Cert); err != nil {
+		t.Fatalf("issued cert not signed by ca: %v", err)
+	}
+	if len(cert.IPAddresses) != 1 || len(cert.DNSNames) != 1 {
+		t.Fatalf("unexpected SANs: ip=%v dns=%v", cert.IPAddresses, cert.DNSNames)
+	}
+	// 有效期不能超过 CA
+	if issued.NotAfter.After(ca.Cert.NotAfter) {
+		t.Fatalf("issued cert outlives ca: %s > %s", issued.NotAfter, ca.Cert.NotAfter)
+	}
+	if issued.Serial != FormatSerial(cert.SerialNumber) {
+		t.Fatalf("serial mismatch: %s vs %s", issued.Serial, FormatSerial(cert.SerialNumber))
+	}
+}
+
+func TestReadCRLFile(t *testing.T) {
+	ca := newTestCA(t)
+	other := newTestCA(t)
+	crl, err := ca.CreateCRL([]RevokedCertificate{{Serial: "abc123", RevokedAt: time.Now()}}, 1, time.Hour)
+	if err != nil {
+		t.Fatalf("create crl failed: %v", err)
+	}
+	crlFile := filepath.Join(t.TempDir(), "crl.pem")
+	_ = os.WriteFile(crlFile, crl, 0644)
+
+	revoked, err := ReadCRLFile(crlFile, []*x509.Certificate{ca.Cert})
+	if err != nil {
+		t.Fatalf("read crl failed: %v", err)
+	}
+	if _, ok := revoked["abc123"]; !ok {
+		t.Fatalf("expected serial abc123 in crl, got %v", revoked)
+	}
+
+	if _, err := ReadCRLFile(crlFile, []*x509.Certificate{other.Cert}); err == nil {
+		t.Fatal("expected error for crl signed by untrusted ca")
+	}
+}
+
+func TestMutualTLS_RevokedClientRejected(t *testing.T) {
+	ca := newTestCA(t)
+	serverIssued, err := ca.Issue(IssueRequest{CommonName: "127.0.0.1", Hosts: []string{"127.0.0.1"}, Validity: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientIssued, err := ca.Issue(IssueRequest{CommonName: "client", Validity: time.Hour, Client: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyCRL, _ := ca.CreateCRL(nil, 1, time.Hour)
+	serverCert := writeBundle(t, ca, serverIssued, emptyCRL)
+	clientCert := writeBundle(t, ca, clientIssued, emptyCRL)
+
+	handshake := func() error {
+		serverConfig, err := serverCert.GetTLSConfigForServer()
+		if err != nil {
+			return err
+		}
+		clientCert.ServerName = "127.0.0.1"
+		creds, err := clientCert.GetTransportCredsForClient()
+		if err != nil {
+			return err
+		}
+		serverCreds := credentials.NewTLS(serverConfig)
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		defer ln.Close()
+		serverErr := make(chan error, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				serverErr <- err
+				return
+			}
+			defer conn.Close()
+			_, _, err = serverCreds.ServerHandshake(conn)
+			serverErr <- err
+		}()
+		rawConn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			return err
+		}
+		defer rawConn.Close()
+		_, _, clientErr := creds.ClientHandshake(t.Context(), "127.0.0.1", rawConn)
+		if err := <-serverErr; err != nil {
+			return err
+		}
+		return clientErr
+	}
+
+	if err := handshake(); err != nil {
+		t.Fatalf("expected handshake to succeed, got %v", err)
+	}
+
+	// 吊销客户端证书后，服务端应拒绝握手
+	crl, _ := ca.CreateCRL([]RevokedCertificate{{Serial: clientIssued.Serial, RevokedAt: time.Now()}}, 2, time.Hour)
+	if err := WriteFileAtomic(serverCert.CRLFile, crl, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(); err == nil {
+		t.Fatal("expected handshake to fail for revoked client certificate")
+	}
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"google.golang.org/grpc/status"
 
+	"github.com/gocronx-team/gocron/internal/modules/artifact"
 	"github.com/gocronx-team/gocron/internal/modules/i18n"
 	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/rpc/auth"
 	"github.com/gocronx-team/gocron/internal/modules/rpc/grpcpool"
 	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"github.com/gocronx-team/gocron/internal/modules/sqlrunner"
 	"google.golang.org/grpc/codes"
 )
 
@@ -27,6 +31,24 @@ func errRPCUnavailable() error {
 	return errors.New(i18n.Translate("rpc_unavailable"))
 }
 
+// StopCommand 停止节点上正在执行的任务
+const StopCommand = "__STOP__"
+
+// reservedCommands 通过 Run 下发的节点内部指令前缀，节点按前缀分发，
+// 用户任务的命令不能以这些前缀开头，否则会被节点当作内部指令执行
+var reservedCommands = []string{StopCommand, sqlrunner.Command, artifact.Command}
+
+// ReservedCommand 返回命令命中的内部指令前缀
+func ReservedCommand(command string) (string, bool) {
+	command = strings.TrimSpace(command)
+	for _, prefix := range reservedCommands {
+		if strings.HasPrefix(command, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
 func generateTaskUniqueKey(ip string, port int, id int64) string {
 	return fmt.Sprintf("%s:%d:%d", ip, port, id)
 }
@@ -45,7 +67,7 @@ func Stop(ip string, port int, id int64) {
 		defer cancel()
 
 		_, err = c.Run(ctx, &pb.TaskRequest{
-			Command: "__STOP__",
+			Command: StopCommand,
 			Id:      id,
 		})
 		if err != nil {
@@ -54,6 +76,29 @@ func Stop(ip string, port int, id int64) {
 	}()
 }
 
+// RotateCertificate 把新签发的证书推送到节点
+func RotateCertificate(ip string, port int, bundle auth.CertificateBundle) error {
+	addr := fmt.Sprintf("%s:%d", ip, port)
+	c, err := grpcpool.Pool.Get(addr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = c.RotateCertificate(ctx, &pb.CertificateRequest{
+		CaCert: bundle.CACert,
+		Cert:   bundle.Cert,
+		Key:    bundle.Key,
+		Crl:    bundle.CRL,
+	})
+	if err != nil {
+		return parseGRPCErrorOnly(err)
+	}
+
+	return nil
+}
+
 func Exec(ip string, port int, taskReq *pb.TaskRequest) (string, error) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -0,0 +1,20 @@
+package client
+
+import "testing"
+
+func TestReservedCommand(t *testing.T) {
+	cases := map[string]string{
+		"__STOP__":                    "__STOP__",
+		"__ROTATE_CERT__\n{}":         "",
+		"  __SQL__\n{}":               "__SQL__",
+		"__ARTIFACT__ ./deploy.sh":    "__ARTIFACT__",
+		"echo __SQL__":                "",
+		"./deploy.sh --stop __STOP__": "",
+	}
+	for command, want := range cases {
+		prefix, reserved := ReservedCommand(command)
+		if prefix != want || reserved != (want != "") {
+			t.Errorf("ReservedCommand(%q) = %q, %v, want %q", command, prefix, reserved, want)
+		}
+	}
+}
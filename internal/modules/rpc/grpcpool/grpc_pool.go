@@ -96,6 +96,7 @@ func (p *GRPCPool) factory(addr string) (*Client, error) {
 			CAFile:     app.Setting.CAFile,
 			CertFile:   app.Setting.CertFile,
 			KeyFile:    app.Setting.KeyFile,
+			CRLFile:    app.Setting.CRLFile,
 			ServerName: server[0],
 		}
 
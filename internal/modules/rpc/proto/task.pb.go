@@ -133,6 +133,110 @@ func (x *TaskResponse) GetError() string {
 	return ""
 }
 
+type CertificateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CaCert        string                 `protobuf:"bytes,1,opt,name=ca_cert,json=caCert,proto3" json:"ca_cert,omitempty"` // CA 证书
+	Cert          string                 `protobuf:"bytes,2,opt,name=cert,proto3" json:"cert,omitempty"`                   // 节点证书
+	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`                     // 节点证书私钥
+	Crl           string                 `protobuf:"bytes,4,opt,name=crl,proto3" json:"crl,omitempty"`                     // 证书吊销列表
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CertificateRequest) Reset() {
+	*x = CertificateRequest{}
+	mi := &file_task_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CertificateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CertificateRequest) ProtoMessage() {}
+
+func (x *CertificateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_task_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CertificateRequest.ProtoReflect.Descriptor instead.
+func (*CertificateRequest) Descriptor() ([]byte, []int) {
+	return file_task_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CertificateRequest) GetCaCert() string {
+	if x != nil {
+		return x.CaCert
+	}
+	return ""
+}
+
+func (x *CertificateRequest) GetCert() string {
+	if x != nil {
+		return x.Cert
+	}
+	return ""
+}
+
+func (x *CertificateRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *CertificateRequest) GetCrl() string {
+	if x != nil {
+		return x.Crl
+	}
+	return ""
+}
+
+type CertificateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CertificateResponse) Reset() {
+	*x = CertificateResponse{}
+	mi := &file_task_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CertificateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CertificateResponse) ProtoMessage() {}
+
+func (x *CertificateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_task_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CertificateResponse.ProtoReflect.Descriptor instead.
+func (*CertificateResponse) Descriptor() ([]byte, []int) {
+	return file_task_proto_rawDescGZIP(), []int{3}
+}
+
 var File_task_proto protoreflect.FileDescriptor
 
 const file_task_proto_rawDesc = "" +
@@ -145,9 +249,16 @@ const file_task_proto_rawDesc = "" +
 	"\x02id\x18\x04 \x01(\x03R\x02id\"<\n" +
 	"\fTaskResponse\x12\x16\n" +
 	"\x06output\x18\x01 \x01(\tR\x06output\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error24\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"e\n" +
+	"\x12CertificateRequest\x12\x17\n" +
+	"\aca_cert\x18\x01 \x01(\tR\x06caCert\x12\x12\n" +
+	"\x04cert\x18\x02 \x01(\tR\x04cert\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x10\n" +
+	"\x03crl\x18\x04 \x01(\tR\x03crl\"\x15\n" +
+	"\x13CertificateResponse2~\n" +
 	"\x04Task\x12,\n" +
-	"\x03Run\x12\x10.rpc.TaskRequest\x1a\x11.rpc.TaskResponse\"\x00B;Z9github.com/gocronx-team/gocron/internal/modules/rpc/protob\x06proto3"
+	"\x03Run\x12\x10.rpc.TaskRequest\x1a\x11.rpc.TaskResponse\"\x00\x12H\n" +
+	"\x11RotateCertificate\x12\x17.rpc.CertificateRequest\x1a\x18.rpc.CertificateResponse\"\x00B;Z9github.com/gocronx-team/gocron/internal/modules/rpc/protob\x06proto3"
 
 var (
 	file_task_proto_rawDescOnce sync.Once
@@ -161,16 +272,20 @@ func file_task_proto_rawDescGZIP() []byte {
 	return file_task_proto_rawDescData
 }
 
-var file_task_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_task_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_task_proto_goTypes = []any{
-	(*TaskRequest)(nil),  // 0: rpc.TaskRequest
-	(*TaskResponse)(nil), // 1: rpc.TaskResponse
+	(*TaskRequest)(nil),         // 0: rpc.TaskRequest
+	(*TaskResponse)(nil),        // 1: rpc.TaskResponse
+	(*CertificateRequest)(nil),  // 2: rpc.CertificateRequest
+	(*CertificateResponse)(nil), // 3: rpc.CertificateResponse
 }
 var file_task_proto_depIdxs = []int32{
 	0, // 0: rpc.Task.Run:input_type -> rpc.TaskRequest
-	1, // 1: rpc.Task.Run:output_type -> rpc.TaskResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
+	2, // 1: rpc.Task.RotateCertificate:input_type -> rpc.CertificateRequest
+	1, // 2: rpc.Task.Run:output_type -> rpc.TaskResponse
+	3, // 3: rpc.Task.RotateCertificate:output_type -> rpc.CertificateResponse
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -187,7 +302,7 @@ func file_task_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_task_proto_rawDesc), len(file_task_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
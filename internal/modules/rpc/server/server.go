@@ -6,18 +6,23 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/gocronx-team/gocron/internal/modules/artifact"
 	"github.com/gocronx-team/gocron/internal/modules/rpc/auth"
 	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"github.com/gocronx-team/gocron/internal/modules/sqlrunner"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
@@ -25,6 +30,9 @@ type Server struct {
 	taskContexts sync.Map // 存储正在运行的任务上下文
 	taskOutputs  sync.Map // 存储任务输出
 	stopChans    sync.Map // 存储停止通道
+	enableTLS    bool
+	certificate  auth.Certificate
+	artifacts    *artifact.Cache // 任务附件缓存
 }
 
 var keepAlivePolicy = keepalive.EnforcementPolicy{
@@ -92,8 +100,16 @@ func (s *Server) Run(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse
 		}
 	}()
 
-	// 执行命令
-	output, execErr := utils.ExecShell(taskCtx, cleanedCmd)
+	// 执行命令，SQL 任务在节点上连接数据源执行，带附件的任务先下载附件
+	var output string
+	var execErr error
+	if strings.HasPrefix(cleanedCmd, sqlrunner.Command) {
+		output, execErr = sqlrunner.RunCommand(taskCtx, strings.TrimPrefix(cleanedCmd, sqlrunner.Command))
+	} else if strings.HasPrefix(cleanedCmd, artifact.Command) {
+		output, execErr = artifact.RunCommand(taskCtx, s.artifacts, strings.TrimPrefix(cleanedCmd, artifact.Command))
+	} else {
+		output, execErr = utils.ExecShell(taskCtx, cleanedCmd)
+	}
 	outputBuf.WriteString(output)
 
 	resp := new(pb.TaskResponse)
@@ -115,7 +131,22 @@ func (s *Server) Run(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse
 	return resp, nil
 }
 
-func Start(addr string, enableTLS bool, certificate auth.Certificate) {
+// RotateCertificate 写入服务端下发的新证书，新连接握手时自动加载
+func (s *Server) RotateCertificate(ctx context.Context, req *pb.CertificateRequest) (*pb.CertificateResponse, error) {
+	if !s.enableTLS {
+		return nil, status.Error(codes.FailedPrecondition, "tls is not enabled on this node")
+	}
+	bundle := auth.CertificateBundle{CACert: req.CaCert, Cert: req.Cert, Key: req.Key, CRL: req.Crl}
+	if err := bundle.WriteTo(s.certificate); err != nil {
+		log.Errorf("Certificate rotation failed: %s", err.Error())
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	log.Infof("Certificate rotated, written to %s", s.certificate.CertFile)
+
+	return &pb.CertificateResponse{}, nil
+}
+
+func Start(addr string, enableTLS bool, certificate auth.Certificate, artifacts *artifact.Cache) {
 	l, err := net.Listen("tcp", addr)
 	if err != nil {
 		log.Fatal(err)
@@ -133,7 +164,7 @@ func Start(addr string, enableTLS bool, certificate auth.Certificate) {
 		opts = append(opts, opt)
 	}
 	server := grpc.NewServer(opts...)
-	pb.RegisterTaskServer(server, &Server{})
+	pb.RegisterTaskServer(server, &Server{enableTLS: enableTLS, certificate: certificate, artifacts: artifacts})
 	log.Infof("server listen on %s", addr)
 
 	go func() {
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/modules/rpc/auth"
+	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRotateCertificate(t *testing.T) {
+	ca, _, err := auth.NewCertificateAuthority("test ca", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued, err := ca.Issue(auth.IssueRequest{CommonName: "node", Hosts: []string{"node"}, Validity: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &pb.CertificateRequest{CaCert: string(ca.CertPEM), Cert: string(issued.CertPEM), Key: string(issued.KeyPEM)}
+
+	// 未启用 TLS 的节点不接受证书
+	_, err = new(Server).RotateCertificate(context.Background(), req)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+
+	dir := t.TempDir()
+	s := &Server{enableTLS: true, certificate: auth.Certificate{
+		CAFile:   filepath.Join(dir, "ca.crt"),
+		CertFile: filepath.Join(dir, "node.crt"),
+		KeyFile:  filepath.Join(dir, "node.key"),
+	}}
+	if _, err = s.RotateCertificate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	written, err := os.ReadFile(s.certificate.CertFile)
+	if err != nil || string(written) != req.Cert {
+		t.Fatalf("certificate not written: %v", err)
+	}
+
+	req.Cert = "invalid"
+	if _, err = s.RotateCertificate(context.Background(), req); status.Code(err) != codes.Internal {
+		t.Fatalf("expected invalid certificate to be rejected, got %v", err)
+	}
+}
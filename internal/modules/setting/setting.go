@@ -36,9 +36,24 @@ type Setting struct {
 	CAFile    string
 	CertFile  string
 	KeyFile   string
+	CRLFile   string
+	// InternalCA 使用内置 CA 自动签发、轮换节点证书，开启后强制启用 TLS
+	InternalCA bool
 
 	ConcurrencyQueue int
 	AuthSecret       string
+	// SecretKey 加密数据源密码等凭据的密钥，为空时由 AuthSecret 生成。多实例部署时必须一致，修改后已保存的凭据无法解密
+	SecretKey string
+	// NotifyWorkers 并发投递通知的 worker 数
+	NotifyWorkers int
+
+	// TemplateDir 启动时加载的模板包目录，相对路径基于配置目录
+	TemplateDir string
+	// TemplateDirConflict 模板目录与已有模板同名时的处理方式 skip|overwrite
+	TemplateDirConflict string
+
+	// SqliteDir SQL 任务允许访问的 SQLite 数据库目录，相对路径基于配置目录，为空时不允许 SQLite 数据源
+	SqliteDir string
 }
 
 // 读取配置
@@ -72,16 +87,29 @@ func Read(filename string) (*Setting, error) {
 	s.McpEnable = section.Key("mcp.enable").MustBool(true)
 	s.ConcurrencyQueue = section.Key("concurrency.queue").MustInt(500)
 	s.AuthSecret = section.Key("auth_secret").MustString("")
+	s.NotifyWorkers = section.Key("notify.workers").MustInt(4)
+	s.SecretKey = section.Key("secret_key").MustString("")
 	if s.AuthSecret == "" {
 		s.AuthSecret = utils.RandAuthToken()
 	}
+	if s.SecretKey == "" {
+		s.SecretKey = s.AuthSecret
+	}
 
 	s.EnableTLS = section.Key("enable_tls").MustBool(false)
 	s.CAFile = section.Key("ca_file").MustString("")
 	s.CertFile = section.Key("cert_file").MustString("")
 	s.KeyFile = section.Key("key_file").MustString("")
-
-	if s.EnableTLS {
+	s.CRLFile = section.Key("crl_file").MustString("")
+	s.InternalCA = section.Key("internal_ca").MustBool(false)
+	s.TemplateDir = section.Key("template_dir").MustString("")
+	s.TemplateDirConflict = section.Key("template_dir_conflict").MustString("skip")
+	s.SqliteDir = section.Key("sqlite_dir").MustString("")
+
+	// 内置 CA 模式下证书文件在启动时生成，这里不校验
+	if s.InternalCA {
+		s.EnableTLS = true
+	} else if s.EnableTLS {
 		if !utils.FileExist(s.CAFile) {
 			logger.Fatalf("failed to read ca cert file: %s", s.CAFile)
 		}
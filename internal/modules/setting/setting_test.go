@@ -49,6 +49,25 @@ func TestReadReturnsConfiguredValues(t *testing.T) {
 	if s.ConcurrencyQueue != 200 || s.AuthSecret != "existing-secret" {
 		t.Fatalf("unexpected concurrency/auth config: %+v", s)
 	}
+	// 未配置 secret_key 时使用 auth_secret
+	if s.SecretKey != "existing-secret" {
+		t.Fatalf("secret key should fall back to auth secret, got %q", s.SecretKey)
+	}
+}
+
+func TestReadSecretKey(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "app.ini")
+	content := "[default]\nauth_secret=auth\nsecret_key=credential-key\n"
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	s, err := Read(configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.SecretKey != "credential-key" || s.AuthSecret != "auth" {
+		t.Fatalf("unexpected secret config: %q %q", s.SecretKey, s.AuthSecret)
+	}
 }
 
 func TestReadGeneratesAuthSecretWhenMissing(t *testing.T) {
@@ -0,0 +1,83 @@
+package sqlrunner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 断言对象
+const (
+	// TargetRows 最后一条查询语句返回的行数
+	TargetRows = "rows"
+	// TargetAffected 所有非查询语句影响的行数之和
+	TargetAffected = "affected"
+)
+
+var assertOps = map[string]func(actual, expected int64) bool{
+	"==": func(a, e int64) bool { return a == e },
+	"!=": func(a, e int64) bool { return a != e },
+	">":  func(a, e int64) bool { return a > e },
+	">=": func(a, e int64) bool { return a >= e },
+	"<":  func(a, e int64) bool { return a < e },
+	"<=": func(a, e int64) bool { return a <= e },
+}
+
+const maxAssertions = 20
+
+// Assertion 行数断言，全部满足时任务才算成功；开启事务时断言失败会回滚
+type Assertion struct {
+	Target string `json:"target"`
+	Op     string `json:"op"`
+	Value  int64  `json:"value"`
+}
+
+func (a Assertion) String() string {
+	return fmt.Sprintf("%s %s %d", a.Target, a.Op, a.Value)
+}
+
+// ParseAssertions 解析并校验断言列表（JSON 数组）
+func ParseAssertions(s string) ([]Assertion, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	var assertions []Assertion
+	if err := json.Unmarshal([]byte(s), &assertions); err != nil {
+		return nil, fmt.Errorf("invalid assertions: %w", err)
+	}
+	if len(assertions) > maxAssertions {
+		return nil, fmt.Errorf("at most %d assertions are allowed", maxAssertions)
+	}
+	for i, a := range assertions {
+		if a.Target != TargetRows && a.Target != TargetAffected {
+			return nil, fmt.Errorf("assertion %d: unsupported target %q", i+1, a.Target)
+		}
+		if assertOps[a.Op] == nil {
+			return nil, fmt.Errorf("assertion %d: unsupported operator %q", i+1, a.Op)
+		}
+	}
+
+	return assertions, nil
+}
+
+func checkAssertions(assertions []Assertion, result runResult) error {
+	for _, a := range assertions {
+		actual := result.affected
+		if a.Target == TargetRows {
+			if !result.queried {
+				return fmt.Errorf("assertion failed: %s: no query statement was executed", a)
+			}
+			actual = result.rows
+		}
+		compare := assertOps[a.Op]
+		if compare == nil {
+			return errors.New("unsupported operator " + a.Op)
+		}
+		if !compare(actual, a.Value) {
+			return fmt.Errorf("assertion failed: %s: actual %d", a, actual)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package sqlrunner
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxCellWidth 单元格最多显示的字符数
+const maxCellWidth = 200
+
+// renderRows 以文本表格输出前 maxRows 行，返回总行数和列数。超出的行只计数
+func renderRows(rows *sql.Rows, maxRows int, output *strings.Builder) (int64, int, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(columns) == 0 {
+		return 0, 0, rows.Err()
+	}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	table := [][]string{columns}
+	var count int64
+	for rows.Next() {
+		count++
+		if count > int64(maxRows) {
+			continue
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return count, len(columns), err
+		}
+		row := make([]string, len(columns))
+		for i, value := range values {
+			row[i] = formatValue(value)
+		}
+		table = append(table, row)
+	}
+	if err := rows.Err(); err != nil {
+		return count, len(columns), err
+	}
+	writeTable(table, output)
+
+	return count, len(columns), nil
+}
+
+func writeTable(table [][]string, output *strings.Builder) {
+	widths := make([]int, len(table[0]))
+	for _, row := range table {
+		for i, cell := range row {
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			if i > 0 {
+				output.WriteString(" | ")
+			}
+			output.WriteString(cell)
+			if i < len(row)-1 {
+				output.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+			}
+		}
+		output.WriteString("\n")
+	}
+	writeRow(table[0])
+	for i, w := range widths {
+		if i > 0 {
+			output.WriteString("-+-")
+		}
+		output.WriteString(strings.Repeat("-", w))
+	}
+	output.WriteString("\n")
+	for _, row := range table[1:] {
+		writeRow(row)
+	}
+}
+
+func formatValue(value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if !utf8.Valid(v) {
+			if len(v) > maxCellWidth/2 {
+				return fmt.Sprintf("0x%x...", v[:maxCellWidth/2])
+			}
+			return fmt.Sprintf("0x%x", v)
+		}
+		s = string(v)
+	case time.Time:
+		if v.Nanosecond() == 0 {
+			return v.Format("2006-01-02 15:04:05")
+		}
+		return v.Format("2006-01-02 15:04:05.999999")
+	default:
+		s = fmt.Sprint(v)
+	}
+	s = strings.NewReplacer("\r", `\r`, "\n", `\n`, "\t", `\t`).Replace(s)
+	if utf8.RuneCountInString(s) > maxCellWidth {
+		s = string([]rune(s)[:maxCellWidth]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,337 @@
+package sqlrunner
+
+// SQL 任务：连接数据源，在同一连接上按顺序执行语句，渲染查询结果的前 N 行并检查行数断言。
+// 服务端直接执行，或者以 Command 前缀通过 RPC 发给节点执行
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "github.com/ncruces/go-sqlite3/driver"
+)
+
+// Command 节点执行 SQL 任务的特殊命令前缀，命令格式为 Command + "\n" + Job 的 JSON
+const Command = "__SQL__"
+
+// 数据源类型
+const (
+	DriverMysql    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+)
+
+const (
+	// DefaultMaxRows 每条查询语句写入日志的默认行数
+	DefaultMaxRows = 20
+	MaxRowsLimit   = 1000
+)
+
+// SqliteDir 允许 SQLite 数据源访问的目录，数据库文件路径相对该目录解析。
+// 为空时不允许 SQLite 数据源，避免任务读写 gocron 自身的数据库等任意文件
+var SqliteDir string
+
+// Source 数据源连接信息，Params 为追加到 DSN 的查询参数，如 tls=true、sslmode=require
+type Source struct {
+	Driver   string `json:"driver"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Database string `json:"database"`
+	Params   string `json:"params"`
+}
+
+// Job 一次执行
+type Job struct {
+	Source Source `json:"source"`
+	Query  string `json:"query"`
+	// Transaction 所有语句在一个事务中执行，出错或断言失败时回滚
+	Transaction bool        `json:"transaction"`
+	MaxRows     int         `json:"max_rows"`
+	Assertions  []Assertion `json:"assertions"`
+}
+
+// Validate 检查数据源类型和必填项，并校验生成的 DSN。
+// SQLite 只检查文件路径，SqliteDir 在执行的服务端或节点上配置
+func (s Source) Validate() error {
+	if s.Driver == DriverSqlite {
+		if err := validateSqliteFile(s.Database); err != nil {
+			return err
+		}
+		if _, err := url.ParseQuery(s.Params); err != nil {
+			return fmt.Errorf("invalid params: %w", err)
+		}
+		return nil
+	}
+	_, _, err := s.dsn()
+	return err
+}
+
+// dsn 返回 database/sql 的驱动名称和连接字符串
+func (s Source) dsn() (string, string, error) {
+	switch s.Driver {
+	case DriverMysql:
+		if strings.TrimSpace(s.Host) == "" {
+			return "", "", errors.New("host is required")
+		}
+		config := mysql.NewConfig()
+		config.User = s.User
+		config.Passwd = s.Password
+		config.Net = "tcp"
+		config.Addr = hostPort(s.Host, s.Port, 3306)
+		config.DBName = s.Database
+		dsn := appendParams(config.FormatDSN(), s.Params)
+		if _, err := mysql.ParseDSN(dsn); err != nil {
+			return "", "", fmt.Errorf("invalid params: %w", err)
+		}
+		return "mysql", dsn, nil
+	case DriverPostgres:
+		if strings.TrimSpace(s.Host) == "" {
+			return "", "", errors.New("host is required")
+		}
+		if _, err := url.ParseQuery(s.Params); err != nil {
+			return "", "", fmt.Errorf("invalid params: %w", err)
+		}
+		u := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(s.User, s.Password),
+			Host:     hostPort(s.Host, s.Port, 5432),
+			Path:     "/" + s.Database,
+			RawQuery: s.Params,
+		}
+		return "pgx", u.String(), nil
+	case DriverSqlite:
+		path, err := sqlitePath(s.Database)
+		if err != nil {
+			return "", "", err
+		}
+		if s.Params == "" {
+			return "sqlite3", path, nil
+		}
+		if _, err := url.ParseQuery(s.Params); err != nil {
+			return "", "", fmt.Errorf("invalid params: %w", err)
+		}
+		return "sqlite3", "file:" + path + "?" + s.Params, nil
+	}
+
+	return "", "", fmt.Errorf("unsupported driver %q", s.Driver)
+}
+
+// validateSqliteFile 数据库文件必须是相对 SqliteDir 的路径，拒绝绝对路径和跳出目录的路径
+func validateSqliteFile(database string) error {
+	if strings.TrimSpace(database) == "" {
+		return errors.New("database file is required")
+	}
+	if !filepath.IsLocal(database) || strings.ContainsAny(database, "?#") {
+		return fmt.Errorf("database file %q must be a relative path inside the sqlite dir", database)
+	}
+	return nil
+}
+
+// sqlitePath 把数据库文件解析到 SqliteDir 下
+func sqlitePath(database string) (string, error) {
+	if err := validateSqliteFile(database); err != nil {
+		return "", err
+	}
+	if SqliteDir == "" {
+		return "", errors.New("sqlite data sources are disabled, no sqlite dir configured")
+	}
+	return filepath.Join(SqliteDir, database), nil
+}
+
+func hostPort(host string, port, defaultPort int) string {
+	if port <= 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(strings.TrimSpace(host), strconv.Itoa(port))
+}
+
+func appendParams(dsn, params string) string {
+	params = strings.TrimPrefix(strings.TrimSpace(params), "?")
+	if params == "" {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + params
+	}
+	return dsn + "?" + params
+}
+
+func (job Job) maxRows() int {
+	if job.MaxRows <= 0 {
+		return DefaultMaxRows
+	}
+	if job.MaxRows > MaxRowsLimit {
+		return MaxRowsLimit
+	}
+	return job.MaxRows
+}
+
+// executor sql.Conn 和 sql.Tx 的公共方法
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Run 执行任务，返回渲染后的结果。ctx 结束时取消正在执行的语句
+func Run(ctx context.Context, job Job) (string, error) {
+	driverName, dsn, err := job.Source.dsn()
+	if err != nil {
+		return "", err
+	}
+	statements := splitStatements(job.Query, job.Source.Driver == DriverMysql)
+	if len(statements) == 0 {
+		return "", errors.New("no SQL statement to execute")
+	}
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	// 所有语句使用同一个连接，SET、临时表等会话状态在语句之间保留
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("connect %s: %w", job.Source.Driver, err)
+	}
+	defer conn.Close()
+
+	var exec executor = conn
+	var tx *sql.Tx
+	if job.Transaction {
+		if tx, err = conn.BeginTx(ctx, nil); err != nil {
+			return "", fmt.Errorf("begin transaction: %w", err)
+		}
+		exec = tx
+	}
+
+	var output strings.Builder
+	result := runStatements(ctx, exec, statements, job.maxRows(), &output)
+	err = result.err
+	if err == nil {
+		err = checkAssertions(job.Assertions, result)
+	}
+	if tx != nil {
+		if err != nil {
+			_ = tx.Rollback()
+			output.WriteString("Transaction rolled back\n")
+		} else if err = tx.Commit(); err != nil {
+			err = fmt.Errorf("commit transaction: %w", err)
+		} else {
+			output.WriteString("Transaction committed\n")
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	return output.String(), err
+}
+
+// RunCommand 节点执行 Command 前缀后的 JSON
+func RunCommand(ctx context.Context, payload string) (string, error) {
+	var job Job
+	if err := json.Unmarshal([]byte(strings.TrimSpace(payload)), &job); err != nil {
+		return "", fmt.Errorf("invalid SQL job: %w", err)
+	}
+	return Run(ctx, job)
+}
+
+// EncodeCommand 生成发给节点的命令
+func EncodeCommand(job Job) (string, error) {
+	// json.Marshal 会转义 &、<、>，节点清理 HTML 实体时不会改变内容
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+	return Command + "\n" + string(payload), nil
+}
+
+// runResult 执行统计，用于断言
+type runResult struct {
+	// rows 最后一条查询语句返回的行数，queried 为 false 表示没有执行查询语句
+	rows     int64
+	queried  bool
+	affected int64
+	err      error
+}
+
+func runStatements(ctx context.Context, exec executor, statements []string, maxRows int, output *strings.Builder) runResult {
+	var result runResult
+	for i, statement := range statements {
+		fmt.Fprintf(output, "[%d] %s\n", i+1, statementSummary(statement))
+		start := time.Now()
+		if isQuery(statement) {
+			rows, err := exec.QueryContext(ctx, statement)
+			if err != nil {
+				result.err = statementError(i, err, output)
+				return result
+			}
+			count, columns, err := renderRows(rows, maxRows, output)
+			if err != nil {
+				result.err = statementError(i, err, output)
+				return result
+			}
+			if columns == 0 {
+				fmt.Fprintf(output, "OK (%s)\n\n", elapsed(start))
+				continue
+			}
+			result.rows, result.queried = count, true
+			if count > int64(maxRows) {
+				fmt.Fprintf(output, "(showing %d of %d rows, %s)\n\n", maxRows, count, elapsed(start))
+			} else {
+				fmt.Fprintf(output, "(%d rows, %s)\n\n", count, elapsed(start))
+			}
+			continue
+		}
+		res, err := exec.ExecContext(ctx, statement)
+		if err != nil {
+			result.err = statementError(i, err, output)
+			return result
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			fmt.Fprintf(output, "OK (%s)\n\n", elapsed(start))
+			continue
+		}
+		result.affected += affected
+		fmt.Fprintf(output, "%d rows affected (%s)\n\n", affected, elapsed(start))
+	}
+
+	return result
+}
+
+func statementError(i int, err error, output *strings.Builder) error {
+	fmt.Fprintf(output, "ERROR: %s\n\n", err)
+	return fmt.Errorf("statement %d: %w", i+1, err)
+}
+
+func elapsed(start time.Time) string {
+	return time.Since(start).Round(time.Millisecond).String()
+}
+
+// statementSummary 语句的第一行，用于输出标题
+func statementSummary(statement string) string {
+	line := strings.TrimSpace(strings.SplitN(statement, "\n", 2)[0])
+	runes := []rune(line)
+	if len(runes) > 80 {
+		line = string(runes[:80]) + "..."
+	}
+	if strings.Contains(statement, "\n") && !strings.HasSuffix(line, "...") {
+		line += " ..."
+	}
+	return line
+}
@@ -0,0 +1,221 @@
+package sqlrunner
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+func sqliteSource(t *testing.T) Source {
+	t.Helper()
+	setSqliteDir(t, t.TempDir())
+	return Source{Driver: DriverSqlite, Database: "jobs.db"}
+}
+
+func setSqliteDir(t *testing.T, dir string) {
+	t.Helper()
+	original := SqliteDir
+	SqliteDir = dir
+	t.Cleanup(func() { SqliteDir = original })
+}
+
+func TestRun(t *testing.T) {
+	source := sqliteSource(t)
+	output, err := Run(context.Background(), Job{
+		Source: source,
+		Query: `CREATE TABLE orders (id INTEGER PRIMARY KEY, name TEXT, note TEXT);
+			INSERT INTO orders (name, note) VALUES ('a;b', NULL), ('it''s', 'x'), ('c', 'multi
+line');
+			-- 只保留前两行
+			SELECT id, name, note FROM orders ORDER BY id;`,
+		MaxRows:    2,
+		Assertions: []Assertion{{Target: TargetAffected, Op: "==", Value: 3}, {Target: TargetRows, Op: ">=", Value: 3}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v\n%s", err, output)
+	}
+	for _, want := range []string{
+		"[1] CREATE TABLE orders",
+		"3 rows affected",
+		"id | name | note\n---+------+-----\n1  | a;b  | NULL\n2  | it's | x\n",
+		"(showing 2 of 3 rows",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "multi") {
+		t.Errorf("rows beyond the limit should not be rendered:\n%s", output)
+	}
+}
+
+func TestRunTransactionRollsBackOnAssertionFailure(t *testing.T) {
+	source := sqliteSource(t)
+	if _, err := Run(context.Background(), Job{Source: source, Query: "CREATE TABLE t (id INTEGER); INSERT INTO t VALUES (1), (2)"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 删除的行数超出预期时回滚
+	output, err := Run(context.Background(), Job{
+		Source:      source,
+		Query:       "DELETE FROM t",
+		Transaction: true,
+		Assertions:  []Assertion{{Target: TargetAffected, Op: "<=", Value: 1}},
+	})
+	if err == nil || err.Error() != "assertion failed: affected <= 1: actual 2" || !strings.Contains(output, "Transaction rolled back") {
+		t.Fatalf("expected assertion failure, got %v\n%s", err, output)
+	}
+	output, err = Run(context.Background(), Job{
+		Source:     source,
+		Query:      "SELECT count(*) AS total FROM t",
+		Assertions: []Assertion{{Target: TargetRows, Op: "==", Value: 1}},
+	})
+	if err != nil || !strings.Contains(output, "total\n-----\n2\n") {
+		t.Fatalf("rows should be kept after rollback, got %v\n%s", err, output)
+	}
+
+	// 语句出错时回滚之前的修改
+	output, err = Run(context.Background(), Job{Source: source, Query: "DELETE FROM t; SELECT * FROM missing", Transaction: true})
+	if err == nil || !strings.Contains(err.Error(), "statement 2:") || !strings.Contains(output, "ERROR:") {
+		t.Fatalf("expected statement error, got %v\n%s", err, output)
+	}
+	output, _ = Run(context.Background(), Job{Source: source, Query: "SELECT count(*) FROM t"})
+	if !strings.Contains(output, "\n2\n") {
+		t.Fatalf("delete should be rolled back:\n%s", output)
+	}
+
+	if _, err := Run(context.Background(), Job{Source: source, Query: "DELETE FROM t WHERE id = 0",
+		Assertions: []Assertion{{Target: TargetRows, Op: "==", Value: 0}}}); err == nil || !strings.Contains(err.Error(), "no query statement") {
+		t.Fatalf("rows assertion requires a query, got %v", err)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, Job{Source: sqliteSource(t), Query: "SELECT 1"}); err != context.Canceled {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+}
+
+func TestCommandRoundTrip(t *testing.T) {
+	source := sqliteSource(t)
+	command, err := EncodeCommand(Job{Source: source, Query: "SELECT 'a&b<c>' AS v"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 节点收到命令后会清理 HTML 实体
+	command = utils.CleanHTMLEntities(command)
+	if !strings.HasPrefix(command, Command+"\n") {
+		t.Fatalf("unexpected command %q", command)
+	}
+	output, err := RunCommand(context.Background(), strings.TrimPrefix(command, Command))
+	if err != nil || !strings.Contains(output, "a&b<c>") {
+		t.Fatalf("unexpected output %v\n%s", err, output)
+	}
+}
+
+func TestSourceDSN(t *testing.T) {
+	setSqliteDir(t, "/data")
+	tests := []struct {
+		source Source
+		driver string
+		dsn    string
+	}{
+		{Source{Driver: DriverMysql, Host: "db1", User: "app", Password: "p@ss:w/rd", Database: "shop", Params: "tls=skip-verify"},
+			"mysql", "app:p@ss:w/rd@tcp(db1:3306)/shop?tls=skip-verify"},
+		{Source{Driver: DriverPostgres, Host: "pg", Port: 6432, User: "app", Password: "p@ss/word", Database: "shop", Params: "sslmode=require"},
+			"pgx", "postgres://app:p%40ss%2Fword@pg:6432/shop?sslmode=require"},
+		{Source{Driver: DriverSqlite, Database: "app.db"}, "sqlite3", "/data/app.db"},
+		{Source{Driver: DriverSqlite, Database: "reports/app.db", Params: "mode=ro"}, "sqlite3", "file:/data/reports/app.db?mode=ro"},
+	}
+	for _, tt := range tests {
+		driver, dsn, err := tt.source.dsn()
+		if err != nil || driver != tt.driver || dsn != tt.dsn {
+			t.Errorf("dsn(%+v) = %s %s %v", tt.source, driver, dsn, err)
+		}
+	}
+	invalid := []Source{
+		{Driver: "oracle", Host: "db"},
+		{Driver: DriverMysql},
+		{Driver: DriverMysql, Host: "db", Params: "timeout=abc"},
+		{Driver: DriverPostgres, Host: "db", Params: "a=%zz"},
+		{Driver: DriverSqlite},
+		// 只允许 SqliteDir 下的文件
+		{Driver: DriverSqlite, Database: "/var/lib/gocron/gocron.db"},
+		{Driver: DriverSqlite, Database: "../gocron.db"},
+		{Driver: DriverSqlite, Database: "app.db?mode=rwc"},
+	}
+	for _, source := range invalid {
+		if err := source.Validate(); err == nil {
+			t.Errorf("expected error for %+v", source)
+		}
+	}
+
+	// 没有配置 SqliteDir 时可以保存数据源，但不能执行
+	setSqliteDir(t, "")
+	source := Source{Driver: DriverSqlite, Database: "app.db"}
+	if err := source.Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, _, err := source.dsn(); err == nil {
+		t.Error("expected sqlite to be disabled without a sqlite dir")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	query := `UPDATE t SET a = 'x;y' WHERE b = "c;d"; -- comment; here
+		/* block; comment */ INSERT INTO t VALUES ('it''s');
+		CREATE FUNCTION f() RETURNS int AS $body$ BEGIN; RETURN 1; END; $body$ LANGUAGE plpgsql;
+		SELECT $1;
+		-- trailing comment only`
+	got := splitStatements(query, false)
+	want := []string{
+		`UPDATE t SET a = 'x;y' WHERE b = "c;d"`,
+		"-- comment; here\n\t\t/* block; comment */ INSERT INTO t VALUES ('it''s')",
+		"CREATE FUNCTION f() RETURNS int AS $body$ BEGIN; RETURN 1; END; $body$ LANGUAGE plpgsql",
+		"SELECT $1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected statements %#v", got)
+	}
+
+	// MySQL 字符串中的反斜杠转义
+	got = splitStatements(`SELECT 'a\';b'; SELECT 2`, true)
+	if len(got) != 2 || got[0] != `SELECT 'a\';b'` {
+		t.Fatalf("unexpected statements %#v", got)
+	}
+}
+
+func TestIsQuery(t *testing.T) {
+	queries := []string{"select 1", "  (SELECT 1) UNION (SELECT 2)", "-- c\nWITH x AS (SELECT 1) SELECT * FROM x",
+		"SHOW TABLES", "explain select 1", "PRAGMA table_info(t)", "DELETE FROM t RETURNING id"}
+	for _, q := range queries {
+		if !isQuery(q) {
+			t.Errorf("%q should be a query", q)
+		}
+	}
+	for _, q := range []string{"UPDATE t SET a = 'returning'", "INSERT INTO t SELECT 1", "/* select */ DELETE FROM t"} {
+		if isQuery(q) {
+			t.Errorf("%q should not be a query", q)
+		}
+	}
+}
+
+func TestParseAssertions(t *testing.T) {
+	assertions, err := ParseAssertions(`[{"target":"rows","op":">","value":0},{"target":"affected","op":"==","value":5}]`)
+	if err != nil || len(assertions) != 2 || assertions[1].Value != 5 {
+		t.Fatalf("unexpected assertions %+v %v", assertions, err)
+	}
+	if assertions, err := ParseAssertions(" "); err != nil || assertions != nil {
+		t.Fatalf("empty assertions should be allowed, got %+v %v", assertions, err)
+	}
+	for _, s := range []string{`{}`, `[{"target":"columns","op":"==","value":1}]`, `[{"target":"rows","op":"~","value":1}]`} {
+		if _, err := ParseAssertions(s); err == nil {
+			t.Errorf("expected error for %s", s)
+		}
+	}
+}
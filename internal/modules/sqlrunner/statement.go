@@ -0,0 +1,161 @@
+package sqlrunner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// queryKeywords 返回结果集的语句
+var queryKeywords = map[string]bool{
+	"SELECT": true, "WITH": true, "SHOW": true, "DESCRIBE": true, "DESC": true,
+	"EXPLAIN": true, "PRAGMA": true, "VALUES": true, "TABLE": true,
+}
+
+var returningPattern = regexp.MustCompile(`(?i)\bRETURNING\b`)
+
+// isQuery 按第一个关键字判断语句是否返回结果集，带 RETURNING 的 DML 也按查询执行
+func isQuery(statement string) bool {
+	text := strings.TrimLeft(stripComments(statement), " \t\r\n(")
+	end := strings.IndexFunc(text, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+	if end < 0 {
+		end = len(text)
+	}
+	if queryKeywords[strings.ToUpper(text[:end])] {
+		return true
+	}
+	// 只检查代码部分，字符串中的 returning 不算
+	var code strings.Builder
+	scanSQL(statement, false, func(text string, isCode bool) {
+		if isCode {
+			code.WriteString(text)
+		}
+		code.WriteString(" ")
+	})
+	return returningPattern.MatchString(code.String())
+}
+
+// splitStatements 按分号拆分语句，忽略字符串、引用标识符、注释和 PostgreSQL $tag$ 字符串中的分号。
+// backslashEscapes 为 true 时（MySQL）字符串中的反斜杠转义下一个字符
+func splitStatements(query string, backslashEscapes bool) []string {
+	statements := make([]string, 0)
+	var current strings.Builder
+	flush := func() {
+		statement := strings.TrimSpace(current.String())
+		current.Reset()
+		if strings.TrimSpace(stripComments(statement)) != "" {
+			statements = append(statements, statement)
+		}
+	}
+	scanSQL(query, backslashEscapes, func(text string, code bool) {
+		if !code {
+			current.WriteString(text)
+			return
+		}
+		for {
+			i := strings.IndexByte(text, ';')
+			if i < 0 {
+				current.WriteString(text)
+				return
+			}
+			current.WriteString(text[:i])
+			flush()
+			text = text[i+1:]
+		}
+	})
+	flush()
+
+	return statements
+}
+
+// stripComments 去掉注释，保留字符串内容
+func stripComments(statement string) string {
+	var b strings.Builder
+	scanSQL(statement, false, func(text string, code bool) {
+		if strings.HasPrefix(text, "--") || strings.HasPrefix(text, "/*") {
+			b.WriteString(" ")
+			return
+		}
+		b.WriteString(text)
+	})
+	return b.String()
+}
+
+// scanSQL 把语句切分为代码片段和字面量片段（字符串、引用标识符、注释），依次回调
+func scanSQL(query string, backslashEscapes bool, emit func(text string, code bool)) {
+	start := 0
+	i := 0
+	for i < len(query) {
+		var end int
+		switch c := query[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			end = quotedEnd(query, i, c, backslashEscapes && c != '`')
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end = strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query)
+			} else {
+				end += i + 1
+			}
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end = strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query)
+			} else {
+				end += i + 4
+			}
+		case c == '$':
+			end = dollarQuotedEnd(query, i)
+		default:
+			i++
+			continue
+		}
+		if end <= i {
+			i++
+			continue
+		}
+		if start < i {
+			emit(query[start:i], true)
+		}
+		emit(query[i:end], false)
+		start, i = end, end
+	}
+	if start < len(query) {
+		emit(query[start:], true)
+	}
+}
+
+// quotedEnd 返回引号结束后的位置，连续两个引号表示转义
+func quotedEnd(query string, start int, quote byte, backslashEscapes bool) int {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+var dollarTagPattern = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// dollarQuotedEnd PostgreSQL 的 $tag$...$tag$ 字符串，不是 $tag$ 时返回 start
+func dollarQuotedEnd(query string, start int) int {
+	tag := dollarTagPattern.FindString(query[start:])
+	if tag == "" {
+		return start
+	}
+	end := strings.Index(query[start+len(tag):], tag)
+	if end < 0 {
+		return len(query)
+	}
+	return start + len(tag) + end + len(tag)
+}
@@ -0,0 +1,331 @@
+package sshclient
+
+// 通过 SSH 在无法运行 gocron-node 的主机上执行命令。
+// 命令以 "echo $$; exec /bin/sh -c '...'" 的形式启动，sshd 为会话创建新的进程组，
+// 输出的第一行即进程组 id，超时或手动停止时通过另一个会话结束整个进程组
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	DefaultPort = 22
+	// dialTimeout 建立连接和完成握手的超时时间
+	dialTimeout = 10 * time.Second
+	// killGracePeriod 发送 SIGTERM 后等待进程退出的时间，之后发送 SIGKILL
+	killGracePeriod = 2 * time.Second
+	// maxOutputSize 保留的输出大小，超出部分丢弃
+	maxOutputSize = 1 << 20
+)
+
+var (
+	ErrManualStop = errors.New("ssh_manual_stop") // 特殊错误标识，用于判断是否手动停止
+	ErrTimeout    = errors.New("timeout killed")
+	// ErrHostKeyMismatch 主机公钥与保存的不一致，可能是中间人攻击或主机重装，需要在主机设置中清空后重新获取
+	ErrHostKeyMismatch = errors.New("ssh host key mismatch")
+)
+
+// Config SSH 连接配置，密码和私钥已从密钥库取出
+type Config struct {
+	Host string
+	Port int
+	User string
+	// Password、PrivateKey 至少配置一个，都配置时先尝试私钥
+	Password   string
+	PrivateKey string
+	// HostKey 保存的主机公钥（authorized_keys 格式），为空时接受首次连接的公钥
+	HostKey string
+}
+
+// Result 执行结果
+type Result struct {
+	Output string
+	// HostKey 本次连接的主机公钥，首次连接时由调用方保存
+	HostKey string
+}
+
+// Validate 检查连接配置和私钥格式
+func (c Config) Validate() error {
+	if strings.TrimSpace(c.Host) == "" {
+		return errors.New("host is required")
+	}
+	if strings.TrimSpace(c.User) == "" {
+		return errors.New("ssh user is required")
+	}
+	if c.Password == "" && c.PrivateKey == "" {
+		return errors.New("ssh password or private key is required")
+	}
+	if c.PrivateKey != "" {
+		if _, err := ssh.ParsePrivateKey([]byte(c.PrivateKey)); err != nil {
+			return fmt.Errorf("invalid ssh private key: %w", err)
+		}
+	}
+	if c.HostKey != "" {
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(c.HostKey)); err != nil {
+			return fmt.Errorf("invalid ssh host key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c Config) address() string {
+	port := c.Port
+	if port <= 0 {
+		port = DefaultPort
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(port))
+}
+
+// dial 建立连接，返回连接和主机公钥
+func dial(ctx context.Context, c Config) (*ssh.Client, string, error) {
+	if err := c.Validate(); err != nil {
+		return nil, "", err
+	}
+	auths := make([]ssh.AuthMethod, 0, 2)
+	if c.PrivateKey != "" {
+		signer, _ := ssh.ParsePrivateKey([]byte(c.PrivateKey))
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+	if c.Password != "" {
+		auths = append(auths, ssh.Password(c.Password))
+	}
+	var hostKey string
+	config := &ssh.ClientConfig{
+		User: c.User,
+		Auth: auths,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			hostKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+			if c.HostKey == "" {
+				return nil
+			}
+			expected, _, _, _, err := ssh.ParseAuthorizedKey([]byte(c.HostKey))
+			if err != nil || !bytes.Equal(expected.Marshal(), key.Marshal()) {
+				return fmt.Errorf("%w: got %s %s", ErrHostKeyMismatch, key.Type(), ssh.FingerprintSHA256(key))
+			}
+			return nil
+		},
+		Timeout: dialTimeout,
+	}
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address())
+	if err != nil {
+		return nil, "", err
+	}
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, c.address(), config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, hostKey, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(sshConn, chans, reqs), hostKey, nil
+}
+
+// Ping 测试连接和认证，返回主机公钥
+func Ping(c Config) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	client, hostKey, err := dial(ctx, c)
+	if err != nil {
+		return hostKey, err
+	}
+	_ = client.Close()
+
+	return hostKey, nil
+}
+
+// runningSessions 运行中的命令，key 为 taskKey 生成的标识，值为 context.CancelFunc
+var runningSessions sync.Map
+
+// taskKey 同一次执行可能在多台主机上运行，按主机地址区分
+func taskKey(c Config, id int64) string {
+	return fmt.Sprintf("%s#%d", c.address(), id)
+}
+
+// Stop 手动停止运行中的命令，命令不在本进程中运行时返回 false
+func Stop(host string, port int, id int64) bool {
+	cancel, ok := runningSessions.Load(taskKey(Config{Host: host, Port: port}, id))
+	if ok {
+		cancel.(context.CancelFunc)()
+	}
+	return ok
+}
+
+// Exec 执行命令，返回标准输出和标准错误合并后的内容。
+// timeout 为 0 时不限制执行时间，id 用于手动停止
+func Exec(c Config, command string, timeout int, id int64) (Result, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	key := taskKey(c, id)
+	manual := make(chan struct{})
+	var stopOnce sync.Once
+	runningSessions.Store(key, context.CancelFunc(func() {
+		stopOnce.Do(func() { close(manual) })
+		cancel()
+	}))
+	defer runningSessions.Delete(key)
+	if timeout > 0 {
+		var timeoutCancel context.CancelFunc
+		ctx, timeoutCancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer timeoutCancel()
+	}
+
+	// stopReason 区分手动停止和超时
+	stopReason := func() error {
+		select {
+		case <-manual:
+			return ErrManualStop
+		default:
+			return ErrTimeout
+		}
+	}
+
+	client, hostKey, err := dial(ctx, c)
+	result := Result{HostKey: hostKey}
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, stopReason()
+		}
+		return result, fmt.Errorf("ssh connect %s: %w", c.address(), err)
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		return result, fmt.Errorf("ssh session: %w", err)
+	}
+	defer session.Close()
+
+	output := &limitedBuffer{limit: maxOutputSize}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return result, err
+	}
+	session.Stderr = output
+	if err := session.Start(wrapCommand(command)); err != nil {
+		return result, fmt.Errorf("ssh start: %w", err)
+	}
+
+	// 第一行为进程组 id，其余内容写入输出
+	pgid := make(chan int, 1)
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		reader := bufio.NewReader(stdout)
+		line, err := reader.ReadString('\n')
+		id, _ := strconv.Atoi(strings.TrimSpace(line))
+		pgid <- id
+		if err == nil {
+			_, _ = io.Copy(output, reader)
+		}
+	}()
+	done := make(chan error, 1)
+	go func() {
+		<-copied
+		done <- session.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// 命令刚启动时进程组 id 可能还没有输出
+		select {
+		case id := <-pgid:
+			killProcessGroup(client, id, done)
+		case <-time.After(killGracePeriod):
+		}
+		err = stopReason()
+	}
+	result.Output = output.String()
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.Signal() != "" {
+			err = fmt.Errorf("signal: %s", exitErr.Signal())
+		} else {
+			err = fmt.Errorf("exit status %d", exitErr.ExitStatus())
+		}
+	} else if errors.Is(err, io.EOF) || isMissingExit(err) {
+		err = errors.New("ssh connection closed before the command exited")
+	}
+
+	return result, err
+}
+
+// killProcessGroup 先发送 SIGTERM，命令未在宽限时间内退出时发送 SIGKILL
+func killProcessGroup(client *ssh.Client, pgid int, done <-chan error) {
+	if pgid <= 1 {
+		return
+	}
+	signal := func(sig string) {
+		session, err := client.NewSession()
+		if err != nil {
+			return
+		}
+		defer session.Close()
+		_ = session.Run(fmt.Sprintf("kill -%s -%d 2>/dev/null", sig, pgid))
+	}
+	signal("TERM")
+	timer := time.NewTimer(killGracePeriod)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		signal("KILL")
+	}
+}
+
+// wrapCommand 输出进程组 id 后执行命令。sshd 为每个会话创建新的会话和进程组，组 id 即 shell 的 pid
+func wrapCommand(command string) string {
+	command = strings.ReplaceAll(command, "\r\n", "\n")
+	return "echo $$; exec /bin/sh -c '" + strings.ReplaceAll(command, "'", `'\''`) + "'"
+}
+
+func isMissingExit(err error) bool {
+	var missing *ssh.ExitMissingError
+	return errors.As(err, &missing)
+}
+
+// limitedBuffer 并发安全，超出 limit 的内容丢弃并在末尾提示
+type limitedBuffer struct {
+	sync.Mutex
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	if remain := b.limit - b.buf.Len(); remain < len(p) {
+		b.truncated = true
+		if remain > 0 {
+			b.buf.Write(p[:remain])
+		}
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	if b.truncated {
+		return b.buf.String() + "\n... output truncated"
+	}
+	return b.buf.String()
+}
@@ -0,0 +1,272 @@
+//go:build !windows
+// +build !windows
+
+package sshclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os/exec"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer 进程内 SSH 服务器，exec 请求通过 /bin/sh 在新的进程组中执行，与 sshd 的行为一致
+type testServer struct {
+	host    string
+	port    int
+	hostKey ssh.Signer
+	started chan string
+	exited  chan string
+}
+
+func newSigner(t *testing.T) (ssh.Signer, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func newTestServer(t *testing.T, password string, authorized ssh.PublicKey) *testServer {
+	t.Helper()
+	hostKey, _ := newSigner(t)
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if conn.User() == "deploy" && string(pass) == password {
+				return nil, nil
+			}
+			return nil, errors.New("access denied")
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if authorized != nil && conn.User() == "deploy" && string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("access denied")
+		},
+	}
+	config.AddHostKey(hostKey)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	addr := listener.Addr().(*net.TCPAddr)
+	server := &testServer{
+		host:    addr.IP.String(),
+		port:    addr.Port,
+		hostKey: hostKey,
+		started: make(chan string, 10),
+		exited:  make(chan string, 10),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn, config)
+		}
+	}()
+
+	return server
+}
+
+func (s *testServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.session(channel, requests)
+	}
+}
+
+func (s *testServer) session(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for req := range requests {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		var payload struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+			_ = req.Reply(false, nil)
+			return
+		}
+		_ = req.Reply(true, nil)
+		cmd := exec.Command("/bin/sh", "-c", payload.Command)
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		cmd.Stdout = channel
+		cmd.Stderr = channel.Stderr()
+		var status uint32
+		if err := cmd.Start(); err != nil {
+			status = 127
+		} else {
+			s.started <- payload.Command
+			if err := cmd.Wait(); err != nil {
+				status = 1
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+					status = uint32(exitErr.ExitCode())
+				}
+			}
+			s.exited <- payload.Command
+		}
+		_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		return
+	}
+}
+
+func (s *testServer) config(password string) Config {
+	return Config{Host: s.host, Port: s.port, User: "deploy", Password: password}
+}
+
+// waitExited 等待服务器上以 marker 结尾的命令退出
+func (s *testServer) waitExited(t *testing.T, marker string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case command := <-s.exited:
+			if strings.Contains(command, marker) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("remote command %q was not killed", marker)
+		}
+	}
+}
+
+func TestExecWithPasswordAndKey(t *testing.T) {
+	signer, privateKey := newSigner(t)
+	server := newTestServer(t, "pa55word", signer.PublicKey())
+
+	result, err := Exec(server.config("pa55word"), "echo hello; echo oops >&2; exit 3", 10, 1)
+	if err == nil || err.Error() != "exit status 3" {
+		t.Fatalf("expected exit status 3, got %v", err)
+	}
+	// 标准输出和标准错误都保留，进程组 id 不出现在输出中
+	lines := strings.Fields(result.Output)
+	sort.Strings(lines)
+	if strings.Join(lines, ",") != "hello,oops" {
+		t.Fatalf("unexpected output %q", result.Output)
+	}
+	expectedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(server.hostKey.PublicKey())))
+	if result.HostKey != expectedKey {
+		t.Fatalf("unexpected host key %q", result.HostKey)
+	}
+
+	// 私钥认证，单引号原样传给远端 shell
+	config := Config{Host: server.host, Port: server.port, User: "deploy", PrivateKey: privateKey, HostKey: result.HostKey}
+	result, err = Exec(config, `echo 'it''s ok'`, 10, 2)
+	if err != nil || strings.TrimSpace(result.Output) != "its ok" {
+		t.Fatalf("key auth failed: %q %v", result.Output, err)
+	}
+
+	if _, err := Exec(server.config("wrong"), "true", 10, 3); err == nil || !strings.Contains(err.Error(), "unable to authenticate") {
+		t.Fatalf("expected authentication failure, got %v", err)
+	}
+
+	// 主机公钥变化时拒绝连接
+	other, _ := newSigner(t)
+	config.HostKey = string(ssh.MarshalAuthorizedKey(other.PublicKey()))
+	if _, err := Exec(config, "true", 10, 4); !errors.Is(err, ErrHostKeyMismatch) {
+		t.Fatalf("expected host key mismatch, got %v", err)
+	}
+}
+
+func TestExecTimeoutKillsProcessGroup(t *testing.T) {
+	server := newTestServer(t, "pa55word", nil)
+	start := time.Now()
+	// 子进程在同一进程组中，超时后一起结束
+	result, err := Exec(server.config("pa55word"), "echo begin; sleep 30 & wait; echo timeout-marker", 1, 5)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 6*time.Second {
+		t.Fatalf("timeout took too long: %s", elapsed)
+	}
+	if strings.TrimSpace(result.Output) != "begin" {
+		t.Fatalf("output before the timeout should be kept, got %q", result.Output)
+	}
+	server.waitExited(t, "timeout-marker")
+}
+
+func TestStopKillsRunningCommand(t *testing.T) {
+	server := newTestServer(t, "pa55word", nil)
+	config := server.config("pa55word")
+	done := make(chan error, 1)
+	go func() {
+		_, err := Exec(config, "sleep 30; echo stop-marker", 0, 42)
+		done <- err
+	}()
+	select {
+	case <-server.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not start")
+	}
+
+	if Stop(config.Host, config.Port, 41) {
+		t.Fatal("stop should only match the same execution")
+	}
+	if !Stop(config.Host, config.Port, 42) {
+		t.Fatal("running command should be found")
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrManualStop) {
+			t.Fatalf("expected manual stop, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop did not end the command")
+	}
+	server.waitExited(t, "stop-marker")
+}
+
+func TestConfigValidate(t *testing.T) {
+	_, privateKey := newSigner(t)
+	invalid := map[string]Config{
+		"host is required":     {User: "deploy", Password: "x"},
+		"ssh user is required": {Host: "db1", Password: "x"},
+		"password or private":  {Host: "db1", User: "deploy"},
+		"invalid ssh private":  {Host: "db1", User: "deploy", PrivateKey: "garbage"},
+		"invalid ssh host key": {Host: "db1", User: "deploy", PrivateKey: privateKey, HostKey: "garbage"},
+	}
+	for want, config := range invalid {
+		if err := config.Validate(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+	if (Config{Host: "db1", Port: 2222}).address() != "db1:2222" || (Config{Host: "db1"}).address() != "db1:22" {
+		t.Error("unexpected address")
+	}
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncryptString(t *testing.T) {
+	key := DeriveKey("test-secret")
+	encrypted, err := EncryptString(key, "db-pa55word")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encrypted, encryptedPrefix) || strings.Contains(encrypted, "pa55word") {
+		t.Fatalf("unexpected ciphertext %q", encrypted)
+	}
+	again, _ := EncryptString(key, "db-pa55word")
+	if again == encrypted {
+		t.Fatal("nonce should differ between calls")
+	}
+	if plain, err := DecryptString(key, encrypted); err != nil || plain != "db-pa55word" {
+		t.Fatalf("decrypt = %q, %v", plain, err)
+	}
+
+	// 密钥不同时无法解密
+	if _, err := DecryptString(DeriveKey("other"), encrypted); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected decrypt error, got %v", err)
+	}
+	// 空值和旧的明文值原样返回
+	if empty, _ := EncryptString(key, ""); empty != "" {
+		t.Fatalf("empty value should stay empty, got %q", empty)
+	}
+	if plain, err := DecryptString(key, "legacy"); err != nil || plain != "legacy" {
+		t.Fatalf("plaintext should be returned as is, got %q %v", plain, err)
+	}
+}
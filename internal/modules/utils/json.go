@@ -44,6 +44,10 @@ func (j *JsonResponse) Failure(code int, message string) string {
 	return j.response(code, message, nil)
 }
 
+func (j *JsonResponse) FailureWithData(message string, data interface{}) string {
+	return j.response(ResponseFailure, message, data)
+}
+
 func (j *JsonResponse) CommonFailure(message string, err ...error) string {
 	if len(err) > 0 {
 		logger.Warn(err)
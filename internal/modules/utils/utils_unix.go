@@ -24,6 +24,11 @@ type Result struct {
 // 执行shell命令，可设置执行超时时间
 // 改进：将命令写入临时脚本执行，即使超时或被取消，也会返回已产生的输出
 func ExecShell(ctx context.Context, command string) (string, error) {
+	return ExecShellInDir(ctx, "", command)
+}
+
+// ExecShellInDir 在指定目录执行shell命令，dir 为空时使用用户家目录
+func ExecShellInDir(ctx context.Context, dir, command string) (string, error) {
 	// 清理可能存在的 HTML 实体编码
 	command = CleanHTMLEntities(command)
 	// 将换行符统一替换为Unix风格的\n
@@ -65,8 +70,10 @@ func ExecShell(ctx context.Context, command string) (string, error) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		Setpgid: true,
 	}
-	// 设置工作目录为用户家目录，避免 getcwd 错误
-	if homeDir, err := os.UserHomeDir(); err == nil {
+	// 未指定时设置工作目录为用户家目录，避免 getcwd 错误
+	if dir != "" {
+		cmd.Dir = dir
+	} else if homeDir, err := os.UserHomeDir(); err == nil {
 		cmd.Dir = homeDir
 	} else {
 		cmd.Dir = tmpDir
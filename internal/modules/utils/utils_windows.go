@@ -29,6 +29,11 @@ type Result struct {
 // 执行shell命令，可设置执行超时时间
 // 改进：将命令写入临时批处理文件执行，即使超时或被取消，也会返回已产生的输出
 func ExecShell(ctx context.Context, command string) (string, error) {
+	return ExecShellInDir(ctx, "", command)
+}
+
+// ExecShellInDir 在指定目录执行命令，dir 为空时使用用户家目录
+func ExecShellInDir(ctx context.Context, dir, command string) (string, error) {
 	// 清理可能存在的 HTML 实体编码,防止 &quot; 等导致命令执行失败
 	// 例如: del &quot;C:\file.txt&quot; -> del "C:\file.txt"
 	command = CleanHTMLEntities(command)
@@ -71,8 +76,10 @@ func ExecShell(ctx context.Context, command string) (string, error) {
 		HideWindow: true,
 		CmdLine:    `cmd /c "` + batFile.Name() + `"`,
 	}
-	// 设置工作目录为用户家目录，避免 getcwd 错误
-	if homeDir, err := os.UserHomeDir(); err == nil {
+	// 未指定时设置工作目录为用户家目录，避免 getcwd 错误
+	if dir != "" {
+		cmd.Dir = dir
+	} else if homeDir, err := os.UserHomeDir(); err == nil {
 		cmd.Dir = homeDir
 	} else {
 		cmd.Dir = os.TempDir()
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"github.com/gocronx-team/gocron/internal/modules/i18n"
 	"github.com/gocronx-team/gocron/internal/modules/logger"
 	"github.com/gocronx-team/gocron/internal/routers/base"
+	"github.com/gocronx-team/gocron/internal/service"
 )
 
 const tokenExpiration = 3 * time.Hour
@@ -174,6 +176,25 @@ else
     exit 1
 fi
 
+# 服务端启用内置 CA 时，注册响应中包含为本节点签发的证书（base64）
+NODE_ARGS=""
+if echo "$RESPONSE" | grep -q '"tls":true'; then
+    CERT_DIR="$INSTALL_DIR/certs"
+    json_field() {
+        echo "$RESPONSE" | sed -n "s/.*\"$1\":\"\([^\"]*\)\".*/\1/p" | base64 --decode
+    }
+    sudo mkdir -p "$CERT_DIR"
+    sudo chown "$(whoami)" "$CERT_DIR"
+    chmod 700 "$CERT_DIR"
+    json_field ca_cert > "$CERT_DIR/ca.crt"
+    json_field cert > "$CERT_DIR/node.crt"
+    json_field key > "$CERT_DIR/node.key"
+    json_field crl > "$CERT_DIR/crl.pem"
+    chmod 600 "$CERT_DIR/node.key"
+    NODE_ARGS="-enable-tls -ca-file $CERT_DIR/ca.crt -cert-file $CERT_DIR/node.crt -key-file $CERT_DIR/node.key -crl-file $CERT_DIR/crl.pem"
+    echo "✓ TLS certificates written to $CERT_DIR"
+fi
+
 if [ "$OS" = "linux" ]; then
     sudo tee /etc/systemd/system/${SERVICE_NAME}.service > /dev/null <<EOF
 [Unit]
@@ -184,7 +205,7 @@ After=network.target
 Type=simple
 User=$(whoami)
 WorkingDirectory=$INSTALL_DIR
-ExecStart=$INSTALL_DIR/gocron-node
+ExecStart=$INSTALL_DIR/gocron-node $NODE_ARGS
 Restart=on-failure
 RestartSec=5s
 
@@ -201,7 +222,7 @@ elif [ "$OS" = "darwin" ]; then
     # 先停止已存在的进程
     pkill -f gocron-node 2>/dev/null || true
     sleep 1
-    nohup $INSTALL_DIR/gocron-node > /tmp/gocron-node.log 2>&1 &
+    nohup $INSTALL_DIR/gocron-node $NODE_ARGS > /tmp/gocron-node.log 2>&1 &
     echo "gocron-node started in background (PID: $!)"
     echo "Log file: /tmp/gocron-node.log"
 fi
@@ -231,7 +252,7 @@ if [ "$OS" = "linux" ]; then
     echo "  sudo rm -rf ${INSTALL_DIR}"
 elif [ "$OS" = "darwin" ]; then
     echo "  Stop:    pkill -f gocron-node"
-    echo "  Start:   nohup ${INSTALL_DIR}/gocron-node > /tmp/gocron-node.log 2>&1 &"
+    echo "  Start:   nohup ${INSTALL_DIR}/gocron-node ${NODE_ARGS} > /tmp/gocron-node.log 2>&1 &"
     echo "  Logs:    tail -f /tmp/gocron-node.log"
     echo "  Status:  ps aux | grep gocron-node | grep -v grep"
     echo ""
@@ -311,9 +332,31 @@ func Register(c *gin.Context) {
 		logger.Infof("主机注册成功: %s", req.Hostname)
 	} else {
 		logger.Infof("主机已存在，跳过创建: %s", req.Hostname)
+		if err := host.FindByName(req.Hostname); err != nil {
+			base.RespondError(c, "Operation failed", err)
+			return
+		}
 	}
 
-	base.RespondSuccess(c, "Registration successful", nil)
+	if !service.CertManager.Enabled() {
+		base.RespondSuccess(c, "Registration successful", nil)
+		return
+	}
+
+	// 内置 CA：为节点签发证书，由安装脚本写入节点并以 TLS 模式启动
+	bundle, err := service.CertManager.IssueForHost(*host)
+	if err != nil {
+		logger.Error("签发节点证书失败:", err)
+		base.RespondError(c, "Failed to issue certificate", err)
+		return
+	}
+	base.RespondSuccess(c, "Registration successful", map[string]interface{}{
+		"tls":     true,
+		"ca_cert": base64.StdEncoding.EncodeToString([]byte(bundle.CACert)),
+		"cert":    base64.StdEncoding.EncodeToString([]byte(bundle.Cert)),
+		"key":     base64.StdEncoding.EncodeToString([]byte(bundle.Key)),
+		"crl":     base64.StdEncoding.EncodeToString([]byte(bundle.CRL)),
+	})
 }
 
 // Download 优先从本地 gocron-node-package 目录下载，如果不存在则重定向到 GitHub Release
@@ -32,6 +32,13 @@ func RespondError(c *gin.Context, message string, err ...error) {
 	c.String(http.StatusOK, result)
 }
 
+// RespondErrorWithData 返回带数据的错误响应（如逐项的校验错误）
+func RespondErrorWithData(c *gin.Context, message string, data interface{}) {
+	json := utils.JsonResponse{}
+	result := json.FailureWithData(message, data)
+	c.String(http.StatusOK, result)
+}
+
 // RespondErrorWithDefaultMsg 返回错误响应（使用默认消息）
 func RespondErrorWithDefaultMsg(c *gin.Context, err ...error) {
 	json := utils.JsonResponse{}
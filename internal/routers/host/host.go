@@ -34,6 +34,7 @@ func Index(c *gin.Context) {
 		base.RespondErrorWithDefaultMsg(c, err)
 		return
 	}
+	attachCertExpiry(hosts)
 
 	base.RespondSuccess(c, utils.SuccessContent, map[string]interface{}{
 		"total": total,
@@ -72,6 +73,12 @@ type HostForm struct {
 	Alias  string `form:"alias" json:"alias" binding:"required,max=32"`
 	Port   int    `form:"port" json:"port" binding:"required,min=1,max=65535"`
 	Remark string `form:"remark" json:"remark"`
+	// SSH 任务使用的连接信息，密码和私钥填写密钥库中的密钥名称
+	SshPort           int    `form:"ssh_port" json:"ssh_port" binding:"min=0,max=65535"`
+	SshUser           string `form:"ssh_user" json:"ssh_user" binding:"max=64"`
+	SshPasswordSecret string `form:"ssh_password_secret" json:"ssh_password_secret" binding:"max=64"`
+	SshKeySecret      string `form:"ssh_key_secret" json:"ssh_key_secret" binding:"max=64"`
+	SshHostKey        string `form:"ssh_host_key" json:"ssh_host_key" binding:"max=16384"`
 }
 
 // Store 保存、修改主机信息
@@ -98,6 +105,17 @@ func Store(c *gin.Context) {
 	hostModel.Alias = strings.TrimSpace(form.Alias)
 	hostModel.Port = form.Port
 	hostModel.Remark = strings.TrimSpace(form.Remark)
+	hostModel.SshPort = form.SshPort
+	hostModel.SshUser = strings.TrimSpace(form.SshUser)
+	hostModel.SshPasswordSecret = strings.TrimSpace(form.SshPasswordSecret)
+	hostModel.SshKeySecret = strings.TrimSpace(form.SshKeySecret)
+	hostModel.SshHostKey = strings.TrimSpace(form.SshHostKey)
+	if hostModel.SshUser != "" || hostModel.SshPasswordSecret != "" || hostModel.SshKeySecret != "" {
+		if err := service.ValidateSSHHost(*hostModel); err != nil {
+			base.RespondError(c, fmt.Sprintf(i18n.T(c, "ssh_config_invalid"), err.Error()))
+			return
+		}
+	}
 	isCreate := false
 	oldHostModel := new(models.Host)
 
@@ -203,6 +221,79 @@ func Ping(c *gin.Context) {
 	}
 }
 
+// PingSSH 测试主机的 SSH 连接，首次连接成功时保存主机公钥
+func PingSSH(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	hostModel := new(models.Host)
+	err := hostModel.Find(id)
+	if err != nil || hostModel.Id <= 0 {
+		base.RespondError(c, i18n.T(c, "host_not_exist"), err)
+		return
+	}
+	if err := service.PingSSH(*hostModel); err != nil {
+		base.RespondError(c, i18n.T(c, "connection_failed")+"-"+err.Error(), err)
+		return
+	}
+
+	base.RespondSuccess(c, i18n.T(c, "connection_success"), nil)
+}
+
+// RotateCert 立即为节点签发新证书并推送
+func RotateCert(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if !service.CertManager.Enabled() {
+		base.RespondError(c, i18n.T(c, "internal_ca_disabled"))
+		return
+	}
+	cert, err := service.CertManager.RotateHost(id)
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "cert_rotate_failed")+"-"+err.Error(), err)
+		return
+	}
+	c.Set("audit_detail", fmt.Sprintf("serial: %s", cert.Serial))
+
+	base.RespondSuccess(c, i18n.T(c, "cert_rotate_success"), cert)
+}
+
+// RevokeCert 吊销节点证书
+func RevokeCert(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if !service.CertManager.Enabled() {
+		base.RespondError(c, i18n.T(c, "internal_ca_disabled"))
+		return
+	}
+	count, err := service.CertManager.RevokeHost(id)
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "operation_failed"), err)
+		return
+	}
+	c.Set("audit_detail", fmt.Sprintf("revoked: %d", count))
+
+	base.RespondSuccess(c, i18n.T(c, "cert_revoke_success"), nil)
+}
+
+// attachCertExpiry 填充主机当前证书的到期时间
+func attachCertExpiry(hosts []models.Host) {
+	if len(hosts) == 0 {
+		return
+	}
+	hostIds := make([]int, 0, len(hosts))
+	for _, item := range hosts {
+		hostIds = append(hostIds, item.Id)
+	}
+	certModel := new(models.HostCertificate)
+	expiry, err := certModel.LatestNotAfterByHosts(hostIds)
+	if err != nil {
+		logger.Error("获取主机证书到期时间失败", err)
+		return
+	}
+	for i := range hosts {
+		if notAfter, ok := expiry[hosts[i].Id]; ok {
+			hosts[i].CertNotAfter = &notAfter
+		}
+	}
+}
+
 // 解析查询参数
 func parseQueryParams(c *gin.Context) models.CommonMap {
 	var params = models.CommonMap{}
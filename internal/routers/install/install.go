@@ -9,6 +9,7 @@ import (
 	"github.com/go-sql-driver/mysql"
 	"github.com/gocronx-team/gocron/internal/models"
 	"github.com/gocronx-team/gocron/internal/modules/app"
+	"github.com/gocronx-team/gocron/internal/modules/notify"
 	"github.com/gocronx-team/gocron/internal/modules/setting"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 	"github.com/gocronx-team/gocron/internal/routers/base"
@@ -66,6 +67,7 @@ func Store(c *gin.Context) {
 		return
 	}
 	app.Setting = appConfig
+	models.SetCredentialKey(appConfig.SecretKey)
 
 	models.Db = models.CreateDb()
 	// 创建数据库表
@@ -98,6 +100,7 @@ func Store(c *gin.Context) {
 	// 初始化并启动定时任务调度器
 	service.ServiceTask.Initialize()
 	service.ServiceTask.StartScheduler()
+	notify.Start(app.Setting.NotifyWorkers)
 
 	base.RespondSuccess(c, "安装成功", nil)
 }
@@ -128,9 +131,15 @@ func writeConfig(form InstallForm) error {
 		"enable_tls", "false",
 		"concurrency.queue", "500",
 		"auth_secret", utils.RandAuthToken(),
+		"secret_key", utils.RandAuthToken(),
 		"ca_file", "",
 		"cert_file", "",
 		"key_file", "",
+		"crl_file", "",
+		"internal_ca", "false",
+		"template_dir", "",
+		"template_dir_conflict", "skip",
+		"notify.workers", "4",
 	}
 
 	return setting.Write(dbConfig, app.AppConfig)
@@ -0,0 +1,99 @@
+package manage
+
+// 聊天应用通知配置（Telegram、Discord、Microsoft Teams），三个渠道共用同一组处理函数
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+)
+
+type updateChatTokenForm struct {
+	Token string `form:"token" json:"token" binding:"required,max=200"`
+}
+
+type createChatReceiverForm struct {
+	Name   string `form:"name" json:"name" binding:"required,max=50"`
+	Target string `form:"target" json:"target" binding:"required,max=500"`
+}
+
+// Chat 返回聊天应用渠道配置，不回传机器人 token
+func Chat(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chatSetting, err := new(models.Setting).Chat(code)
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		base.RespondSuccess(c, utils.SuccessContent, gin.H{
+			"token_set": chatSetting.Token != "",
+			"receivers": chatSetting.Receivers,
+		})
+	}
+}
+
+// UpdateChatToken 更新 Telegram 机器人 token
+func UpdateChatToken(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var form updateChatTokenForm
+		if err := c.ShouldBind(&form); err != nil {
+			base.RespondError(c, i18n.T(c, "form_validation_failed"))
+			return
+		}
+		if err := new(models.Setting).UpdateChatToken(code, strings.TrimSpace(form.Token)); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		base.RespondSuccessWithDefaultMsg(c, nil)
+	}
+}
+
+// CreateChatReceiver 添加接收者，Discord 和 Teams 的接收者必须是 Webhook 地址
+func CreateChatReceiver(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var form createChatReceiverForm
+		if err := c.ShouldBind(&form); err != nil {
+			base.RespondError(c, i18n.T(c, "form_validation_failed"))
+			return
+		}
+		receiver := models.ChatReceiver{
+			Name:   strings.TrimSpace(form.Name),
+			Target: strings.TrimSpace(form.Target),
+		}
+		if code != models.TelegramCode && !isHttpUrl(receiver.Target) {
+			base.RespondError(c, i18n.T(c, "form_validation_failed"))
+			return
+		}
+		id, err := new(models.Setting).CreateChatReceiver(code, receiver)
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		c.Set("audit_target_id", id)
+		c.Set("audit_target_name", receiver.Name)
+		base.RespondSuccessWithDefaultMsg(c, nil)
+	}
+}
+
+// RemoveChatReceiver 删除接收者
+func RemoveChatReceiver(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, _ := strconv.Atoi(c.Param("id"))
+		if _, err := new(models.Setting).RemoveChatReceiver(code, id); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		base.RespondSuccessWithDefaultMsg(c, nil)
+	}
+}
+
+func isHttpUrl(s string) bool {
+	u, err := url.ParseRequestURI(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,184 @@
+package manage
+
+// 数据源：SQL 任务连接的数据库，密码加密保存
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/sqlrunner"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+)
+
+// pingTimeout 测试连接的超时时间
+const pingTimeout = 10 * time.Second
+
+type DataSourceForm struct {
+	Id     int    `form:"id" json:"id"`
+	Name   string `form:"name" json:"name" binding:"required,max=64"`
+	Driver string `form:"driver" json:"driver" binding:"required,oneof=mysql postgres sqlite"`
+	Host   string `form:"host" json:"host" binding:"max=255"`
+	Port   int    `form:"port" json:"port" binding:"min=0,max=65535"`
+	User   string `form:"user" json:"user" binding:"max=128"`
+	// Password 修改时为空表示保留原密码
+	Password string `form:"password" json:"password" binding:"max=512"`
+	Database string `form:"database" json:"database" binding:"max=512"`
+	Params   string `form:"params" json:"params" binding:"max=512"`
+	Remark   string `form:"remark" json:"remark" binding:"max=200"`
+}
+
+// source 表单中的连接信息，密码为空且是修改时使用已保存的密码
+func (form DataSourceForm) source() (models.DataSource, sqlrunner.Source, error) {
+	dataSource := models.DataSource{
+		Name:     strings.TrimSpace(form.Name),
+		Driver:   form.Driver,
+		Host:     strings.TrimSpace(form.Host),
+		Port:     form.Port,
+		User:     strings.TrimSpace(form.User),
+		Database: strings.TrimSpace(form.Database),
+		Params:   strings.TrimSpace(form.Params),
+		Remark:   form.Remark,
+	}
+	source, err := dataSource.Source()
+	if err != nil {
+		return dataSource, source, err
+	}
+	source.Password = form.Password
+	if form.Id > 0 && form.Password == "" {
+		saved, err := new(models.DataSource).Detail(form.Id)
+		if err != nil {
+			return dataSource, source, err
+		}
+		savedSource, err := saved.Source()
+		if err != nil {
+			return dataSource, source, err
+		}
+		source.Password = savedSource.Password
+	}
+	return dataSource, source, nil
+}
+
+// DataSources 数据源列表，不返回密码
+func DataSources(c *gin.Context) {
+	dataSourceModel := new(models.DataSource)
+	list, err := dataSourceModel.List()
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	base.RespondSuccess(c, utils.SuccessContent, list)
+}
+
+// StoreDataSource 新增或修改数据源
+func StoreDataSource(c *gin.Context) {
+	var form DataSourceForm
+	if err := c.ShouldBind(&form); err != nil {
+		base.RespondValidationError(c, err)
+		return
+	}
+	dataSource, source, err := form.source()
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "param_error"), err)
+		return
+	}
+	if dataSource.Name == "" {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+	dataSourceModel := new(models.DataSource)
+	exists, err := dataSourceModel.NameExist(dataSource.Name, form.Id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	if exists {
+		base.RespondError(c, i18n.T(c, "data_source_name_exists"))
+		return
+	}
+	if err := source.Validate(); err != nil {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "data_source_invalid"), err.Error()))
+		return
+	}
+	if err := dataSource.SetPassword(form.Password); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+
+	if form.Id == 0 {
+		id, err := dataSource.Create()
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		c.Set("audit_target_id", id)
+	} else {
+		if err := dataSource.Update(form.Id, form.Password == ""); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		c.Set("audit_target_id", form.Id)
+	}
+	c.Set("audit_target_name", dataSource.Name)
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// PingDataSource 使用表单中的连接信息执行 SELECT 1
+func PingDataSource(c *gin.Context) {
+	var form DataSourceForm
+	if err := c.ShouldBind(&form); err != nil {
+		base.RespondValidationError(c, err)
+		return
+	}
+	_, source, err := form.source()
+	if err == nil {
+		err = source.Validate()
+	}
+	if err != nil {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "data_source_invalid"), err.Error()))
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), pingTimeout)
+	defer cancel()
+	if _, err := sqlrunner.Run(ctx, sqlrunner.Job{Source: source, Query: "SELECT 1"}); err != nil {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "data_source_connect_failed"), err.Error()))
+		return
+	}
+	base.RespondSuccess(c, i18n.T(c, "data_source_connect_success"), nil)
+}
+
+// RemoveDataSource 删除未被任务使用的数据源
+func RemoveDataSource(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+	dataSourceModel := new(models.DataSource)
+	dataSource, err := dataSourceModel.Detail(id)
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "param_error"), err)
+		return
+	}
+	tasks, err := dataSourceModel.UsedByTasks(id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	if len(tasks) > 0 {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "data_source_in_use"), strings.Join(tasks, ", ")))
+		return
+	}
+	if _, err := dataSourceModel.Delete(id); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	c.Set("audit_target_name", dataSource.Name)
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
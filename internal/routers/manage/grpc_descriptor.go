@@ -0,0 +1,147 @@
+package manage
+
+// gRPC 描述文件集：目标服务未开启反射时，gRPC 任务使用上传的描述文件解析方法
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/grpcclient"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+)
+
+// maxDescriptorSize 描述文件集的最大字节数
+const maxDescriptorSize = 4 << 20
+
+type GrpcDescriptorForm struct {
+	Id     int    `form:"id" json:"id"`
+	Name   string `form:"name" json:"name" binding:"required,max=64"`
+	Remark string `form:"remark" json:"remark" binding:"max=200"`
+}
+
+// GrpcDescriptors 描述文件集列表，不返回文件内容
+func GrpcDescriptors(c *gin.Context) {
+	descriptorModel := new(models.GrpcDescriptor)
+	list, err := descriptorModel.List()
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	base.RespondSuccess(c, utils.SuccessContent, list)
+}
+
+// StoreGrpcDescriptor 上传或修改描述文件集，修改时不上传文件表示保留原文件
+func StoreGrpcDescriptor(c *gin.Context) {
+	var form GrpcDescriptorForm
+	if err := c.ShouldBind(&form); err != nil {
+		base.RespondValidationError(c, err)
+		return
+	}
+	descriptor := models.GrpcDescriptor{Name: strings.TrimSpace(form.Name), Remark: form.Remark}
+	if descriptor.Name == "" {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+	descriptorModel := new(models.GrpcDescriptor)
+	exists, err := descriptorModel.NameExist(descriptor.Name, form.Id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	if exists {
+		base.RespondError(c, i18n.T(c, "grpc_descriptor_name_exists"))
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	keepContent := err != nil
+	if keepContent && form.Id == 0 {
+		base.RespondError(c, i18n.T(c, "grpc_descriptor_file_required"))
+		return
+	}
+	if !keepContent {
+		if fileHeader.Size > maxDescriptorSize {
+			base.RespondError(c, i18n.T(c, "grpc_descriptor_too_large"))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(io.LimitReader(file, maxDescriptorSize+1))
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		if len(data) > maxDescriptorSize {
+			base.RespondError(c, i18n.T(c, "grpc_descriptor_too_large"))
+			return
+		}
+		files, err := grpcclient.ParseDescriptorSet(data)
+		if err != nil {
+			base.RespondError(c, fmt.Sprintf(i18n.T(c, "grpc_descriptor_invalid"), err.Error()))
+			return
+		}
+		services := grpcclient.Services(files)
+		if len(services) == 0 {
+			base.RespondError(c, fmt.Sprintf(i18n.T(c, "grpc_descriptor_invalid"), "no services defined"))
+			return
+		}
+		descriptor.SetContent(data, services)
+	}
+
+	if form.Id == 0 {
+		id, err := descriptor.Create()
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		c.Set("audit_target_id", id)
+	} else {
+		if err := descriptor.Update(form.Id, keepContent); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		c.Set("audit_target_id", form.Id)
+	}
+	c.Set("audit_target_name", descriptor.Name)
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// RemoveGrpcDescriptor 删除未被任务使用的描述文件集
+func RemoveGrpcDescriptor(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+	descriptorModel := new(models.GrpcDescriptor)
+	descriptor, err := descriptorModel.Detail(id)
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "param_error"), err)
+		return
+	}
+	tasks, err := descriptorModel.UsedByTasks(id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	if len(tasks) > 0 {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "grpc_descriptor_in_use"), strings.Join(tasks, ", ")))
+		return
+	}
+	if _, err := descriptorModel.Delete(id); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	c.Set("audit_target_name", descriptor.Name)
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
@@ -0,0 +1,138 @@
+package manage
+
+// HTTP 配置：多个 HTTP 任务共用的 CA 证书、客户端证书和代理设置
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+)
+
+type HttpProfileForm struct {
+	Id                  int    `form:"id" json:"id"`
+	Name                string `form:"name" json:"name" binding:"required,max=64"`
+	CaCert              string `form:"ca_cert" json:"ca_cert" binding:"max=65536"`
+	ClientCert          string `form:"client_cert" json:"client_cert" binding:"max=65536"`
+	ClientKeySecret     string `form:"client_key_secret" json:"client_key_secret" binding:"max=64"`
+	InsecureSkipVerify  bool   `form:"insecure_skip_verify" json:"insecure_skip_verify"`
+	ProxyUrl            string `form:"proxy_url" json:"proxy_url" binding:"max=512"`
+	ProxyUsername       string `form:"proxy_username" json:"proxy_username" binding:"max=128"`
+	ProxyPasswordSecret string `form:"proxy_password_secret" json:"proxy_password_secret" binding:"max=64"`
+	Remark              string `form:"remark" json:"remark" binding:"max=200"`
+}
+
+// HttpProfiles HTTP 配置列表
+func HttpProfiles(c *gin.Context) {
+	profileModel := new(models.HttpProfile)
+	list, err := profileModel.List()
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	base.RespondSuccess(c, utils.SuccessContent, list)
+}
+
+// StoreHttpProfile 新增或修改 HTTP 配置，保存前校验证书、私钥和代理地址
+func StoreHttpProfile(c *gin.Context) {
+	var form HttpProfileForm
+	if err := c.ShouldBind(&form); err != nil {
+		base.RespondValidationError(c, err)
+		return
+	}
+	profile := models.HttpProfile{
+		Name:                strings.TrimSpace(form.Name),
+		CaCert:              strings.TrimSpace(form.CaCert),
+		ClientCert:          strings.TrimSpace(form.ClientCert),
+		ClientKeySecret:     strings.TrimSpace(form.ClientKeySecret),
+		InsecureSkipVerify:  form.InsecureSkipVerify,
+		ProxyUrl:            strings.TrimSpace(form.ProxyUrl),
+		ProxyUsername:       strings.TrimSpace(form.ProxyUsername),
+		ProxyPasswordSecret: strings.TrimSpace(form.ProxyPasswordSecret),
+		Remark:              form.Remark,
+	}
+	if profile.Name == "" {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+	profileModel := new(models.HttpProfile)
+	exists, err := profileModel.NameExist(profile.Name, form.Id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	if exists {
+		base.RespondError(c, i18n.T(c, "http_profile_name_exists"))
+		return
+	}
+	if profile.ProxyUsername == "" && profile.ProxyPasswordSecret != "" {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "http_profile_invalid"), "proxy_password_secret requires proxy_username"))
+		return
+	}
+	resolved, err := profile.Resolve(models.LookupSecret)
+	if err == nil {
+		err = resolved.Validate()
+	}
+	if err != nil {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "http_profile_invalid"), err.Error()))
+		return
+	}
+
+	if form.Id == 0 {
+		id, err := profile.Create()
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		c.Set("audit_target_id", id)
+	} else {
+		if _, err := profileModel.Detail(form.Id); err != nil {
+			base.RespondError(c, i18n.T(c, "param_error"), err)
+			return
+		}
+		if err := profile.Update(form.Id); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		c.Set("audit_target_id", form.Id)
+	}
+	c.Set("audit_target_name", profile.Name)
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// RemoveHttpProfile 删除未被任务使用的 HTTP 配置
+func RemoveHttpProfile(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+	profileModel := new(models.HttpProfile)
+	profile, err := profileModel.Detail(id)
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "param_error"), err)
+		return
+	}
+	tasks, err := profileModel.UsedByTasks(id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	if len(tasks) > 0 {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "http_profile_in_use"), strings.Join(tasks, ", ")))
+		return
+	}
+	if _, err := profileModel.Delete(id); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	httpclient.CloseProfile(fmt.Sprintf("profile-%d", id))
+	c.Set("audit_target_name", profile.Name)
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
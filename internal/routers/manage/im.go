@@ -0,0 +1,120 @@
+package manage
+
+// 群机器人通知配置（钉钉、企业微信、飞书），三个渠道共用同一组处理函数
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+)
+
+type updateIMForm struct {
+	Template string `form:"template" json:"template" binding:"required"`
+}
+
+type createIMBotForm struct {
+	Name      string `form:"name" json:"name" binding:"required,max=50"`
+	Webhook   string `form:"webhook" json:"webhook" binding:"required,url,max=500"`
+	Secret    string `form:"secret" json:"secret" binding:"max=200"`
+	AtMobiles string `form:"at_mobiles" json:"at_mobiles" binding:"max=500"`
+	AtUserIds string `form:"at_user_ids" json:"at_user_ids" binding:"max=500"`
+	AtAll     bool   `form:"at_all" json:"at_all"`
+}
+
+// imBotView 返回给前端的机器人配置，不回传加签密钥
+type imBotView struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Webhook   string `json:"webhook"`
+	SecretSet bool   `json:"secret_set"`
+	AtMobiles string `json:"at_mobiles"`
+	AtUserIds string `json:"at_user_ids"`
+	AtAll     bool   `json:"at_all"`
+}
+
+// IM 返回群机器人渠道配置
+func IM(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imSetting, err := new(models.Setting).IM(code)
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		bots := make([]imBotView, 0, len(imSetting.Bots))
+		for _, bot := range imSetting.Bots {
+			bots = append(bots, imBotView{
+				Id:        bot.Id,
+				Name:      bot.Name,
+				Webhook:   bot.Webhook,
+				SecretSet: bot.Secret != "",
+				AtMobiles: bot.AtMobiles,
+				AtUserIds: bot.AtUserIds,
+				AtAll:     bot.AtAll,
+			})
+		}
+		base.RespondSuccess(c, utils.SuccessContent, gin.H{
+			"template": imSetting.Template,
+			"bots":     bots,
+		})
+	}
+}
+
+// UpdateIM 更新群机器人消息模板
+func UpdateIM(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var form updateIMForm
+		if err := c.ShouldBind(&form); err != nil {
+			base.RespondError(c, i18n.T(c, "form_validation_failed"))
+			return
+		}
+		if err := new(models.Setting).UpdateIMTemplate(code, form.Template); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		base.RespondSuccessWithDefaultMsg(c, nil)
+	}
+}
+
+// CreateIMBot 添加群机器人
+func CreateIMBot(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var form createIMBotForm
+		if err := c.ShouldBind(&form); err != nil {
+			base.RespondError(c, i18n.T(c, "form_validation_failed"))
+			return
+		}
+		bot := models.IMBot{
+			Name:      strings.TrimSpace(form.Name),
+			Webhook:   strings.TrimSpace(form.Webhook),
+			Secret:    strings.TrimSpace(form.Secret),
+			AtMobiles: strings.TrimSpace(form.AtMobiles),
+			AtUserIds: strings.TrimSpace(form.AtUserIds),
+			AtAll:     form.AtAll,
+		}
+		id, err := new(models.Setting).CreateIMBot(code, bot)
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		c.Set("audit_target_id", id)
+		c.Set("audit_target_name", bot.Name)
+		base.RespondSuccessWithDefaultMsg(c, nil)
+	}
+}
+
+// RemoveIMBot 删除群机器人
+func RemoveIMBot(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, _ := strconv.Atoi(c.Param("id"))
+		if _, err := new(models.Setting).RemoveIMBot(code, id); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		base.RespondSuccessWithDefaultMsg(c, nil)
+	}
+}
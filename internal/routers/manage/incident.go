@@ -0,0 +1,115 @@
+package manage
+
+// 告警平台通知配置（PagerDuty、Opsgenie）及事件状态
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+)
+
+type createIncidentServiceForm struct {
+	Name   string `form:"name" json:"name" binding:"required,max=50"`
+	Key    string `form:"key" json:"key" binding:"required,max=200"`
+	ApiUrl string `form:"api_url" json:"api_url" binding:"max=500"`
+}
+
+// incidentServiceView 返回给前端的服务配置，不回传 integration key
+type incidentServiceView struct {
+	Id     int    `json:"id"`
+	Name   string `json:"name"`
+	ApiUrl string `json:"api_url"`
+}
+
+// IncidentServices 返回告警平台的接收服务列表
+func IncidentServices(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		services, err := new(models.Setting).IncidentServices(code)
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		views := make([]incidentServiceView, 0, len(services))
+		for _, service := range services {
+			views = append(views, incidentServiceView{Id: service.Id, Name: service.Name, ApiUrl: service.ApiUrl})
+		}
+		base.RespondSuccess(c, utils.SuccessContent, gin.H{"services": views})
+	}
+}
+
+// CreateIncidentService 添加接收服务
+func CreateIncidentService(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var form createIncidentServiceForm
+		if err := c.ShouldBind(&form); err != nil {
+			base.RespondError(c, i18n.T(c, "form_validation_failed"))
+			return
+		}
+		service := models.IncidentService{
+			Name:   strings.TrimSpace(form.Name),
+			Key:    strings.TrimSpace(form.Key),
+			ApiUrl: strings.TrimSpace(form.ApiUrl),
+		}
+		if service.ApiUrl != "" && !isHttpUrl(service.ApiUrl) {
+			base.RespondError(c, i18n.T(c, "form_validation_failed"))
+			return
+		}
+		id, err := new(models.Setting).CreateIncidentService(code, service)
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		c.Set("audit_target_id", id)
+		c.Set("audit_target_name", service.Name)
+		base.RespondSuccessWithDefaultMsg(c, nil)
+	}
+}
+
+// RemoveIncidentService 删除接收服务
+func RemoveIncidentService(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, _ := strconv.Atoi(c.Param("id"))
+		if _, err := new(models.Setting).RemoveIncidentService(code, id); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		base.RespondSuccessWithDefaultMsg(c, nil)
+	}
+}
+
+// Incidents 任务在告警平台上的事件状态
+func Incidents(c *gin.Context) {
+	incidentModel := new(models.TaskIncident)
+	params := models.CommonMap{}
+	base.ParsePageAndPageSize(c, params)
+	params["Status"] = c.Query("status")
+	params["TaskId"], _ = strconv.Atoi(c.Query("task_id"))
+	params["Channel"] = -1
+	if channel, err := strconv.Atoi(c.Query("channel")); err == nil {
+		params["Channel"] = channel
+	}
+
+	total, err := incidentModel.Total(params)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	list, err := incidentModel.List(params)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"total": total,
+		"data":  list,
+	})
+	c.String(http.StatusOK, result)
+}
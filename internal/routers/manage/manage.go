@@ -2,11 +2,14 @@ package manage
 
 import (
 	"encoding/json"
+	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
 	"github.com/gocronx-team/gocron/internal/modules/logger"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 	"github.com/gocronx-team/gocron/internal/routers/base"
@@ -110,10 +113,21 @@ type UpdateWebHookForm struct {
 	Template string `form:"template" json:"template" binding:"required"`
 }
 
-// CreateWebhookUrlForm 创建Webhook地址表单
+// CreateWebhookUrlForm 创建、修改Webhook地址表单。修改时令牌、密码和签名密钥留空表示不变
 type CreateWebhookUrlForm struct {
-	Name string `form:"name" json:"name" binding:"required,max=50"`
-	Url  string `form:"url" json:"url" binding:"required,url,max=200"`
+	Name   string `form:"name" json:"name" binding:"required,max=50"`
+	Url    string `form:"url" json:"url" binding:"required,url,max=200"`
+	Method string `form:"method" json:"method" binding:"omitempty,oneof=POST PUT PATCH"`
+	// Headers JSON 对象，例如 {"X-Source": "gocron"}
+	Headers  string `form:"headers" json:"headers" binding:"max=2000"`
+	AuthType string `form:"auth_type" json:"auth_type" binding:"omitempty,oneof=bearer basic"`
+	Token    string `form:"token" json:"token" binding:"max=500"`
+	Username string `form:"username" json:"username" binding:"max=100"`
+	Password string `form:"password" json:"password" binding:"max=200"`
+	Timeout  int    `form:"timeout" json:"timeout" binding:"min=0,max=300"`
+	Secret   string `form:"secret" json:"secret" binding:"max=200"`
+	// RemoveSecret 修改时删除签名密钥
+	RemoveSecret bool `form:"remove_secret" json:"remove_secret"`
 }
 
 // CreateSlackChannelForm 创建Slack频道表单
@@ -196,6 +210,21 @@ func RemoveMailUser(c *gin.Context) {
 	}
 }
 
+// webhookUrlView 返回给前端的webhook地址配置，不回传令牌、密码和签名密钥
+type webhookUrlView struct {
+	Id          int               `json:"id"`
+	Name        string            `json:"name"`
+	Url         string            `json:"url"`
+	Method      string            `json:"method"`
+	Headers     map[string]string `json:"headers"`
+	AuthType    string            `json:"auth_type"`
+	Username    string            `json:"username"`
+	Timeout     int               `json:"timeout"`
+	TokenSet    bool              `json:"token_set"`
+	PasswordSet bool              `json:"password_set"`
+	SecretSet   bool              `json:"secret_set"`
+}
+
 func WebHook(c *gin.Context) {
 	settingModel := new(models.Setting)
 	webHook, err := settingModel.Webhook()
@@ -203,7 +232,30 @@ func WebHook(c *gin.Context) {
 		base.RespondErrorWithDefaultMsg(c, err)
 		return
 	}
-	base.RespondSuccess(c, "", webHook)
+	urls := make([]webhookUrlView, 0, len(webHook.WebhookUrls))
+	for _, v := range webHook.WebhookUrls {
+		method := v.Method
+		if method == "" {
+			method = http.MethodPost
+		}
+		urls = append(urls, webhookUrlView{
+			Id:          v.Id,
+			Name:        v.Name,
+			Url:         v.Url,
+			Method:      method,
+			Headers:     v.Headers,
+			AuthType:    v.AuthType,
+			Username:    v.Username,
+			Timeout:     v.Timeout,
+			TokenSet:    v.Token != "",
+			PasswordSet: v.Password != "",
+			SecretSet:   v.Secret != "",
+		})
+	}
+	base.RespondSuccess(c, "", gin.H{
+		"template":     webHook.Template,
+		"webhook_urls": urls,
+	})
 }
 
 func UpdateWebHook(c *gin.Context) {
@@ -230,16 +282,96 @@ func CreateWebhookUrl(c *gin.Context) {
 		base.RespondError(c, "表单验证失败, 请检测输入")
 		return
 	}
+	webhookUrl, err := webhookUrlFromForm(form, models.WebhookUrl{})
+	if err != nil {
+		base.RespondError(c, "headers: "+err.Error())
+		return
+	}
 
 	settingModel := new(models.Setting)
-	_, err := settingModel.CreateWebhookUrl(form.Name, form.Url)
+	id, err := settingModel.AddWebhookUrl(webhookUrl)
 	if err != nil {
 		base.RespondErrorWithDefaultMsg(c, err)
 	} else {
+		c.Set("audit_target_id", id)
+		c.Set("audit_target_name", webhookUrl.Name)
 		base.RespondSuccessWithDefaultMsg(c, nil)
 	}
 }
 
+// UpdateWebhookUrl 修改webhook地址的请求方法、认证和签名配置
+func UpdateWebhookUrl(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var form CreateWebhookUrlForm
+	if err := c.ShouldBind(&form); err != nil {
+		logger.Errorf("修改Webhook地址表单验证失败: %v", err)
+		base.RespondError(c, "表单验证失败, 请检测输入")
+		return
+	}
+	settingModel := new(models.Setting)
+	existing, err := settingModel.WebhookUrlById(id)
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "param_error"), err)
+		return
+	}
+	webhookUrl, err := webhookUrlFromForm(form, existing)
+	if err != nil {
+		base.RespondError(c, "headers: "+err.Error())
+		return
+	}
+	webhookUrl.Id = id
+	if err := settingModel.UpdateWebhookUrl(webhookUrl); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	c.Set("audit_target_id", id)
+	c.Set("audit_target_name", webhookUrl.Name)
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// webhookUrlFromForm 表单中留空的令牌、密码和签名密钥沿用 existing 中的值，切换认证方式时清除不再使用的凭据
+func webhookUrlFromForm(form CreateWebhookUrlForm, existing models.WebhookUrl) (models.WebhookUrl, error) {
+	if err := httpclient.ValidateHeaders(form.Headers); err != nil {
+		return existing, err
+	}
+	var headers map[string]string
+	if strings.TrimSpace(form.Headers) != "" {
+		_ = json.Unmarshal([]byte(form.Headers), &headers)
+	}
+	webhookUrl := models.WebhookUrl{
+		Name:     strings.TrimSpace(form.Name),
+		Url:      strings.TrimSpace(form.Url),
+		Method:   form.Method,
+		Headers:  headers,
+		AuthType: form.AuthType,
+		Timeout:  form.Timeout,
+		Token:    firstNonEmpty(strings.TrimSpace(form.Token), existing.Token),
+		Username: strings.TrimSpace(form.Username),
+		Password: firstNonEmpty(form.Password, existing.Password),
+		Secret:   firstNonEmpty(strings.TrimSpace(form.Secret), existing.Secret),
+	}
+	if webhookUrl.AuthType != models.WebhookAuthBearer {
+		webhookUrl.Token = ""
+	}
+	if webhookUrl.AuthType != models.WebhookAuthBasic {
+		webhookUrl.Username, webhookUrl.Password = "", ""
+	}
+	if form.RemoveSecret {
+		webhookUrl.Secret = ""
+	}
+
+	return webhookUrl, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 func RemoveWebhookUrl(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 	settingModel := new(models.Setting)
@@ -299,3 +431,32 @@ func UpdateLogRetentionDays(c *gin.Context) {
 }
 
 // endregion
+
+// region 审批配置
+
+// Approval 返回受保护的任务标签
+func Approval(c *gin.Context) {
+	settingModel := new(models.Setting)
+	base.RespondSuccess(c, "", map[string]interface{}{
+		"protected_tags": settingModel.GetProtectedTags(),
+	})
+}
+
+func UpdateApproval(c *gin.Context) {
+	var form struct {
+		ProtectedTags []string `json:"protected_tags"`
+	}
+	if err := c.ShouldBindJSON(&form); err != nil {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+
+	settingModel := new(models.Setting)
+	if err := settingModel.UpdateProtectedTags(form.ProtectedTags); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// endregion
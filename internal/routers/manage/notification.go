@@ -0,0 +1,176 @@
+package manage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/notify"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+	"gorm.io/gorm"
+)
+
+// NotificationDeliveries 通知投递历史
+func NotificationDeliveries(c *gin.Context) {
+	outboxModel := new(models.NotificationOutbox)
+	params := models.CommonMap{}
+	base.ParsePageAndPageSize(c, params)
+	params["Status"] = c.Query("status")
+	params["TaskId"], _ = strconv.Atoi(c.Query("task_id"))
+	params["Channel"] = -1
+	if channel, err := strconv.Atoi(c.Query("channel")); err == nil {
+		params["Channel"] = channel
+	}
+
+	total, err := outboxModel.Total(params)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	list, err := outboxModel.List(params)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"total": total,
+		"data":  list,
+	})
+	c.String(http.StatusOK, result)
+}
+
+// NotificationDelivery 通知详情，包含每次投递记录
+func NotificationDelivery(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	outboxModel := new(models.NotificationOutbox)
+	item, err := outboxModel.Detail(id)
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	attempts, err := outboxModel.AttemptList(id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"delivery": item,
+		"attempts": attempts,
+	})
+	c.String(http.StatusOK, result)
+}
+
+// ResendNotification 重新投递已成功或已失败的通知
+func ResendNotification(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	outboxModel := new(models.NotificationOutbox)
+	if err := outboxModel.Resend(id); err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+	notify.Wake()
+
+	c.Set("audit_target_id", id)
+	c.Set("audit_detail", fmt.Sprintf("resend notification #%d", id))
+	base.RespondSuccess(c, i18n.T(c, "notification_resend_queued"), nil)
+}
+
+func respondNotificationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		base.RespondError(c, i18n.T(c, "notification_not_found"))
+	case errors.Is(err, models.ErrNotificationNotFinished):
+		base.RespondError(c, i18n.T(c, "notification_not_finished"))
+	default:
+		base.RespondErrorWithDefaultMsg(c, err)
+	}
+}
+
+// SiteUrl 通知中日志链接使用的站点地址
+func SiteUrl(c *gin.Context) {
+	base.RespondSuccess(c, "", map[string]interface{}{
+		"site_url": new(models.Setting).GetSiteUrl(),
+	})
+}
+
+func UpdateSiteUrl(c *gin.Context) {
+	var form struct {
+		SiteUrl string `json:"site_url" binding:"omitempty,url,max=255"`
+	}
+	if err := c.ShouldBindJSON(&form); err != nil {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+	if err := new(models.Setting).UpdateSiteUrl(form.SiteUrl); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// NotificationTestForm 预览和测试发送的参数，Template 为空时使用渠道模板
+type NotificationTestForm struct {
+	Channel     int8   `json:"channel" binding:"min=0"`
+	ReceiverIds string `json:"receiver_ids"`
+	Template    string `json:"template" binding:"max=2048"`
+	Failed      bool   `json:"failed"`
+}
+
+func bindNotificationTest(c *gin.Context) (notify.Message, bool) {
+	var form NotificationTestForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return nil, false
+	}
+	if err := notify.CheckTemplate(form.Template); err != nil {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "notify_template_invalid"), err))
+		return nil, false
+	}
+
+	return notify.SampleMessage(models.NotifyChannel(form.Channel), form.ReceiverIds, form.Template, form.Failed), true
+}
+
+// PreviewNotification 用示例消息渲染通知内容，不发送
+func PreviewNotification(c *gin.Context) {
+	msg, ok := bindNotificationTest(c)
+	if !ok {
+		return
+	}
+	content, targets, err := notify.Preview(msg)
+	if err != nil {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "notify_test_failed"), err))
+		return
+	}
+	base.RespondSuccess(c, "", map[string]interface{}{
+		"content": content,
+		"targets": targets,
+	})
+}
+
+// TestNotification 发送一条示例通知到选择的接收者，直接投递，不进入通知队列
+func TestNotification(c *gin.Context) {
+	msg, ok := bindNotificationTest(c)
+	if !ok {
+		return
+	}
+	results, err := notify.SendTest(msg)
+	if err != nil {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "notify_test_failed"), err))
+		return
+	}
+	if len(results) == 0 {
+		base.RespondError(c, i18n.T(c, "notify_test_no_target"))
+		return
+	}
+	c.Set("audit_detail", fmt.Sprintf("test notification to channel %d", msg["task_type"]))
+	base.RespondSuccess(c, "", results)
+}
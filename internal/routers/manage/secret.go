@@ -0,0 +1,153 @@
+package manage
+
+// 密钥库：HTTP 任务认证按名称引用密钥，接口不返回密钥的值
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+)
+
+type SecretForm struct {
+	Id     int    `form:"id" json:"id"`
+	Name   string `form:"name" json:"name" binding:"required,max=64"`
+	Value  string `form:"value" json:"value" binding:"max=8192"`
+	Remark string `form:"remark" json:"remark" binding:"max=200"`
+}
+
+// Secrets 密钥列表
+func Secrets(c *gin.Context) {
+	secretModel := new(models.Secret)
+	list, err := secretModel.List()
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	base.RespondSuccess(c, utils.SuccessContent, list)
+}
+
+// StoreSecret 新增或修改密钥，修改时值为空表示保留原值
+func StoreSecret(c *gin.Context) {
+	var form SecretForm
+	if err := c.ShouldBind(&form); err != nil {
+		base.RespondValidationError(c, err)
+		return
+	}
+	form.Name = strings.TrimSpace(form.Name)
+	if !models.ValidSecretName(form.Name) {
+		base.RespondError(c, i18n.T(c, "secret_name_invalid"))
+		return
+	}
+	secretModel := new(models.Secret)
+	exists, err := secretModel.NameExist(form.Name, form.Id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	if exists {
+		base.RespondError(c, i18n.T(c, "secret_name_exists"))
+		return
+	}
+
+	if form.Id == 0 {
+		if form.Value == "" {
+			base.RespondError(c, i18n.T(c, "secret_value_required"))
+			return
+		}
+		secret := models.Secret{Name: form.Name, Remark: form.Remark}
+		if err := secret.SetValue(form.Value); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		id, err := secret.Create()
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		c.Set("audit_target_id", id)
+		c.Set("audit_target_name", form.Name)
+		base.RespondSuccessWithDefaultMsg(c, nil)
+		return
+	}
+
+	existing, err := secretModel.Detail(form.Id)
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "param_error"), err)
+		return
+	}
+	// 任务按名称引用密钥，被引用时不允许改名
+	if existing.Name != form.Name {
+		if !respondSecretNotUsed(c, existing.Name) {
+			return
+		}
+	}
+	if err := secretModel.Update(form.Id, form.Name, form.Value, form.Remark); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	c.Set("audit_target_id", form.Id)
+	c.Set("audit_target_name", form.Name)
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// RemoveSecret 删除未被任务引用的密钥
+func RemoveSecret(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+	secretModel := new(models.Secret)
+	secret, err := secretModel.Detail(id)
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "param_error"), err)
+		return
+	}
+	if !respondSecretNotUsed(c, secret.Name) {
+		return
+	}
+	if _, err := secretModel.Delete(id); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	c.Set("audit_target_name", secret.Name)
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// respondSecretNotUsed 密钥被任务、HTTP 配置或主机 SSH 设置引用时返回错误并列出名称
+func respondSecretNotUsed(c *gin.Context, name string) bool {
+	tasks, err := models.SecretUsage(name)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return false
+	}
+	if len(tasks) > 0 {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "secret_in_use"), strings.Join(tasks, ", ")))
+		return false
+	}
+	profiles, err := models.HttpProfileSecretUsage(name)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return false
+	}
+	if len(profiles) > 0 {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "secret_in_use_by_profile"), strings.Join(profiles, ", ")))
+		return false
+	}
+	hosts, err := models.HostSecretUsage(name)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return false
+	}
+	if len(hosts) > 0 {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "secret_in_use_by_host"), strings.Join(hosts, ", ")))
+		return false
+	}
+	return true
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/gocronx-team/gocron/internal/routers/tasklog"
 	"github.com/gocronx-team/gocron/internal/routers/template"
 	"github.com/gocronx-team/gocron/internal/routers/user"
+	"github.com/gocronx-team/gocron/internal/service"
 )
 
 const (
@@ -83,8 +84,14 @@ func Register(r *gin.Engine) {
 	taskGroup := api.Group("/task")
 	{
 		taskGroup.GET("/versions/:id", task.VersionList)
+		taskGroup.GET("/versions/:id/diff", task.VersionDiff)
 		taskGroup.GET("/versions/:id/:version_id", task.VersionDetail)
 		taskGroup.POST("/versions/:id/:version_id/rollback", task.VersionRollback)
+		taskGroup.GET("/change-requests", task.ChangeRequestList)
+		taskGroup.GET("/change-requests/:id", task.ChangeRequestDetail)
+		taskGroup.POST("/change-requests/:id/approve", task.ChangeRequestApprove)
+		taskGroup.POST("/change-requests/:id/reject", task.ChangeRequestReject)
+		taskGroup.POST("/change-requests/:id/cancel", task.ChangeRequestCancel)
 		taskGroup.POST("/store", task.Store)
 		taskGroup.POST("/cron-preview", task.CronPreview)
 		taskGroup.POST("/nl-to-cron", task.NlToCron)
@@ -103,6 +110,11 @@ func Register(r *gin.Engine) {
 		taskGroup.POST("/batch-disable", task.BatchDisable)
 		taskGroup.POST("/batch-remove", task.BatchRemove)
 		taskGroup.GET("/run/:id", task.Run)
+		taskGroup.POST("/heartbeat-token/:id", task.ResetHeartbeatToken)
+		taskGroup.GET("/artifact/:id", task.Artifacts)
+		taskGroup.POST("/artifact/upload/:id", task.UploadArtifact)
+		taskGroup.POST("/artifact/restore/:id/:artifact_id", task.RestoreArtifact)
+		taskGroup.POST("/artifact/remove/:id", task.RemoveArtifact)
 	}
 
 	// 主机
@@ -113,7 +125,10 @@ func Register(r *gin.Engine) {
 		hostGroup.GET("", host.Index)
 		hostGroup.GET("/all", host.All)
 		hostGroup.GET("/ping/:id", host.Ping)
+		hostGroup.GET("/ping-ssh/:id", host.PingSSH)
 		hostGroup.POST("/remove/:id", host.Remove)
+		hostGroup.POST("/cert/rotate/:id", host.RotateCert)
+		hostGroup.POST("/cert/revoke/:id", host.RevokeCert)
 	}
 
 	// Agent注册
@@ -135,6 +150,8 @@ func Register(r *gin.Engine) {
 		templateGroup.POST("/remove/:id", template.Remove)
 		templateGroup.POST("/apply/:id", template.Apply)
 		templateGroup.POST("/save-from-task", template.SaveFromTask)
+		templateGroup.GET("/export", template.Export)
+		templateGroup.POST("/import", template.Import)
 	}
 
 	// 管理
@@ -159,11 +176,66 @@ func Register(r *gin.Engine) {
 			webhookGroup.GET("", manage.WebHook)
 			webhookGroup.POST("/update", manage.UpdateWebHook)
 			webhookGroup.POST("/url", manage.CreateWebhookUrl)
+			webhookGroup.POST("/url/update/:id", manage.UpdateWebhookUrl)
 			webhookGroup.POST("/url/remove/:id", manage.RemoveWebhookUrl)
 		}
+		// 钉钉、企业微信、飞书群机器人: /api/system/dingtalk、/api/system/wecom、/api/system/feishu
+		for _, code := range models.IMCodes {
+			imGroup := systemGroup.Group("/" + code)
+			{
+				imGroup.GET("", manage.IM(code))
+				imGroup.POST("/update", manage.UpdateIM(code))
+				imGroup.POST("/bot", manage.CreateIMBot(code))
+				imGroup.POST("/bot/remove/:id", manage.RemoveIMBot(code))
+			}
+		}
+		// Telegram、Discord、Microsoft Teams: /api/system/telegram、/api/system/discord、/api/system/teams
+		for _, code := range models.ChatCodes {
+			chatGroup := systemGroup.Group("/" + code)
+			{
+				chatGroup.GET("", manage.Chat(code))
+				if code == models.TelegramCode {
+					chatGroup.POST("/update", manage.UpdateChatToken(code))
+				}
+				chatGroup.POST("/receiver", manage.CreateChatReceiver(code))
+				chatGroup.POST("/receiver/remove/:id", manage.RemoveChatReceiver(code))
+			}
+		}
+		// PagerDuty、Opsgenie: /api/system/pagerduty、/api/system/opsgenie
+		for _, code := range models.IncidentCodes {
+			incidentGroup := systemGroup.Group("/" + code)
+			{
+				incidentGroup.GET("", manage.IncidentServices(code))
+				incidentGroup.POST("/service", manage.CreateIncidentService(code))
+				incidentGroup.POST("/service/remove/:id", manage.RemoveIncidentService(code))
+			}
+		}
 		systemGroup.GET("/login-log", loginlog.Index)
 		systemGroup.GET("/log-retention", manage.GetLogRetentionDays)
 		systemGroup.POST("/log-retention", manage.UpdateLogRetentionDays)
+		systemGroup.GET("/notification/deliveries", manage.NotificationDeliveries)
+		systemGroup.GET("/notification/deliveries/:id", manage.NotificationDelivery)
+		systemGroup.POST("/notification/deliveries/:id/resend", manage.ResendNotification)
+		systemGroup.GET("/notification/incidents", manage.Incidents)
+		systemGroup.GET("/notification/site-url", manage.SiteUrl)
+		systemGroup.POST("/notification/site-url", manage.UpdateSiteUrl)
+		systemGroup.POST("/notification/preview", manage.PreviewNotification)
+		systemGroup.POST("/notification/test", manage.TestNotification)
+		systemGroup.GET("/approval", manage.Approval)
+		systemGroup.POST("/approval/update", manage.UpdateApproval)
+		systemGroup.GET("/secret", manage.Secrets)
+		systemGroup.POST("/secret/store", manage.StoreSecret)
+		systemGroup.POST("/secret/remove/:id", manage.RemoveSecret)
+		systemGroup.GET("/http-profile", manage.HttpProfiles)
+		systemGroup.POST("/http-profile/store", manage.StoreHttpProfile)
+		systemGroup.POST("/http-profile/remove/:id", manage.RemoveHttpProfile)
+		systemGroup.GET("/data-source", manage.DataSources)
+		systemGroup.POST("/data-source/store", manage.StoreDataSource)
+		systemGroup.POST("/data-source/ping", manage.PingDataSource)
+		systemGroup.POST("/data-source/remove/:id", manage.RemoveDataSource)
+		systemGroup.GET("/grpc-descriptor", manage.GrpcDescriptors)
+		systemGroup.POST("/grpc-descriptor/store", manage.StoreGrpcDescriptor)
+		systemGroup.POST("/grpc-descriptor/remove/:id", manage.RemoveGrpcDescriptor)
 		systemGroup.GET("/llm", manage.LLM)
 		systemGroup.POST("/llm/update", manage.UpdateLLM)
 	}
@@ -197,6 +269,24 @@ func Register(r *gin.Engine) {
 		mcpGroup.Any("", gin.WrapH(gocronmcp.Handler()))
 	}
 
+	// 心跳任务 ping 地址，顶级路径跳过 JWT 鉴权，由令牌识别任务
+	pingGroup := r.Group("/ping")
+	{
+		pingGroup.Any("/:token", task.Ping(service.HeartbeatSuccess))
+		pingGroup.Any("/:token/start", task.Ping(service.HeartbeatStart))
+		pingGroup.Any("/:token/fail", task.Ping(service.HeartbeatFail))
+	}
+
+	// 异步 HTTP 任务回调地址，顶级路径跳过 JWT 鉴权，由令牌识别执行
+	callbackGroup := r.Group("/callback")
+	{
+		callbackGroup.Any("/:token", task.Callback(false))
+		callbackGroup.Any("/:token/fail", task.Callback(true))
+	}
+
+	// 节点下载任务附件，顶级路径跳过 JWT 鉴权，由签名令牌识别附件
+	r.GET("/artifact/:token", task.DownloadArtifact)
+
 	// API
 	v1Group := api.Group("/v1")
 	v1Group.Use(apiAuth)
@@ -540,6 +630,22 @@ func resolveModuleAction(path string, c *gin.Context) (module, action string) {
 		return "task", "batch-disable"
 	case "/api/task/batch-remove":
 		return "task", "batch-remove"
+	case "/api/task/heartbeat-token/:id":
+		return "task", "reset-token"
+	case "/api/task/artifact/upload/:id":
+		return "task", "upload-artifact"
+	case "/api/task/artifact/restore/:id/:artifact_id":
+		return "task", "restore-artifact"
+	case "/api/task/artifact/remove/:id":
+		return "task", "remove-artifact"
+	case "/api/task/versions/:id/:version_id/rollback":
+		return "task", "rollback"
+	case "/api/task/change-requests/:id/approve":
+		return "task", "approve"
+	case "/api/task/change-requests/:id/reject":
+		return "task", "reject"
+	case "/api/task/change-requests/:id/cancel":
+		return "task", "cancel"
 
 	// Host routes
 	case "/api/host/store":
@@ -550,6 +656,10 @@ func resolveModuleAction(path string, c *gin.Context) (module, action string) {
 		return "host", "update"
 	case "/api/host/remove/:id":
 		return "host", "delete"
+	case "/api/host/cert/rotate/:id":
+		return "host", "cert-rotate"
+	case "/api/host/cert/revoke/:id":
+		return "host", "cert-revoke"
 
 	// User routes
 	case "/api/user/store":
@@ -582,6 +692,56 @@ func resolveModuleAction(path string, c *gin.Context) (module, action string) {
 		return "template", "update"
 	case "/api/template/save-from-task":
 		return "template", "create"
+	case "/api/template/import":
+		return "template", "import"
+
+	// 密钥库
+	case "/api/system/secret/store":
+		idStr := c.PostForm("id")
+		if idStr == "" || idStr == "0" {
+			return "system", "create"
+		}
+		return "system", "update"
+	case "/api/system/secret/remove/:id":
+		return "system", "delete"
+
+	// HTTP 配置
+	case "/api/system/http-profile/store":
+		idStr := c.PostForm("id")
+		if idStr == "" || idStr == "0" {
+			return "system", "create"
+		}
+		return "system", "update"
+	case "/api/system/http-profile/remove/:id":
+		return "system", "delete"
+
+	// 数据源，测试连接不记录审计
+	case "/api/system/data-source/store":
+		idStr := c.PostForm("id")
+		if idStr == "" || idStr == "0" {
+			return "system", "create"
+		}
+		return "system", "update"
+	case "/api/system/data-source/remove/:id":
+		return "system", "delete"
+	case "/api/system/data-source/ping":
+		return "", ""
+
+	// gRPC 描述文件集
+	case "/api/system/grpc-descriptor/store":
+		idStr := c.PostForm("id")
+		if idStr == "" || idStr == "0" {
+			return "system", "create"
+		}
+		return "system", "update"
+	case "/api/system/grpc-descriptor/remove/:id":
+		return "system", "delete"
+
+	// 通知预览只渲染内容，不记录审计
+	case "/api/system/notification/preview":
+		return "", ""
+	case "/api/system/notification/test":
+		return "system", "test-notification"
 
 	// System routes — any POST under /api/system
 	default:
@@ -0,0 +1,204 @@
+package task
+
+// 任务附件：随 Shell 任务分发到节点的脚本和文件，同名文件每次上传生成新版本
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/app"
+	"github.com/gocronx-team/gocron/internal/modules/artifact"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+	"github.com/gocronx-team/gocron/internal/routers/user"
+	"gorm.io/gorm"
+)
+
+// artifactTask 读取路径参数中的任务，只有 Shell 任务可以使用附件
+func artifactTask(c *gin.Context) (models.Task, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return models.Task{}, false
+	}
+	task, err := new(models.Task).Detail(id)
+	if err != nil || task.Id <= 0 {
+		base.RespondError(c, i18n.T(c, "task_not_found"))
+		return task, false
+	}
+	if task.Protocol != models.TaskRPC {
+		base.RespondError(c, i18n.T(c, "artifact_shell_only"))
+		return task, false
+	}
+	return task, true
+}
+
+// editableArtifactTask 读取可以修改附件的任务。附件不经过变更审批，受保护的任务不能直接修改
+func editableArtifactTask(c *gin.Context) (models.Task, bool) {
+	task, ok := artifactTask(c)
+	if !ok {
+		return task, false
+	}
+	if isProtected(task.Tag) {
+		base.RespondError(c, i18n.T(c, "artifact_protected"))
+		return task, false
+	}
+	return task, true
+}
+
+// Artifacts 任务附件的全部版本，不返回文件内容
+func Artifacts(c *gin.Context) {
+	task, ok := artifactTask(c)
+	if !ok {
+		return
+	}
+	list, err := new(models.TaskArtifact).List(task.Id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	base.RespondSuccess(c, utils.SuccessContent, list)
+}
+
+// UploadArtifact 上传附件，名称默认取文件名，已存在时生成新版本
+func UploadArtifact(c *gin.Context) {
+	task, ok := editableArtifactTask(c)
+	if !ok {
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "artifact_file_required"))
+		return
+	}
+	name := strings.TrimSpace(c.PostForm("name"))
+	if name == "" {
+		name = fileHeader.Filename
+	}
+	if err := artifact.ValidateName(name); err != nil {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "artifact_invalid"), err.Error()))
+		return
+	}
+	if fileHeader.Size > artifact.MaxFileSize {
+		base.RespondError(c, i18n.T(c, "artifact_too_large"))
+		return
+	}
+	artifactModel := new(models.TaskArtifact)
+	names, err := artifactModel.Names(task.Id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	if len(names) >= artifact.MaxFiles && !utils.InStringSlice(names, name) {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "artifact_too_many"), artifact.MaxFiles))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, artifact.MaxFileSize+1))
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	if len(data) > artifact.MaxFileSize {
+		base.RespondError(c, i18n.T(c, "artifact_too_large"))
+		return
+	}
+
+	saved := models.TaskArtifact{TaskId: task.Id, Name: name, Username: user.Username(c)}
+	saved.SetContent(data)
+	if _, err := saved.Create(); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	c.Set("audit_target_name", task.Name)
+	c.Set("audit_detail", fmt.Sprintf("upload artifact %s v%d sha256:%s", saved.Name, saved.Version, saved.Sha256))
+	base.RespondSuccessWithDefaultMsg(c, saved)
+}
+
+// RestoreArtifact 把历史版本的内容保存为最新版本
+func RestoreArtifact(c *gin.Context) {
+	task, ok := editableArtifactTask(c)
+	if !ok {
+		return
+	}
+	artifactId, err := strconv.Atoi(c.Param("artifact_id"))
+	if err != nil || artifactId <= 0 {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+	artifactModel := new(models.TaskArtifact)
+	version, err := artifactModel.Detail(artifactId)
+	if err != nil || version.TaskId != task.Id {
+		base.RespondError(c, i18n.T(c, "artifact_not_found"))
+		return
+	}
+	restored := models.TaskArtifact{TaskId: task.Id, Name: version.Name, Username: user.Username(c)}
+	restored.SetContent(version.Content)
+	if _, err := restored.Create(); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	c.Set("audit_target_name", task.Name)
+	c.Set("audit_detail", fmt.Sprintf("restore artifact %s v%d as v%d", version.Name, version.Version, restored.Version))
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// RemoveArtifact 删除附件的全部版本
+func RemoveArtifact(c *gin.Context) {
+	task, ok := editableArtifactTask(c)
+	if !ok {
+		return
+	}
+	name := strings.TrimSpace(c.PostForm("name"))
+	removed, err := new(models.TaskArtifact).Remove(task.Id, name)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	if removed == 0 {
+		base.RespondError(c, i18n.T(c, "artifact_not_found"))
+		return
+	}
+	c.Set("audit_target_name", task.Name)
+	c.Set("audit_detail", fmt.Sprintf("remove artifact %s", name))
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// DownloadArtifact 节点下载附件，通过带有效期的签名令牌识别附件，不需要登录
+func DownloadArtifact(c *gin.Context) {
+	if !app.Installed {
+		c.String(http.StatusServiceUnavailable, "not installed")
+		return
+	}
+	id, err := models.ParseArtifactToken(c.Param("token"))
+	if err != nil {
+		c.String(http.StatusForbidden, "forbidden")
+		return
+	}
+	saved, err := new(models.TaskArtifact).Detail(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.String(http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		logger.Errorf("读取任务附件失败#ID-%d#%s", id, err)
+		c.String(http.StatusInternalServerError, "error")
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", saved.Name))
+	c.Header("X-Checksum-Sha256", saved.Sha256)
+	c.Data(http.StatusOK, "application/octet-stream", saved.Content)
+}
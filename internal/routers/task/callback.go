@@ -0,0 +1,44 @@
+package task
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/app"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+// Callback 异步 HTTP 任务的回调地址，由被调用方在任务完成后调用，通过令牌识别执行，不需要登录。
+// 请求体作为回调内容，按任务配置的成功、失败条件判断
+func Callback(failed bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !app.Installed {
+			c.String(http.StatusServiceUnavailable, "not installed")
+			return
+		}
+		var body string
+		if c.Request.Body != nil {
+			data, err := io.ReadAll(io.LimitReader(c.Request.Body, pingBodyLimit))
+			if err == nil {
+				body = string(data)
+			}
+		}
+		err := service.HttpCallback(c.Param("token"), failed, body, utils.ClientIP(c))
+		switch {
+		case errors.Is(err, models.ErrTaskCallbackNotFound):
+			c.String(http.StatusNotFound, "not found")
+		case errors.Is(err, models.ErrTaskCallbackReceived):
+			c.String(http.StatusConflict, "already received")
+		case err != nil:
+			logger.Errorf("处理异步任务回调失败#%s", err)
+			c.String(http.StatusInternalServerError, "error")
+		default:
+			c.String(http.StatusOK, "OK")
+		}
+	}
+}
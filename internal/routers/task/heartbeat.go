@@ -0,0 +1,76 @@
+package task
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/app"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+// ping 请求体作为执行输出，超出部分丢弃
+const pingBodyLimit = 64 * 1024
+
+// Ping 心跳任务的 ping 地址，由外部任务调用，通过令牌识别任务，不需要登录。
+// 输出取自请求体，没有请求体时取查询参数 msg
+func Ping(event service.HeartbeatEvent) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !app.Installed {
+			c.String(http.StatusServiceUnavailable, "not installed")
+			return
+		}
+		output := c.Query("msg")
+		if c.Request.Body != nil {
+			body, err := io.ReadAll(io.LimitReader(c.Request.Body, pingBodyLimit))
+			if err == nil && len(body) > 0 {
+				output = string(body)
+			}
+		}
+		err := service.Heartbeat(c.Param("token"), event, utils.ClientIP(c), output)
+		if errors.Is(err, service.ErrHeartbeatTaskNotFound) {
+			c.String(http.StatusNotFound, "not found")
+			return
+		}
+		// 停用期间外部任务可能仍在运行，忽略 ping 但不返回错误，避免外部任务因此失败
+		if errors.Is(err, service.ErrHeartbeatTaskDisabled) {
+			c.String(http.StatusOK, "disabled")
+			return
+		}
+		if err != nil {
+			logger.Errorf("处理心跳 ping 失败#%s", err)
+			c.String(http.StatusInternalServerError, "error")
+			return
+		}
+		c.String(http.StatusOK, "OK")
+	}
+}
+
+// ResetHeartbeatToken 重新生成心跳任务的 ping 令牌
+func ResetHeartbeatToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+	taskModel := new(models.Task)
+	task, err := taskModel.Detail(id)
+	if err != nil || task.Id <= 0 || task.Protocol != models.TaskHeartbeat {
+		base.RespondError(c, i18n.T(c, "get_task_detail_failed"), err)
+		return
+	}
+	token, err := taskModel.ResetHeartbeatToken(id)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	c.Set("audit_target_name", task.Name)
+	base.RespondSuccessWithDefaultMsg(c, gin.H{"heartbeat_token": token})
+}
@@ -1,6 +1,8 @@
 package task
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -8,9 +10,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gocronx-team/cron"
 	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/grpcclient"
 	"github.com/gocronx-team/gocron/internal/modules/httpclient"
 	"github.com/gocronx-team/gocron/internal/modules/i18n"
 	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/notify"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+	"github.com/gocronx-team/gocron/internal/modules/sqlrunner"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 	"github.com/gocronx-team/gocron/internal/routers/base"
 	"github.com/gocronx-team/gocron/internal/routers/user"
@@ -18,30 +24,51 @@ import (
 )
 
 type TaskForm struct {
-	Id               int                         `form:"id" json:"id"`
-	Level            models.TaskLevel            `form:"level" json:"level" binding:"required,oneof=1 2"`
-	DependencyStatus models.TaskDependencyStatus `form:"dependency_status" json:"dependency_status" binding:"oneof=1 2"`
-	DependencyTaskId string                      `form:"dependency_task_id" json:"dependency_task_id"`
-	Name             string                      `form:"name" json:"name" binding:"required,max=32"`
-	Spec             string                      `form:"spec" json:"spec"`
-	Protocol         models.TaskProtocol         `form:"protocol" json:"protocol" binding:"oneof=1 2"`
-	Command          string                      `form:"command" json:"command" binding:"required,max=65535"`
-	HttpMethod       models.TaskHTTPMethod       `form:"http_method" json:"http_method" binding:"oneof=1 2"`
-	HttpBody         string                      `form:"http_body" json:"http_body" binding:"max=65535"`
-	HttpHeaders      string                      `form:"http_headers" json:"http_headers" binding:"max=4096"`
-	SuccessPattern   string                      `form:"success_pattern" json:"success_pattern" binding:"max=512"`
-	Timeout          int                         `form:"timeout" json:"timeout" binding:"min=0,max=86400"`
-	Multi            int8                        `form:"multi" json:"multi" binding:"oneof=0 1"`
-	RetryTimes       int8                        `form:"retry_times" json:"retry_times"`
-	RetryInterval    int16                       `form:"retry_interval" json:"retry_interval"`
-	HostId           string                      `form:"host_id" json:"host_id"`
-	Tag              string                      `form:"tag" json:"tag"`
-	Remark           string                      `form:"remark" json:"remark"`
-	NotifyStatus     int8                        `form:"notify_status" json:"notify_status" binding:"oneof=0 1 2 3"`
-	NotifyType       int8                        `form:"notify_type" json:"notify_type" binding:"oneof=0 1 2"`
-	NotifyReceiverId string                      `form:"notify_receiver_id" json:"notify_receiver_id"`
-	NotifyKeyword    string                      `form:"notify_keyword" json:"notify_keyword"`
-	LogRetentionDays int                         `form:"log_retention_days" json:"log_retention_days" binding:"min=0,max=3650"`
+	Id                  int                         `form:"id" json:"id"`
+	Level               models.TaskLevel            `form:"level" json:"level" binding:"required,oneof=1 2"`
+	DependencyStatus    models.TaskDependencyStatus `form:"dependency_status" json:"dependency_status" binding:"oneof=1 2"`
+	DependencyTaskId    string                      `form:"dependency_task_id" json:"dependency_task_id"`
+	Name                string                      `form:"name" json:"name" binding:"required,max=32"`
+	Spec                string                      `form:"spec" json:"spec"`
+	Protocol            models.TaskProtocol         `form:"protocol" json:"protocol" binding:"oneof=1 2 3 4 5 6"`
+	Command             string                      `form:"command" json:"command" binding:"max=65535"`
+	HttpMethod          models.TaskHTTPMethod       `form:"http_method" json:"http_method" binding:"oneof=1 2 3 4 5 6"`
+	HttpBody            string                      `form:"http_body" json:"http_body" binding:"max=65535"`
+	HttpBodyType        string                      `form:"http_body_type" json:"http_body_type" binding:"omitempty,oneof=json form multipart raw"`
+	HttpContentType     string                      `form:"http_content_type" json:"http_content_type" binding:"max=128"`
+	HttpHeaders         string                      `form:"http_headers" json:"http_headers" binding:"max=4096"`
+	SuccessPattern      string                      `form:"success_pattern" json:"success_pattern" binding:"max=512"`
+	HttpSuccessCodes    string                      `form:"http_success_codes" json:"http_success_codes" binding:"max=128"`
+	HttpAssertions      string                      `form:"http_assertions" json:"http_assertions" binding:"max=8192"`
+	HttpMaxResponseTime int                         `form:"http_max_response_time" json:"http_max_response_time" binding:"min=0,max=86400000"`
+	HttpAuthType        string                      `form:"http_auth_type" json:"http_auth_type" binding:"omitempty,oneof=basic bearer oauth2"`
+	HttpAuth            string                      `form:"http_auth" json:"http_auth" binding:"max=4096"`
+	HttpProfileId       int                         `form:"http_profile_id" json:"http_profile_id" binding:"min=0"`
+	HttpAsyncMode       string                      `form:"http_async_mode" json:"http_async_mode" binding:"omitempty,oneof=poll callback"`
+	HttpAsync           string                      `form:"http_async" json:"http_async" binding:"max=8192"`
+	HttpMaxBodySize     int                         `form:"http_max_body_size" json:"http_max_body_size" binding:"min=0,max=10240"`
+	Timeout             int                         `form:"timeout" json:"timeout" binding:"min=0,max=86400"`
+	Multi               int8                        `form:"multi" json:"multi" binding:"oneof=0 1"`
+	RetryTimes          int8                        `form:"retry_times" json:"retry_times"`
+	RetryInterval       int16                       `form:"retry_interval" json:"retry_interval"`
+	HostId              string                      `form:"host_id" json:"host_id"`
+	Tag                 string                      `form:"tag" json:"tag"`
+	Remark              string                      `form:"remark" json:"remark"`
+	Notifications       string                      `form:"notifications" json:"notifications"` // 通知规则 JSON 数组
+	LogRetentionDays    int                         `form:"log_retention_days" json:"log_retention_days" binding:"min=0,max=3650"`
+	SlaMaxDuration      int                         `form:"sla_max_duration" json:"sla_max_duration" binding:"min=0,max=604800"`
+	SlaSuccessInterval  int                         `form:"sla_success_interval" json:"sla_success_interval" binding:"min=0,max=2678400"`
+	HeartbeatGrace      int                         `form:"heartbeat_grace" json:"heartbeat_grace" binding:"min=0,max=86400"`
+	SqlDataSourceId     int                         `form:"sql_data_source_id" json:"sql_data_source_id" binding:"min=0"`
+	SqlTransaction      bool                        `form:"sql_transaction" json:"sql_transaction"`
+	SqlMaxRows          int                         `form:"sql_max_rows" json:"sql_max_rows" binding:"min=0,max=1000"`
+	SqlAssertions       string                      `form:"sql_assertions" json:"sql_assertions" binding:"max=4096"`
+	GrpcMethod          string                      `form:"grpc_method" json:"grpc_method" binding:"max=255"`
+	GrpcRequest         string                      `form:"grpc_request" json:"grpc_request" binding:"max=65535"`
+	GrpcMetadata        string                      `form:"grpc_metadata" json:"grpc_metadata" binding:"max=4096"`
+	GrpcTls             bool                        `form:"grpc_tls" json:"grpc_tls"`
+	GrpcServerName      string                      `form:"grpc_server_name" json:"grpc_server_name" binding:"max=255"`
+	GrpcDescriptorId    int                         `form:"grpc_descriptor_id" json:"grpc_descriptor_id" binding:"min=0"`
 }
 
 // 首页
@@ -96,22 +123,39 @@ func Store(c *gin.Context) {
 		base.RespondValidationError(c, err)
 		return
 	}
+	_, requestId, ok := Save(c, form, models.Running)
+	if !ok {
+		return
+	}
+	if requestId > 0 {
+		base.RespondSuccess(c, fmt.Sprintf(i18n.T(c, "task_enable_pending"), requestId), map[string]interface{}{
+			"change_request_id": requestId,
+		})
+		return
+	}
+
+	base.RespondSuccess(c, i18n.T(c, "save_success"), nil)
+}
 
+// Save 校验并保存任务，保存任务和从模板创建任务共用，status 为新建任务的状态。
+// 新建的受保护任务先停用，启用需要审批，此时返回启用申请的 ID。
+// 校验失败、保存失败或提交了修改申请时已写入响应，返回 false
+func Save(c *gin.Context, form TaskForm, status models.Status) (int, int, bool) {
 	taskModel := models.Task{}
 	var id = form.Id
 	nameExists, err := taskModel.NameExist(form.Name, form.Id)
 	if err != nil {
 		base.RespondErrorWithDefaultMsg(c, err)
-		return
+		return 0, 0, false
 	}
 	if nameExists {
 		base.RespondError(c, i18n.T(c, "task_name_exists"))
-		return
+		return 0, 0, false
 	}
 
-	if form.Protocol == models.TaskRPC && form.HostId == "" {
+	if form.Protocol.RequiresHosts() && form.HostId == "" {
 		base.RespondError(c, i18n.T(c, "select_hostname"))
-		return
+		return 0, 0, false
 	}
 
 	taskModel.Name = form.Name
@@ -123,56 +167,172 @@ func Store(c *gin.Context) {
 		logger.Infof("[HTML Entity Cleaned] Task: %s, Original length: %d, Cleaned length: %d", form.Name, len(originalCmd), len(cleanedCmd))
 	}
 	taskModel.Command = cleanedCmd
+	// 心跳任务不执行命令
+	if taskModel.Protocol == models.TaskHeartbeat {
+		taskModel.Command = ""
+	} else if taskModel.Command == "" {
+		base.RespondError(c, i18n.T(c, "command_required"))
+		return 0, 0, false
+	}
+	if taskModel.Protocol == models.TaskRPC {
+		if prefix, reserved := rpcClient.ReservedCommand(taskModel.Command); reserved {
+			base.RespondError(c, fmt.Sprintf(i18n.T(c, "command_reserved"), prefix))
+			return 0, 0, false
+		}
+	}
 	taskModel.Timeout = form.Timeout
 	taskModel.Tag = form.Tag
 	taskModel.Remark = form.Remark
 	taskModel.Multi = form.Multi
 	taskModel.RetryTimes = form.RetryTimes
 	taskModel.RetryInterval = form.RetryInterval
-	taskModel.NotifyStatus = form.NotifyStatus
-	taskModel.NotifyType = form.NotifyType
-	taskModel.NotifyReceiverId = form.NotifyReceiverId
-	taskModel.NotifyKeyword = form.NotifyKeyword
 	taskModel.LogRetentionDays = form.LogRetentionDays
+	taskModel.SlaMaxDuration = form.SlaMaxDuration
+	taskModel.SlaSuccessInterval = form.SlaSuccessInterval
+	taskModel.HeartbeatGrace = form.HeartbeatGrace
 	taskModel.Spec = form.Spec
 	taskModel.Level = form.Level
 	taskModel.DependencyStatus = form.DependencyStatus
 	taskModel.DependencyTaskId = strings.TrimSpace(form.DependencyTaskId)
-	if taskModel.NotifyStatus > 0 && taskModel.NotifyType != 2 && taskModel.NotifyReceiverId == "" {
-		base.RespondError(c, i18n.T(c, "select_at_least_one_receiver"))
-		return
+	taskModel.Notifications, err = models.ParseTaskNotifications(form.Notifications)
+	if err != nil {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "notify_rules_invalid"), err))
+		return 0, 0, false
+	}
+	for i, rule := range taskModel.Notifications {
+		if err := notify.CheckTemplate(rule.Template); err != nil {
+			base.RespondError(c, fmt.Sprintf(i18n.T(c, "notify_rules_invalid"), fmt.Errorf("rule %d: template: %w", i+1, err)))
+			return 0, 0, false
+		}
 	}
 	taskModel.HttpMethod = form.HttpMethod
 	// 校验 HttpHeaders（JSON 格式 + 黑名单检查）
 	if err := httpclient.ValidateHeaders(form.HttpHeaders); err != nil {
 		base.RespondError(c, "http_headers: "+err.Error())
-		return
+		return 0, 0, false
+	}
+	if taskModel.Protocol == models.TaskHTTP {
+		if err := httpclient.ValidateBody(form.HttpBodyType, form.HttpBody); err != nil {
+			base.RespondError(c, "http_body: "+err.Error())
+			return 0, 0, false
+		}
 	}
 	taskModel.HttpBody = form.HttpBody
 	taskModel.HttpHeaders = form.HttpHeaders
+	taskModel.HttpBodyType = form.HttpBodyType
+	if strings.TrimSpace(form.HttpSuccessCodes) != "" {
+		if _, err := httpclient.ParseStatusCodes(form.HttpSuccessCodes); err != nil {
+			base.RespondError(c, "http_success_codes: "+err.Error())
+			return 0, 0, false
+		}
+	}
+	if _, err := httpclient.ParseAssertions(form.HttpAssertions); err != nil {
+		base.RespondError(c, "http_assertions: "+err.Error())
+		return 0, 0, false
+	}
+	taskModel.HttpSuccessCodes = strings.TrimSpace(form.HttpSuccessCodes)
+	taskModel.HttpAssertions = strings.TrimSpace(form.HttpAssertions)
+	taskModel.HttpMaxResponseTime = form.HttpMaxResponseTime
+	taskModel.HttpContentType = strings.TrimSpace(form.HttpContentType)
+	taskModel.HttpMaxBodySize = form.HttpMaxBodySize
+	if taskModel.Protocol == models.TaskHTTP && form.HttpAuthType != httpclient.AuthNone {
+		if err := validateHttpAuth(form.HttpAuthType, form.HttpAuth); err != nil {
+			base.RespondError(c, "http_auth: "+err.Error())
+			return 0, 0, false
+		}
+		taskModel.HttpAuthType = form.HttpAuthType
+		taskModel.HttpAuth = strings.TrimSpace(form.HttpAuth)
+	}
+	// gRPC 任务开启 TLS 时使用 HTTP 配置中的 CA 和客户端证书
+	usesProfile := taskModel.Protocol == models.TaskHTTP || taskModel.Protocol == models.TaskGRPC && form.GrpcTls
+	if usesProfile && form.HttpProfileId > 0 {
+		if _, err := new(models.HttpProfile).Detail(form.HttpProfileId); err != nil {
+			base.RespondError(c, i18n.T(c, "http_profile_not_found"))
+			return 0, 0, false
+		}
+		taskModel.HttpProfileId = form.HttpProfileId
+	}
+	if taskModel.Protocol == models.TaskHTTP && form.HttpAsyncMode != httpclient.AsyncNone {
+		if _, err := httpclient.ParseAsyncConfig(form.HttpAsyncMode, form.HttpAsync); err != nil {
+			base.RespondError(c, "http_async: "+err.Error())
+			return 0, 0, false
+		}
+		// 回调地址由站点地址生成
+		if form.HttpAsyncMode == httpclient.AsyncCallback && new(models.Setting).GetSiteUrl() == "" {
+			base.RespondError(c, i18n.T(c, "http_async_site_url_required"))
+			return 0, 0, false
+		}
+		taskModel.HttpAsyncMode = form.HttpAsyncMode
+		taskModel.HttpAsync = strings.TrimSpace(form.HttpAsync)
+	}
+	if taskModel.Protocol == models.TaskSQL {
+		if _, err := new(models.DataSource).Detail(form.SqlDataSourceId); form.SqlDataSourceId <= 0 || err != nil {
+			base.RespondError(c, i18n.T(c, "data_source_not_found"))
+			return 0, 0, false
+		}
+		if _, err := sqlrunner.ParseAssertions(form.SqlAssertions); err != nil {
+			base.RespondError(c, "sql_assertions: "+err.Error())
+			return 0, 0, false
+		}
+		// 节点执行时连接信息随命令发送，必须加密传输
+		if len(parseHostIds(form)) > 0 && !service.SQLNodeAllowed() {
+			base.RespondError(c, i18n.T(c, "sql_node_requires_tls"))
+			return 0, 0, false
+		}
+		taskModel.SqlDataSourceId = form.SqlDataSourceId
+		taskModel.SqlTransaction = form.SqlTransaction
+		taskModel.SqlMaxRows = form.SqlMaxRows
+		taskModel.SqlAssertions = strings.TrimSpace(form.SqlAssertions)
+	}
+	if taskModel.Protocol == models.TaskGRPC {
+		if _, _, err := grpcclient.ParseMethod(form.GrpcMethod); err != nil {
+			base.RespondError(c, "grpc_method: "+err.Error())
+			return 0, 0, false
+		}
+		if body := strings.TrimSpace(form.GrpcRequest); body != "" && !json.Valid([]byte(body)) {
+			base.RespondError(c, "grpc_request: invalid JSON")
+			return 0, 0, false
+		}
+		if _, err := grpcclient.ParseMetadata(form.GrpcMetadata); err != nil {
+			base.RespondError(c, "grpc_metadata: "+err.Error())
+			return 0, 0, false
+		}
+		if form.GrpcDescriptorId > 0 {
+			if exists, err := new(models.GrpcDescriptor).Exists(form.GrpcDescriptorId); err != nil || !exists {
+				base.RespondError(c, i18n.T(c, "grpc_descriptor_not_found"))
+				return 0, 0, false
+			}
+		}
+		taskModel.GrpcMethod = strings.TrimSpace(form.GrpcMethod)
+		taskModel.GrpcRequest = strings.TrimSpace(form.GrpcRequest)
+		taskModel.GrpcMetadata = strings.TrimSpace(form.GrpcMetadata)
+		taskModel.GrpcTls = form.GrpcTls
+		taskModel.GrpcServerName = strings.TrimSpace(form.GrpcServerName)
+		taskModel.GrpcDescriptorId = form.GrpcDescriptorId
+	}
 	taskModel.SuccessPattern = form.SuccessPattern
 	if taskModel.Protocol == models.TaskHTTP {
 		command := strings.ToLower(taskModel.Command)
 		if !strings.HasPrefix(command, "http://") && !strings.HasPrefix(command, "https://") {
 			base.RespondError(c, i18n.T(c, "invalid_url"))
-			return
+			return 0, 0, false
 		}
 	}
 
 	if taskModel.RetryTimes > 10 || taskModel.RetryTimes < 0 {
 		base.RespondError(c, i18n.T(c, "retry_times_range_0_10"))
-		return
+		return 0, 0, false
 	}
 
 	if taskModel.RetryInterval > 3600 || taskModel.RetryInterval < 0 {
 		base.RespondError(c, i18n.T(c, "retry_interval_range_0_3600"))
-		return
+		return 0, 0, false
 	}
 
 	if taskModel.DependencyStatus != models.TaskDependencyStatusStrong &&
 		taskModel.DependencyStatus != models.TaskDependencyStatusWeak {
 		base.RespondError(c, i18n.T(c, "select_dependency"))
-		return
+		return 0, 0, false
 	}
 
 	if taskModel.Level == models.TaskLevelParent {
@@ -181,7 +341,7 @@ func Store(c *gin.Context) {
 		})
 		if err != nil {
 			base.RespondError(c, i18n.T(c, "crontab_parse_failed"), err)
-			return
+			return 0, 0, false
 		}
 	} else {
 		taskModel.DependencyTaskId = ""
@@ -192,12 +352,17 @@ func Store(c *gin.Context) {
 		dependencyTaskIds := strings.Split(taskModel.DependencyTaskId, ",")
 		if utils.InStringSlice(dependencyTaskIds, strconv.Itoa(id)) {
 			base.RespondError(c, i18n.T(c, "cannot_set_self_as_child"))
-			return
+			return 0, 0, false
 		}
 	}
 
+	pendingEnable := false
 	if id == 0 {
-		taskModel.Status = models.Running
+		pendingEnable = status == models.Enabled && isProtected(taskModel.Tag)
+		taskModel.Status = status
+		if pendingEnable {
+			taskModel.Status = models.Disabled
+		}
 		logger.Infof("[Task Create] Before Create - Multi: %d", taskModel.Multi)
 		id, err = taskModel.Create()
 		if err == nil {
@@ -212,19 +377,26 @@ func Store(c *gin.Context) {
 		// 更新前记录旧值用于审计 diff
 		oldTask, _ := taskModel.Detail(id)
 
-		// 保存脚本版本（命令变更时）
-		if oldTask.Command != taskModel.Command {
-			versionModel := new(models.TaskScriptVersion)
-			latestVersion, _ := versionModel.GetLatestVersion(id)
-			newVersion := &models.TaskScriptVersion{
-				TaskId:   id,
-				Command:  oldTask.Command,
-				Username: user.Username(c),
-				Version:  latestVersion + 1,
-			}
-			if _, vErr := newVersion.Create(); vErr != nil {
-				logger.Warnf("保存脚本版本失败 TaskID-%d: %v", id, vErr)
+		// 任务定义（含主机和依赖）变更时保存旧定义为新版本
+		oldDefinition := models.NewTaskDefinition(oldTask)
+		newDefinition := models.NewTaskDefinitionWithHosts(taskModel, parseHostIds(form))
+		changes := oldDefinition.Diff(newDefinition)
+		// 受保护任务的修改先生成变更申请，审批通过后才生效
+		if len(changes) > 0 && isProtected(oldTask.Tag, taskModel.Tag) {
+			submitChangeRequest(c, &models.TaskChangeRequest{
+				TaskId:     id,
+				TaskName:   oldTask.Name,
+				Type:       models.ChangeRequestUpdate,
+				Base:       &oldDefinition,
+				Definition: &newDefinition,
+			}, "task_change_pending")
+			return 0, 0, false
+		}
+		if len(changes) > 0 {
+			if _, vErr := models.RecordTaskVersion(models.Db, id, oldDefinition, user.Username(c), ""); vErr != nil {
+				logger.Warnf("保存任务版本失败 TaskID-%d: %v", id, vErr)
 			}
+			versionModel := new(models.TaskScriptVersion)
 			if cErr := versionModel.CleanOldVersions(id, 30); cErr != nil {
 				logger.Warnf("清理旧版本失败 TaskID-%d: %v", id, cErr)
 			}
@@ -238,24 +410,20 @@ func Store(c *gin.Context) {
 			logger.Infof("[Task Update] After Update - ID: %d, Multi in DB: %d", id, verifyTask.Multi)
 
 			// 生成审计 diff
-			if diff := buildTaskDiff(oldTask, verifyTask); diff != "" {
-				c.Set("audit_detail", diff)
+			if len(changes) > 0 {
+				c.Set("audit_detail", models.FormatTaskChanges(changes))
 			}
 		}
 	}
 
 	if err != nil {
 		base.RespondError(c, i18n.T(c, "save_failed"), err)
-		return
+		return 0, 0, false
 	}
 
 	taskHostModel := new(models.TaskHost)
-	if form.Protocol == models.TaskRPC {
-		hostIdStrList := strings.Split(form.HostId, ",")
-		hostIds := make([]int, len(hostIdStrList))
-		for i, hostIdStr := range hostIdStrList {
-			hostIds[i], _ = strconv.Atoi(hostIdStr)
-		}
+	// SQL 任务可以不关联主机，此时由服务端执行
+	if hostIds := parseHostIds(form); len(hostIds) > 0 {
 		if err := taskHostModel.Add(id, hostIds); err != nil {
 			logger.Errorf("保存任务主机关联失败#任务ID-%d#%s", id, err)
 		}
@@ -265,12 +433,39 @@ func Store(c *gin.Context) {
 		}
 	}
 
-	status, _ := taskModel.GetStatus(id)
-	if status == models.Enabled && taskModel.Level == models.TaskLevelParent {
+	if err := models.SaveTaskNotifications(id, taskModel.Notifications); err != nil {
+		logger.Errorf("保存任务通知规则失败#任务ID-%d#%s", id, err)
+	}
+
+	if pendingEnable {
+		requestId, err := createChangeRequest(c, &models.TaskChangeRequest{
+			TaskId:   id,
+			TaskName: taskModel.Name,
+			Type:     models.ChangeRequestEnable,
+		})
+		if err != nil {
+			base.RespondError(c, i18n.T(c, "save_failed"), err)
+			return 0, 0, false
+		}
+		c.Set("audit_detail", fmt.Sprintf("pending change request #%d", requestId))
+		return id, requestId, true
+	}
+	if current, _ := taskModel.GetStatus(id); current == models.Enabled && taskModel.Level == models.TaskLevelParent {
 		addTaskToTimer(id)
 	}
 
-	base.RespondSuccess(c, i18n.T(c, "save_success"), nil)
+	return id, 0, true
+}
+
+// validateHttpAuth 校验认证配置，引用的密钥必须已存在
+func validateHttpAuth(authType, auth string) error {
+	config, err := httpclient.ParseAuthConfig(authType, strings.TrimSpace(auth))
+	if err != nil {
+		return err
+	}
+	_, err = config.Resolve(authType, models.LookupSecret)
+
+	return err
 }
 
 // 删除任务
@@ -280,18 +475,39 @@ func Remove(c *gin.Context) {
 		base.RespondError(c, i18n.T(c, "param_error"))
 		return
 	}
-	taskModel := new(models.Task)
-	_, err = taskModel.Delete(id)
-	if err != nil {
+	// 受保护任务的删除先生成变更申请
+	if task, ok := protectedTask(id); ok {
+		submitChangeRequest(c, &models.TaskChangeRequest{
+			TaskId:   id,
+			TaskName: task.Name,
+			Type:     models.ChangeRequestRemove,
+		}, "task_change_pending")
+		return
+	}
+	if err = removeTask(id); err != nil {
 		base.RespondErrorWithDefaultMsg(c, err)
-	} else {
-		taskHostModel := new(models.TaskHost)
-		if err := taskHostModel.Remove(id); err != nil {
-			logger.Errorf("移除任务主机关联失败#任务ID-%d#%s", id, err)
-		}
-		service.ServiceTask.Remove(id)
-		base.RespondSuccessWithDefaultMsg(c, nil)
+		return
+	}
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// removeTask 删除任务及其主机关联、通知规则和附件，并从调度中移除
+func removeTask(id int) error {
+	if _, err := new(models.Task).Delete(id); err != nil {
+		return err
 	}
+	if err := new(models.TaskHost).Remove(id); err != nil {
+		logger.Errorf("移除任务主机关联失败#任务ID-%d#%s", id, err)
+	}
+	if err := new(models.TaskNotification).Remove(id); err != nil {
+		logger.Errorf("移除任务通知规则失败#任务ID-%d#%s", id, err)
+	}
+	if err := new(models.TaskArtifact).RemoveByTask(id); err != nil {
+		logger.Errorf("移除任务附件失败#任务ID-%d#%s", id, err)
+	}
+	service.ServiceTask.Remove(id)
+
+	return nil
 }
 
 // 激活任务
@@ -316,6 +532,10 @@ func Run(c *gin.Context) {
 	if err != nil || task.Id <= 0 {
 		base.RespondError(c, i18n.T(c, "get_task_detail_failed"), err)
 	} else {
+		if isProtected(task.Tag) {
+			submitRunRequest(c, task)
+			return
+		}
 		task.Spec = i18n.T(c, "manual_run")
 		service.ServiceTask.Run(task)
 		base.RespondSuccess(c, i18n.T(c, "task_started_check_log"), nil)
@@ -342,26 +562,22 @@ func batchChangeStatus(c *gin.Context, status models.Status) {
 		return
 	}
 
-	taskModel := new(models.Task)
 	successCount := 0
+	pending := make([]int, 0)
 	for _, id := range form.Ids {
-		_, err := taskModel.Update(id, models.CommonMap{
-			"status": status,
-		})
-		if err == nil {
-			successCount++
-			if status == models.Enabled {
-				addTaskToTimer(id)
-			} else {
-				service.ServiceTask.Remove(id)
+		// 受保护任务的启停先生成变更申请
+		if task, ok := protectedTask(id); ok && task.Status != status {
+			if requestId, err := submitBatchChangeRequest(c, task, statusChangeRequestType(status)); err == nil {
+				pending = append(pending, requestId)
 			}
+			continue
+		}
+		if err := setTaskStatus(id, status); err == nil {
+			successCount++
 		}
 	}
 
-	base.RespondSuccess(c, i18n.T(c, "operation_success"), map[string]interface{}{
-		"success_count": successCount,
-		"total_count":   len(form.Ids),
-	})
+	respondBatchResult(c, successCount, len(form.Ids), pending)
 }
 
 // 批量删除任务
@@ -374,24 +590,54 @@ func BatchRemove(c *gin.Context) {
 		return
 	}
 
-	taskModel := new(models.Task)
-	taskHostModel := new(models.TaskHost)
 	successCount := 0
+	pending := make([]int, 0)
 	for _, id := range form.Ids {
-		_, err := taskModel.Delete(id)
-		if err == nil {
-			successCount++
-			if err := taskHostModel.Remove(id); err != nil {
-				logger.Errorf("移除任务主机关联失败#任务ID-%d#%s", id, err)
+		// 受保护任务的删除先生成变更申请
+		if task, ok := protectedTask(id); ok {
+			if requestId, err := submitBatchChangeRequest(c, task, models.ChangeRequestRemove); err == nil {
+				pending = append(pending, requestId)
 			}
-			service.ServiceTask.Remove(id)
+			continue
+		}
+		if err := removeTask(id); err == nil {
+			successCount++
 		}
 	}
 
-	base.RespondSuccess(c, i18n.T(c, "operation_success"), map[string]interface{}{
-		"success_count": successCount,
-		"total_count":   len(form.Ids),
+	respondBatchResult(c, successCount, len(form.Ids), pending)
+}
+
+// submitBatchChangeRequest 批量操作中为受保护任务创建变更申请
+func submitBatchChangeRequest(c *gin.Context, task models.Task, requestType string) (int, error) {
+	requestId, err := createChangeRequest(c, &models.TaskChangeRequest{
+		TaskId:   task.Id,
+		TaskName: task.Name,
+		Type:     requestType,
 	})
+	if err != nil {
+		logger.Errorf("创建变更申请失败#任务ID-%d#%s", task.Id, err)
+	}
+	return requestId, err
+}
+
+// respondBatchResult 返回批量操作结果，受保护任务生成的变更申请记入审计
+func respondBatchResult(c *gin.Context, successCount, totalCount int, pending []int) {
+	data := map[string]interface{}{
+		"success_count": successCount,
+		"total_count":   totalCount,
+	}
+	if len(pending) == 0 {
+		base.RespondSuccess(c, i18n.T(c, "operation_success"), data)
+		return
+	}
+	ids := make([]string, len(pending))
+	for i, id := range pending {
+		ids[i] = fmt.Sprintf("#%d", id)
+	}
+	c.Set("audit_detail", "pending change requests "+strings.Join(ids, ", "))
+	data["change_request_ids"] = pending
+	base.RespondSuccess(c, fmt.Sprintf(i18n.T(c, "task_batch_pending"), len(pending)), data)
 }
 
 // 改变任务状态
@@ -401,20 +647,37 @@ func changeStatus(c *gin.Context, status models.Status) {
 		base.RespondError(c, i18n.T(c, "param_error"))
 		return
 	}
-	taskModel := new(models.Task)
-	_, err = taskModel.Update(id, models.CommonMap{
+	// 受保护任务的启停先生成变更申请
+	if task, ok := protectedTask(id); ok && task.Status != status {
+		submitChangeRequest(c, &models.TaskChangeRequest{
+			TaskId:   id,
+			TaskName: task.Name,
+			Type:     statusChangeRequestType(status),
+		}, "task_change_pending")
+		return
+	}
+	if err = setTaskStatus(id, status); err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	base.RespondSuccessWithDefaultMsg(c, nil)
+}
+
+// setTaskStatus 更新任务状态并同步调度
+func setTaskStatus(id int, status models.Status) error {
+	_, err := new(models.Task).Update(id, models.CommonMap{
 		"status": status,
 	})
 	if err != nil {
-		base.RespondErrorWithDefaultMsg(c, err)
+		return err
+	}
+	if status == models.Enabled {
+		addTaskToTimer(id)
 	} else {
-		if status == models.Enabled {
-			addTaskToTimer(id)
-		} else {
-			service.ServiceTask.Remove(id)
-		}
-		base.RespondSuccessWithDefaultMsg(c, nil)
+		service.ServiceTask.Remove(id)
 	}
+
+	return nil
 }
 
 // 添加任务到定时器
@@ -463,52 +726,15 @@ func parseQueryParams(c *gin.Context) models.CommonMap {
 	return params
 }
 
-// buildTaskDiff 对比任务的旧值和新值，返回可读的变更摘要
-func buildTaskDiff(old, new models.Task) string {
-	type change struct {
-		Field string `json:"field"`
-		Old   string `json:"old"`
-		New   string `json:"new"`
+// parseHostIds 解析表单中的主机 ID，HTTP 任务不绑定主机
+func parseHostIds(form TaskForm) []int {
+	if !form.Protocol.UsesHosts() || strings.TrimSpace(form.HostId) == "" {
+		return []int{}
 	}
-	var changes []change
-
-	add := func(field, oldVal, newVal string) {
-		if oldVal != newVal {
-			changes = append(changes, change{field, oldVal, newVal})
-		}
-	}
-
-	add("name", old.Name, new.Name)
-	add("spec", old.Spec, new.Spec)
-	add("command", old.Command, new.Command)
-	add("tag", old.Tag, new.Tag)
-	add("timeout", strconv.Itoa(old.Timeout), strconv.Itoa(new.Timeout))
-	add("retry_times", strconv.Itoa(int(old.RetryTimes)), strconv.Itoa(int(new.RetryTimes)))
-	add("retry_interval", strconv.Itoa(int(old.RetryInterval)), strconv.Itoa(int(new.RetryInterval)))
-	add("remark", old.Remark, new.Remark)
-	add("http_method", strconv.Itoa(int(old.HttpMethod)), strconv.Itoa(int(new.HttpMethod)))
-	add("http_body", old.HttpBody, new.HttpBody)
-	add("http_headers", old.HttpHeaders, new.HttpHeaders)
-	add("success_pattern", old.SuccessPattern, new.SuccessPattern)
-	add("notify_status", strconv.Itoa(int(old.NotifyStatus)), strconv.Itoa(int(new.NotifyStatus)))
-	add("notify_keyword", old.NotifyKeyword, new.NotifyKeyword)
-	add("log_retention_days", strconv.Itoa(old.LogRetentionDays), strconv.Itoa(new.LogRetentionDays))
-
-	if len(changes) == 0 {
-		return ""
-	}
-
-	// 生成简洁的文本格式
-	var b strings.Builder
-	for i, ch := range changes {
-		if i > 0 {
-			b.WriteString("\n")
-		}
-		b.WriteString(ch.Field)
-		b.WriteString(": ")
-		b.WriteString(ch.Old)
-		b.WriteString(" → ")
-		b.WriteString(ch.New)
+	hostIdStrList := strings.Split(form.HostId, ",")
+	hostIds := make([]int, len(hostIdStrList))
+	for i, hostIdStr := range hostIdStrList {
+		hostIds[i], _ = strconv.Atoi(hostIdStr)
 	}
-	return b.String()
+	return hostIds
 }
@@ -0,0 +1,284 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+	"github.com/gocronx-team/gocron/internal/routers/user"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+// isProtected 任务标签（修改前或修改后）命中受保护标签时返回 true
+func isProtected(tags ...string) bool {
+	protectedTags := new(models.Setting).GetProtectedTags()
+	for _, tag := range tags {
+		if models.IsProtectedTag(tag, protectedTags) {
+			return true
+		}
+	}
+	return false
+}
+
+// submitChangeRequest 创建待审批的变更申请，审计日志记录提交
+func submitChangeRequest(c *gin.Context, request *models.TaskChangeRequest, messageKey string) {
+	id, err := createChangeRequest(c, request)
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "save_failed"), err)
+		return
+	}
+
+	detail := fmt.Sprintf("pending change request #%d", id)
+	if changes := request.Changes(); len(changes) > 0 {
+		detail += "\n" + models.FormatTaskChanges(changes)
+	}
+	c.Set("audit_target_id", request.TaskId)
+	c.Set("audit_target_name", request.TaskName)
+	c.Set("audit_detail", detail)
+
+	base.RespondSuccess(c, fmt.Sprintf(i18n.T(c, messageKey), id), map[string]interface{}{
+		"change_request_id": id,
+	})
+}
+
+// createChangeRequest 以当前用户为作者创建变更申请
+func createChangeRequest(c *gin.Context, request *models.TaskChangeRequest) (int, error) {
+	request.Author = user.Username(c)
+	return request.Create()
+}
+
+// protectedTask 返回受保护的任务；任务不存在或不受保护时返回 false
+func protectedTask(id int) (models.Task, bool) {
+	task, err := new(models.Task).Detail(id)
+	if err != nil || task.Id <= 0 {
+		return task, false
+	}
+	return task, isProtected(task.Tag)
+}
+
+// statusChangeRequestType 启用或停用对应的变更申请类型
+func statusChangeRequestType(status models.Status) string {
+	if status == models.Enabled {
+		return models.ChangeRequestEnable
+	}
+	return models.ChangeRequestDisable
+}
+
+// submitRunRequest 受保护任务的手动执行先提交申请。
+// 手动执行是 GET 请求，审计中间件不会记录，这里直接写入审计日志。
+func submitRunRequest(c *gin.Context, task models.Task) {
+	request := &models.TaskChangeRequest{
+		TaskId:   task.Id,
+		TaskName: task.Name,
+		Type:     models.ChangeRequestRun,
+		Author:   user.Username(c),
+	}
+	id, err := request.Create()
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+
+	auditLog := &models.AuditLog{
+		Username:   request.Author,
+		Ip:         utils.ClientIP(c),
+		Module:     "task",
+		Action:     "run",
+		TargetId:   task.Id,
+		TargetName: task.Name,
+		Detail:     fmt.Sprintf("pending change request #%d", id),
+	}
+	if _, err := auditLog.Create(); err != nil {
+		logger.Warnf("写入审计日志失败: %v", err)
+	}
+
+	base.RespondSuccess(c, fmt.Sprintf(i18n.T(c, "task_run_pending"), id), map[string]interface{}{
+		"change_request_id": id,
+	})
+}
+
+// ChangeRequestList 变更申请列表
+func ChangeRequestList(c *gin.Context) {
+	requestModel := new(models.TaskChangeRequest)
+	params := models.CommonMap{}
+	base.ParsePageAndPageSize(c, params)
+	params["Status"] = c.Query("status")
+	params["TaskId"], _ = strconv.Atoi(c.Query("task_id"))
+
+	total, err := requestModel.Total(params)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	list, err := requestModel.List(params)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	for i := range list {
+		list[i] = list[i].Masked()
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"total": total,
+		"data":  list,
+	})
+	c.String(http.StatusOK, result)
+}
+
+// ChangeRequestDetail 变更申请详情，包含字段级变更
+func ChangeRequestDetail(c *gin.Context) {
+	request, ok := changeRequestFromParam(c)
+	if !ok {
+		return
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"request": request.Masked(),
+		"changes": request.Changes(),
+	})
+	c.String(http.StatusOK, result)
+}
+
+// ChangeRequestApprove 审批通过：修改和回滚申请应用到任务并重新调度，执行申请立即执行任务，
+// 启停和删除申请执行对应操作
+func ChangeRequestApprove(c *gin.Context) {
+	request, ok := changeRequestFromParam(c)
+	if !ok {
+		return
+	}
+	comment := c.PostForm("comment")
+
+	err := request.Approve(user.Username(c), comment)
+	if err != nil {
+		respondChangeRequestError(c, err)
+		return
+	}
+	c.Set("audit_target_id", request.TaskId)
+	c.Set("audit_target_name", request.TaskName)
+	c.Set("audit_detail", fmt.Sprintf("approve %s change request #%d by %s", request.Type, request.Id, request.Author))
+
+	taskModel := new(models.Task)
+	task, err := taskModel.Detail(request.TaskId)
+	if err != nil || task.Id <= 0 {
+		base.RespondError(c, i18n.T(c, "get_task_detail_failed"), err)
+		return
+	}
+	switch request.Type {
+	case models.ChangeRequestRun:
+		task.Spec = i18n.T(c, "manual_run")
+		service.ServiceTask.Run(task)
+	case models.ChangeRequestEnable, models.ChangeRequestDisable:
+		status := models.Disabled
+		if request.Type == models.ChangeRequestEnable {
+			status = models.Enabled
+		}
+		if err = setTaskStatus(task.Id, status); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+	case models.ChangeRequestRemove:
+		if err = removeTask(task.Id); err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+	default:
+		versionModel := new(models.TaskScriptVersion)
+		if cErr := versionModel.CleanOldVersions(task.Id, 30); cErr != nil {
+			logger.Warnf("清理旧版本失败 TaskID-%d: %v", task.Id, cErr)
+		}
+		if task.Status == models.Enabled && task.Level == models.TaskLevelParent {
+			service.ServiceTask.RemoveAndAdd(task)
+		} else {
+			service.ServiceTask.Remove(task.Id)
+		}
+	}
+
+	base.RespondSuccess(c, i18n.T(c, "change_request_approved"), nil)
+}
+
+// ChangeRequestReject 驳回变更申请
+func ChangeRequestReject(c *gin.Context) {
+	request, ok := changeRequestFromParam(c)
+	if !ok {
+		return
+	}
+	comment := c.PostForm("comment")
+
+	if err := request.Reject(user.Username(c), comment); err != nil {
+		respondChangeRequestError(c, err)
+		return
+	}
+	c.Set("audit_target_id", request.TaskId)
+	c.Set("audit_target_name", request.TaskName)
+	detail := fmt.Sprintf("reject %s change request #%d by %s", request.Type, request.Id, request.Author)
+	if comment != "" {
+		detail += "\n" + comment
+	}
+	c.Set("audit_detail", detail)
+
+	base.RespondSuccess(c, i18n.T(c, "change_request_rejected"), nil)
+}
+
+// ChangeRequestCancel 提交人撤回变更申请
+func ChangeRequestCancel(c *gin.Context) {
+	request, ok := changeRequestFromParam(c)
+	if !ok {
+		return
+	}
+
+	if err := request.Cancel(user.Username(c)); err != nil {
+		respondChangeRequestError(c, err)
+		return
+	}
+	c.Set("audit_target_id", request.TaskId)
+	c.Set("audit_target_name", request.TaskName)
+	c.Set("audit_detail", fmt.Sprintf("cancel %s change request #%d", request.Type, request.Id))
+
+	base.RespondSuccess(c, i18n.T(c, "change_request_cancelled"), nil)
+}
+
+func changeRequestFromParam(c *gin.Context) (models.TaskChangeRequest, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return models.TaskChangeRequest{}, false
+	}
+	requestModel := new(models.TaskChangeRequest)
+	request, err := requestModel.Detail(id)
+	if err != nil || request.Id == 0 {
+		base.RespondError(c, i18n.T(c, "change_request_not_found"))
+		return request, false
+	}
+	return request, true
+}
+
+func respondChangeRequestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrChangeRequestNotPending):
+		base.RespondError(c, i18n.T(c, "change_request_not_pending"))
+	case errors.Is(err, models.ErrChangeRequestSelfApproval):
+		base.RespondError(c, i18n.T(c, "change_request_self_review"))
+	case errors.Is(err, models.ErrChangeRequestNotAuthor):
+		base.RespondError(c, i18n.T(c, "change_request_not_author"))
+	case errors.Is(err, models.ErrChangeRequestStale):
+		base.RespondError(c, i18n.T(c, "change_request_stale"))
+	case errors.Is(err, models.ErrRollbackNameExists):
+		base.RespondError(c, i18n.T(c, "task_name_exists"))
+	case errors.Is(err, models.ErrRollbackHostsMissing):
+		base.RespondError(c, i18n.T(c, "rollback_hosts_missing"))
+	case errors.Is(err, models.ErrRollbackMasked):
+		base.RespondError(c, i18n.T(c, "rollback_masked"))
+	default:
+		base.RespondErrorWithDefaultMsg(c, err)
+	}
+}
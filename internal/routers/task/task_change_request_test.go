@@ -0,0 +1,234 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/ncruces/go-sqlite3/gormlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func setupChangeRequestRouter(t *testing.T) (*gin.Engine, func()) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	originalDb := models.Db
+
+	db, err := gorm.Open(gormlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	err = db.AutoMigrate(&models.Task{}, &models.TaskHost{}, &models.Host{}, &models.TaskNotification{},
+		&models.TaskScriptVersion{}, &models.TaskChangeRequest{}, &models.TaskArtifact{}, &models.Setting{})
+	if err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	models.Db = db
+	if err := new(models.Setting).UpdateProtectedTags([]string{"prod"}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("username", c.GetHeader("X-Username"))
+	})
+	r.POST("/api/task/store", Store)
+	r.POST("/api/task/remove/:id", Remove)
+	r.POST("/api/task/enable/:id", Enable)
+	r.POST("/api/task/disable/:id", Disable)
+	r.POST("/api/task/batch-disable", BatchDisable)
+	r.POST("/api/task/batch-remove", BatchRemove)
+	r.POST("/api/task/change-requests/:id/approve", ChangeRequestApprove)
+	r.POST("/api/task/artifact/upload/:id", UploadArtifact)
+	r.POST("/api/task/artifact/restore/:id/:artifact_id", RestoreArtifact)
+	r.POST("/api/task/artifact/remove/:id", RemoveArtifact)
+
+	return r, func() {
+		models.Db = originalDb
+	}
+}
+
+func postAs(t *testing.T, r *gin.Engine, username, path, contentType string, body string) apiResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-Username", username)
+	r.ServeHTTP(w, req)
+
+	var resp apiResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", w.Body.String(), err)
+	}
+	return resp
+}
+
+func changeRequestId(t *testing.T, resp apiResponse) int {
+	t.Helper()
+	var data struct {
+		ChangeRequestId int `json:"change_request_id"`
+	}
+	_ = json.Unmarshal(resp.Data, &data)
+	if resp.Code != 0 || data.ChangeRequestId <= 0 {
+		t.Fatalf("expected a change request, got %+v", resp)
+	}
+	return data.ChangeRequestId
+}
+
+func createRouterTestTask(t *testing.T, name, tag string, status models.Status) models.Task {
+	t.Helper()
+	task := models.Task{Name: name, Level: models.TaskLevelParent, Spec: "0 0 2 * * *", Protocol: models.TaskHTTP,
+		Command: "http://example.com", Tag: tag, Status: status, DependencyStatus: models.TaskDependencyStatusStrong}
+	if _, err := task.Create(); err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func TestProtectedTaskStatusAndRemoveNeedApproval(t *testing.T) {
+	r, cleanup := setupChangeRequestRouter(t)
+	defer cleanup()
+
+	protected := createRouterTestTask(t, "billing", "prod", models.Enabled)
+	plain := createRouterTestTask(t, "report", "dev", models.Enabled)
+
+	// 受保护任务停用先生成申请，任务保持启用
+	requestId := changeRequestId(t, postAs(t, r, "alice", fmt.Sprintf("/api/task/disable/%d", protected.Id), "", ""))
+	if status, _ := protected.GetStatus(protected.Id); status != models.Enabled {
+		t.Fatal("pending disable must not change the task")
+	}
+	if resp := postAs(t, r, "alice", fmt.Sprintf("/api/task/change-requests/%d/approve", requestId), "", ""); resp.Code == 0 {
+		t.Fatal("author must not approve own request")
+	}
+	if resp := postAs(t, r, "bob", fmt.Sprintf("/api/task/change-requests/%d/approve", requestId), "", ""); resp.Code != 0 {
+		t.Fatalf("approve failed: %+v", resp)
+	}
+	if status, _ := protected.GetStatus(protected.Id); status != models.Disabled {
+		t.Fatal("approved disable should disable the task")
+	}
+
+	// 批量操作只直接处理不受保护的任务
+	ids := fmt.Sprintf(`{"ids":[%d,%d]}`, protected.Id, plain.Id)
+	resp := postAs(t, r, "alice", "/api/task/batch-remove", "application/json", ids)
+	var batch struct {
+		SuccessCount     int   `json:"success_count"`
+		ChangeRequestIds []int `json:"change_request_ids"`
+	}
+	_ = json.Unmarshal(resp.Data, &batch)
+	if resp.Code != 0 || batch.SuccessCount != 1 || len(batch.ChangeRequestIds) != 1 {
+		t.Fatalf("unexpected batch result: %+v", resp)
+	}
+	if detail, _ := new(models.Task).Detail(protected.Id); detail.Id != protected.Id {
+		t.Fatal("pending remove must not delete the protected task")
+	}
+	if detail, _ := new(models.Task).Detail(plain.Id); detail.Id != 0 {
+		t.Fatal("unprotected task should be deleted directly")
+	}
+	if resp := postAs(t, r, "bob", fmt.Sprintf("/api/task/change-requests/%d/approve", batch.ChangeRequestIds[0]), "", ""); resp.Code != 0 {
+		t.Fatalf("approve failed: %+v", resp)
+	}
+	if detail, _ := new(models.Task).Detail(protected.Id); detail.Id != 0 {
+		t.Fatal("approved remove should delete the task")
+	}
+}
+
+func TestStoreProtectedTaskStartsDisabled(t *testing.T) {
+	r, cleanup := setupChangeRequestRouter(t)
+	defer cleanup()
+
+	form := url.Values{
+		"name":              {"billing"},
+		"level":             {"1"},
+		"dependency_status": {"1"},
+		"spec":              {"0 0 2 * * *"},
+		"protocol":          {"1"},
+		"http_method":       {"1"},
+		"command":           {"http://example.com"},
+		"multi":             {"0"},
+		"tag":               {"prod"},
+	}
+	requestId := changeRequestId(t, postAs(t, r, "alice", "/api/task/store", "application/x-www-form-urlencoded", form.Encode()))
+
+	list := make([]models.Task, 0)
+	models.Db.Find(&list)
+	if len(list) != 1 || list[0].Status != models.Disabled {
+		t.Fatalf("new protected task should be saved disabled: %+v", list)
+	}
+	request, _ := new(models.TaskChangeRequest).Detail(requestId)
+	if request.Type != models.ChangeRequestEnable || request.TaskId != list[0].Id {
+		t.Fatalf("unexpected change request: %+v", request)
+	}
+	if resp := postAs(t, r, "bob", fmt.Sprintf("/api/task/change-requests/%d/approve", requestId), "", ""); resp.Code != 0 {
+		t.Fatalf("approve failed: %+v", resp)
+	}
+	if status, _ := list[0].GetStatus(list[0].Id); status != models.Enabled {
+		t.Fatal("approved enable should enable the task")
+	}
+
+	// 不受保护的任务直接按请求状态保存
+	form.Set("name", "report")
+	form.Set("tag", "dev")
+	if resp := postAs(t, r, "alice", "/api/task/store", "application/x-www-form-urlencoded", form.Encode()); resp.Code != 0 || strings.Contains(string(resp.Data), "change_request_id") {
+		t.Fatalf("unprotected task should be saved directly: %+v", resp)
+	}
+}
+
+func TestProtectedTaskArtifactsCannotChange(t *testing.T) {
+	r, cleanup := setupChangeRequestRouter(t)
+	defer cleanup()
+
+	protected := createRouterTestTask(t, "deploy", "prod", models.Enabled)
+	protected.Protocol = models.TaskRPC
+	protected.Command = "./deploy.sh"
+	if _, err := protected.UpdateBean(protected.Id); err != nil {
+		t.Fatal(err)
+	}
+	saved := models.TaskArtifact{TaskId: protected.Id, Name: "deploy.sh", Username: "alice"}
+	saved.SetContent([]byte("echo v1"))
+	if _, err := saved.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "deploy.sh")
+	_, _ = part.Write([]byte("echo v2"))
+	_ = writer.Close()
+	if resp := postAs(t, r, "alice", fmt.Sprintf("/api/task/artifact/upload/%d", protected.Id), writer.FormDataContentType(), body.String()); resp.Code == 0 {
+		t.Fatal("upload to a protected task must be rejected")
+	}
+	if resp := postAs(t, r, "alice", fmt.Sprintf("/api/task/artifact/restore/%d/%d", protected.Id, saved.Id), "", ""); resp.Code == 0 {
+		t.Fatal("restore on a protected task must be rejected")
+	}
+	if resp := postAs(t, r, "alice", fmt.Sprintf("/api/task/artifact/remove/%d", protected.Id), "application/x-www-form-urlencoded", "name=deploy.sh"); resp.Code == 0 {
+		t.Fatal("remove on a protected task must be rejected")
+	}
+	list, _ := new(models.TaskArtifact).List(protected.Id)
+	if len(list) != 1 {
+		t.Fatalf("artifacts of the protected task must not change: %+v", list)
+	}
+
+	// 移除受保护标签后可以修改
+	protected.Tag = "dev"
+	if _, err := protected.UpdateBean(protected.Id); err != nil {
+		t.Fatal(err)
+	}
+	if resp := postAs(t, r, "alice", fmt.Sprintf("/api/task/artifact/remove/%d", protected.Id), "application/x-www-form-urlencoded", "name=deploy.sh"); resp.Code != 0 {
+		t.Fatalf("remove failed: %+v", resp)
+	}
+}
@@ -1,6 +1,8 @@
 package task
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -12,7 +14,6 @@ import (
 	"github.com/gocronx-team/gocron/internal/routers/base"
 	"github.com/gocronx-team/gocron/internal/routers/user"
 	"github.com/gocronx-team/gocron/internal/service"
-	"gorm.io/gorm"
 )
 
 // VersionList 获取任务脚本版本列表
@@ -38,6 +39,9 @@ func VersionList(c *gin.Context) {
 		base.RespondErrorWithDefaultMsg(c, err)
 		return
 	}
+	for i := range list {
+		list[i] = list[i].Masked()
+	}
 
 	jsonResp := utils.JsonResponse{}
 	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
@@ -64,11 +68,72 @@ func VersionDetail(c *gin.Context) {
 	}
 
 	jsonResp := utils.JsonResponse{}
-	result := jsonResp.Success(utils.SuccessContent, version)
+	result := jsonResp.Success(utils.SuccessContent, version.Masked())
 	c.String(http.StatusOK, result)
 }
 
-// VersionRollback 回滚任务命令到指定版本
+// VersionDiff 对比两个版本的任务定义，to 为 0 时与当前定义对比
+func VersionDiff(c *gin.Context) {
+	taskId, _ := strconv.Atoi(c.Param("id"))
+	fromId, _ := strconv.Atoi(c.Query("from"))
+	toId, _ := strconv.Atoi(c.Query("to"))
+	if taskId <= 0 || fromId <= 0 || toId < 0 {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+
+	versionModel := new(models.TaskScriptVersion)
+	from, err := versionModel.Detail(fromId)
+	if err != nil || from.TaskId != taskId {
+		base.RespondError(c, i18n.T(c, "version_not_found"))
+		return
+	}
+	fromDef, fromComplete := from.Snapshot()
+
+	taskModel := new(models.Task)
+	currentTask, err := taskModel.Detail(taskId)
+	if err != nil || currentTask.Id == 0 {
+		base.RespondError(c, i18n.T(c, "get_task_detail_failed"))
+		return
+	}
+	currentDef := models.NewTaskDefinition(currentTask)
+
+	toDef := currentDef
+	toComplete := true
+	toVersion := 0
+	if toId > 0 {
+		to, err := versionModel.Detail(toId)
+		if err != nil || to.TaskId != taskId {
+			base.RespondError(c, i18n.T(c, "version_not_found"))
+			return
+		}
+		toDef, toComplete = to.Snapshot()
+		toVersion = to.Version
+	}
+
+	// 快照中隐藏的凭据按当前值对比，避免只因脱敏产生差异
+	changes := fromDef.Unmask(currentDef).Diff(toDef.Unmask(currentDef))
+	// 旧版本只记录了命令，其余字段无法对比
+	partial := !fromComplete || !toComplete
+	if partial {
+		commandOnly := make([]models.TaskFieldChange, 0, 1)
+		for _, change := range changes {
+			if change.Field == "command" {
+				commandOnly = append(commandOnly, change)
+			}
+		}
+		changes = commandOnly
+	}
+
+	base.RespondSuccessWithDefaultMsg(c, map[string]interface{}{
+		"from_version": from.Version,
+		"to_version":   toVersion,
+		"partial":      partial,
+		"changes":      changes,
+	})
+}
+
+// VersionRollback 回滚任务到指定版本的完整定义
 func VersionRollback(c *gin.Context) {
 	taskId, _ := strconv.Atoi(c.Param("id"))
 	versionId, _ := strconv.Atoi(c.Param("version_id"))
@@ -91,29 +156,37 @@ func VersionRollback(c *gin.Context) {
 		return
 	}
 
-	// 使用事务保证回滚操作的原子性
-	txErr := models.Db.Transaction(func(tx *gorm.DB) error {
-		// 回滚前保存当前命令为新版本
-		if currentTask.Command != version.Command {
-			latestVersion, _ := versionModel.GetLatestVersion(taskId)
-			saveVersion := &models.TaskScriptVersion{
-				TaskId:   taskId,
-				Command:  currentTask.Command,
-				Remark:   "auto-save before rollback",
-				Username: user.Username(c),
-				Version:  latestVersion + 1,
-			}
-			if err := tx.Create(saveVersion).Error; err != nil {
-				logger.Warnf("回滚前保存版本失败 TaskID-%d: %v", taskId, err)
-			}
+	if isProtected(currentTask.Tag) {
+		baseDefinition := models.NewTaskDefinition(currentTask)
+		target, _ := models.RollbackTarget(currentTask, version)
+		if target.HasMasked() {
+			base.RespondError(c, i18n.T(c, "rollback_masked"))
+			return
 		}
+		submitChangeRequest(c, &models.TaskChangeRequest{
+			TaskId:     taskId,
+			TaskName:   currentTask.Name,
+			Type:       models.ChangeRequestRollback,
+			Base:       &baseDefinition,
+			Definition: &target,
+			Remark:     fmt.Sprintf("rollback to version %d", version.Version),
+		}, "task_change_pending")
+		return
+	}
 
-		// 更新任务命令
-		return tx.Model(&models.Task{}).Where("id = ?", taskId).
-			UpdateColumn("command", version.Command).Error
-	})
-	if txErr != nil {
-		base.RespondError(c, i18n.T(c, "rollback_failed"), txErr)
+	changes, err := models.RollbackTask(currentTask, version, user.Username(c))
+	switch {
+	case errors.Is(err, models.ErrRollbackNameExists):
+		base.RespondError(c, i18n.T(c, "task_name_exists"))
+		return
+	case errors.Is(err, models.ErrRollbackHostsMissing):
+		base.RespondError(c, i18n.T(c, "rollback_hosts_missing"))
+		return
+	case errors.Is(err, models.ErrRollbackMasked):
+		base.RespondError(c, i18n.T(c, "rollback_masked"))
+		return
+	case err != nil:
+		base.RespondError(c, i18n.T(c, "rollback_failed"), err)
 		return
 	}
 
@@ -122,9 +195,12 @@ func VersionRollback(c *gin.Context) {
 		logger.Warnf("清理旧版本失败 TaskID-%d: %v", taskId, cErr)
 	}
 
+	c.Set("audit_target_name", currentTask.Name)
+	c.Set("audit_detail", fmt.Sprintf("rollback to version %d\n%s", version.Version, models.FormatTaskChanges(changes)))
+
 	// 重新加入调度器
 	status, _ := taskModel.GetStatus(taskId)
-	if status == models.Enabled {
+	if status == models.Enabled && currentTask.Level == models.TaskLevelParent {
 		task, _ := taskModel.Detail(taskId)
 		service.ServiceTask.RemoveAndAdd(task)
 	}
@@ -112,6 +112,12 @@ func buildDiagnosePrompt(log *models.TaskLog) string {
 	protocol := "HTTP"
 	if log.Protocol == models.TaskRPC {
 		protocol = "RPC(Shell)"
+	} else if log.Protocol == models.TaskSSH {
+		protocol = "SSH(Shell)"
+	} else if log.Protocol == models.TaskSQL {
+		protocol = "SQL"
+	} else if log.Protocol == models.TaskGRPC {
+		protocol = "gRPC"
 	}
 	result := strings.TrimSpace(log.Result)
 	if r := []rune(result); len(r) > maxResultRunes {
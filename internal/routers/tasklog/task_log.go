@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gocronx-team/gocron/internal/models"
 	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 	"github.com/gocronx-team/gocron/internal/routers/base"
 	"github.com/gocronx-team/gocron/internal/service"
@@ -61,7 +62,16 @@ func Stop(c *gin.Context) {
 		base.RespondError(c, i18n.T(c, "get_task_info_failed")+"#"+err.Error(), err)
 		return
 	}
-	if task.Protocol != models.TaskRPC {
+	// HTTP、gRPC 任务和没有关联主机的 SQL 任务由服务端执行
+	if task.Protocol == models.TaskHTTP || task.Protocol == models.TaskGRPC ||
+		(task.Protocol == models.TaskSQL && len(task.Hosts) == 0) {
+		if !service.ServiceTask.StopLocal(id) {
+			logger.Warnf("Task is not running in this instance#Log ID-%d", id)
+		}
+		base.RespondSuccess(c, i18n.T(c, "stop_task_sent"), nil)
+		return
+	}
+	if !task.Protocol.UsesHosts() {
 		base.RespondError(c, i18n.T(c, "only_shell_task_can_stop"))
 		return
 	}
@@ -70,6 +80,13 @@ func Stop(c *gin.Context) {
 		return
 	}
 	for _, host := range task.Hosts {
+		if task.Protocol == models.TaskSSH {
+			// SSH 命令由执行任务的实例管理，结束远端进程组后关闭会话
+			if !service.ServiceTask.StopSSH(host.HostId, id) {
+				logger.Warnf("SSH command is not running in this instance#Log ID-%d#Host ID-%d", id, host.HostId)
+			}
+			continue
+		}
 		service.ServiceTask.Stop(host.Name, host.Port, id)
 	}
 
@@ -116,6 +133,8 @@ func parseQueryParams(c *gin.Context) models.CommonMap {
 	taskId, _ := strconv.Atoi(c.Query("task_id"))
 	protocol, _ := strconv.Atoi(c.Query("protocol"))
 	status, _ := strconv.Atoi(c.Query("status"))
+	logId, _ := strconv.ParseInt(c.Query("log_id"), 10, 64)
+	params["Id"] = logId
 	params["TaskId"] = taskId
 	params["Protocol"] = protocol
 	if status >= 0 {
@@ -1,19 +1,28 @@
 package template
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/gocronx-team/gocron/internal/models"
 	"github.com/gocronx-team/gocron/internal/modules/i18n"
 	"github.com/gocronx-team/gocron/internal/modules/logger"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 	"github.com/gocronx-team/gocron/internal/routers/base"
+	"github.com/gocronx-team/gocron/internal/routers/task"
 	"github.com/gocronx-team/gocron/internal/routers/user"
 )
 
+// maxTemplateBundleSize 导入模板包的最大字节数
+const maxTemplateBundleSize = 2 << 20
+
 type TemplateForm struct {
 	Id               int    `form:"id" json:"id"`
 	Name             string `form:"name" json:"name" binding:"required,max=64"`
@@ -21,8 +30,10 @@ type TemplateForm struct {
 	Category         string `form:"category" json:"category" binding:"required,max=32"`
 	Protocol         int8   `form:"protocol" json:"protocol" binding:"oneof=1 2"`
 	Command          string `form:"command" json:"command" binding:"required,max=65535"`
-	HttpMethod       int8   `form:"http_method" json:"http_method" binding:"oneof=1 2"`
+	HttpMethod       int8   `form:"http_method" json:"http_method" binding:"oneof=1 2 3 4 5 6"`
 	HttpBody         string `form:"http_body" json:"http_body"`
+	HttpBodyType     string `form:"http_body_type" json:"http_body_type" binding:"omitempty,oneof=json form multipart raw"`
+	HttpContentType  string `form:"http_content_type" json:"http_content_type" binding:"max=128"`
 	HttpHeaders      string `form:"http_headers" json:"http_headers"`
 	SuccessPattern   string `form:"success_pattern" json:"success_pattern" binding:"max=512"`
 	Tag              string `form:"tag" json:"tag"`
@@ -36,6 +47,17 @@ type TemplateForm struct {
 	NotifyType       int8   `form:"notify_type" json:"notify_type"`
 	NotifyKeyword    string `form:"notify_keyword" json:"notify_keyword"`
 	LogRetentionDays int    `form:"log_retention_days" json:"log_retention_days" binding:"min=0,max=3650"`
+	// Parameters 参数声明，JSON 数组
+	Parameters string `form:"parameters" json:"parameters"`
+}
+
+// ApplyForm 应用模板时提交的参数值；Create 为 true 时直接创建任务
+type ApplyForm struct {
+	Params   map[string]interface{} `json:"params"`
+	Create   bool                   `json:"create"`
+	TaskName string                 `json:"task_name"`
+	HostId   string                 `json:"host_id"`
+	Enable   bool                   `json:"enable"`
 }
 
 type SaveFromTaskForm struct {
@@ -101,8 +123,10 @@ func Store(c *gin.Context) {
 	id := form.Id
 
 	// 内置模板不可修改
+	var existing models.TaskTemplate
 	if id > 0 {
-		existing, detailErr := tmplModel.Detail(id)
+		var detailErr error
+		existing, detailErr = tmplModel.Detail(id)
 		if detailErr != nil || existing.Id == 0 {
 			base.RespondError(c, i18n.T(c, "template_not_found"))
 			return
@@ -131,6 +155,8 @@ func Store(c *gin.Context) {
 	tmplModel.HttpMethod = form.HttpMethod
 	tmplModel.HttpBody = form.HttpBody
 	tmplModel.HttpHeaders = form.HttpHeaders
+	tmplModel.HttpBodyType = form.HttpBodyType
+	tmplModel.HttpContentType = strings.TrimSpace(form.HttpContentType)
 	tmplModel.SuccessPattern = form.SuccessPattern
 	tmplModel.Tag = form.Tag
 	tmplModel.Spec = form.Spec
@@ -143,6 +169,17 @@ func Store(c *gin.Context) {
 	tmplModel.NotifyType = form.NotifyType
 	tmplModel.NotifyKeyword = form.NotifyKeyword
 	tmplModel.LogRetentionDays = form.LogRetentionDays
+	// 未提交参数声明的旧客户端保留原有参数
+	if _, ok := c.GetPostForm("parameters"); ok || id == 0 {
+		params, err := models.ParseTemplateParameters(form.Parameters)
+		if err != nil {
+			base.RespondError(c, i18n.T(c, "template_params_invalid")+": "+err.Error())
+			return
+		}
+		tmplModel.Parameters = params
+	} else {
+		tmplModel.Parameters = existing.Parameters
+	}
 
 	if id == 0 {
 		tmplModel.CreatedBy = user.Username(c)
@@ -190,7 +227,8 @@ func Remove(c *gin.Context) {
 	base.RespondSuccessWithDefaultMsg(c, nil)
 }
 
-// Apply 应用模板（增加使用次数并返回模板数据）
+// Apply 应用模板：校验参数并渲染占位符，返回渲染后的模板，
+// create=true 时直接创建任务（默认停用，便于确认后再启用）
 func Apply(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 	tmplModel := new(models.TaskTemplate)
@@ -201,13 +239,127 @@ func Apply(c *gin.Context) {
 		return
 	}
 
+	var form ApplyForm
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&form); err != nil {
+			base.RespondValidationError(c, err)
+			return
+		}
+	}
+
+	values := make(map[string]string, len(form.Params))
+	for name, value := range form.Params {
+		if value != nil {
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	rendered, paramErrs := tmpl.Render(values)
+	if len(paramErrs) > 0 {
+		base.RespondErrorWithData(c, translateParamErrors(c, paramErrs), paramErrs)
+		return
+	}
+
+	if !form.Create {
+		if uErr := tmplModel.IncrementUsage(id); uErr != nil {
+			logger.Warnf("增加模板使用次数失败 TemplateID-%d: %v", id, uErr)
+		}
+		base.RespondSuccess(c, utils.SuccessContent, rendered)
+		return
+	}
+
+	taskId, requestId, ok := createTaskFromTemplate(c, rendered, form)
+	if !ok {
+		return
+	}
 	if uErr := tmplModel.IncrementUsage(id); uErr != nil {
 		logger.Warnf("增加模板使用次数失败 TemplateID-%d: %v", id, uErr)
 	}
+	data := map[string]interface{}{
+		"task_id":  taskId,
+		"template": rendered,
+	}
+	if requestId > 0 {
+		c.Set("audit_detail", fmt.Sprintf("task_id: %d\npending change request #%d", taskId, requestId))
+		data["change_request_id"] = requestId
+		base.RespondSuccess(c, fmt.Sprintf(i18n.T(c, "task_enable_pending"), requestId), data)
+		return
+	}
+	c.Set("audit_detail", fmt.Sprintf("task_id: %d", taskId))
 
-	jsonResp := utils.JsonResponse{}
-	result := jsonResp.Success(utils.SuccessContent, tmpl)
-	c.String(http.StatusOK, result)
+	base.RespondSuccess(c, i18n.T(c, "save_success"), data)
+}
+
+// createTaskFromTemplate 用渲染后的模板创建任务，与保存任务走同一校验和保存流程；
+// 受保护任务创建后保持停用，返回启用申请的 ID。失败时已写入响应，返回 false
+func createTaskFromTemplate(c *gin.Context, tmpl models.TaskTemplate, form ApplyForm) (int, int, bool) {
+	name := strings.TrimSpace(form.TaskName)
+	if name == "" {
+		name = tmpl.Name
+	}
+	if utf8.RuneCountInString(name) > 32 {
+		base.RespondError(c, i18n.T(c, "template_task_name_too_long"))
+		return 0, 0, false
+	}
+	spec := strings.TrimSpace(tmpl.Spec)
+	if spec == "" {
+		base.RespondError(c, i18n.T(c, "crontab_parse_failed"))
+		return 0, 0, false
+	}
+	if tmpl.Timezone != "" {
+		spec = "CRON_TZ=" + tmpl.Timezone + " " + spec
+	}
+
+	taskForm := task.TaskForm{
+		Level:            models.TaskLevelParent,
+		DependencyStatus: models.TaskDependencyStatusStrong,
+		Name:             name,
+		Spec:             spec,
+		Protocol:         models.TaskProtocol(tmpl.Protocol),
+		Command:          tmpl.Command,
+		HttpMethod:       models.TaskHTTPMethod(tmpl.HttpMethod),
+		HttpBody:         tmpl.HttpBody,
+		HttpBodyType:     tmpl.HttpBodyType,
+		HttpContentType:  tmpl.HttpContentType,
+		HttpHeaders:      tmpl.HttpHeaders,
+		SuccessPattern:   tmpl.SuccessPattern,
+		Timeout:          tmpl.Timeout,
+		Multi:            tmpl.Multi,
+		RetryTimes:       tmpl.RetryTimes,
+		RetryInterval:    tmpl.RetryInterval,
+		Tag:              tmpl.Tag,
+		LogRetentionDays: tmpl.LogRetentionDays,
+		// 通知接收人依赖具体环境，模板创建的任务不继承通知配置
+		Remark: fmt.Sprintf("Created from template: %s", tmpl.Name),
+	}
+	if taskForm.Protocol.RequiresHosts() {
+		taskForm.HostId = form.HostId
+	}
+	if err := binding.Validator.ValidateStruct(&taskForm); err != nil {
+		base.RespondValidationError(c, err)
+		return 0, 0, false
+	}
+
+	status := models.Disabled
+	if form.Enable {
+		status = models.Enabled
+	}
+	taskId, requestId, ok := task.Save(c, taskForm, status)
+	if !ok {
+		return 0, 0, false
+	}
+	c.Set("audit_target_id", tmpl.Id)
+	c.Set("audit_target_name", tmpl.Name)
+
+	return taskId, requestId, true
+}
+
+// translateParamErrors 把参数校验错误转换为可读消息
+func translateParamErrors(c *gin.Context, errs []models.TemplateParamError) string {
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, fmt.Sprintf(i18n.T(c, "template_param_"+e.Reason), e.Name))
+	}
+	return strings.Join(messages, "; ")
 }
 
 // SaveFromTask 从现有任务保存为模板
@@ -224,6 +376,11 @@ func SaveFromTask(c *gin.Context) {
 		base.RespondError(c, i18n.T(c, "task_not_found"))
 		return
 	}
+	// 模板只支持 HTTP 和 Shell 任务
+	if task.Protocol != models.TaskHTTP && task.Protocol != models.TaskRPC {
+		base.RespondError(c, i18n.T(c, "template_protocol_unsupported"))
+		return
+	}
 
 	tmplModel := models.TaskTemplate{}
 	nameExists, err := tmplModel.NameExist(form.Name, 0)
@@ -244,6 +401,8 @@ func SaveFromTask(c *gin.Context) {
 	tmplModel.HttpMethod = int8(task.HttpMethod)
 	tmplModel.HttpBody = task.HttpBody
 	tmplModel.HttpHeaders = task.HttpHeaders
+	tmplModel.HttpBodyType = task.HttpBodyType
+	tmplModel.HttpContentType = task.HttpContentType
 	tmplModel.SuccessPattern = task.SuccessPattern
 	tmplModel.Tag = task.Tag
 	// 从 spec 中解析 timezone（格式: CRON_TZ=Asia/Shanghai 0 0 2 * * *）
@@ -261,9 +420,14 @@ func SaveFromTask(c *gin.Context) {
 	tmplModel.Multi = task.Multi
 	tmplModel.RetryTimes = task.RetryTimes
 	tmplModel.RetryInterval = task.RetryInterval
-	tmplModel.NotifyStatus = task.NotifyStatus
-	tmplModel.NotifyType = task.NotifyType
-	tmplModel.NotifyKeyword = task.NotifyKeyword
+	// 模板只保存一条默认通知设置，取任务的第一条通知规则（接收者与环境相关，不保存），
+	// 首次失败、恢复等旧版不支持的触发条件不保存
+	if len(task.Notifications) > 0 && task.Notifications[0].Trigger.Legacy() {
+		rule := task.Notifications[0]
+		tmplModel.NotifyStatus = int8(rule.Trigger)
+		tmplModel.NotifyType = int8(rule.Channel)
+		tmplModel.NotifyKeyword = rule.Keyword
+	}
 	tmplModel.LogRetentionDays = task.LogRetentionDays
 	tmplModel.CreatedBy = user.Username(c)
 
@@ -296,3 +460,96 @@ func parseQueryParams(c *gin.Context) models.CommonMap {
 	base.ParsePageAndPageSize(c, params)
 	return params
 }
+
+// Export 导出模板包，ids 为空时导出全部模板
+func Export(c *gin.Context) {
+	ids := make([]int, 0)
+	for _, item := range strings.Split(c.Query("ids"), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, err := strconv.Atoi(item)
+		if err != nil || id <= 0 {
+			base.RespondError(c, i18n.T(c, "param_error"))
+			return
+		}
+		ids = append(ids, id)
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", models.TemplateBundleJSON)))
+	if format == "yml" {
+		format = models.TemplateBundleYAML
+	}
+	if format != models.TemplateBundleJSON && format != models.TemplateBundleYAML {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+
+	bundle, err := models.ExportTemplates(ids)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+	data, err := models.MarshalTemplateBundle(bundle, format)
+	if err != nil {
+		base.RespondErrorWithDefaultMsg(c, err)
+		return
+	}
+
+	// 与其他接口一样返回 JSON，由前端生成下载文件
+	base.RespondSuccessWithDefaultMsg(c, map[string]interface{}{
+		"filename": fmt.Sprintf("gocron-templates-%s.%s", time.Now().Format("20060102150405"), format),
+		"format":   format,
+		"count":    len(bundle.Templates),
+		"content":  string(data),
+	})
+}
+
+// Import 导入模板包，支持上传文件(file)或直接提交内容(content)
+func Import(c *gin.Context) {
+	mode := strings.TrimSpace(c.DefaultPostForm("conflict", models.TemplateConflictSkip))
+	if !models.ValidTemplateConflictMode(mode) {
+		base.RespondError(c, i18n.T(c, "param_error"))
+		return
+	}
+
+	var data []byte
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		if fileHeader.Size > maxTemplateBundleSize {
+			base.RespondError(c, i18n.T(c, "template_bundle_too_large"))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+		defer file.Close()
+		data, err = io.ReadAll(io.LimitReader(file, maxTemplateBundleSize))
+		if err != nil {
+			base.RespondErrorWithDefaultMsg(c, err)
+			return
+		}
+	} else {
+		data = []byte(c.PostForm("content"))
+	}
+	if len(data) > maxTemplateBundleSize {
+		base.RespondError(c, i18n.T(c, "template_bundle_too_large"))
+		return
+	}
+
+	bundle, err := models.ParseTemplateBundle(data)
+	if err != nil {
+		base.RespondError(c, fmt.Sprintf(i18n.T(c, "template_bundle_invalid"), err.Error()))
+		return
+	}
+	result, err := models.ImportTemplates(bundle, mode, user.Username(c))
+	if err != nil {
+		base.RespondError(c, i18n.T(c, "template_import_failed"), err)
+		return
+	}
+
+	c.Set("audit_detail", fmt.Sprintf("conflict: %s, created: %d, overwritten: %d, renamed: %d, skipped: %d, failed: %d",
+		mode, len(result.Created), len(result.Overwritten), len(result.Renamed), len(result.Skipped), len(result.Failed)))
+	base.RespondSuccess(c, i18n.T(c, "template_import_success"), result)
+}
@@ -0,0 +1,186 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/ncruces/go-sqlite3/gormlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+type apiResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func setupTestRouter(t *testing.T) (*gin.Engine, func()) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	originalDb := models.Db
+
+	db, err := gorm.Open(gormlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	err = db.AutoMigrate(&models.Task{}, &models.TaskHost{}, &models.Host{}, &models.TaskNotification{},
+		&models.TaskTemplate{}, &models.Setting{})
+	if err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	models.Db = db
+
+	r := gin.New()
+	r.POST("/api/template/apply/:id", Apply)
+	r.POST("/api/template/save-from-task", SaveFromTask)
+
+	return r, func() {
+		models.Db = originalDb
+	}
+}
+
+func applyTemplate(t *testing.T, r *gin.Engine, id int, form ApplyForm) apiResponse {
+	t.Helper()
+	body, _ := json.Marshal(form)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/api/template/apply/%d", id), bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var resp apiResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", w.Body.String(), err)
+	}
+	return resp
+}
+
+func TestApply_CreateUsesTaskValidation(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	host := &models.Host{Name: "10.0.0.8", Alias: "node", Port: 5921}
+	if _, err := host.Create(); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &models.TaskTemplate{Name: "backup", Category: "ops", Protocol: int8(models.TaskRPC),
+		Command: "backup.sh", HttpMethod: 1, Spec: "0 0 2 * * *", Timeout: 60}
+	if _, err := tmpl.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := applyTemplate(t, r, tmpl.Id, ApplyForm{Create: true, HostId: fmt.Sprint(host.Id)})
+	if resp.Code != 0 {
+		t.Fatalf("expected task to be created, got %+v", resp)
+	}
+	var data struct {
+		TaskId int `json:"task_id"`
+	}
+	_ = json.Unmarshal(resp.Data, &data)
+	created, err := new(models.Task).Detail(data.TaskId)
+	if err != nil || created.Status != models.Disabled || len(created.Hosts) != 1 || created.Remark != "Created from template: backup" {
+		t.Fatalf("unexpected created task: %+v err=%v", created, err)
+	}
+
+	// 与保存任务相同的校验：RPC 任务需要主机、不能使用节点内部指令前缀
+	if resp := applyTemplate(t, r, tmpl.Id, ApplyForm{Create: true, TaskName: "no-host"}); resp.Code == 0 {
+		t.Fatal("expected rpc task without hosts to be rejected")
+	}
+	tmpl.Command = "__SQL__\n{}"
+	if _, err := tmpl.UpdateBean(tmpl.Id); err != nil {
+		t.Fatal(err)
+	}
+	if resp := applyTemplate(t, r, tmpl.Id, ApplyForm{Create: true, TaskName: "reserved", HostId: fmt.Sprint(host.Id)}); resp.Code == 0 {
+		t.Fatal("expected reserved command prefix to be rejected")
+	}
+
+	total, _ := new(models.Task).Total(models.CommonMap{})
+	if total != 1 {
+		t.Fatalf("expected only one task to be created, got %d", total)
+	}
+}
+
+func TestSaveFromTask_OnlyHTTPAndShell(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	cases := map[models.TaskProtocol]bool{
+		models.TaskHTTP:      true,
+		models.TaskRPC:       true,
+		models.TaskHeartbeat: false,
+		models.TaskSSH:       false,
+		models.TaskSQL:       false,
+		models.TaskGRPC:      false,
+	}
+	for protocol, allowed := range cases {
+		task := models.Task{Name: fmt.Sprintf("task-%d", protocol), Level: models.TaskLevelParent,
+			Spec: "0 0 2 * * *", Protocol: protocol, Command: "http://example.com"}
+		if _, err := task.Create(); err != nil {
+			t.Fatal(err)
+		}
+		body, _ := json.Marshal(SaveFromTaskForm{TaskId: task.Id, Name: task.Name, Category: "custom"})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/template/save-from-task", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		var resp apiResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if (resp.Code == 0) != allowed {
+			t.Errorf("protocol %d: allowed=%v, got %+v", protocol, allowed, resp)
+		}
+	}
+}
+
+func TestSaveFromTask_OnlyLegacyNotifyTriggers(t *testing.T) {
+	r, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	cases := map[models.NotifyTrigger]int8{
+		models.NotifyOnKeyword:      int8(models.NotifyOnKeyword),
+		models.NotifyOnFirstFailure: 0,
+		models.NotifyOnRecovery:     0,
+		models.NotifyOnStateChange:  0,
+	}
+	for trigger, status := range cases {
+		task := models.Task{Name: fmt.Sprintf("notify-%d", trigger), Level: models.TaskLevelParent,
+			Spec: "0 0 2 * * *", Protocol: models.TaskRPC, Command: "echo ok"}
+		if _, err := task.Create(); err != nil {
+			t.Fatal(err)
+		}
+		rules := []models.TaskNotification{{Channel: models.NotifyChannelSlack, ReceiverIds: "1", Trigger: trigger, Keyword: "error"}}
+		if err := models.SaveTaskNotifications(task.Id, rules); err != nil {
+			t.Fatal(err)
+		}
+		body, _ := json.Marshal(SaveFromTaskForm{TaskId: task.Id, Name: task.Name, Category: "custom"})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/template/save-from-task", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		var saved models.TaskTemplate
+		if err := models.Db.Where("name = ?", task.Name).First(&saved).Error; err != nil {
+			t.Fatalf("trigger %d: template not saved: %v %s", trigger, err, w.Body.String())
+		}
+		if saved.NotifyStatus != status {
+			t.Errorf("trigger %d: expected notify_status %d, got %d", trigger, status, saved.NotifyStatus)
+		}
+	}
+}
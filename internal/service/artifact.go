@@ -0,0 +1,51 @@
+package service
+
+// 任务附件：Shell 任务上传了附件时，把附件清单和带签名的下载地址随命令发给节点，
+// 节点下载并校验后在放置附件的工作目录中执行命令
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/artifact"
+)
+
+// artifactTokenTTL 附件下载地址的有效期
+const artifactTokenTTL = 30 * time.Minute
+
+var taskArtifactsFunc = new(models.TaskArtifact).Latest
+
+// artifactCommand 返回发给节点的命令，任务没有附件时为原命令
+func artifactCommand(taskModel models.Task) (string, error) {
+	artifacts, err := taskArtifactsFunc(taskModel.Id)
+	if err != nil {
+		return "", fmt.Errorf("load artifacts: %w", err)
+	}
+	if len(artifacts) == 0 {
+		return taskModel.Command, nil
+	}
+	siteUrl := new(models.Setting).GetSiteUrl()
+	if siteUrl == "" {
+		return "", errors.New("artifacts: site URL is not configured, nodes cannot download artifacts")
+	}
+	expires := time.Now().Add(artifactTokenTTL)
+	job := artifact.Job{Command: taskModel.Command, Files: make([]artifact.File, 0, len(artifacts))}
+	for _, a := range artifacts {
+		job.Files = append(job.Files, artifact.File{
+			Name:    a.Name,
+			Version: a.Version,
+			Sha256:  a.Sha256,
+			Size:    a.Size,
+			Url:     artifactDownloadUrl(siteUrl, a.DownloadToken(expires)),
+		})
+	}
+	return artifact.EncodeCommand(job)
+}
+
+// artifactDownloadUrl 下载地址，顶级路径跳过 JWT 鉴权，由签名令牌识别附件
+func artifactDownloadUrl(siteUrl, token string) string {
+	return fmt.Sprintf("%s/artifact/%s", strings.TrimRight(siteUrl, "/"), token)
+}
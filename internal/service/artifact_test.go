@@ -0,0 +1,70 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/artifact"
+	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+)
+
+func TestRPCHandlerWithArtifacts(t *testing.T) {
+	setupServiceTestDB(t)
+	if err := models.Db.AutoMigrate(&models.TaskArtifact{}); err != nil {
+		t.Fatal(err)
+	}
+	originalExec := rpcExecFunc
+	defer func() { rpcExecFunc = originalExec }()
+	var received []string
+	rpcExecFunc = func(ip string, port int, req *pb.TaskRequest) (string, error) {
+		received = append(received, req.Command)
+		return "ok", nil
+	}
+	task := models.Task{Id: 5, Protocol: models.TaskRPC, Command: "./deploy.sh prod", Timeout: 60,
+		Hosts: []models.TaskHostDetail{{Name: "10.0.0.1", Alias: "web", Port: 5921}}}
+
+	// 没有附件时发送原命令
+	if _, err := new(RPCHandler).Run(task, 1); err != nil || received[0] != "./deploy.sh prod" {
+		t.Fatalf("unexpected command %q %v", received, err)
+	}
+
+	for _, content := range []string{"echo v1", "echo v2"} {
+		a := models.TaskArtifact{TaskId: 5, Name: "deploy.sh"}
+		a.SetContent([]byte(content))
+		if _, err := a.Create(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 未配置站点地址时节点无法下载
+	if _, err := new(RPCHandler).Run(task, 2); err == nil || !strings.Contains(err.Error(), "site URL") {
+		t.Fatalf("expected site URL error, got %v", err)
+	}
+
+	if err := new(models.Setting).UpdateSiteUrl("https://cron.example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := new(RPCHandler).Run(task, 3); err != nil {
+		t.Fatal(err)
+	}
+	payload, ok := strings.CutPrefix(received[len(received)-1], artifact.Command+"\n")
+	var job artifact.Job
+	if !ok || json.Unmarshal([]byte(payload), &job) != nil {
+		t.Fatalf("unexpected command %q", received[len(received)-1])
+	}
+	if job.Command != "./deploy.sh prod" || len(job.Files) != 1 || job.Files[0].Version != 2 || job.Files[0].Size != 7 {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	token, ok := strings.CutPrefix(job.Files[0].Url, "https://cron.example.com/artifact/")
+	if !ok {
+		t.Fatalf("unexpected url %s", job.Files[0].Url)
+	}
+	id, err := models.ParseArtifactToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := new(models.TaskArtifact).Detail(id); saved.Sha256 != job.Files[0].Sha256 || string(saved.Content) != "echo v2" {
+		t.Fatalf("token points to the wrong artifact %+v", saved)
+	}
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/rpc/auth"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+	"github.com/gocronx-team/gocron/internal/modules/rpc/grpcpool"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+const (
+	caValidity       = 10 * 365 * 24 * time.Hour
+	nodeCertValidity = 365 * 24 * time.Hour
+	// 证书到期前 30 天自动轮换
+	certRenewBefore = 30 * 24 * time.Hour
+	crlValidity     = 30 * 24 * time.Hour
+
+	caCommonName     = "gocron internal ca"
+	clientCommonName = "gocron-server"
+)
+
+var (
+	CertManager = &CertificateManager{}
+
+	rotateCertificateFunc = rpcClient.RotateCertificate
+)
+
+// CertificateManager 内置 CA 的证书管理：为节点签发、轮换、吊销证书，
+// 同时维护服务端连接节点使用的客户端证书和 CRL 文件。
+// CA 保存在数据库中，多个 web 实例共用；目录中只保存 CA 证书、本实例的客户端证书和 CRL
+type CertificateManager struct {
+	mu  sync.Mutex
+	dir string
+	ca  *auth.CertificateAuthority
+	// rotating 正在轮换证书的节点 ID
+	rotating sync.Map
+}
+
+// Init 加载或生成 CA，确保 CA 证书、服务端客户端证书和 CRL 文件存在
+func (m *CertificateManager) Init(dir string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	m.dir = dir
+	ca, err := loadCertificateAuthority(dir)
+	if err != nil {
+		return err
+	}
+	if err = auth.WriteFileAtomic(m.paths().CAFile, ca.CertPEM, 0644); err != nil {
+		return err
+	}
+	m.ca = ca
+
+	if err := m.renewClientCertificate(); err != nil {
+		return err
+	}
+
+	return m.writeCRL()
+}
+
+// Enabled 是否启用了内置 CA
+func (m *CertificateManager) Enabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ca != nil
+}
+
+// Paths 服务端连接节点时使用的证书路径
+func (m *CertificateManager) Paths() auth.Certificate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paths()
+}
+
+func (m *CertificateManager) paths() auth.Certificate {
+	return auth.Certificate{
+		CAFile:   filepath.Join(m.dir, "ca.crt"),
+		CertFile: filepath.Join(m.dir, "client.crt"),
+		KeyFile:  filepath.Join(m.dir, "client.key"),
+		CRLFile:  filepath.Join(m.dir, "crl.pem"),
+	}
+}
+
+// IssueForHost 为节点签发证书并记录，返回需要下发给节点的证书文件
+func (m *CertificateManager) IssueForHost(host models.Host) (auth.CertificateBundle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	issued, bundle, err := m.issue(host)
+	if err != nil {
+		return bundle, err
+	}
+	if err = m.saveIssued(host, issued); err != nil {
+		return bundle, err
+	}
+
+	return bundle, nil
+}
+
+// RotateHost 签发新证书并推送到节点，推送成功后吊销旧证书。
+// 推送是网络调用，期间不持有锁，避免阻塞节点注册；同一节点同时只允许一次轮换
+func (m *CertificateManager) RotateHost(hostId int) (*models.HostCertificate, error) {
+	if _, busy := m.rotating.LoadOrStore(hostId, struct{}{}); busy {
+		return nil, errors.New("certificate rotation is already in progress for this host")
+	}
+	defer m.rotating.Delete(hostId)
+
+	host := new(models.Host)
+	if err := host.Find(hostId); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	issued, bundle, err := m.issue(*host)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if err = rotateCertificateFunc(host.Name, host.Port, bundle); err != nil {
+		return nil, fmt.Errorf("push certificate to %s:%d failed: %s", host.Name, host.Port, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err = m.saveIssued(*host, issued); err != nil {
+		return nil, err
+	}
+	certModel := new(models.HostCertificate)
+	if _, err = certModel.RevokeByHost(host.Id, issued.Serial); err != nil {
+		return nil, err
+	}
+	if err = m.writeCRL(); err != nil {
+		return nil, err
+	}
+	logger.Infof("节点证书已轮换#主机-%s#序列号-%s#到期时间-%s", host.Name, issued.Serial, issued.NotAfter.Format(time.DateTime))
+
+	current := new(models.HostCertificate)
+	err = current.ActiveByHost(host.Id)
+
+	return current, err
+}
+
+// RevokeHost 吊销节点全部证书，之后该节点无法再与服务端建立连接
+func (m *CertificateManager) RevokeHost(hostId int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ca == nil {
+		return 0, errors.New("internal ca is not enabled")
+	}
+	host := new(models.Host)
+	if err := host.Find(hostId); err != nil {
+		return 0, err
+	}
+	certModel := new(models.HostCertificate)
+	count, err := certModel.RevokeByHost(host.Id, "")
+	if err != nil {
+		return 0, err
+	}
+	if err = m.writeCRL(); err != nil {
+		return count, err
+	}
+	// 断开已有连接，下次握手时 CRL 生效
+	grpcpool.Pool.Release(fmt.Sprintf("%s:%d", host.Name, host.Port))
+	logger.Infof("节点证书已吊销#主机-%s#数量-%d", host.Name, count)
+
+	return count, nil
+}
+
+// RotateExpiring 轮换即将到期的节点证书，并刷新服务端证书和 CRL
+func (m *CertificateManager) RotateExpiring() {
+	if !m.Enabled() {
+		return
+	}
+	m.mu.Lock()
+	if err := m.renewClientCertificate(); err != nil {
+		logger.Errorf("服务端证书续期失败: %s", err)
+	}
+	if err := m.writeCRL(); err != nil {
+		logger.Errorf("刷新CRL失败: %s", err)
+	}
+	m.mu.Unlock()
+
+	certModel := new(models.HostCertificate)
+	expiring, err := certModel.ExpiringBefore(time.Now().Add(certRenewBefore))
+	if err != nil {
+		logger.Errorf("查询即将到期的节点证书失败: %s", err)
+		return
+	}
+	for _, item := range expiring {
+		if _, err := m.RotateHost(item.HostId); err != nil {
+			logger.Errorf("节点证书轮换失败#主机ID-%d#%s", item.HostId, err)
+		}
+	}
+}
+
+// loadCertificateAuthority 从数据库加载 CA。数据库中没有时导入目录中升级前生成的 CA，
+// 都没有时生成新的 CA；多个实例同时生成时使用最先保存的
+func loadCertificateAuthority(dir string) (*auth.CertificateAuthority, error) {
+	certPEM, keyPEM, err := models.LoadInternalCA()
+	if err != nil {
+		return nil, err
+	}
+	if len(certPEM) > 0 {
+		return auth.ParseCertificateAuthority(certPEM, keyPEM)
+	}
+
+	caFile, caKeyFile := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+	imported := utils.FileExist(caFile) && utils.FileExist(caKeyFile)
+	if imported {
+		if _, err = auth.LoadCertificateAuthority(caFile, caKeyFile); err != nil {
+			return nil, err
+		}
+		if certPEM, err = os.ReadFile(caFile); err != nil {
+			return nil, err
+		}
+		if keyPEM, err = os.ReadFile(caKeyFile); err != nil {
+			return nil, err
+		}
+	} else {
+		ca, key, err := auth.NewCertificateAuthority(caCommonName, caValidity)
+		if err != nil {
+			return nil, err
+		}
+		certPEM, keyPEM = ca.CertPEM, key
+	}
+	if certPEM, keyPEM, err = models.SaveInternalCA(certPEM, keyPEM); err != nil {
+		return nil, err
+	}
+	if imported {
+		// 私钥已加密保存到数据库，不再保留在磁盘上
+		if err = os.Remove(caKeyFile); err != nil {
+			logger.Warnf("删除已导入的CA私钥文件失败: %s", err)
+		}
+		logger.Infof("内置CA已从 %s 导入数据库", dir)
+	} else {
+		logger.Info("内置CA已生成并保存到数据库")
+	}
+
+	return auth.ParseCertificateAuthority(certPEM, keyPEM)
+}
+
+func (m *CertificateManager) issue(host models.Host) (*auth.IssuedCertificate, auth.CertificateBundle, error) {
+	bundle := auth.CertificateBundle{}
+	if m.ca == nil {
+		return nil, bundle, errors.New("internal ca is not enabled")
+	}
+	issued, err := m.ca.Issue(auth.IssueRequest{
+		CommonName: host.Name,
+		Hosts:      []string{host.Name},
+		Validity:   nodeCertValidity,
+	})
+	if err != nil {
+		return nil, bundle, err
+	}
+	crl, err := os.ReadFile(m.paths().CRLFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, bundle, err
+	}
+	bundle = auth.CertificateBundle{
+		CACert: string(m.ca.CertPEM),
+		Cert:   string(issued.CertPEM),
+		Key:    string(issued.KeyPEM),
+		CRL:    string(crl),
+	}
+
+	return issued, bundle, nil
+}
+
+func (m *CertificateManager) saveIssued(host models.Host, issued *auth.IssuedCertificate) error {
+	record := &models.HostCertificate{
+		HostId:     host.Id,
+		Serial:     issued.Serial,
+		CommonName: host.Name,
+		NotBefore:  issued.NotBefore,
+		NotAfter:   issued.NotAfter,
+	}
+	return record.Create()
+}
+
+// renewClientCertificate 服务端客户端证书不存在或即将到期时重新签发
+func (m *CertificateManager) renewClientCertificate() error {
+	paths := m.paths()
+	if utils.FileExist(paths.CertFile) && utils.FileExist(paths.KeyFile) {
+		data, err := os.ReadFile(paths.CertFile)
+		if err == nil {
+			cert, err := auth.ParseCertificatePEM(data)
+			if err == nil && cert.NotAfter.After(time.Now().Add(certRenewBefore)) && cert.CheckSignatureFrom(m.ca.Cert) == nil {
+				return nil
+			}
+		}
+	}
+	issued, err := m.ca.Issue(auth.IssueRequest{
+		CommonName: clientCommonName,
+		Validity:   nodeCertValidity,
+		Client:     true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return auth.CertificateBundle{Cert: string(issued.CertPEM), Key: string(issued.KeyPEM)}.WriteTo(paths)
+}
+
+func (m *CertificateManager) writeCRL() error {
+	certModel := new(models.HostCertificate)
+	list, err := certModel.RevokedList()
+	if err != nil {
+		return err
+	}
+	revoked := make([]auth.RevokedCertificate, 0, len(list))
+	for _, item := range list {
+		revokedAt := item.CreatedAt
+		if item.RevokedAt != nil {
+			revokedAt = *item.RevokedAt
+		}
+		revoked = append(revoked, auth.RevokedCertificate{Serial: item.Serial, RevokedAt: revokedAt})
+	}
+	crl, err := m.ca.CreateCRL(revoked, time.Now().Unix(), crlValidity)
+	if err != nil {
+		return err
+	}
+
+	return auth.WriteFileAtomic(m.paths().CRLFile, crl, 0644)
+}
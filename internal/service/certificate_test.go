@@ -0,0 +1,205 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/rpc/auth"
+	"github.com/ncruces/go-sqlite3/gormlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupCertTestDB(t *testing.T) func() {
+	t.Helper()
+	originalDb := models.Db
+
+	db, err := gorm.Open(gormlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err = db.AutoMigrate(&models.Host{}, &models.HostCertificate{}, &models.InternalCA{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	models.Db = db
+
+	originalRotate := rotateCertificateFunc
+	return func() {
+		models.Db = originalDb
+		rotateCertificateFunc = originalRotate
+	}
+}
+
+func TestCertificateManager_IssueRotateRevoke(t *testing.T) {
+	defer setupCertTestDB(t)()
+
+	manager := &CertificateManager{}
+	if err := manager.Init(t.TempDir()); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	if !manager.Enabled() {
+		t.Fatal("expected manager to be enabled after init")
+	}
+
+	host := &models.Host{Name: "10.0.0.8", Alias: "node", Port: 5921}
+	if _, err := host.Create(); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := manager.IssueForHost(*host)
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	first, err := auth.ParseCertificatePEM([]byte(bundle.Cert))
+	if err != nil {
+		t.Fatalf("invalid issued cert: %v", err)
+	}
+	if bundle.CACert == "" || bundle.Key == "" || bundle.CRL == "" {
+		t.Fatal("expected bundle to contain ca cert, key and crl")
+	}
+
+	// 推送失败时不应记录新证书，也不应吊销旧证书
+	rotateCertificateFunc = func(string, int, auth.CertificateBundle) error { return errors.New("unreachable") }
+	if _, err := manager.RotateHost(host.Id); err == nil {
+		t.Fatal("expected rotate to fail when push fails")
+	}
+	current := new(models.HostCertificate)
+	if err := current.ActiveByHost(host.Id); err != nil || current.Serial != auth.FormatSerial(first.SerialNumber) {
+		t.Fatalf("old certificate should stay active, got %+v err=%v", current, err)
+	}
+
+	// 推送期间不持有锁，节点注册等操作不被阻塞；同一节点的并发轮换被拒绝
+	var pushed auth.CertificateBundle
+	var concurrentErr error
+	rotateCertificateFunc = func(ip string, port int, b auth.CertificateBundle) error {
+		pushed = b
+		if !manager.Enabled() {
+			return errors.New("manager should stay usable during push")
+		}
+		_, concurrentErr = manager.RotateHost(host.Id)
+		return nil
+	}
+	rotated, err := manager.RotateHost(host.Id)
+	if err != nil {
+		t.Fatalf("rotate failed: %v", err)
+	}
+	if pushed.Cert == "" || rotated.Serial == current.Serial {
+		t.Fatalf("expected a new certificate to be pushed, got serial %s", rotated.Serial)
+	}
+	if concurrentErr == nil {
+		t.Fatal("expected concurrent rotation of the same host to be rejected")
+	}
+
+	// 旧证书应出现在 CRL 中
+	revoked, err := auth.ReadCRLFile(manager.Paths().CRLFile, nil)
+	if err != nil {
+		t.Fatalf("read crl failed: %v", err)
+	}
+	if _, ok := revoked[current.Serial]; !ok {
+		t.Fatalf("expected old serial %s in crl", current.Serial)
+	}
+	if _, ok := revoked[rotated.Serial]; ok {
+		t.Fatal("new serial must not be revoked")
+	}
+
+	count, err := manager.RevokeHost(host.Id)
+	if err != nil || count != 1 {
+		t.Fatalf("expected 1 revoked certificate, got %d err=%v", count, err)
+	}
+	revoked, _ = auth.ReadCRLFile(manager.Paths().CRLFile, nil)
+	if _, ok := revoked[rotated.Serial]; !ok {
+		t.Fatal("expected rotated serial to be revoked")
+	}
+}
+
+func TestCertificateManager_RotateExpiring(t *testing.T) {
+	defer setupCertTestDB(t)()
+
+	manager := &CertificateManager{}
+	if err := manager.Init(t.TempDir()); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+
+	soon := &models.Host{Name: "node-soon", Port: 5921}
+	later := &models.Host{Name: "node-later", Port: 5921}
+	_, _ = soon.Create()
+	_, _ = later.Create()
+	now := time.Now()
+	_ = (&models.HostCertificate{HostId: soon.Id, Serial: "a1", NotBefore: now, NotAfter: now.Add(5 * 24 * time.Hour)}).Create()
+	_ = (&models.HostCertificate{HostId: later.Id, Serial: "b1", NotBefore: now, NotAfter: now.Add(200 * 24 * time.Hour)}).Create()
+
+	pushedHosts := make([]string, 0)
+	rotateCertificateFunc = func(ip string, port int, b auth.CertificateBundle) error {
+		pushedHosts = append(pushedHosts, ip)
+		return nil
+	}
+	manager.RotateExpiring()
+
+	if len(pushedHosts) != 1 || pushedHosts[0] != "node-soon" {
+		t.Fatalf("expected only node-soon to be rotated, got %v", pushedHosts)
+	}
+	expiry, err := new(models.HostCertificate).LatestNotAfterByHosts([]int{soon.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expiry[soon.Id].After(now.Add(300 * 24 * time.Hour)) {
+		t.Fatalf("expected renewed expiry, got %s", expiry[soon.Id])
+	}
+}
+
+func TestCertificateManager_SharedCA(t *testing.T) {
+	defer setupCertTestDB(t)()
+	models.SetCredentialKey("test-secret")
+
+	// 升级前生成在目录中的 CA 会被导入数据库，私钥文件随后删除
+	legacyDir := t.TempDir()
+	legacy, legacyKey, err := auth.NewCertificateAuthority(caCommonName, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(legacyDir, "ca.crt"), legacy.CertPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(legacyDir, "ca.key"), legacyKey, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	first := &CertificateManager{}
+	if err = first.Init(legacyDir); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	if string(first.ca.CertPEM) != string(legacy.CertPEM) {
+		t.Fatal("expected the legacy CA to be imported")
+	}
+	if _, err = os.Stat(filepath.Join(legacyDir, "ca.key")); !os.IsNotExist(err) {
+		t.Fatalf("expected imported CA key to be removed, got %v", err)
+	}
+
+	// 其他实例使用各自的目录，但共用数据库中的 CA
+	second := &CertificateManager{}
+	otherDir := t.TempDir()
+	if err = second.Init(otherDir); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	if string(second.ca.CertPEM) != string(legacy.CertPEM) {
+		t.Fatal("expected instances to share the CA")
+	}
+	written, err := os.ReadFile(filepath.Join(otherDir, "ca.crt"))
+	if err != nil || string(written) != string(legacy.CertPEM) {
+		t.Fatalf("expected CA certificate written to the config dir: %v", err)
+	}
+
+	var stored models.InternalCA
+	if err = models.Db.First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stored.KeyPEM, "PRIVATE KEY") {
+		t.Fatal("expected CA key to be stored encrypted")
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+// gRPC 任务：由服务端调用目标服务的方法，Command 为服务地址，
+// 非 OK 状态码视为失败，响应以 JSON 写入日志，调用期间可以手动停止
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/grpcclient"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+var (
+	grpcCallFunc       = grpcclient.Call
+	grpcDescriptorFunc = new(models.GrpcDescriptor).Detail
+)
+
+// gRPC 任务
+type GRPCHandler struct{}
+
+func (h *GRPCHandler) Run(taskModel models.Task, taskUniqueId int64) (result string, err error) {
+	if taskModel.Timeout <= 0 {
+		taskModel.Timeout = HttpDefaultTimeout
+	}
+	logger.Infof("gRPC task execution started#Task ID-%d#Method-%s#Target-%s", taskModel.Id, taskModel.GrpcMethod, taskModel.Command)
+	request, insecureProfile, err := grpcRequest(taskModel)
+	if err != nil {
+		return "", err
+	}
+	if insecureProfile != "" {
+		// 跳过证书校验时在每次执行结果中提示，与 HTTP 任务一致
+		defer func() {
+			result = fmt.Sprintf("[WARN] TLS certificate verification is disabled by HTTP profile %s\n%s", insecureProfile, result)
+		}()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(taskModel.Timeout)*time.Second)
+	defer cancel()
+	run, finish := startLocalRun(ctx, taskUniqueId)
+	defer finish()
+	output, err := grpcCallFunc(run, request)
+	if err != nil && run.Stopped() {
+		return strings.TrimSpace(output + "\nManually stopped"), ErrLocalManualStop
+	}
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("gRPC call timed out after %ds: %w", taskModel.Timeout, err)
+	}
+	return output, err
+}
+
+// grpcRequest 根据任务配置生成请求，开启 TLS 时使用 HTTP 配置中的 CA 和客户端证书。
+// HTTP 配置跳过证书校验时返回配置名称
+func grpcRequest(taskModel models.Task) (request grpcclient.Request, insecureProfile string, err error) {
+	request = grpcclient.Request{
+		Target: strings.TrimSpace(taskModel.Command),
+		Method: taskModel.GrpcMethod,
+		Body:   taskModel.GrpcRequest,
+	}
+	md, err := grpcclient.ParseMetadata(taskModel.GrpcMetadata)
+	if err != nil {
+		return request, "", err
+	}
+	request.Metadata = md
+	if taskModel.GrpcTls {
+		tlsConfig := &tls.Config{}
+		profile, profileName, err := resolveHttpProfile(taskModel)
+		if err != nil {
+			return request, "", err
+		}
+		if profile != nil {
+			if tlsConfig, err = profile.TLSConfig(); err != nil {
+				return request, "", fmt.Errorf("HTTP profile %s: %w", profileName, err)
+			}
+			if profile.InsecureSkipVerify {
+				insecureProfile = profileName
+			}
+		}
+		tlsConfig.MinVersion = tls.VersionTLS12
+		tlsConfig.ServerName = strings.TrimSpace(taskModel.GrpcServerName)
+		request.TLS = tlsConfig
+	}
+	if taskModel.GrpcDescriptorId > 0 {
+		descriptor, err := grpcDescriptorFunc(taskModel.GrpcDescriptorId)
+		if err != nil {
+			return request, "", fmt.Errorf("gRPC descriptor set #%d: %w", taskModel.GrpcDescriptorId, err)
+		}
+		request.Descriptor = descriptor.Content
+	}
+	return request, insecureProfile, nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/grpcclient"
+)
+
+func TestGRPCHandlerRun(t *testing.T) {
+	originalCall, originalDescriptor, originalProfile := grpcCallFunc, grpcDescriptorFunc, httpProfileFunc
+	defer func() {
+		grpcCallFunc, grpcDescriptorFunc, httpProfileFunc = originalCall, originalDescriptor, originalProfile
+	}()
+	grpcDescriptorFunc = func(id int) (models.GrpcDescriptor, error) {
+		if id != 3 {
+			return models.GrpcDescriptor{}, errors.New("record not found")
+		}
+		return models.GrpcDescriptor{Id: 3, Content: []byte{0x0a}}, nil
+	}
+	httpProfileFunc = func(id int) (models.HttpProfile, error) {
+		return models.HttpProfile{Name: "internal", InsecureSkipVerify: true}, nil
+	}
+
+	var got grpcclient.Request
+	var deadline time.Time
+	grpcCallFunc = func(ctx context.Context, req grpcclient.Request) (string, error) {
+		got = req
+		deadline, _ = ctx.Deadline()
+		return "{\n  \"status\": \"SERVING\"\n}", nil
+	}
+	task := models.Task{Protocol: models.TaskGRPC, Command: " orders:50051 ", GrpcMethod: "grpc.health.v1.Health/Check",
+		GrpcRequest: `{"service":"orders"}`, GrpcMetadata: `{"Tenant":"a"}`, GrpcDescriptorId: 3}
+	output, err := new(GRPCHandler).Run(task, 1)
+	if err != nil || !strings.Contains(output, "SERVING") {
+		t.Fatalf("unexpected result %v %s", err, output)
+	}
+	if got.Target != "orders:50051" || got.Body != `{"service":"orders"}` || got.Metadata.Get("tenant")[0] != "a" || got.TLS != nil || len(got.Descriptor) != 1 {
+		t.Fatalf("unexpected request %+v", got)
+	}
+	// 未设置超时使用默认值
+	if remaining := time.Until(deadline); remaining < (HttpDefaultTimeout-5)*time.Second || remaining > HttpDefaultTimeout*time.Second {
+		t.Fatalf("unexpected deadline %v", remaining)
+	}
+
+	// 开启 TLS 时使用 HTTP 配置的证书设置
+	task.GrpcTls, task.GrpcServerName, task.HttpProfileId, task.GrpcDescriptorId = true, "orders.internal", 2, 0
+	output, err = new(GRPCHandler).Run(task, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "[WARN] TLS certificate verification is disabled by HTTP profile internal\n") {
+		t.Fatalf("insecure profile should be reported, got %q", output)
+	}
+	if got.TLS == nil || !got.TLS.InsecureSkipVerify || got.TLS.ServerName != "orders.internal" || got.TLS.MinVersion != tls.VersionTLS12 || got.Descriptor != nil {
+		t.Fatalf("unexpected TLS config %+v", got.TLS)
+	}
+
+	grpcCallFunc = func(ctx context.Context, req grpcclient.Request) (string, error) {
+		return "Status: Unavailable\nMessage: connection refused\n", errors.New("gRPC status Unavailable: connection refused")
+	}
+	output, err = new(GRPCHandler).Run(task, 3)
+	if err == nil || !strings.Contains(output, "\nStatus: Unavailable") {
+		t.Fatalf("expected failure, got %v %s", err, output)
+	}
+
+	task.GrpcDescriptorId = 4
+	if _, err := new(GRPCHandler).Run(task, 4); err == nil || !strings.Contains(err.Error(), "descriptor set #4") {
+		t.Fatalf("expected missing descriptor error, got %v", err)
+	}
+	task.GrpcDescriptorId, task.GrpcMetadata = 0, `{"grpc-timeout":"1S"}`
+	if _, err := new(GRPCHandler).Run(task, 5); err == nil {
+		t.Fatal("expected metadata error")
+	}
+
+	// 手动停止时取消调用
+	task.GrpcMetadata = ""
+	started := make(chan struct{})
+	grpcCallFunc = func(ctx context.Context, req grpcclient.Request) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	done := make(chan TaskResult, 1)
+	go func() {
+		output, err := new(GRPCHandler).Run(task, 6)
+		done <- TaskResult{Result: output, Err: err}
+	}()
+	<-started
+	if !ServiceTask.StopLocal(6) {
+		t.Fatal("running call should be stopped")
+	}
+	select {
+	case result := <-done:
+		if !errors.Is(result.Err, ErrLocalManualStop) || !strings.HasSuffix(result.Result, "Manually stopped") {
+			t.Fatalf("unexpected result %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stopped call did not return")
+	}
+}
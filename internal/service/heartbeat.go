@@ -0,0 +1,191 @@
+package service
+
+// 心跳任务：任务在外部运行（Kubernetes CronJob、Windows 计划任务等），gocron 不执行命令，
+// 只在调度时间点记录截止时间，由 leader 上的检查任务在宽限时间结束后检查，
+// 没有收到 ping 或 start 之后没有结束时记录失败日志并发送通知。
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocronx-team/cron"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// HeartbeatDefaultGrace 未设置宽限时间时使用的默认值（秒）
+const HeartbeatDefaultGrace = 300
+
+// heartbeatEarlyPing 调度时间之前这么久内收到的 ping 也算作本次调度，外部任务的时钟可能略快
+const heartbeatEarlyPing = 30 * time.Second
+
+// HeartbeatEvent ping 事件
+type HeartbeatEvent string
+
+const (
+	HeartbeatStart   HeartbeatEvent = "start"
+	HeartbeatSuccess HeartbeatEvent = "success"
+	HeartbeatFail    HeartbeatEvent = "fail"
+)
+
+var (
+	ErrHeartbeatTaskNotFound = errors.New("heartbeat task not found")
+	ErrHeartbeatTaskDisabled = errors.New("heartbeat task is disabled")
+	errHeartbeatMissed       = errors.New("heartbeat missed")
+	errHeartbeatUnfinished   = errors.New("heartbeat run not finished")
+	errHeartbeatFailed       = errors.New("failure reported by ping")
+)
+
+func heartbeatGrace(taskModel models.Task) time.Duration {
+	if taskModel.HeartbeatGrace <= 0 {
+		return HeartbeatDefaultGrace * time.Second
+	}
+	return time.Duration(taskModel.HeartbeatGrace) * time.Second
+}
+
+// createHeartbeatJob 调度时间到达时记录截止时间，宽限时间结束后由 checkHeartbeats 检查
+func createHeartbeatJob(taskModel models.Task) cron.FuncJob {
+	return func() {
+		scheduledAt := time.Now().Truncate(time.Second)
+		check := &models.TaskHeartbeatCheck{
+			TaskId:      taskModel.Id,
+			ScheduledAt: scheduledAt,
+			Deadline:    scheduledAt.Add(heartbeatGrace(taskModel)),
+		}
+		if err := check.Create(); err != nil {
+			logger.Errorf("Heartbeat#Failed to record check#Task ID-%d#%s", taskModel.Id, err)
+		}
+	}
+}
+
+// checkHeartbeats 检查截止时间已到的调度，随 SLA 检查任务每分钟执行一次
+func checkHeartbeats(now time.Time) {
+	checks, err := models.DueHeartbeatChecks(now)
+	if err != nil {
+		logger.Errorf("Heartbeat check#Failed to get due checks: %s", err)
+		return
+	}
+	for _, check := range checks {
+		claimed, err := check.Claim()
+		if err != nil {
+			logger.Errorf("Heartbeat check#Failed to remove check#Task ID-%d#%s", check.TaskId, err)
+			continue
+		}
+		if claimed {
+			checkHeartbeat(check.TaskId, check.ScheduledAt)
+		}
+	}
+}
+
+// checkHeartbeat 宽限时间结束后，start 之后仍在运行的执行记为失败；
+// 调度时间之后（允许略早 heartbeatEarlyPing）没有任何 ping 时记录失败日志。
+// 失败日志的开始时间为调度时间，不会被之后的检查当作 ping
+func checkHeartbeat(taskId int, scheduledAt time.Time) {
+	// 任务可能已被修改、禁用或删除
+	taskModel, err := new(models.Task).Detail(taskId)
+	if err != nil || taskModel.Id == 0 || taskModel.Protocol != models.TaskHeartbeat ||
+		taskModel.Status != models.Enabled {
+		return
+	}
+	grace := heartbeatGrace(taskModel)
+	taskLogModel := new(models.TaskLog)
+	running, err := taskLogModel.RunningBefore(taskId, scheduledAt.Add(grace))
+	if err != nil {
+		logger.Errorf("Heartbeat check failed#Task ID-%d#%s", taskId, err)
+		return
+	}
+	for _, taskLog := range running {
+		startTime := time.Time(taskLog.StartTime)
+		logger.Warnf("Heartbeat run not finished#Task ID-%d#Log ID-%d", taskId, taskLog.Id)
+		afterExecJob(taskModel, TaskResult{
+			Result: fmt.Sprintf("Started at %s, but no success or fail ping was received within %s after the scheduled time %s",
+				startTime.Format(models.DefaultTimeFormat), grace, scheduledAt.Format(models.DefaultTimeFormat)),
+			Err:      errHeartbeatUnfinished,
+			Duration: time.Since(startTime),
+		}, taskLog.Id)
+	}
+	if len(running) > 0 {
+		return
+	}
+
+	received, err := taskLogModel.StartedSince(taskId, scheduledAt.Add(-heartbeatEarlyPing))
+	if err != nil {
+		logger.Errorf("Heartbeat check failed#Task ID-%d#%s", taskId, err)
+		return
+	}
+	if received {
+		return
+	}
+	taskLogId, err := createHeartbeatLog(taskModel, scheduledAt, "")
+	if err != nil {
+		logger.Errorf("Heartbeat check#Failed to write task log#Task ID-%d#%s", taskId, err)
+		return
+	}
+	logger.Warnf("Heartbeat missed#Task ID-%d#Scheduled at-%s", taskId, scheduledAt.Format(models.DefaultTimeFormat))
+	afterExecJob(taskModel, TaskResult{
+		Result: fmt.Sprintf("No ping received within %s after the scheduled time %s",
+			grace, scheduledAt.Format(models.DefaultTimeFormat)),
+		Err:      errHeartbeatMissed,
+		Duration: time.Since(scheduledAt),
+	}, taskLogId)
+}
+
+// Heartbeat 处理外部任务的 ping。start 记录一次运行中的执行；
+// success、fail 结束最近一次运行中的执行，没有时直接记录一次完成的执行，并按通知规则发送通知。
+// 任务停用时忽略 ping，返回 ErrHeartbeatTaskDisabled
+func Heartbeat(token string, event HeartbeatEvent, source, output string) error {
+	taskModel, err := new(models.Task).DetailByHeartbeatToken(token)
+	if err != nil {
+		return err
+	}
+	if taskModel.Id == 0 {
+		return ErrHeartbeatTaskNotFound
+	}
+	// 停用的任务不记录执行，也不发送通知
+	if taskModel.Status != models.Enabled {
+		return ErrHeartbeatTaskDisabled
+	}
+	now := time.Now()
+	if event == HeartbeatStart {
+		_, err = createHeartbeatLog(taskModel, now, source)
+		return err
+	}
+
+	running, err := new(models.TaskLog).LatestRunning(taskModel.Id)
+	if err != nil {
+		return err
+	}
+	taskLogId := running.Id
+	startTime := now
+	if taskLogId > 0 {
+		startTime = time.Time(running.StartTime)
+	} else if taskLogId, err = createHeartbeatLog(taskModel, now, source); err != nil {
+		return err
+	}
+	taskResult := TaskResult{Result: output, Duration: now.Sub(startTime)}
+	if event == HeartbeatFail {
+		taskResult.Err = errHeartbeatFailed
+		if taskResult.Result == "" {
+			taskResult.Result = errHeartbeatFailed.Error()
+		}
+	}
+	afterExecJob(taskModel, taskResult, taskLogId)
+
+	return nil
+}
+
+// createHeartbeatLog 写入一条运行中的心跳日志，source 为 ping 来源地址
+func createHeartbeatLog(taskModel models.Task, startTime time.Time, source string) (int64, error) {
+	taskLogModel := &models.TaskLog{
+		TaskId:    taskModel.Id,
+		Name:      taskModel.Name,
+		Spec:      taskModel.Spec,
+		Protocol:  taskModel.Protocol,
+		Hostname:  source,
+		StartTime: models.LocalTime(startTime),
+		Status:    models.Running,
+	}
+
+	return taskLogModel.Create()
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/notify"
+)
+
+// awaitNotifyPush 替换通知推送，afterExecJob 在 goroutine 中发送通知，通过 channel 等待
+func awaitNotifyPush(t *testing.T) <-chan notify.Message {
+	t.Helper()
+	pushed := make(chan notify.Message, 10)
+	original := notifyPushFunc
+	notifyPushFunc = func(msg notify.Message) { pushed <- msg }
+	t.Cleanup(func() { notifyPushFunc = original })
+	return pushed
+}
+
+func receiveNotification(t *testing.T, pushed <-chan notify.Message) notify.Message {
+	t.Helper()
+	select {
+	case msg := <-pushed:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a notification")
+	}
+	return nil
+}
+
+func createHeartbeatTask(t *testing.T) models.Task {
+	t.Helper()
+	task := models.Task{Name: "k8s-backup", Spec: "0 0 * * * *", Protocol: models.TaskHeartbeat,
+		HeartbeatGrace: 600, Status: models.Enabled, Level: models.TaskLevelParent}
+	if _, err := task.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if len(task.HeartbeatToken) != 64 {
+		t.Fatalf("expected a generated ping token, got %q", task.HeartbeatToken)
+	}
+	err := models.SaveTaskNotifications(task.Id, []models.TaskNotification{
+		{Trigger: models.NotifyOnFailure, Channel: models.NotifyChannelWebhook},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func TestCheckHeartbeatMissed(t *testing.T) {
+	setupServiceTestDB(t)
+	pushed := awaitNotifyPush(t)
+	task := createHeartbeatTask(t)
+	scheduledAt := time.Now().Add(-10 * time.Minute)
+
+	checkHeartbeat(task.Id, scheduledAt)
+	msg := receiveNotification(t, pushed)
+	if msg["status"] != "Failed" || msg["task_id"] != task.Id {
+		t.Fatalf("unexpected notification: %+v", msg)
+	}
+	var logs []models.TaskLog
+	models.Db.Where("task_id = ?", task.Id).Find(&logs)
+	if len(logs) != 1 || logs[0].Status != models.Failure ||
+		time.Time(logs[0].StartTime).Unix() != scheduledAt.Unix() {
+		t.Fatalf("expected a failed log starting at the scheduled time, got %+v", logs)
+	}
+
+	// 失败日志不算作下一次调度的 ping
+	nextScheduledAt := scheduledAt.Add(time.Minute)
+	checkHeartbeat(task.Id, nextScheduledAt)
+	receiveNotification(t, pushed)
+
+	// 调度时间前不久收到的 ping 也算作本次调度
+	if err := Heartbeat(task.HeartbeatToken, HeartbeatSuccess, "10.0.0.8", ""); err != nil {
+		t.Fatal(err)
+	}
+	checkHeartbeat(task.Id, time.Now().Add(10*time.Second))
+	var count int64
+	models.Db.Model(&models.TaskLog{}).Where("task_id = ? AND status = ?", task.Id, models.Failure).Count(&count)
+	if count != 2 {
+		t.Fatalf("expected no failure after a ping, got %d failed logs", count)
+	}
+
+	// start 之后没有结束，宽限时间结束后记为失败
+	if err := Heartbeat(task.HeartbeatToken, HeartbeatStart, "10.0.0.8", ""); err != nil {
+		t.Fatal(err)
+	}
+	checkHeartbeat(task.Id, time.Now().Add(-time.Second))
+	msg = receiveNotification(t, pushed)
+	if msg["status"] != "Failed" {
+		t.Fatalf("unexpected notification: %+v", msg)
+	}
+	if running, _ := new(models.TaskLog).LatestRunning(task.Id); running.Id != 0 {
+		t.Fatalf("unfinished run should be failed, got %+v", running)
+	}
+	models.Db.Model(&models.TaskLog{}).Where("task_id = ? AND status = ?", task.Id, models.Failure).Count(&count)
+	if count != 3 {
+		t.Fatalf("expected the unfinished run to be the only new failure, got %d failed logs", count)
+	}
+}
+
+func TestHeartbeatJobRecordsDeadline(t *testing.T) {
+	setupServiceTestDB(t)
+	pushed := awaitNotifyPush(t)
+	task := createHeartbeatTask(t)
+
+	// 调度时只记录截止时间，不在任务中等待
+	createHeartbeatJob(task)()
+	var checks []models.TaskHeartbeatCheck
+	models.Db.Find(&checks)
+	if len(checks) != 1 || checks[0].Deadline.Sub(checks[0].ScheduledAt) != 600*time.Second {
+		t.Fatalf("expected a pending check with the grace deadline, got %+v", checks)
+	}
+
+	checkHeartbeats(time.Now())
+	var count int64
+	models.Db.Model(&models.TaskHeartbeatCheck{}).Count(&count)
+	if count != 1 {
+		t.Fatal("check must wait for the deadline")
+	}
+
+	checkHeartbeats(checks[0].Deadline)
+	msg := receiveNotification(t, pushed)
+	if msg["status"] != "Failed" || msg["task_id"] != task.Id {
+		t.Fatalf("unexpected notification: %+v", msg)
+	}
+	models.Db.Model(&models.TaskHeartbeatCheck{}).Count(&count)
+	if count != 0 {
+		t.Fatal("evaluated check should be removed")
+	}
+}
+
+func TestHeartbeatPings(t *testing.T) {
+	setupServiceTestDB(t)
+	pushed := awaitNotifyPush(t)
+	task := createHeartbeatTask(t)
+
+	if err := Heartbeat("unknown", HeartbeatSuccess, "", ""); !errors.Is(err, ErrHeartbeatTaskNotFound) {
+		t.Fatalf("expected not found for an unknown token, got %v", err)
+	}
+
+	// start + success 结束同一条日志
+	if err := Heartbeat(task.HeartbeatToken, HeartbeatStart, "10.0.0.8", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := Heartbeat(task.HeartbeatToken, HeartbeatSuccess, "10.0.0.8", "42 rows"); err != nil {
+		t.Fatal(err)
+	}
+	var logs []models.TaskLog
+	models.Db.Where("task_id = ?", task.Id).Order("id").Find(&logs)
+	if len(logs) != 1 || logs[0].Status != models.Finish || logs[0].Result != "42 rows" || logs[0].Hostname != "10.0.0.8" {
+		t.Fatalf("expected one finished log, got %+v", logs)
+	}
+
+	// 只有 fail 时直接记录失败并通知
+	if err := Heartbeat(task.HeartbeatToken, HeartbeatFail, "10.0.0.9", ""); err != nil {
+		t.Fatal(err)
+	}
+	msg := receiveNotification(t, pushed)
+	if msg["status"] != "Failed" || msg["output"] != errHeartbeatFailed.Error() {
+		t.Fatalf("unexpected notification: %+v", msg)
+	}
+	logs = nil
+	models.Db.Where("task_id = ?", task.Id).Order("id").Find(&logs)
+	if len(logs) != 2 || logs[1].Status != models.Failure {
+		t.Fatalf("expected a failed log, got %+v", logs)
+	}
+
+	// 停用的任务忽略 ping
+	models.Db.Model(&models.Task{}).Where("id = ?", task.Id).Update("status", models.Disabled)
+	if err := Heartbeat(task.HeartbeatToken, HeartbeatFail, "10.0.0.9", ""); !errors.Is(err, ErrHeartbeatTaskDisabled) {
+		t.Fatalf("expected ping of a disabled task to be ignored, got %v", err)
+	}
+	var total int64
+	models.Db.Model(&models.TaskLog{}).Where("task_id = ?", task.Id).Count(&total)
+	if total != 2 {
+		t.Fatalf("disabled task must not record runs, got %d logs", total)
+	}
+
+	// 重置令牌后旧地址失效
+	token, err := new(models.Task).ResetHeartbeatToken(task.Id)
+	if err != nil || token == task.HeartbeatToken {
+		t.Fatalf("expected a new token, got %q %v", token, err)
+	}
+	if err := Heartbeat(task.HeartbeatToken, HeartbeatSuccess, "", ""); !errors.Is(err, ErrHeartbeatTaskNotFound) {
+		t.Fatalf("old token should be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+// 异步 HTTP 任务：触发请求成功后轮询状态地址，或者等待被调用方回调本次执行的地址。
+// 等待期间任务日志保持运行中，结果中显示触发响应和等待进度，手动停止时结束等待
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// httpCallbackCheckInterval 等待回调时查询回调记录的间隔
+const httpCallbackCheckInterval = time.Second
+
+var (
+	errHttpAsyncFailed    = errors.New("async job failed: failure conditions matched")
+	errHttpCallbackFailed = errors.New("failure reported by callback")
+)
+
+// prepareHttpCallback 生成本次执行的回调地址，替换请求地址、Header 和请求体中的 {{.callback_url}}
+func prepareHttpCallback(taskModel *models.Task, taskLogId int64) (models.TaskCallback, error) {
+	siteUrl := new(models.Setting).GetSiteUrl()
+	if siteUrl == "" {
+		return models.TaskCallback{}, errors.New("HTTP async: site URL is not configured, cannot generate the callback URL")
+	}
+	callback, err := models.CreateTaskCallback(taskModel.Id, taskLogId)
+	if err != nil {
+		return callback, fmt.Errorf("HTTP async: %w", err)
+	}
+	values := map[string]string{httpclient.AsyncCallbackVar: httpCallbackUrl(siteUrl, callback.Token)}
+	taskModel.Command = httpclient.Expand(taskModel.Command, values)
+	taskModel.HttpHeaders = httpclient.Expand(taskModel.HttpHeaders, values)
+	taskModel.HttpBody = httpclient.Expand(taskModel.HttpBody, values)
+
+	return callback, nil
+}
+
+// httpCallbackUrl 回调地址，顶级路径跳过 JWT 鉴权，由令牌识别执行
+func httpCallbackUrl(siteUrl, token string) string {
+	return fmt.Sprintf("%s/callback/%s", strings.TrimRight(siteUrl, "/"), token)
+}
+
+// removeHttpCallback 等待结束后删除回调记录，之后的回调返回 404
+func removeHttpCallback(callback models.TaskCallback) {
+	if err := new(models.TaskCallback).Delete(callback.Id); err != nil {
+		logger.Errorf("Failed to remove task callback#ID-%d#%s", callback.Id, err)
+	}
+}
+
+// waitHttpAsync 触发请求成功后等待异步任务完成
+func waitHttpAsync(run *localRun, taskModel models.Task, taskLogId int64, config httpclient.AsyncConfig,
+	trigger httpclient.ResponseWrapper, callback models.TaskCallback,
+	auth *httpclient.Auth, profile *httpclient.Profile, exchanges *httpExchangeRecorder) (string, error) {
+	triggerResult := fmt.Sprintf("Trigger: HTTP %d\n%s", trigger.StatusCode, trigger.Body)
+	if taskModel.HttpAsyncMode == httpclient.AsyncCallback {
+		reportHttpAsyncProgress(taskLogId, auth.Redact(triggerResult+"\n\nWaiting for callback..."))
+		return waitHttpCallback(run, config, callback, triggerResult)
+	}
+
+	values, err := config.ExtractValues(trigger)
+	if err != nil {
+		return triggerResult, fmt.Errorf("HTTP async: %w", err)
+	}
+	statusUrl, err := httpclient.ExpandStatusUrl(config.StatusUrl, taskModel.Command, values)
+	if err != nil {
+		return triggerResult, fmt.Errorf("HTTP async: %w", err)
+	}
+	reportHttpAsyncProgress(taskLogId, auth.Redact(fmt.Sprintf("%s\n\nPolling %s every %ds...",
+		triggerResult, httpclient.MaskURL(statusUrl), config.Interval)))
+
+	headers := httpclient.Expand(strings.TrimSpace(taskModel.HttpHeaders), values)
+	polls := (config.Timeout + config.Interval - 1) / config.Interval
+	result := triggerResult
+	var pending error
+	for i := 1; i <= polls; i++ {
+		if !waitFunc(run, time.Duration(config.Interval)*time.Second) {
+			return result + "\n\nManually stopped", ErrLocalManualStop
+		}
+		resp := httpSendFunc(httpclient.RequestSpec{
+			Method:      http.MethodGet,
+			Url:         statusUrl,
+			Headers:     headers,
+			Auth:        auth,
+			Profile:     profile,
+			MaxBodySize: httpMaxBodySize(taskModel),
+		}, taskModel.Timeout)
+		exchanges.record(httpStagePoll, resp)
+		result = fmt.Sprintf("%s\n\nStatus (poll %d): HTTP %d\n%s", triggerResult, i, resp.StatusCode, resp.Body)
+		var state httpclient.AsyncState
+		state, pending = config.Evaluate(resp)
+		switch state {
+		case httpclient.AsyncSucceeded:
+			return result, nil
+		case httpclient.AsyncFailed:
+			return result, errHttpAsyncFailed
+		}
+	}
+
+	return result, fmt.Errorf("async job did not finish within %ds: %v", config.Timeout, pending)
+}
+
+// waitHttpCallback 等待回调，配置了成功、失败条件时按回调内容判断
+func waitHttpCallback(run *localRun, config httpclient.AsyncConfig, callback models.TaskCallback, triggerResult string) (string, error) {
+	checks := int(time.Duration(config.Timeout) * time.Second / httpCallbackCheckInterval)
+	for i := 0; i < checks; i++ {
+		if !waitFunc(run, httpCallbackCheckInterval) {
+			return triggerResult + "\n\nManually stopped", ErrLocalManualStop
+		}
+		received, err := new(models.TaskCallback).Detail(callback.Id)
+		if errors.Is(err, models.ErrTaskCallbackNotFound) {
+			return triggerResult, fmt.Errorf("HTTP async: %w", err)
+		}
+		if err != nil {
+			logger.Warnf("Failed to check task callback#ID-%d#%s", callback.Id, err)
+			continue
+		}
+		if received.Status == models.TaskCallbackWaiting {
+			continue
+		}
+		result := fmt.Sprintf("%s\n\nCallback from %s:\n%s", triggerResult, received.Source, received.Body)
+		if received.Status == models.TaskCallbackFailed {
+			return result, errHttpCallbackFailed
+		}
+		state, err := config.Evaluate(httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: received.Body, Header: make(http.Header)})
+		switch state {
+		case httpclient.AsyncSucceeded:
+			return result, nil
+		case httpclient.AsyncFailed:
+			return result, errHttpAsyncFailed
+		}
+		return result, fmt.Errorf("callback does not match success conditions: %v", err)
+	}
+
+	return triggerResult, fmt.Errorf("no callback received within %ds", config.Timeout)
+}
+
+// reportHttpAsyncProgress 等待期间把触发响应和等待状态写入运行中的日志
+func reportHttpAsyncProgress(taskLogId int64, progress string) {
+	if taskLogId <= 0 {
+		return
+	}
+	if _, err := new(models.TaskLog).Update(taskLogId, models.CommonMap{"result": progress}); err != nil {
+		logger.Warnf("Failed to update task log progress#Log ID-%d#%s", taskLogId, err)
+	}
+}
+
+// HttpCallback 接收异步 HTTP 任务的回调，failed 为 true 表示被调用方报告失败
+func HttpCallback(token string, failed bool, body, source string) error {
+	status := models.TaskCallbackSuccess
+	if failed {
+		status = models.TaskCallbackFailed
+	}
+	return models.ReceiveTaskCallback(token, status, body, source)
+}
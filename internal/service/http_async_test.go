@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+)
+
+func TestHTTPHandlerRunAsyncPoll(t *testing.T) {
+	setupServiceTestDB(t)
+	originalSend, originalWait := httpSendFunc, waitFunc
+	defer func() { httpSendFunc, waitFunc = originalSend, originalWait }()
+	var slept time.Duration
+	waitFunc = func(ctx context.Context, d time.Duration) bool {
+		slept += d
+		return true
+	}
+
+	states := []string{"running", "running", "done"}
+	var statusUrls []string
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		if spec.Method == http.MethodPut {
+			return httpclient.ResponseWrapper{StatusCode: http.StatusAccepted, Body: `{"job":{"id":"j-42"}}`}
+		}
+		statusUrls = append(statusUrls, spec.Url)
+		state := states[0]
+		if len(states) > 1 {
+			states = states[1:]
+		}
+		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: `{"state":"` + state + `"}`}
+	}
+
+	// 触发请求返回 202 时继续轮询，直到成功条件满足
+	handler := &HTTPHandler{}
+	task := models.Task{
+		Command:       "https://api.example.com/v1/export",
+		HttpMethod:    models.TaskHTTPMethodPut,
+		HttpAsyncMode: httpclient.AsyncPoll,
+		HttpAsync: `{"extract":{"job_id":"$.job.id"},"status_url":"/v1/jobs/{{.job_id}}","interval":5,"timeout":60,` +
+			`"success":[{"source":"json","target":"$.state","op":"==","value":"done"}],` +
+			`"failure":[{"source":"json","target":"$.state","op":"==","value":"failed"}]}`,
+	}
+	result, err := handler.Run(task, 0)
+	if err != nil {
+		t.Fatalf("expected success, got %q %v", result, err)
+	}
+	if len(statusUrls) != 3 || statusUrls[0] != "https://api.example.com/v1/jobs/j-42" || slept != 15*time.Second {
+		t.Fatalf("unexpected polls %v slept %s", statusUrls, slept)
+	}
+	if !strings.Contains(result, "Trigger: HTTP 202") || !strings.Contains(result, `Status (poll 3): HTTP 200`) {
+		t.Fatalf("result should contain trigger and final status:\n%s", result)
+	}
+
+	// 失败条件满足时任务失败
+	states = []string{"failed"}
+	if _, err := handler.Run(task, 0); !errors.Is(err, errHttpAsyncFailed) {
+		t.Fatalf("expected async failure, got %v", err)
+	}
+
+	// 超时
+	states = []string{"running"}
+	statusUrls = nil
+	result, err = handler.Run(task, 0)
+	if err == nil || !strings.Contains(err.Error(), "did not finish within 60s") || len(statusUrls) != 12 {
+		t.Fatalf("expected timeout after 12 polls, got %d %v", len(statusUrls), err)
+	}
+	if !strings.Contains(err.Error(), `actual "running"`) {
+		t.Fatalf("timeout should include the unmet condition, got %v", err)
+	}
+
+	// 手动停止时结束轮询
+	statusUrls = nil
+	waitFunc = func(ctx context.Context, d time.Duration) bool {
+		if len(statusUrls) == 2 && !ServiceTask.StopLocal(9) {
+			t.Error("waiting task should be stopped")
+		}
+		return sleepContext(ctx, time.Millisecond)
+	}
+	result, err = handler.Run(task, 9)
+	if !errors.Is(err, ErrLocalManualStop) || len(statusUrls) != 2 || !strings.HasSuffix(result, "Manually stopped") {
+		t.Fatalf("expected manual stop after 2 polls, got %d %q %v", len(statusUrls), result, err)
+	}
+	if ServiceTask.StopLocal(9) {
+		t.Fatal("finished task should not be stopped")
+	}
+}
+
+func TestHTTPHandlerRunAsyncCallback(t *testing.T) {
+	setupServiceTestDB(t)
+	if err := models.Db.AutoMigrate(&models.TaskCallback{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := new(models.Setting).UpdateSiteUrl("https://cron.example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	originalSend, originalWait := httpSendFunc, waitFunc
+	defer func() { httpSendFunc, waitFunc = originalSend, originalWait }()
+
+	tokenPattern := regexp.MustCompile(`https://cron\.example\.com/callback/([0-9a-f]{64})`)
+	var token string
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		if match := tokenPattern.FindStringSubmatch(spec.Body); match != nil {
+			token = match[1]
+		}
+		return httpclient.ResponseWrapper{StatusCode: http.StatusAccepted, Body: "accepted"}
+	}
+	// 等待第 3 次时被调用方回调
+	var checks int
+	callbackBody, callbackFailed := `{"state":"done"}`, false
+	waitFunc = func(context.Context, time.Duration) bool {
+		checks++
+		if checks == 3 {
+			if err := HttpCallback(token, callbackFailed, callbackBody, "10.0.0.8"); err != nil {
+				t.Errorf("callback failed: %v", err)
+			}
+		}
+		return true
+	}
+
+	handler := &HTTPHandler{}
+	task := models.Task{
+		Id:            7,
+		Command:       "https://api.example.com/v1/export",
+		HttpMethod:    models.TaskHttpMethodPost,
+		HttpBodyType:  httpclient.BodyTypeJson,
+		HttpBody:      `{"notify":"{{.callback_url}}"}`,
+		HttpAsyncMode: httpclient.AsyncCallback,
+		HttpAsync:     `{"timeout":30,"success":[{"source":"json","target":"$.state","op":"==","value":"done"}]}`,
+	}
+	result, err := handler.Run(task, 0)
+	if err != nil || token == "" || checks != 3 {
+		t.Fatalf("expected success after callback, got %q %v checks=%d", result, err, checks)
+	}
+	if !strings.Contains(result, "Callback from 10.0.0.8:\n"+callbackBody) {
+		t.Fatalf("result should contain the callback:\n%s", result)
+	}
+	// 等待结束后回调地址失效
+	if err := HttpCallback(token, false, "", ""); !errors.Is(err, models.ErrTaskCallbackNotFound) {
+		t.Fatalf("callback should be removed, got %v", err)
+	}
+
+	checks = 0
+	callbackFailed = true
+	if _, err := handler.Run(task, 0); !errors.Is(err, errHttpCallbackFailed) {
+		t.Fatalf("expected failure reported by callback, got %v", err)
+	}
+
+	checks = 0
+	callbackBody, callbackFailed = `{"state":"partial"}`, false
+	if _, err := handler.Run(task, 0); err == nil || !strings.Contains(err.Error(), "does not match success conditions") {
+		t.Fatalf("expected condition failure, got %v", err)
+	}
+
+	// 没有回调时超时
+	checks = -100
+	if _, err := handler.Run(task, 0); err == nil || !strings.Contains(err.Error(), "no callback received within 30s") {
+		t.Fatalf("expected callback timeout, got %v", err)
+	}
+}
+
+func TestReceiveTaskCallbackOnce(t *testing.T) {
+	setupServiceTestDB(t)
+	if err := models.Db.AutoMigrate(&models.TaskCallback{}); err != nil {
+		t.Fatal(err)
+	}
+	callback, err := models.CreateTaskCallback(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := HttpCallback(callback.Token, false, "ok", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := HttpCallback(callback.Token, true, "again", ""); !errors.Is(err, models.ErrTaskCallbackReceived) {
+		t.Fatalf("second callback should be rejected, got %v", err)
+	}
+	if err := HttpCallback("unknown", false, "", ""); !errors.Is(err, models.ErrTaskCallbackNotFound) {
+		t.Fatalf("unknown token should not be found, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+// HTTP 请求详情：执行过程中把触发请求和最近一次状态查询的请求详情写入任务日志，
+// 日志详情中按结构化面板展示
+
+import (
+	"encoding/json"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// 请求详情所属阶段
+const (
+	httpStageRequest = "request"
+	httpStagePoll    = "poll"
+)
+
+// httpExchangeEntry 带阶段的请求详情
+type httpExchangeEntry struct {
+	Stage string `json:"stage"`
+	*httpclient.Exchange
+}
+
+// httpExchangeRecorder 记录本次执行的请求详情，轮询只保留最近一次
+type httpExchangeRecorder struct {
+	taskLogId int64
+	auth      *httpclient.Auth
+	entries   []httpExchangeEntry
+}
+
+func newHttpExchangeRecorder(taskLogId int64, auth *httpclient.Auth) *httpExchangeRecorder {
+	return &httpExchangeRecorder{taskLogId: taskLogId, auth: auth}
+}
+
+// record 记录并写入任务日志，手动运行等没有日志的执行只保留在内存中
+func (r *httpExchangeRecorder) record(stage string, resp httpclient.ResponseWrapper) {
+	if r == nil || resp.Exchange == nil {
+		return
+	}
+	entry := httpExchangeEntry{Stage: stage, Exchange: resp.Exchange}
+	if n := len(r.entries); stage == httpStagePoll && n > 0 && r.entries[n-1].Stage == httpStagePoll {
+		r.entries[n-1] = entry
+	} else {
+		r.entries = append(r.entries, entry)
+	}
+	if r.taskLogId <= 0 {
+		return
+	}
+	data, err := json.Marshal(r.entries)
+	if err != nil {
+		logger.Warnf("Failed to encode HTTP exchange#Log ID-%d#%s", r.taskLogId, err)
+		return
+	}
+	// 响应 Header 中回显的凭据同样隐藏
+	exchange := r.auth.Redact(string(data))
+	if _, err := new(models.TaskLog).Update(r.taskLogId, models.CommonMap{"http_exchange": exchange}); err != nil {
+		logger.Warnf("Failed to save HTTP exchange#Log ID-%d#%s", r.taskLogId, err)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+)
+
+func TestHTTPHandlerRunRecordsExchange(t *testing.T) {
+	setupServiceTestDB(t)
+	originalSend, originalWait, originalLookup := httpSendFunc, waitFunc, lookupSecretFunc
+	defer func() { httpSendFunc, waitFunc, lookupSecretFunc = originalSend, originalWait, originalLookup }()
+	waitFunc = func(context.Context, time.Duration) bool { return true }
+	lookupSecretFunc = func(name string) (string, error) { return "s3cret-token", nil }
+
+	polls := 0
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		if spec.MaxBodySize != 64*1024 {
+			t.Errorf("unexpected max body size %d", spec.MaxBodySize)
+		}
+		exchange := &httpclient.Exchange{Method: spec.Method, Url: spec.Url, BodyLimit: spec.MaxBodySize}
+		if spec.Method == http.MethodPost {
+			// 响应 Header 回显了凭据
+			exchange.StatusCode = http.StatusAccepted
+			exchange.ResponseHeaders = map[string]string{"X-Echo": "s3cret-token"}
+			return httpclient.ResponseWrapper{StatusCode: http.StatusAccepted, Body: `{"id":"7"}`, Exchange: exchange}
+		}
+		polls++
+		state := "running"
+		if polls == 3 {
+			state = "done"
+		}
+		exchange.StatusCode = http.StatusOK
+		exchange.Url = spec.Url + "#" + state
+		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: `{"state":"` + state + `"}`, Exchange: exchange}
+	}
+
+	taskLog := models.TaskLog{Name: "export", Spec: "@daily", Protocol: models.TaskHTTP, Command: "https://api.example.com/export"}
+	taskLogId, err := taskLog.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := models.Task{
+		Command:         "https://api.example.com/export",
+		HttpMethod:      models.TaskHttpMethodPost,
+		HttpAuthType:    httpclient.AuthBearer,
+		HttpAuth:        `{"token_secret":"api-token"}`,
+		HttpMaxBodySize: 64,
+		HttpAsyncMode:   httpclient.AsyncPoll,
+		HttpAsync: `{"extract":{"id":"$.id"},"status_url":"/jobs/{{.id}}","interval":1,"timeout":10,` +
+			`"success":[{"source":"json","target":"$.state","op":"==","value":"done"}]}`,
+	}
+	if result, err := new(HTTPHandler).Run(task, taskLogId); err != nil {
+		t.Fatalf("expected success, got %q %v", result, err)
+	}
+
+	// 保留触发请求和最后一次轮询
+	if err := taskLog.Find(taskLogId); err != nil {
+		t.Fatal(err)
+	}
+	var entries []struct {
+		Stage string `json:"stage"`
+		httpclient.Exchange
+	}
+	if err := json.Unmarshal([]byte(taskLog.HttpExchange), &entries); err != nil {
+		t.Fatalf("invalid exchange JSON %q: %v", taskLog.HttpExchange, err)
+	}
+	if len(entries) != 2 || entries[0].Stage != httpStageRequest || entries[0].StatusCode != http.StatusAccepted ||
+		entries[1].Stage != httpStagePoll || entries[1].Url != "https://api.example.com/jobs/7#done" {
+		t.Fatalf("unexpected exchanges %s", taskLog.HttpExchange)
+	}
+	if strings.Contains(taskLog.HttpExchange, "s3cret-token") {
+		t.Fatalf("credential should be redacted: %s", taskLog.HttpExchange)
+	}
+}
+
+func TestHTTPHandlerRunMaxBodySizeKeepsLegacyRequest(t *testing.T) {
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
+	var got httpclient.RequestSpec
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		got = spec
+		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
+	}
+
+	// 设置大小上限后仍把查询参数作为表单发送
+	task := models.Task{Command: "http://example.com/run?a=1&b=2", HttpMethod: models.TaskHttpMethodPost, HttpMaxBodySize: 8}
+	if _, err := new(HTTPHandler).Run(task, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got.Url != "http://example.com/run" || got.BodyType != httpclient.BodyTypeForm || got.Body != "a=1&b=2" || got.MaxBodySize != 8192 {
+		t.Fatalf("unexpected request %+v", got)
+	}
+
+	task.HttpBody = `{"x":1}`
+	if _, err := new(HTTPHandler).Run(task, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got.Url != task.Command || got.BodyType != httpclient.BodyTypeJson || got.Body != task.HttpBody {
+		t.Fatalf("unexpected request %+v", got)
+	}
+}
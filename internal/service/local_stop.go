@@ -0,0 +1,69 @@
+package service
+
+// 服务端执行的任务（SQL、HTTP 异步等待、gRPC）的手动停止：执行期间按任务日志 ID 登记取消函数，
+// 停止请求由执行任务的实例处理
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLocalManualStop 服务端执行的任务被手动停止
+var ErrLocalManualStop = errors.New("local_manual_stop")
+
+// waitFunc 等待 d 或 ctx 结束，ctx 结束时返回 false
+var waitFunc = sleepContext
+
+// runningLocal 服务端执行中的任务，key 为任务日志 ID，值为 *localRun
+var runningLocal sync.Map
+
+// localRun 一次服务端执行，Context 在手动停止时取消
+type localRun struct {
+	context.Context
+	cancel  context.CancelFunc
+	stopped atomic.Bool
+}
+
+// startLocalRun 登记执行，返回的 Context 在 parent 结束或手动停止时取消，执行结束后调用 finish
+func startLocalRun(parent context.Context, taskLogId int64) (run *localRun, finish func()) {
+	run = new(localRun)
+	run.Context, run.cancel = context.WithCancel(parent)
+	runningLocal.Store(taskLogId, run)
+
+	return run, func() {
+		runningLocal.CompareAndDelete(taskLogId, run)
+		run.cancel()
+	}
+}
+
+// Stopped 是否被手动停止
+func (run *localRun) Stopped() bool {
+	return run.stopped.Load()
+}
+
+// StopLocal 停止服务端执行的任务，任务不在本实例中运行时返回 false
+func (task Task) StopLocal(id int64) bool {
+	value, ok := runningLocal.Load(id)
+	if !ok {
+		return false
+	}
+	run := value.(*localRun)
+	run.stopped.Store(true)
+	run.cancel()
+
+	return true
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
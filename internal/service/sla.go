@@ -0,0 +1,143 @@
+package service
+
+// 任务 SLA 检查：单次执行超过最长预期耗时，或超过规定间隔没有成功执行时发送通知。
+// 检查任务只在 leader 节点运行，违约记录写入数据库去重，切换 leader 后不会重复通知。
+// 心跳任务的到期检查也由该任务执行。
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/notify"
+)
+
+// 每分钟检查一次
+const slaWatchdogSpec = "0 * * * * *"
+
+// 初始化 SLA 检查任务
+func (task Task) initSlaWatchdogTask() {
+	if serviceCron == nil {
+		return
+	}
+	serviceCron.AddFunc(slaWatchdogSpec, func() {
+		now := time.Now()
+		checkSla(now)
+		checkHeartbeats(now)
+	}, "sla-watchdog")
+	logger.Info("SLA watchdog task added")
+}
+
+// checkSla 检查所有设置了 SLA 阈值的任务
+func checkSla(now time.Time) {
+	tasks, err := new(models.Task).SlaList()
+	if err != nil {
+		logger.Errorf("SLA watchdog#Failed to get task list: %s", err)
+		return
+	}
+	for _, taskModel := range tasks {
+		if taskModel.SlaMaxDuration > 0 {
+			checkSlaDuration(taskModel, now)
+		}
+		if taskModel.SlaSuccessInterval > 0 {
+			checkSlaSuccess(taskModel, now)
+		}
+	}
+}
+
+// checkSlaDuration 运行时间超过最长预期耗时的执行，每条日志只通知一次
+func checkSlaDuration(taskModel models.Task, now time.Time) {
+	limit := time.Duration(taskModel.SlaMaxDuration) * time.Second
+	logs, err := new(models.TaskLog).RunningBefore(taskModel.Id, now.Add(-limit))
+	if err != nil {
+		logger.Errorf("SLA watchdog#Failed to get running logs#Task ID-%d#%s", taskModel.Id, err)
+		return
+	}
+	for _, taskLog := range logs {
+		elapsed := now.Sub(time.Time(taskLog.StartTime)).Truncate(time.Second)
+		detail := fmt.Sprintf("Execution #%d has been running for %s, longer than the expected %s", taskLog.Id, elapsed, limit)
+		if taskLog.Hostname != "" {
+			detail += fmt.Sprintf(" (host %s)", taskLog.Hostname)
+		}
+		breach := &models.TaskSlaBreach{
+			TaskId:    taskModel.Id,
+			TaskName:  taskModel.Name,
+			Kind:      models.SlaKindDuration,
+			RefId:     taskLog.Id,
+			Threshold: taskModel.SlaMaxDuration,
+			Detail:    detail,
+		}
+		reportSlaBreach(taskModel, breach, elapsed)
+	}
+}
+
+// checkSlaSuccess 超过规定间隔没有成功执行，同一次成功之后只通知一次。
+// 从未成功过的任务从创建时间开始计算
+func checkSlaSuccess(taskModel models.Task, now time.Time) {
+	lastSuccess, err := new(models.TaskLog).LastSuccess(taskModel.Id)
+	if err != nil {
+		logger.Errorf("SLA watchdog#Failed to get last success#Task ID-%d#%s", taskModel.Id, err)
+		return
+	}
+	interval := time.Duration(taskModel.SlaSuccessInterval) * time.Second
+	since := taskModel.CreatedAt
+	detail := fmt.Sprintf("No successful execution since the task was created %s ago, expected at least once every %s",
+		now.Sub(since).Truncate(time.Second), interval)
+	if lastSuccess.Id > 0 {
+		since = time.Time(lastSuccess.EndTime)
+		detail = fmt.Sprintf("Last successful execution #%d finished %s ago, expected at least once every %s",
+			lastSuccess.Id, now.Sub(since).Truncate(time.Second), interval)
+	}
+	if now.Sub(since) <= interval {
+		return
+	}
+	breach := &models.TaskSlaBreach{
+		TaskId:    taskModel.Id,
+		TaskName:  taskModel.Name,
+		Kind:      models.SlaKindSuccess,
+		RefId:     lastSuccess.Id,
+		Threshold: taskModel.SlaSuccessInterval,
+		Detail:    detail,
+	}
+	reportSlaBreach(taskModel, breach, 0)
+}
+
+// reportSlaBreach 记录违约，首次记录时按任务的通知规则发送通知
+func reportSlaBreach(taskModel models.Task, breach *models.TaskSlaBreach, duration time.Duration) {
+	created, err := breach.Record()
+	if err != nil {
+		logger.Errorf("SLA watchdog#Failed to record breach#Task ID-%d#%s", taskModel.Id, err)
+		return
+	}
+	if !created {
+		return
+	}
+	logger.Warnf("SLA breached#Task ID-%d#%s", taskModel.Id, breach.Detail)
+	var baseMsg notify.Message
+	for _, rule := range taskModel.Notifications {
+		if !rule.MatchesSlaBreach() {
+			continue
+		}
+		if rule.Channel != models.NotifyChannelWebhook && rule.ReceiverIds == "" {
+			continue
+		}
+		if notifiedWithin(taskModel.Id, rule) {
+			continue
+		}
+		if baseMsg == nil {
+			// 运行超时的违约关联到超时的那次执行
+			var taskLogId int64
+			if breach.Kind == models.SlaKindDuration {
+				taskLogId = breach.RefId
+			}
+			baseMsg = notifyMessage(taskModel, taskLogId)
+			baseMsg["output"] = breach.Detail
+			baseMsg["status"] = notify.StatusSlaBreached
+			if duration > 0 {
+				baseMsg["duration"] = duration
+			}
+		}
+		notifyPushFunc(ruleMessage(baseMsg, rule))
+	}
+}
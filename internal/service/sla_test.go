@@ -0,0 +1,154 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/notify"
+	"github.com/ncruces/go-sqlite3/gormlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func setupServiceTestDB(t *testing.T) {
+	t.Helper()
+	originalDb, originalPrefix := models.Db, models.TablePrefix
+	originalLast := lastNotificationTimeFunc
+	db, err := gorm.Open(gormlite.Open(":memory:"), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	models.TablePrefix = ""
+	models.Db = db
+	err = db.AutoMigrate(&models.Task{}, &models.Host{}, &models.TaskHost{},
+		&models.TaskNotification{}, &models.TaskSlaBreach{}, &models.TaskHeartbeatCheck{}, &models.Setting{})
+	if err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	// sqlite 的 bigint 主键不会自增，手动建表
+	err = db.Exec(`CREATE TABLE task_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL DEFAULT 0,
+		name TEXT NOT NULL,
+		spec TEXT NOT NULL,
+		protocol INTEGER NOT NULL,
+		command TEXT NOT NULL,
+		timeout INTEGER NOT NULL DEFAULT 0,
+		retry_times INTEGER NOT NULL DEFAULT 0,
+		hostname TEXT NOT NULL DEFAULT '',
+		start_time DATETIME,
+		end_time DATETIME,
+		status INTEGER NOT NULL DEFAULT 1,
+		result TEXT NOT NULL DEFAULT '',
+		http_exchange TEXT
+	)`).Error
+	if err != nil {
+		t.Fatalf("failed to create task_log: %v", err)
+	}
+	lastNotificationTimeFunc = func(taskId int, ruleId int) (time.Time, error) { return time.Time{}, nil }
+	t.Cleanup(func() {
+		models.Db, models.TablePrefix = originalDb, originalPrefix
+		lastNotificationTimeFunc = originalLast
+	})
+}
+
+func createSlaTask(t *testing.T, task models.Task, rules []models.TaskNotification) models.Task {
+	t.Helper()
+	task.Status = models.Enabled
+	task.Level = models.TaskLevelParent
+	if err := models.Db.Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := models.SaveTaskNotifications(task.Id, rules); err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func createSlaTaskLog(t *testing.T, id int64, taskId int, status models.Status, start, end time.Time) {
+	t.Helper()
+	taskLog := models.TaskLog{Id: id, TaskId: taskId, Name: "job", Spec: "* * * * *", Command: "true",
+		StartTime: models.LocalTime(start)}
+	if err := models.Db.Create(&taskLog).Error; err != nil {
+		t.Fatal(err)
+	}
+	models.Db.Model(&models.TaskLog{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"status": status, "end_time": end})
+}
+
+func TestCheckSlaDuration(t *testing.T) {
+	setupServiceTestDB(t)
+	now := time.Now()
+	task := createSlaTask(t, models.Task{Name: "etl", Spec: "0 * * * * *", Command: "run", SlaMaxDuration: 600},
+		[]models.TaskNotification{
+			{Trigger: models.NotifyOnFailure, Channel: models.NotifyChannelSlack, ReceiverIds: "1"},
+			{Trigger: models.NotifyOnRecovery, Channel: models.NotifyChannelWebhook},
+		})
+	createSlaTaskLog(t, 1, task.Id, models.Running, now.Add(-time.Hour), now)
+	createSlaTaskLog(t, 2, task.Id, models.Running, now.Add(-time.Minute), now)
+	createSlaTaskLog(t, 3, task.Id, models.Finish, now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	captured := stubNotifyPush(t)
+	checkSla(now)
+	if len(*captured) != 1 {
+		t.Fatalf("expected 1 notification, got %+v", *captured)
+	}
+	msg := (*captured)[0]
+	if msg["status"] != notify.StatusSlaBreached || msg["task_type"] != int8(models.NotifyChannelSlack) ||
+		!strings.Contains(msg["output"].(string), "Execution #1") {
+		t.Fatalf("unexpected notification: %+v", msg)
+	}
+	if msg["duration"] != time.Hour {
+		t.Fatalf("expected running duration of 1h, got %v", msg["duration"])
+	}
+
+	// 同一次执行不重复通知
+	checkSla(now.Add(time.Minute))
+	if len(*captured) != 1 {
+		t.Fatalf("breach should be notified once, got %d notifications", len(*captured))
+	}
+}
+
+func TestCheckSlaSuccessInterval(t *testing.T) {
+	setupServiceTestDB(t)
+	now := time.Now()
+	rules := []models.TaskNotification{{Trigger: models.NotifyOnStateChange, Channel: models.NotifyChannelWebhook}}
+	task := createSlaTask(t, models.Task{Name: "report", Spec: "0 0 * * * *", Command: "run", SlaSuccessInterval: 3600}, rules)
+	createSlaTaskLog(t, 1, task.Id, models.Finish, now.Add(-3*time.Hour), now.Add(-3*time.Hour))
+	createSlaTaskLog(t, 2, task.Id, models.Failure, now.Add(-time.Hour), now.Add(-time.Hour))
+
+	captured := stubNotifyPush(t)
+	checkSla(now)
+	checkSla(now.Add(time.Minute))
+	if len(*captured) != 1 || !strings.Contains((*captured)[0]["output"].(string), "#1") {
+		t.Fatalf("expected one breach for the last success #1, got %+v", *captured)
+	}
+	if _, ok := (*captured)[0]["duration"]; ok {
+		t.Fatal("success interval breach should not carry a duration")
+	}
+
+	// 新的成功执行后重新计时
+	createSlaTaskLog(t, 3, task.Id, models.Finish, now, now)
+	checkSla(now.Add(30 * time.Minute))
+	if len(*captured) != 1 {
+		t.Fatalf("no breach expected within the interval, got %d", len(*captured))
+	}
+	checkSla(now.Add(2 * time.Hour))
+	if len(*captured) != 2 {
+		t.Fatalf("expected a new breach after the next interval, got %d", len(*captured))
+	}
+
+	// 未启用的任务不检查
+	createSlaTask(t, models.Task{Name: "idle", Spec: "0 0 * * * *", Command: "run", SlaSuccessInterval: 60}, rules)
+	models.Db.Model(&models.Task{}).Where("name = ?", "idle").UpdateColumn("status", models.Disabled)
+	checkSla(now.Add(3 * time.Hour))
+	if len(*captured) != 2 {
+		t.Fatalf("disabled task should be skipped, got %d", len(*captured))
+	}
+}
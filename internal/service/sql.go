@@ -0,0 +1,113 @@
+package service
+
+// SQL 任务：连接数据源执行语句。没有关联主机时由服务端执行，
+// 关联主机时把语句和解密后的连接信息发给节点执行，节点不记录命令内容。
+// 连接信息包含密码，未启用 TLS 时不发给节点
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/app"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"github.com/gocronx-team/gocron/internal/modules/sqlrunner"
+)
+
+// SqlMaxTimeout SQL 任务未设置超时时的上限（秒），与节点执行命令的上限一致
+const SqlMaxTimeout = 86400
+
+var (
+	sqlSourceFunc  = findDataSource
+	sqlRunFunc     = sqlrunner.Run
+	sqlRpcExecFunc = rpcClient.Exec
+	// ErrSQLNodeRequiresTLS 未启用 TLS 时不把 SQL 任务发给节点
+	ErrSQLNodeRequiresTLS = errors.New("SQL tasks can only run on nodes when agent TLS is enabled (enable_tls or internal_ca), remove the hosts to run on the server")
+	sqlTLSEnabledFunc     = func() bool { return app.Setting != nil && app.Setting.EnableTLS }
+)
+
+// SQL 任务
+type SQLHandler struct{}
+
+func (h *SQLHandler) Run(taskModel models.Task, taskUniqueId int64) (result string, err error) {
+	logger.Infof("SQL task execution started#Task ID-%d#Data source ID-%d#Host count-%d",
+		taskModel.Id, taskModel.SqlDataSourceId, len(taskModel.Hosts))
+	job, err := sqlJob(taskModel)
+	if err != nil {
+		return "", err
+	}
+	if len(taskModel.Hosts) == 0 {
+		return runSQLLocal(job, taskModel.Timeout, taskUniqueId)
+	}
+	if !SQLNodeAllowed() {
+		return "", ErrSQLNodeRequiresTLS
+	}
+
+	command, err := sqlrunner.EncodeCommand(job)
+	if err != nil {
+		return "", err
+	}
+
+	return runOnHosts(taskModel.Hosts, "SQL task on node", func(th models.TaskHostDetail) (string, int, error) {
+		// 每个节点使用独立的请求，Exec 会修改其中的超时
+		request := &pb.TaskRequest{Timeout: int32(taskModel.Timeout), Command: command, Id: taskUniqueId}
+		output, err := sqlRpcExecFunc(th.Name, th.Port, request)
+		return output, th.Port, err
+	})
+}
+
+// SQLNodeAllowed SQL 任务是否可以发给节点执行，要求与节点之间启用 TLS
+func SQLNodeAllowed() bool {
+	return sqlTLSEnabledFunc()
+}
+
+// sqlJob 根据任务设置生成执行参数，密码在这里解密
+func sqlJob(taskModel models.Task) (sqlrunner.Job, error) {
+	job := sqlrunner.Job{
+		Query:       taskModel.Command,
+		Transaction: taskModel.SqlTransaction,
+		MaxRows:     taskModel.SqlMaxRows,
+	}
+	dataSource, err := sqlSourceFunc(taskModel.SqlDataSourceId)
+	if err != nil {
+		return job, fmt.Errorf("data source #%d: %w", taskModel.SqlDataSourceId, err)
+	}
+	if job.Source, err = dataSource.Source(); err != nil {
+		return job, fmt.Errorf("data source %s: %w", dataSource.Name, err)
+	}
+	if job.Assertions, err = sqlrunner.ParseAssertions(taskModel.SqlAssertions); err != nil {
+		return job, err
+	}
+
+	return job, nil
+}
+
+// runSQLLocal 在服务端执行，超时或手动停止时取消正在执行的语句
+func runSQLLocal(job sqlrunner.Job, timeout int, taskUniqueId int64) (string, error) {
+	if timeout <= 0 || timeout > SqlMaxTimeout {
+		timeout = SqlMaxTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	run, finish := startLocalRun(ctx, taskUniqueId)
+	defer finish()
+
+	output, err := sqlRunFunc(run, job)
+	if err != nil && run.Stopped() {
+		return strings.TrimSpace(output + "\nManually stopped"), ErrLocalManualStop
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("execution timed out after %ds", timeout)
+	}
+
+	return output, err
+}
+
+func findDataSource(id int) (models.DataSource, error) {
+	return new(models.DataSource).Detail(id)
+}
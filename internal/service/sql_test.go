@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"github.com/gocronx-team/gocron/internal/modules/sqlrunner"
+)
+
+func createSQLiteDataSource(t *testing.T) models.DataSource {
+	t.Helper()
+	if err := models.Db.AutoMigrate(&models.DataSource{}); err != nil {
+		t.Fatal(err)
+	}
+	originalDir := sqlrunner.SqliteDir
+	sqlrunner.SqliteDir = t.TempDir()
+	t.Cleanup(func() { sqlrunner.SqliteDir = originalDir })
+	dataSource := models.DataSource{Name: "reports", Driver: sqlrunner.DriverSqlite, Database: "reports.db"}
+	if err := dataSource.SetPassword("s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dataSource.Create(); err != nil {
+		t.Fatal(err)
+	}
+	return dataSource
+}
+
+func TestSQLHandlerRunLocal(t *testing.T) {
+	setupServiceTestDB(t)
+	dataSource := createSQLiteDataSource(t)
+
+	task := models.Task{Protocol: models.TaskSQL, SqlDataSourceId: dataSource.Id, SqlTransaction: true, Timeout: 30,
+		Command:       "CREATE TABLE jobs (id INTEGER); INSERT INTO jobs VALUES (1), (2); SELECT id FROM jobs ORDER BY id",
+		SqlAssertions: `[{"target":"affected","op":"==","value":2},{"target":"rows","op":">","value":0}]`}
+	output, err := new(SQLHandler).Run(task, 1)
+	if err != nil || !strings.Contains(output, "id\n--\n1\n2\n") || !strings.Contains(output, "Transaction committed") {
+		t.Fatalf("unexpected result %v\n%s", err, output)
+	}
+
+	// 断言失败时任务失败，事务回滚
+	task.Command = "DELETE FROM jobs"
+	task.SqlAssertions = `[{"target":"affected","op":"<=","value":1}]`
+	output, err = new(SQLHandler).Run(task, 2)
+	if err == nil || !strings.Contains(err.Error(), "assertion failed") || !strings.Contains(output, "rolled back") {
+		t.Fatalf("expected assertion failure, got %v\n%s", err, output)
+	}
+
+	task.SqlDataSourceId = dataSource.Id + 100
+	if _, err := new(SQLHandler).Run(task, 3); err == nil || !strings.Contains(err.Error(), "data source #") {
+		t.Fatalf("expected missing data source error, got %v", err)
+	}
+}
+
+func TestSQLHandlerRunOnNodes(t *testing.T) {
+	setupServiceTestDB(t)
+	dataSource := createSQLiteDataSource(t)
+	originalExec, originalTLS := sqlRpcExecFunc, sqlTLSEnabledFunc
+	defer func() { sqlRpcExecFunc, sqlTLSEnabledFunc = originalExec, originalTLS }()
+
+	sqlTLSEnabledFunc = func() bool { return true }
+	sqlRpcExecFunc = func(ip string, port int, req *pb.TaskRequest) (string, error) {
+		payload, ok := strings.CutPrefix(req.Command, sqlrunner.Command+"\n")
+		var job sqlrunner.Job
+		if !ok || json.Unmarshal([]byte(payload), &job) != nil {
+			t.Fatalf("unexpected command %q", req.Command)
+		}
+		// 节点收到解密后的密码
+		if job.Source.Password != "s3cret" || job.Query != "SELECT 1" || job.MaxRows != 5 || req.Timeout != 10 || req.Id != 7 {
+			t.Errorf("unexpected job %+v %+v", job, req)
+		}
+		return "1\n", nil
+	}
+	task := models.Task{Protocol: models.TaskSQL, SqlDataSourceId: dataSource.Id, SqlMaxRows: 5, Timeout: 10, Command: "SELECT 1",
+		Hosts: []models.TaskHostDetail{{Name: "10.0.0.9", Alias: "node", Port: 5921}}}
+	output, err := new(SQLHandler).Run(task, 7)
+	if err != nil || output != "Host: [node-10.0.0.9:5921]\n1" {
+		t.Fatalf("unexpected result %v %q", err, output)
+	}
+
+	// 未启用 TLS 时不把连接信息发给节点
+	sqlTLSEnabledFunc = func() bool { return false }
+	sqlRpcExecFunc = func(ip string, port int, req *pb.TaskRequest) (string, error) {
+		t.Fatal("SQL job must not be sent without TLS")
+		return "", nil
+	}
+	if _, err = new(SQLHandler).Run(task, 8); !errors.Is(err, ErrSQLNodeRequiresTLS) {
+		t.Fatalf("expected ErrSQLNodeRequiresTLS, got %v", err)
+	}
+}
+
+func TestStopSQL(t *testing.T) {
+	setupServiceTestDB(t)
+	dataSource := createSQLiteDataSource(t)
+	originalRun := sqlRunFunc
+	defer func() { sqlRunFunc = originalRun }()
+	started := make(chan struct{})
+	sqlRunFunc = func(ctx context.Context, job sqlrunner.Job) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "[1] SELECT 1\n", ctx.Err()
+	}
+
+	done := make(chan TaskResult, 1)
+	go func() {
+		task := models.Task{Protocol: models.TaskSQL, SqlDataSourceId: dataSource.Id, Command: "SELECT 1"}
+		output, err := new(SQLHandler).Run(task, 11)
+		done <- TaskResult{Result: output, Err: err}
+	}()
+	<-started
+	if ServiceTask.StopLocal(12) {
+		t.Fatal("unknown task should not be stopped")
+	}
+	if !ServiceTask.StopLocal(11) {
+		t.Fatal("running task should be stopped")
+	}
+	select {
+	case result := <-done:
+		if result.Err != ErrLocalManualStop || !strings.HasSuffix(result.Result, "Manually stopped") {
+			t.Fatalf("unexpected result %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("task was not stopped")
+	}
+}
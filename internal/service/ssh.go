@@ -0,0 +1,136 @@
+package service
+
+// SSH 任务：在无法运行 gocron-node 的主机上通过 SSH 执行命令，
+// 连接使用主机设置中的用户名和密钥库中的密码或私钥
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
+)
+
+var (
+	sshExecFunc  = sshclient.Exec
+	sshHostFunc  = findSSHHost
+	sshStopFunc  = sshclient.Stop
+	errNoSSHUser = errors.New("SSH user is not configured for this host")
+)
+
+// SSH调用执行任务
+type SSHHandler struct{}
+
+func (h *SSHHandler) Run(taskModel models.Task, taskUniqueId int64) (result string, err error) {
+	logger.Infof("SSH task execution started#Task ID-%d#Host count-%d", taskModel.Id, len(taskModel.Hosts))
+	if len(taskModel.Hosts) == 0 {
+		return "", fmt.Errorf("task is not associated with any host")
+	}
+
+	return runOnHosts(taskModel.Hosts, "SSH command", func(th models.TaskHostDetail) (string, int, error) {
+		return execSSH(th.HostId, taskModel, taskUniqueId)
+	})
+}
+
+// execSSH 在一台主机上执行命令，首次连接时保存主机公钥
+func execSSH(hostId int, taskModel models.Task, taskUniqueId int64) (string, int, error) {
+	host, err := sshHostFunc(hostId)
+	if err != nil {
+		return "", sshclient.DefaultPort, fmt.Errorf("host #%d: %w", hostId, err)
+	}
+	config, err := resolveSSHConfig(host)
+	port := sshPort(host)
+	if err != nil {
+		return "", port, err
+	}
+	resp, err := sshExecFunc(config, taskModel.Command, taskModel.Timeout, taskUniqueId)
+	if host.SshHostKey == "" && resp.HostKey != "" {
+		saveSSHHostKey(host.Id, resp.HostKey)
+	}
+
+	return resp.Output, port, err
+}
+
+// resolveSSHConfig 从密钥库取出主机的 SSH 密码或私钥
+func resolveSSHConfig(host models.Host) (sshclient.Config, error) {
+	config := sshclient.Config{
+		Host:    host.Name,
+		Port:    host.SshPort,
+		User:    host.SshUser,
+		HostKey: host.SshHostKey,
+	}
+	if strings.TrimSpace(host.SshUser) == "" {
+		return config, errNoSSHUser
+	}
+	var err error
+	if host.SshKeySecret != "" {
+		if config.PrivateKey, err = lookupSecretFunc(host.SshKeySecret); err != nil {
+			return config, fmt.Errorf("SSH private key secret %s: %w", host.SshKeySecret, err)
+		}
+	}
+	if host.SshPasswordSecret != "" {
+		if config.Password, err = lookupSecretFunc(host.SshPasswordSecret); err != nil {
+			return config, fmt.Errorf("SSH password secret %s: %w", host.SshPasswordSecret, err)
+		}
+	}
+
+	return config, nil
+}
+
+func findSSHHost(id int) (models.Host, error) {
+	host := models.Host{}
+	err := host.Find(id)
+	return host, err
+}
+
+func sshPort(host models.Host) int {
+	if host.SshPort > 0 {
+		return host.SshPort
+	}
+	return sshclient.DefaultPort
+}
+
+// saveSSHHostKey 保存首次连接时的主机公钥，之后公钥变化时拒绝连接
+func saveSSHHostKey(hostId int, hostKey string) {
+	_, err := new(models.Host).Update(hostId, models.CommonMap{"ssh_host_key": hostKey})
+	if err != nil {
+		logger.Errorf("Failed to save SSH host key#Host ID-%d#%s", hostId, err)
+		return
+	}
+	logger.Infof("SSH host key saved#Host ID-%d#%s", hostId, hostKey)
+}
+
+// StopSSH 手动停止 SSH 任务在主机上运行的命令，命令不在本实例中运行时返回 false
+func (task Task) StopSSH(hostId int, id int64) bool {
+	host, err := sshHostFunc(hostId)
+	if err != nil {
+		logger.Errorf("Failed to stop SSH task#Host ID-%d#%s", hostId, err)
+		return false
+	}
+	return sshStopFunc(host.Name, host.SshPort, id)
+}
+
+// ValidateSSHHost 检查主机的 SSH 设置，引用的密钥必须存在，私钥和主机公钥格式正确
+func ValidateSSHHost(host models.Host) error {
+	config, err := resolveSSHConfig(host)
+	if err != nil {
+		return err
+	}
+	return config.Validate()
+}
+
+// PingSSH 测试主机的 SSH 连接，首次连接时保存主机公钥
+func PingSSH(host models.Host) error {
+	config, err := resolveSSHConfig(host)
+	if err != nil {
+		return err
+	}
+	hostKey, err := sshclient.Ping(config)
+	if err == nil && host.SshHostKey == "" && hostKey != "" {
+		saveSSHHostKey(host.Id, hostKey)
+	}
+
+	return err
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
+)
+
+func TestSSHHandlerRun(t *testing.T) {
+	setupServiceTestDB(t)
+	originalExec, originalLookup, originalSleep := sshExecFunc, lookupSecretFunc, sleepFunc
+	defer func() { sshExecFunc, lookupSecretFunc, sleepFunc = originalExec, originalLookup, originalSleep }()
+	sleepFunc = func(time.Duration) {}
+	lookupSecretFunc = func(name string) (string, error) {
+		if name == "db-password" {
+			return "pa55word", nil
+		}
+		return "", errors.New("secret not found")
+	}
+
+	host := models.Host{Name: "10.0.0.8", Alias: "db", Port: 5921, SshPort: 2222, SshUser: "deploy", SshPasswordSecret: "db-password"}
+	hostId, err := host.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	sshExecFunc = func(c sshclient.Config, command string, timeout int, id int64) (sshclient.Result, error) {
+		calls++
+		if c.Host != "10.0.0.8" || c.Port != 2222 || c.User != "deploy" || c.Password != "pa55word" || timeout != 30 || id != 9 {
+			t.Errorf("unexpected call %+v %q %d %d", c, command, timeout, id)
+		}
+		// 第一次失败，重试后成功
+		if calls == 1 {
+			return sshclient.Result{Output: "partial", HostKey: "ssh-ed25519 AAAA"}, errors.New("exit status 1")
+		}
+		return sshclient.Result{Output: "done\n", HostKey: "ssh-ed25519 AAAA"}, nil
+	}
+
+	task := models.Task{Command: "backup.sh", Timeout: 30, RetryTimes: 1, Protocol: models.TaskSSH,
+		Hosts: []models.TaskHostDetail{{TaskHost: models.TaskHost{HostId: hostId}, Name: "10.0.0.8", Alias: "db"}}}
+	result := execJob(new(SSHHandler), task, 9)
+	if result.Err != nil || result.Result != "Host: [db-10.0.0.8:2222]\ndone" || result.RetryTimes != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	// 首次连接保存主机公钥
+	if err := host.Find(hostId); err != nil || host.SshHostKey != "ssh-ed25519 AAAA" {
+		t.Fatalf("host key should be saved, got %q %v", host.SshHostKey, err)
+	}
+
+	sshExecFunc = func(c sshclient.Config, command string, timeout int, id int64) (sshclient.Result, error) {
+		if c.HostKey != "ssh-ed25519 AAAA" {
+			t.Errorf("saved host key should be used, got %q", c.HostKey)
+		}
+		return sshclient.Result{Output: "killed"}, sshclient.ErrManualStop
+	}
+	output, err := new(SSHHandler).Run(task, 9)
+	if !errors.Is(err, sshclient.ErrManualStop) || !strings.Contains(output, "Manually stopped") {
+		t.Fatalf("unexpected manual stop result %q %v", output, err)
+	}
+}
+
+func TestResolveSSHConfig(t *testing.T) {
+	original := lookupSecretFunc
+	defer func() { lookupSecretFunc = original }()
+	lookupSecretFunc = func(name string) (string, error) { return "", errors.New("secret not found") }
+
+	if _, err := resolveSSHConfig(models.Host{Name: "web1"}); !errors.Is(err, errNoSSHUser) {
+		t.Fatalf("expected missing user error, got %v", err)
+	}
+	_, err := resolveSSHConfig(models.Host{Name: "web1", SshUser: "root", SshKeySecret: "web-key"})
+	if err == nil || !strings.Contains(err.Error(), "web-key") {
+		t.Fatalf("expected secret error, got %v", err)
+	}
+	if err := ValidateSSHHost(models.Host{Name: "web1", SshUser: "root"}); err == nil {
+		t.Fatal("password or private key should be required")
+	}
+}
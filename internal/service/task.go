@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,6 +22,7 @@ import (
 	"github.com/gocronx-team/gocron/internal/modules/notify"
 	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
 	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 )
 
@@ -29,14 +31,15 @@ var (
 )
 
 var (
-	httpGetFunc              = httpclient.Get
-	httpPostParamsFunc       = httpclient.PostParams
-	httpPostJsonFunc         = httpclient.PostJson
-	httpGetWithHeadersFunc   = httpclient.GetWithHeaders
-	httpPostJsonWithHdrsFunc = httpclient.PostJsonWithHeaders
-	httpPostParamsWithHdrs   = httpclient.PostParamsWithHeaders
+	httpSendFunc             = httpclient.Send
+	rpcExecFunc              = rpcClient.Exec
+	lookupSecretFunc         = models.LookupSecret
+	httpProfileFunc          = new(models.HttpProfile).Detail
 	notifyPushFunc           = notify.Push
 	sleepFunc                = time.Sleep
+	previousFailuresFunc     = new(models.TaskLog).ConsecutiveFailuresBefore
+	lastNotificationTimeFunc = models.LastNotificationTime
+	notifyLogFunc            = loadNotifyLog
 
 	// 定时任务调度管理器
 	serviceCron *cron.Cron
@@ -126,6 +129,8 @@ type TaskResult struct {
 	Result     string
 	Err        error
 	RetryTimes int8
+	// Duration 执行耗时，包含重试等待时间
+	Duration time.Duration
 }
 
 // Initialize 初始化调度器基础设施（不加载任务）
@@ -173,6 +178,8 @@ func (task Task) StartScheduler() {
 	logger.Infof("Scheduled task initialization completed, %d tasks added to scheduler", taskNum)
 
 	task.initLogCleanupTask()
+	task.initCertRotationTask()
+	task.initSlaWatchdogTask()
 	schedulerRunning = true
 }
 
@@ -253,6 +260,17 @@ func (task Task) initLogCleanupTask() {
 			} else {
 				logger.Infof("Auto-cleanup database logs older than %d days, deleted %d records", days, count)
 			}
+			// 清理已结束的通知投递记录
+			if count, err := models.RemoveNotificationsBefore(days); err != nil {
+				logger.Errorf("Failed to cleanup notification deliveries: %s", err)
+			} else if count > 0 {
+				logger.Infof("Cleaned up %d notification deliveries older than %d days", count, days)
+			}
+			if count, err := models.RemoveSlaBreachesBefore(days); err != nil {
+				logger.Errorf("Failed to cleanup SLA breaches: %s", err)
+			} else if count > 0 {
+				logger.Infof("Cleaned up %d SLA breaches older than %d days", count, days)
+			}
 			// 清理日志文件
 			cleanupLogFiles()
 		}
@@ -260,6 +278,15 @@ func (task Task) initLogCleanupTask() {
 	logger.Infof("Log auto-cleanup task added, execution time: %s", cleanupTime)
 }
 
+// 初始化节点证书轮换任务，仅在启用内置 CA 时添加
+func (task Task) initCertRotationTask() {
+	if serviceCron == nil || !CertManager.Enabled() {
+		return
+	}
+	serviceCron.AddFunc("0 30 2 * * *", CertManager.RotateExpiring, "cert-rotation")
+	logger.Info("Certificate rotation task added")
+}
+
 // 重新加载日志清理任务
 func (task Task) ReloadLogCleanupTask() {
 	if serviceCron == nil {
@@ -370,36 +397,125 @@ func (h *HTTPHandler) Run(taskModel models.Task, taskUniqueId int64) (result str
 	if taskModel.Timeout <= 0 {
 		taskModel.Timeout = HttpDefaultTimeout
 	}
+	// 异步任务的触发请求通常返回 202，未设置状态码时接受 2xx
+	if taskModel.HttpAsyncMode != httpclient.AsyncNone && strings.TrimSpace(taskModel.HttpSuccessCodes) == "" {
+		taskModel.HttpSuccessCodes = "200-299"
+	}
 
-	headers := strings.TrimSpace(taskModel.HttpHeaders)
-	var resp httpclient.ResponseWrapper
-	if taskModel.HttpMethod == models.TaskHTTPMethodGet {
-		if headers != "" {
-			resp = httpGetWithHeadersFunc(taskModel.Command, headers, taskModel.Timeout)
-		} else {
-			resp = httpGetFunc(taskModel.Command, taskModel.Timeout)
-		}
-	} else {
-		// POST: 优先使用 HttpBody (JSON)，否则回退到 URL query 参数
-		if strings.TrimSpace(taskModel.HttpBody) != "" {
-			if headers != "" {
-				resp = httpPostJsonWithHdrsFunc(taskModel.Command, taskModel.HttpBody, headers, taskModel.Timeout)
-			} else {
-				resp = httpPostJsonFunc(taskModel.Command, taskModel.HttpBody, taskModel.Timeout)
-			}
-		} else {
-			urlFields := strings.Split(taskModel.Command, "?")
-			url := urlFields[0]
-			var params string
-			if len(urlFields) >= 2 {
-				params = urlFields[1]
-			}
-			if headers != "" {
-				resp = httpPostParamsWithHdrs(url, params, headers, taskModel.Timeout)
-			} else {
-				resp = httpPostParamsFunc(url, params, taskModel.Timeout)
+	auth, err := resolveHttpAuth(taskModel)
+	if err != nil {
+		return err.Error(), err
+	}
+	if auth != nil {
+		// 响应中回显的凭据不写入任务日志和通知
+		defer func() {
+			result = auth.Redact(result)
+			if err != nil {
+				err = errors.New(auth.Redact(err.Error()))
 			}
+		}()
+	}
+	profile, profileName, err := resolveHttpProfile(taskModel)
+	if err != nil {
+		return err.Error(), err
+	}
+	if profile != nil && profile.InsecureSkipVerify {
+		// 跳过证书校验时在每次执行结果中提示
+		defer func() {
+			result = fmt.Sprintf("[WARN] TLS certificate verification is disabled by HTTP profile %s\n%s", profileName, result)
+		}()
+	}
+	asyncConfig, err := httpclient.ParseAsyncConfig(taskModel.HttpAsyncMode, taskModel.HttpAsync)
+	if err != nil {
+		err = fmt.Errorf("HTTP async: %w", err)
+		return err.Error(), err
+	}
+	var run *localRun
+	if taskModel.HttpAsyncMode != httpclient.AsyncNone {
+		// 异步任务等待期间可以手动停止
+		var finish func()
+		run, finish = startLocalRun(context.Background(), taskUniqueId)
+		defer finish()
+	}
+	var callback models.TaskCallback
+	if taskModel.HttpAsyncMode == httpclient.AsyncCallback {
+		if callback, err = prepareHttpCallback(&taskModel, taskUniqueId); err != nil {
+			return err.Error(), err
 		}
+		defer removeHttpCallback(callback)
+	}
+
+	headers := strings.TrimSpace(taskModel.HttpHeaders)
+	exchanges := newHttpExchangeRecorder(taskUniqueId, auth)
+	start := time.Now()
+	resp := httpSendFunc(httpRequestSpec(taskModel, headers, auth, profile), taskModel.Timeout)
+
+	exchanges.record(httpStageRequest, resp)
+	result, err = checkHttpResponse(taskModel, resp, time.Since(start))
+	if err != nil || taskModel.HttpAsyncMode == httpclient.AsyncNone {
+		return result, err
+	}
+
+	return waitHttpAsync(run, taskModel, taskUniqueId, asyncConfig, resp, callback, auth, profile, exchanges)
+}
+
+// httpMaxBodySize 任务设置的响应体大小上限（字节），0 表示默认值
+func httpMaxBodySize(taskModel models.Task) int64 {
+	return int64(taskModel.HttpMaxBodySize) * 1024
+}
+
+// httpRequestSpec 生成任务的请求，未指定请求体类型时按旧版语义生成
+func httpRequestSpec(taskModel models.Task, headers string, auth *httpclient.Auth, profile *httpclient.Profile) httpclient.RequestSpec {
+	spec := httpclient.RequestSpec{
+		Method:      taskModel.HttpMethod.String(),
+		Url:         taskModel.Command,
+		Headers:     headers,
+		BodyType:    taskModel.HttpBodyType,
+		Body:        taskModel.HttpBody,
+		ContentType: taskModel.HttpContentType,
+		MaxBodySize: httpMaxBodySize(taskModel),
+	}
+	if taskModel.HttpBodyType == httpclient.BodyTypeAuto {
+		spec = legacyHttpSpec(taskModel, headers)
+	}
+	spec.Auth = auth
+	spec.Profile = profile
+
+	return spec
+}
+
+// legacyHttpSpec 与旧版请求相同：GET 请求不发送请求体；有请求体时发送 JSON；
+// POST 没有请求体时把地址中的查询参数作为表单发送
+func legacyHttpSpec(taskModel models.Task, headers string) httpclient.RequestSpec {
+	spec := httpclient.RequestSpec{
+		Method:      taskModel.HttpMethod.String(),
+		Url:         taskModel.Command,
+		Headers:     headers,
+		MaxBodySize: httpMaxBodySize(taskModel),
+	}
+	if taskModel.HttpMethod == models.TaskHTTPMethodGet {
+		return spec
+	}
+	if strings.TrimSpace(taskModel.HttpBody) != "" {
+		spec.BodyType = httpclient.BodyTypeJson
+		spec.Body = taskModel.HttpBody
+		return spec
+	}
+	if taskModel.HttpMethod != models.TaskHttpMethodPost {
+		return spec
+	}
+	url, params, _ := strings.Cut(taskModel.Command, "?")
+	spec.Url = url
+	spec.BodyType = httpclient.BodyTypeForm
+	spec.Body = params
+
+	return spec
+}
+
+// checkHttpResponse 按断言或状态码 200、success_pattern 判断响应是否成功
+func checkHttpResponse(taskModel models.Task, resp httpclient.ResponseWrapper, elapsed time.Duration) (string, error) {
+	if hasHttpAssertions(taskModel) {
+		return checkHttpAssertions(taskModel, resp, elapsed)
 	}
 
 	// 返回状态码非200，均为失败
@@ -422,7 +538,101 @@ func (h *HTTPHandler) Run(taskModel models.Task, taskUniqueId int64) (result str
 		}
 	}
 
-	return resp.Body, err
+	return resp.Body, nil
+}
+
+// resolveHttpAuth 从密钥库取出任务认证使用的凭据，未配置认证时返回 nil
+func resolveHttpAuth(taskModel models.Task) (*httpclient.Auth, error) {
+	if taskModel.HttpAuthType == httpclient.AuthNone {
+		return nil, nil
+	}
+	config, err := httpclient.ParseAuthConfig(taskModel.HttpAuthType, taskModel.HttpAuth)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP auth: %w", err)
+	}
+	auth, err := config.Resolve(taskModel.HttpAuthType, lookupSecretFunc)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP auth: %w", err)
+	}
+
+	return auth, nil
+}
+
+// resolveHttpProfile 读取任务使用的 HTTP 配置并取出私钥、代理密码，未配置时返回 nil
+func resolveHttpProfile(taskModel models.Task) (*httpclient.Profile, string, error) {
+	if taskModel.HttpProfileId <= 0 {
+		return nil, "", nil
+	}
+	profileModel, err := httpProfileFunc(taskModel.HttpProfileId)
+	if err != nil {
+		return nil, "", fmt.Errorf("HTTP profile #%d: %w", taskModel.HttpProfileId, err)
+	}
+	profile, err := profileModel.Resolve(lookupSecretFunc)
+	if err != nil {
+		return nil, "", fmt.Errorf("HTTP profile %s: %w", profileModel.Name, err)
+	}
+
+	return profile, profileModel.Name, nil
+}
+
+// hasHttpAssertions 任务是否配置了状态码、响应时间或 JSONPath、响应头断言
+func hasHttpAssertions(taskModel models.Task) bool {
+	return strings.TrimSpace(taskModel.HttpSuccessCodes) != "" ||
+		strings.TrimSpace(taskModel.HttpAssertions) != "" ||
+		taskModel.HttpMaxResponseTime > 0
+}
+
+// checkHttpAssertions 执行全部断言，失败时在结果开头列出每条未通过的断言，后面附上响应内容
+func checkHttpAssertions(taskModel models.Task, resp httpclient.ResponseWrapper, elapsed time.Duration) (string, error) {
+	if resp.StatusCode == 0 {
+		return resp.Body, errors.New("HTTP request failed")
+	}
+	failures := make([]string, 0)
+	codes, err := httpclient.ParseStatusCodes(taskModel.HttpSuccessCodes)
+	if err != nil {
+		failures = append(failures, "invalid success codes: "+err.Error())
+	} else if !codes.Accept(resp.StatusCode) {
+		accepted := taskModel.HttpSuccessCodes
+		if strings.TrimSpace(accepted) == "" {
+			accepted = strconv.Itoa(http.StatusOK)
+		}
+		failures = append(failures, fmt.Sprintf("status code %d is not in %s", resp.StatusCode, accepted))
+	}
+	if maxTime := time.Duration(taskModel.HttpMaxResponseTime) * time.Millisecond; maxTime > 0 && elapsed > maxTime {
+		failures = append(failures, fmt.Sprintf("response time %dms exceeds %dms", elapsed.Milliseconds(), taskModel.HttpMaxResponseTime))
+	}
+	assertions, err := httpclient.ParseAssertions(taskModel.HttpAssertions)
+	if err != nil {
+		failures = append(failures, err.Error())
+	}
+	for _, assertion := range assertions {
+		if err := assertion.Check(resp); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if taskModel.SuccessPattern != "" {
+		re, err := regexp.Compile(taskModel.SuccessPattern)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("invalid success_pattern regex: %v", err))
+		} else if !re.MatchString(resp.Body) && !re.MatchString(compactJSON(resp.Body)) {
+			failures = append(failures, "response body does not match success_pattern: "+taskModel.SuccessPattern)
+		}
+	}
+	if len(failures) == 0 {
+		return resp.Body, nil
+	}
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "Assertions failed (%d):\n", len(failures))
+	for _, failure := range failures {
+		report.WriteString("  - ")
+		report.WriteString(failure)
+		report.WriteString("\n")
+	}
+	report.WriteString("\n")
+	report.WriteString(resp.Body)
+
+	return report.String(), fmt.Errorf("%d HTTP assertion(s) failed: %s", len(failures), failures[0])
 }
 
 // compactJSON 压缩 JSON 字符串，去掉格式化空白。非 JSON 则原样返回。
@@ -442,19 +652,33 @@ func (h *RPCHandler) Run(taskModel models.Task, taskUniqueId int64) (result stri
 	if len(taskModel.Hosts) == 0 {
 		return "", fmt.Errorf("task is not associated with any host")
 	}
+	command, err := artifactCommand(taskModel)
+	if err != nil {
+		return "", err
+	}
 	taskRequest := new(pb.TaskRequest)
 	taskRequest.Timeout = int32(taskModel.Timeout)
-	taskRequest.Command = taskModel.Command
+	taskRequest.Command = command
 	taskRequest.Id = taskUniqueId
-	resultChan := make(chan TaskResult, len(taskModel.Hosts))
-	for _, taskHost := range taskModel.Hosts {
-		logger.Infof("Preparing RPC call#Host-%s:%d#Command-%s", taskHost.Name, taskHost.Port, taskModel.Command)
+
+	return runOnHosts(taskModel.Hosts, "RPC call", func(th models.TaskHostDetail) (string, int, error) {
+		logger.Infof("Preparing RPC call#Host-%s:%d#Command-%s", th.Name, th.Port, taskModel.Command)
+		output, err := rpcExecFunc(th.Name, th.Port, taskRequest)
+		return output, th.Port, err
+	})
+}
+
+// runOnHosts 在每台主机上并发执行，按完成顺序拼接各主机的输出，返回最后一个错误。
+// exec 返回输出、实际连接的端口和错误
+func runOnHosts(hosts []models.TaskHostDetail, action string, exec func(th models.TaskHostDetail) (string, int, error)) (string, error) {
+	resultChan := make(chan TaskResult, len(hosts))
+	for _, taskHost := range hosts {
 		go func(th models.TaskHostDetail) {
-			output, err := rpcClient.Exec(th.Name, th.Port, taskRequest)
+			output, port, err := exec(th)
 			errorMessage := ""
 			if err != nil {
 				// 如果是手动停止错误，保留原始错误以便后续判断，但显示翻译后的文本
-				if errors.Is(err, rpcClient.ErrManualStop) {
+				if isManualStop(err) {
 					errorMessage = "Manually stopped"
 				} else {
 					errorMessage = err.Error()
@@ -465,16 +689,16 @@ func (h *RPCHandler) Run(taskModel models.Task, taskUniqueId int64) (result stri
 				errorMessage = strings.TrimSpace(errorMessage) + "\n"
 			}
 			outputMessage := fmt.Sprintf("Host: [%s-%s:%d]\n%s%s",
-				th.Alias, th.Name, th.Port, errorMessage, output,
+				th.Alias, th.Name, port, errorMessage, output,
 			)
-			logger.Infof("RPC call completed#Host-%s:%d#Output length-%d#Error-%v", th.Name, th.Port, len(output), err)
+			logger.Infof("%s completed#Host-%s:%d#Output length-%d#Error-%v", action, th.Name, port, len(output), err)
 			resultChan <- TaskResult{Err: err, Result: outputMessage}
 		}(taskHost)
 	}
 
 	var aggregationErr error
 	var resultBuilder strings.Builder
-	for i := 0; i < len(taskModel.Hosts); i++ {
+	for i := 0; i < len(hosts); i++ {
 		taskResult := <-resultChan
 		resultBuilder.WriteString(taskResult.Result)
 		if taskResult.Err != nil {
@@ -485,6 +709,11 @@ func (h *RPCHandler) Run(taskModel models.Task, taskUniqueId int64) (result stri
 	return resultBuilder.String(), aggregationErr
 }
 
+// isManualStop 节点或 SSH 执行被手动停止
+func isManualStop(err error) bool {
+	return errors.Is(err, rpcClient.ErrManualStop) || errors.Is(err, sshclient.ErrManualStop)
+}
+
 // 创建任务日志
 func createTaskLog(taskModel models.Task, status models.Status) (int64, error) {
 	taskLogModel := new(models.TaskLog)
@@ -493,8 +722,11 @@ func createTaskLog(taskModel models.Task, status models.Status) (int64, error) {
 	taskLogModel.Spec = taskModel.Spec
 	taskLogModel.Protocol = taskModel.Protocol
 	taskLogModel.Command = taskModel.Command
+	if taskModel.Protocol == models.TaskHTTP {
+		taskLogModel.Command = httpclient.MaskURL(taskModel.Command)
+	}
 	taskLogModel.Timeout = taskModel.Timeout
-	if taskModel.Protocol == models.TaskRPC {
+	if taskModel.Protocol.UsesHosts() {
 		var hostBuilder strings.Builder
 		for _, host := range taskModel.Hosts {
 			hostBuilder.WriteString(host.Alias)
@@ -520,7 +752,8 @@ func updateTaskLog(taskLogId int64, taskResult TaskResult) (int64, error) {
 	// 根据错误类型设置状态
 	if taskResult.Err != nil {
 		// 检查是否是手动停止
-		if errors.Is(taskResult.Err, rpcClient.ErrManualStop) {
+		if errors.Is(taskResult.Err, rpcClient.ErrManualStop) || errors.Is(taskResult.Err, sshclient.ErrManualStop) ||
+			errors.Is(taskResult.Err, ErrLocalManualStop) {
 			status = models.Cancel
 		} else {
 			status = models.Failure
@@ -538,6 +771,9 @@ func updateTaskLog(taskLogId int64, taskResult TaskResult) (int64, error) {
 }
 
 func createJob(taskModel models.Task) cron.FuncJob {
+	if taskModel.Protocol == models.TaskHeartbeat {
+		return createHeartbeatJob(taskModel)
+	}
 	handler := createHandler(taskModel)
 	if handler == nil {
 		return nil
@@ -561,7 +797,9 @@ func createJob(taskModel models.Task) cron.FuncJob {
 		defer concurrencyQueue.Done()
 
 		logger.Infof("Starting task execution#%s#Command-%s", taskModel.Name, taskModel.Command)
+		startTime := time.Now()
 		taskResult := execJob(handler, taskModel, taskLogId)
+		taskResult.Duration = time.Since(startTime)
 		logger.Infof("Task completed#%s#Command-%s", taskModel.Name, taskModel.Command)
 		afterExecJob(taskModel, taskResult, taskLogId)
 	}
@@ -576,6 +814,12 @@ func createHandler(taskModel models.Task) Handler {
 		handler = new(HTTPHandler)
 	case models.TaskRPC:
 		handler = new(RPCHandler)
+	case models.TaskSSH:
+		handler = new(SSHHandler)
+	case models.TaskSQL:
+		handler = new(SQLHandler)
+	case models.TaskGRPC:
+		handler = new(GRPCHandler)
 	}
 
 	return handler
@@ -613,7 +857,7 @@ func afterExecJob(taskModel models.Task, taskResult TaskResult, taskLogId int64)
 	}
 
 	// 发送邮件
-	go SendNotification(taskModel, taskResult)
+	go SendNotification(taskModel, taskResult, taskLogId)
 	// 执行依赖任务
 	go execDependencyTask(taskModel, taskResult)
 }
@@ -656,44 +900,162 @@ func execDependencyTask(taskModel models.Task, taskResult TaskResult) {
 	}
 }
 
-// 发送任务结果通知
-func SendNotification(taskModel models.Task, taskResult TaskResult) {
-	var statusName string
-	// 未开启通知
-	if taskModel.NotifyStatus == 0 {
-		return
+// 发送任务结果通知，按任务的通知规则分别推送到每个匹配的渠道
+func SendNotification(taskModel models.Task, taskResult TaskResult, taskLogId int64) {
+	failed := taskResult.Err != nil
+	statusName := "Success"
+	if failed {
+		statusName = "Failed"
 	}
-	if taskModel.NotifyStatus == 1 && taskResult.Err == nil {
-		// 执行失败才发送通知
-		return
+	// 本次执行之前的连续失败次数，仅在状态变化类规则需要时查询
+	previousFailures := -1
+	// 与渠道无关的消息内容，在第一条规则匹配时生成
+	var baseMsg notify.Message
+	for _, rule := range taskModel.Notifications {
+		// 告警平台渠道在执行成功时总是推送，由渠道关闭此前打开的事件
+		autoResolve := rule.Channel.IsIncident() && !failed
+		if !autoResolve {
+			failures := 0
+			if rule.NeedsRunHistory() {
+				if previousFailures < 0 {
+					previousFailures = loadPreviousFailures(taskModel.Id, taskLogId)
+				}
+				failures = previousFailures
+			}
+			if !rule.Matches(taskResult.Result, failed, failures) {
+				continue
+			}
+		}
+		// WebHook 不需要 receiver_id，其他渠道需要
+		if rule.Channel != models.NotifyChannelWebhook && rule.ReceiverIds == "" {
+			continue
+		}
+		// 恢复通知和自动关闭事件不受最小间隔限制
+		recovery := autoResolve || (!failed && rule.NeedsRunHistory())
+		if !recovery && notifiedWithin(taskModel.Id, rule) {
+			continue
+		}
+		if baseMsg == nil {
+			baseMsg = notifyMessage(taskModel, taskLogId)
+			baseMsg["output"] = taskResult.Result
+			baseMsg["status"] = statusName
+			baseMsg["duration"] = taskResult.Duration
+			baseMsg["retry_times"] = taskResult.RetryTimes
+			baseMsg["exit_code"] = exitCode(taskResult)
+		}
+		notifyPushFunc(ruleMessage(baseMsg, rule))
 	}
-	if taskModel.NotifyStatus == 3 {
-		// 关键字匹配通知
-		if !strings.Contains(taskResult.Result, taskModel.NotifyKeyword) {
-			return
+}
+
+// notifyMessage 通知消息中与渠道无关的任务信息，taskLogId 对应的执行日志提供开始、结束时间和日志链接
+func notifyMessage(taskModel models.Task, taskLogId int64) notify.Message {
+	hosts := make([]string, 0, len(taskModel.Hosts))
+	for _, host := range taskModel.Hosts {
+		hosts = append(hosts, fmt.Sprintf("%s:%d", host.Name, host.Port))
+	}
+	msg := notify.Message{
+		"name":    taskModel.Name,
+		"task_id": taskModel.Id,
+		"remark":  taskModel.Remark,
+		"tags":    taskModel.Tag,
+		"hosts":   strings.Join(hosts, ", "),
+	}
+	taskLog, siteUrl := notifyLogFunc(taskLogId)
+	if taskLog.Id > 0 {
+		msg["log_id"] = taskLog.Id
+		msg["retry_times"] = taskLog.RetryTimes
+		msg["start_time"] = time.Time(taskLog.StartTime)
+		if taskLog.Status != models.Running {
+			msg["end_time"] = time.Time(taskLog.EndTime)
 		}
 	}
-	// NotifyType: 0=邮件, 1=Slack, 2=WebHook
-	// WebHook(type=2)不需要receiver_id，其他类型需要
-	if taskModel.NotifyType != 2 && taskModel.NotifyReceiverId == "" {
-		return
+	if siteUrl != "" {
+		msg["log_url"] = taskLogUrl(siteUrl, taskModel.Id, taskLog.Id)
 	}
-	if taskResult.Err != nil {
-		statusName = "Failed"
-	} else {
-		statusName = "Success"
+
+	return msg
+}
+
+// ruleMessage 复制消息并填入规则的渠道、接收者和自定义模板，渠道渲染时会修改消息
+func ruleMessage(baseMsg notify.Message, rule models.TaskNotification) notify.Message {
+	msg := make(notify.Message, len(baseMsg)+4)
+	for k, v := range baseMsg {
+		msg[k] = v
 	}
-	// 发送通知
-	msg := notify.Message{
-		"task_type":        taskModel.NotifyType,
-		"task_receiver_id": taskModel.NotifyReceiverId,
-		"name":             taskModel.Name,
-		"output":           taskResult.Result,
-		"status":           statusName,
-		"task_id":          taskModel.Id,
-		"remark":           taskModel.Remark,
-	}
-	notifyPushFunc(msg)
+	msg["task_type"] = int8(rule.Channel)
+	msg["task_receiver_id"] = rule.ReceiverIds
+	msg["template"] = rule.Template
+	msg["rule_id"] = rule.Id
+
+	return msg
+}
+
+// loadNotifyLog 查询执行日志和站点地址，查询失败时不影响通知发送
+func loadNotifyLog(taskLogId int64) (models.TaskLog, string) {
+	taskLog := models.TaskLog{}
+	if taskLogId > 0 {
+		if err := taskLog.Find(taskLogId); err != nil {
+			logger.Errorf("Failed to load task log for notification#Log ID-%d#%s", taskLogId, err.Error())
+			taskLog = models.TaskLog{}
+		}
+	}
+
+	return taskLog, new(models.Setting).GetSiteUrl()
+}
+
+// taskLogUrl 任务日志页面地址，logId 为 0 时只按任务筛选
+func taskLogUrl(siteUrl string, taskId int, logId int64) string {
+	url := fmt.Sprintf("%s/#/task/log?task_id=%d", strings.TrimRight(siteUrl, "/"), taskId)
+	if logId > 0 {
+		url += fmt.Sprintf("&log_id=%d", logId)
+	}
+
+	return url
+}
+
+var exitStatusPattern = regexp.MustCompile(`exit status (\d+)`)
+
+// exitCode 命令退出码：成功为 0，失败时从输出中的 "exit status N" 解析，无法确定时为 -1
+func exitCode(taskResult TaskResult) int {
+	if taskResult.Err == nil {
+		return 0
+	}
+	for _, text := range []string{taskResult.Result, taskResult.Err.Error()} {
+		matches := exitStatusPattern.FindAllStringSubmatch(text, -1)
+		if len(matches) > 0 {
+			code, _ := strconv.Atoi(matches[len(matches)-1][1])
+			return code
+		}
+	}
+
+	return -1
+}
+
+// loadPreviousFailures 查询失败时按 0 处理，宁可多发一次通知也不漏报
+func loadPreviousFailures(taskId int, taskLogId int64) int {
+	failures, err := previousFailuresFunc(taskId, taskLogId)
+	if err != nil {
+		logger.Errorf("Failed to load previous task results#Task ID-%d#%s", taskId, err.Error())
+		return 0
+	}
+	return failures
+}
+
+// notifiedWithin 规则是否在最小间隔内已经通知过，同一渠道上的其他规则互不影响
+func notifiedWithin(taskId int, rule models.TaskNotification) bool {
+	if rule.MinInterval <= 0 {
+		return false
+	}
+	last, err := lastNotificationTimeFunc(taskId, rule.Id)
+	if err != nil {
+		logger.Errorf("Failed to load last notification time#Task ID-%d#%s", taskId, err.Error())
+		return false
+	}
+	if !last.IsZero() && time.Since(last) < time.Duration(rule.MinInterval)*time.Second {
+		logger.Infof("Notification throttled#Task ID-%d#Rule ID-%d#Channel-%d#Min interval-%ds", taskId, rule.Id, rule.Channel, rule.MinInterval)
+		return true
+	}
+	return false
 }
 
 // 执行具体任务
@@ -21,13 +21,13 @@ func TestMain(m *testing.M) {
 }
 
 func TestHTTPHandlerRunGetUsesCustomTimeout(t *testing.T) {
-	original := httpGetFunc
-	defer func() { httpGetFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
 	var capturedTimeout int
-	httpGetFunc = func(url string, timeout int) httpclient.ResponseWrapper {
-		if url != "http://example.com" {
-			t.Fatalf("unexpected url %s", url)
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		if spec.Url != "http://example.com" || spec.Method != http.MethodGet {
+			t.Fatalf("unexpected request %+v", spec)
 		}
 		capturedTimeout = timeout
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
@@ -53,11 +53,11 @@ func TestHTTPHandlerRunGetUsesCustomTimeout(t *testing.T) {
 }
 
 func TestHTTPHandlerRunGetDefaultTimeout(t *testing.T) {
-	original := httpGetFunc
-	defer func() { httpGetFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
 	var capturedTimeout int
-	httpGetFunc = func(url string, timeout int) httpclient.ResponseWrapper {
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
 		capturedTimeout = timeout
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
 	}
@@ -75,14 +75,17 @@ func TestHTTPHandlerRunGetDefaultTimeout(t *testing.T) {
 }
 
 func TestHTTPHandlerRunPostParsesParams(t *testing.T) {
-	original := httpPostParamsFunc
-	defer func() { httpPostParamsFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
 	var capturedURL, capturedParams string
 	var capturedTimeout int
-	httpPostParamsFunc = func(url, params string, timeout int) httpclient.ResponseWrapper {
-		capturedURL = url
-		capturedParams = params
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		if spec.BodyType != httpclient.BodyTypeForm {
+			t.Fatalf("expected form body, got %q", spec.BodyType)
+		}
+		capturedURL = spec.Url
+		capturedParams = spec.Body
 		capturedTimeout = timeout
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "posted"}
 	}
@@ -109,10 +112,10 @@ func TestHTTPHandlerRunPostParsesParams(t *testing.T) {
 }
 
 func TestHTTPHandlerRunReturnsErrorForNon200(t *testing.T) {
-	original := httpGetFunc
-	defer func() { httpGetFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
-	httpGetFunc = func(url string, timeout int) httpclient.ResponseWrapper {
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
 		return httpclient.ResponseWrapper{StatusCode: http.StatusInternalServerError, Body: "bad"}
 	}
 	handler := &HTTPHandler{}
@@ -127,12 +130,12 @@ func TestHTTPHandlerRunReturnsErrorForNon200(t *testing.T) {
 }
 
 func TestHTTPHandlerRunPostJsonBody(t *testing.T) {
-	original := httpPostJsonFunc
-	defer func() { httpPostJsonFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
 	var capturedBody string
-	httpPostJsonFunc = func(url, body string, timeout int) httpclient.ResponseWrapper {
-		capturedBody = body
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		capturedBody = spec.Body
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
 	}
 
@@ -152,13 +155,189 @@ func TestHTTPHandlerRunPostJsonBody(t *testing.T) {
 	}
 }
 
+func TestHTTPHandlerRunBodyTypes(t *testing.T) {
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
+
+	var captured httpclient.RequestSpec
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		captured = spec
+		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
+	}
+
+	handler := &HTTPHandler{}
+	task := models.Task{
+		Command:         "http://example.com/api",
+		HttpMethod:      models.TaskHTTPMethodPut,
+		HttpBody:        "<a/>",
+		HttpBodyType:    httpclient.BodyTypeRaw,
+		HttpContentType: "application/xml",
+		HttpHeaders:     `{"X-Id": "1"}`,
+	}
+	if _, err := handler.Run(task, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if captured.Method != "PUT" || captured.Body != "<a/>" || captured.BodyType != httpclient.BodyTypeRaw ||
+		captured.ContentType != "application/xml" || captured.Headers != `{"X-Id": "1"}` {
+		t.Fatalf("unexpected request spec: %+v", captured)
+	}
+
+	// POST 指定请求体类型时同样走通用请求
+	task = models.Task{Command: "http://example.com/api", HttpMethod: models.TaskHttpMethodPost, HttpBody: `{"a":"1"}`, HttpBodyType: httpclient.BodyTypeForm}
+	if _, err := handler.Run(task, 1); err != nil || captured.Method != "POST" || captured.BodyType != httpclient.BodyTypeForm {
+		t.Fatalf("unexpected request spec: %+v %v", captured, err)
+	}
+}
+
+func TestHTTPHandlerRunAssertions(t *testing.T) {
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
+
+	resp := httpclient.ResponseWrapper{StatusCode: http.StatusCreated, Body: `{"code":0,"data":{"id":7}}`, Header: http.Header{"X-Request-Id": {"abc"}}}
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		return resp
+	}
+
+	handler := &HTTPHandler{}
+	task := models.Task{
+		Command:          "http://example.com/api",
+		HttpMethod:       models.TaskHTTPMethodPut,
+		HttpSuccessCodes: "200-299",
+		HttpAssertions:   `[{"source":"json","target":"$.code","op":"==","value":"0"},{"source":"header","target":"X-Request-Id","op":"exists"}]`,
+	}
+	result, err := handler.Run(task, 1)
+	if err != nil || result != resp.Body {
+		t.Fatalf("expected success, got %q %v", result, err)
+	}
+
+	// 每条失败的断言都写入结果，响应内容附在后面
+	resp = httpclient.ResponseWrapper{StatusCode: http.StatusInternalServerError, Body: `{"code":5}`, Header: http.Header{}}
+	task.SuccessPattern = `"ok"`
+	result, err = handler.Run(task, 1)
+	if err == nil || !strings.Contains(err.Error(), "4 HTTP assertion(s) failed") {
+		t.Fatalf("expected assertion error, got %v", err)
+	}
+	for _, want := range []string{
+		"Assertions failed (4):",
+		"status code 500 is not in 200-299",
+		`json $.code == "0": actual 5`,
+		"header X-Request-Id exists: not found",
+		"does not match success_pattern",
+		"\n\n{\"code\":5}",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("result should contain %q:\n%s", want, result)
+		}
+	}
+
+	// 只配置最大响应时间时，状态码仍按 200 判断
+	resp = httpclient.ResponseWrapper{StatusCode: http.StatusNoContent}
+	task = models.Task{Command: "http://example.com/api", HttpMethod: models.TaskHTTPMethodDelete, HttpMaxResponseTime: 60000}
+	if _, err := handler.Run(task, 1); err == nil || !strings.Contains(err.Error(), "status code 204 is not in 200") {
+		t.Fatalf("expected status code failure, got %v", err)
+	}
+
+	_, err = checkHttpAssertions(models.Task{HttpMaxResponseTime: 100}, httpclient.ResponseWrapper{StatusCode: http.StatusOK}, 150*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "response time 150ms exceeds 100ms") {
+		t.Fatalf("expected response time failure, got %v", err)
+	}
+}
+
+func TestHTTPHandlerRunAuth(t *testing.T) {
+	originalSend, originalLookup := httpSendFunc, lookupSecretFunc
+	defer func() { httpSendFunc, lookupSecretFunc = originalSend, originalLookup }()
+
+	lookupSecretFunc = func(name string) (string, error) {
+		if name == "api-token" {
+			return "token-123", nil
+		}
+		return "", models.ErrSecretNotFound
+	}
+	var captured httpclient.RequestSpec
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		captured = spec
+		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "echo Bearer " + spec.Auth.Token}
+	}
+
+	// 配置认证后 GET 请求也走通用发送，回显的 token 不写入结果
+	handler := &HTTPHandler{}
+	task := models.Task{
+		Command:      "http://example.com/api",
+		HttpMethod:   models.TaskHTTPMethodGet,
+		HttpAuthType: httpclient.AuthBearer,
+		HttpAuth:     `{"token_secret":"api-token"}`,
+	}
+	result, err := handler.Run(task, 1)
+	if err != nil || captured.Auth == nil || captured.Auth.Token != "token-123" {
+		t.Fatalf("auth should be resolved from secrets, got %+v %v", captured, err)
+	}
+	if result != "echo Bearer "+httpclient.MaskedValue {
+		t.Fatalf("token should be redacted, got %q", result)
+	}
+
+	task.HttpAuth = `{"token_secret":"missing"}`
+	if _, err := handler.Run(task, 1); err == nil || !strings.Contains(err.Error(), `HTTP auth: secret "missing"`) {
+		t.Fatalf("missing secret should fail the task, got %v", err)
+	}
+}
+
+func TestHTTPHandlerRunProfile(t *testing.T) {
+	originalSend, originalLookup, originalProfile := httpSendFunc, lookupSecretFunc, httpProfileFunc
+	defer func() {
+		httpSendFunc, lookupSecretFunc, httpProfileFunc = originalSend, originalLookup, originalProfile
+	}()
+
+	lookupSecretFunc = func(name string) (string, error) {
+		if name == "proxy-password" {
+			return "s3cret", nil
+		}
+		return "", models.ErrSecretNotFound
+	}
+	httpProfileFunc = func(id int) (models.HttpProfile, error) {
+		if id != 3 {
+			return models.HttpProfile{}, errors.New("record not found")
+		}
+		return models.HttpProfile{Id: 3, Name: "egress", InsecureSkipVerify: true, ProxyUrl: "http://proxy:3128", ProxyUsername: "egress", ProxyPasswordSecret: "proxy-password"}, nil
+	}
+	var captured httpclient.RequestSpec
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		captured = spec
+		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
+	}
+
+	// 使用 HTTP 配置时走通用发送，跳过证书校验在结果中提示
+	handler := &HTTPHandler{}
+	task := models.Task{Command: "https://internal.example/api", HttpMethod: models.TaskHTTPMethodGet, HttpProfileId: 3}
+	result, err := handler.Run(task, 1)
+	if err != nil || captured.Profile == nil || captured.Profile.ProxyPassword != "s3cret" || captured.Profile.Name != "profile-3" {
+		t.Fatalf("profile should be resolved, got %+v %v", captured.Profile, err)
+	}
+	if result != "[WARN] TLS certificate verification is disabled by HTTP profile egress\nok" {
+		t.Fatalf("insecure profile should be reported, got %q", result)
+	}
+
+	// 使用 HTTP 配置的旧版 POST 仍把查询参数作为表单发送
+	task = models.Task{Command: "https://internal.example/api?a=1", HttpMethod: models.TaskHttpMethodPost, HttpProfileId: 3}
+	if _, err = handler.Run(task, 1); err != nil || captured.Profile == nil {
+		t.Fatalf("unexpected result %+v %v", captured, err)
+	}
+	if captured.Url != "https://internal.example/api" || captured.BodyType != httpclient.BodyTypeForm || captured.Body != "a=1" {
+		t.Fatalf("legacy POST should send the query as a form body, got %+v", captured)
+	}
+
+	task.HttpProfileId = 4
+	if _, err := handler.Run(task, 1); err == nil || !strings.Contains(err.Error(), "HTTP profile #4") {
+		t.Fatalf("missing profile should fail the task, got %v", err)
+	}
+}
+
 func TestHTTPHandlerRunPostFallbackToParams(t *testing.T) {
-	original := httpPostParamsFunc
-	defer func() { httpPostParamsFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
 	var capturedParams string
-	httpPostParamsFunc = func(url, params string, timeout int) httpclient.ResponseWrapper {
-		capturedParams = params
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		capturedParams = spec.Body
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
 	}
 
@@ -179,10 +358,10 @@ func TestHTTPHandlerRunPostFallbackToParams(t *testing.T) {
 }
 
 func TestHTTPHandlerRunSuccessPatternMatch(t *testing.T) {
-	original := httpGetFunc
-	defer func() { httpGetFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
-	httpGetFunc = func(url string, timeout int) httpclient.ResponseWrapper {
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: `{"status":"ok","code":0}`}
 	}
 
@@ -200,10 +379,10 @@ func TestHTTPHandlerRunSuccessPatternMatch(t *testing.T) {
 }
 
 func TestHTTPHandlerRunSuccessPatternNoMatch(t *testing.T) {
-	original := httpGetFunc
-	defer func() { httpGetFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
-	httpGetFunc = func(url string, timeout int) httpclient.ResponseWrapper {
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: `{"status":"error","code":1}`}
 	}
 
@@ -224,10 +403,10 @@ func TestHTTPHandlerRunSuccessPatternNoMatch(t *testing.T) {
 }
 
 func TestHTTPHandlerRunSuccessPatternInvalidRegex(t *testing.T) {
-	original := httpGetFunc
-	defer func() { httpGetFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
-	httpGetFunc = func(url string, timeout int) httpclient.ResponseWrapper {
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
 	}
 
@@ -248,11 +427,11 @@ func TestHTTPHandlerRunSuccessPatternInvalidRegex(t *testing.T) {
 }
 
 func TestHTTPHandlerRunSuccessPatternMatchCompactJSON(t *testing.T) {
-	original := httpGetFunc
-	defer func() { httpGetFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
 	// 模拟 pretty-printed JSON 响应（如 httpbin.org）
-	httpGetFunc = func(url string, timeout int) httpclient.ResponseWrapper {
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
 		return httpclient.ResponseWrapper{
 			StatusCode: http.StatusOK,
 			Body: `{
@@ -278,10 +457,10 @@ func TestHTTPHandlerRunSuccessPatternMatchCompactJSON(t *testing.T) {
 }
 
 func TestHTTPHandlerRunEmptyPatternSkipsCheck(t *testing.T) {
-	original := httpGetFunc
-	defer func() { httpGetFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
-	httpGetFunc = func(url string, timeout int) httpclient.ResponseWrapper {
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "anything"}
 	}
 
@@ -299,12 +478,12 @@ func TestHTTPHandlerRunEmptyPatternSkipsCheck(t *testing.T) {
 }
 
 func TestHTTPHandlerRunGetWithHeaders(t *testing.T) {
-	original := httpGetWithHeadersFunc
-	defer func() { httpGetWithHeadersFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
 	var capturedHeaders string
-	httpGetWithHeadersFunc = func(url, headers string, timeout int) httpclient.ResponseWrapper {
-		capturedHeaders = headers
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		capturedHeaders = spec.Headers
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
 	}
 
@@ -325,13 +504,13 @@ func TestHTTPHandlerRunGetWithHeaders(t *testing.T) {
 }
 
 func TestHTTPHandlerRunPostJsonWithHeaders(t *testing.T) {
-	original := httpPostJsonWithHdrsFunc
-	defer func() { httpPostJsonWithHdrsFunc = original }()
+	original := httpSendFunc
+	defer func() { httpSendFunc = original }()
 
 	var capturedBody, capturedHeaders string
-	httpPostJsonWithHdrsFunc = func(url, body, headers string, timeout int) httpclient.ResponseWrapper {
-		capturedBody = body
-		capturedHeaders = headers
+	httpSendFunc = func(spec httpclient.RequestSpec, timeout int) httpclient.ResponseWrapper {
+		capturedBody = spec.Body
+		capturedHeaders = spec.Headers
 		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
 	}
 
@@ -444,50 +623,79 @@ func TestSendNotificationBehavior(t *testing.T) {
 	tests := []expectation{
 		{
 			name:  "disabled",
-			task:  models.Task{NotifyStatus: 0},
+			task:  models.Task{},
 			count: 0,
 		},
 		{
 			name:   "failOnlySuccess",
-			task:   models.Task{NotifyStatus: 1, NotifyType: 1, NotifyReceiverId: "user"},
+			task:   models.Task{Notifications: []models.TaskNotification{{Trigger: models.NotifyOnFailure, Channel: models.NotifyChannelSlack, ReceiverIds: "user"}}},
 			result: TaskResult{Result: "ok", Err: nil},
 			count:  0,
 		},
 		{
 			name:   "failOnlyTriggered",
-			task:   models.Task{Name: "job", NotifyStatus: 1, NotifyType: 1, NotifyReceiverId: "user"},
+			task:   models.Task{Name: "job", Notifications: []models.TaskNotification{{Trigger: models.NotifyOnFailure, Channel: models.NotifyChannelSlack, ReceiverIds: "user"}}},
 			result: TaskResult{Result: "bad", Err: errors.New("boom")},
 			count:  1,
 		},
 		{
 			name:   "keywordMismatch",
-			task:   models.Task{NotifyStatus: 3, NotifyType: 2, NotifyKeyword: "ERROR"},
+			task:   models.Task{Notifications: []models.TaskNotification{{Trigger: models.NotifyOnKeyword, Channel: models.NotifyChannelWebhook, Keyword: "ERROR"}}},
 			result: TaskResult{Result: "all good"},
 			count:  0,
 		},
 		{
 			name:   "keywordMatch",
-			task:   models.Task{Name: "job", NotifyStatus: 3, NotifyType: 2, NotifyKeyword: "ERROR"},
-			result: TaskResult{Result: "found ERROR", Err: nil},
+			task:   models.Task{Name: "job", Notifications: []models.TaskNotification{{Trigger: models.NotifyOnKeyword, Channel: models.NotifyChannelWebhook, Keyword: "ERROR"}}},
+			result: TaskResult{Result: "found ERROR", Err: nil, Duration: 3 * time.Second},
 			count:  1,
 			check: func(t *testing.T, msg notify.Message) {
 				if msg["status"] != "Success" {
 					t.Fatalf("expected status Success, got %v", msg["status"])
 				}
+				if msg["duration"] != 3*time.Second {
+					t.Fatalf("expected duration 3s, got %v", msg["duration"])
+				}
 			},
 		},
 		{
 			name:   "missingReceiverForMail",
-			task:   models.Task{NotifyStatus: 2, NotifyType: 1, NotifyReceiverId: ""},
+			task:   models.Task{Notifications: []models.TaskNotification{{Trigger: models.NotifyAlways, Channel: models.NotifyChannelSlack, ReceiverIds: ""}}},
 			result: TaskResult{Result: "any"},
 			count:  0,
 		},
+		{
+			name:   "incidentResolveOnSuccess",
+			task:   models.Task{Name: "job", Notifications: []models.TaskNotification{{Trigger: models.NotifyOnFailure, Channel: models.NotifyChannelPagerDuty, ReceiverIds: "1"}}},
+			result: TaskResult{Result: "ok"},
+			count:  1,
+			check: func(t *testing.T, msg notify.Message) {
+				if msg["status"] != "Success" || msg["task_type"] != int8(models.NotifyChannelPagerDuty) {
+					t.Fatalf("unexpected message: %+v", msg)
+				}
+			},
+		},
+		{
+			name: "multipleChannels",
+			task: models.Task{Name: "job", Notifications: []models.TaskNotification{
+				{Trigger: models.NotifyOnFailure, Channel: models.NotifyChannelMail, ReceiverIds: "1"},
+				{Trigger: models.NotifyAlways, Channel: models.NotifyChannelSlack, ReceiverIds: "2,3"},
+				{Trigger: models.NotifyOnKeyword, Channel: models.NotifyChannelWebhook, Keyword: "never"},
+			}},
+			result: TaskResult{Result: "bad", Err: errors.New("boom")},
+			count:  2,
+			check: func(t *testing.T, msg notify.Message) {
+				if msg["task_type"] != int8(0) || msg["task_receiver_id"] != "1" || msg["status"] != "Failed" {
+					t.Fatalf("unexpected first message: %+v", msg)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			captured := stubNotifyPush(t)
-			SendNotification(tt.task, tt.result)
+			SendNotification(tt.task, tt.result, 1)
 			if len(*captured) != tt.count {
 				t.Fatalf("expected %d notifications, got %d", tt.count, len(*captured))
 			}
@@ -498,10 +706,145 @@ func TestSendNotificationBehavior(t *testing.T) {
 	}
 }
 
+func TestSendNotificationTransitions(t *testing.T) {
+	previousFailures := 0
+	var lastNotified time.Time
+	originalFailures, originalLast := previousFailuresFunc, lastNotificationTimeFunc
+	previousFailuresFunc = func(taskId int, taskLogId int64) (int, error) { return previousFailures, nil }
+	lastNotificationTimeFunc = func(taskId int, ruleId int) (time.Time, error) { return lastNotified, nil }
+	defer func() { previousFailuresFunc, lastNotificationTimeFunc = originalFailures, originalLast }()
+
+	stateChange := models.Task{Name: "job", Notifications: []models.TaskNotification{
+		{Trigger: models.NotifyOnStateChange, Channel: models.NotifyChannelSlack, ReceiverIds: "1", RepeatEvery: 3},
+	}}
+	failed := TaskResult{Result: "bad", Err: errors.New("boom")}
+	tests := []struct {
+		name             string
+		previousFailures int
+		result           TaskResult
+		count            int
+	}{
+		{"firstFailure", 0, failed, 1},
+		{"secondFailure", 1, failed, 0},
+		{"fourthFailureRepeats", 3, failed, 1},
+		{"recovery", 5, TaskResult{Result: "ok"}, 1},
+		{"stillHealthy", 0, TaskResult{Result: "ok"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previousFailures = tt.previousFailures
+			captured := stubNotifyPush(t)
+			SendNotification(stateChange, tt.result, 10)
+			if len(*captured) != tt.count {
+				t.Fatalf("expected %d notifications, got %d", tt.count, len(*captured))
+			}
+		})
+	}
+
+	// 最小间隔内的失败通知被抑制，恢复通知不受限制
+	throttled := models.Task{Name: "job", Notifications: []models.TaskNotification{
+		{Trigger: models.NotifyOnFailure, Channel: models.NotifyChannelSlack, ReceiverIds: "1", MinInterval: 600},
+		{Trigger: models.NotifyOnRecovery, Channel: models.NotifyChannelWebhook, MinInterval: 600},
+	}}
+	lastNotified = time.Now().Add(-time.Minute)
+	previousFailures = 2
+	captured := stubNotifyPush(t)
+	SendNotification(throttled, failed, 10)
+	SendNotification(throttled, TaskResult{Result: "ok"}, 11)
+	if len(*captured) != 1 || (*captured)[0]["task_type"] != int8(models.NotifyChannelWebhook) {
+		t.Fatalf("expected only the recovery notification, got %+v", *captured)
+	}
+
+	lastNotified = time.Now().Add(-time.Hour)
+	captured = stubNotifyPush(t)
+	SendNotification(throttled, failed, 12)
+	if len(*captured) != 1 {
+		t.Fatalf("expected failure notification after the interval, got %d", len(*captured))
+	}
+
+	// 同一渠道上的规则分别计算最小间隔
+	sameChannel := models.Task{Name: "job", Notifications: []models.TaskNotification{
+		{Id: 1, Trigger: models.NotifyOnFailure, Channel: models.NotifyChannelSlack, ReceiverIds: "1", MinInterval: 600},
+		{Id: 2, Trigger: models.NotifyOnKeyword, Keyword: "bad", Channel: models.NotifyChannelSlack, ReceiverIds: "2", MinInterval: 600},
+	}}
+	lastNotificationTimeFunc = func(taskId int, ruleId int) (time.Time, error) {
+		if ruleId == 1 {
+			return time.Now().Add(-time.Minute), nil
+		}
+		return time.Time{}, nil
+	}
+	captured = stubNotifyPush(t)
+	SendNotification(sameChannel, failed, 13)
+	if len(*captured) != 1 || (*captured)[0]["rule_id"] != 2 {
+		t.Fatalf("expected only rule 2 to notify, got %+v", *captured)
+	}
+}
+
+func TestSendNotificationTemplateVars(t *testing.T) {
+	setupServiceTestDB(t)
+	if err := new(models.Setting).UpdateSiteUrl("https://cron.example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().Add(-time.Minute)
+	createSlaTaskLog(t, 7, 3, models.Running, start, start)
+	captured := stubNotifyPush(t)
+	notifyLogFunc = loadNotifyLog
+
+	task := models.Task{Id: 3, Name: "backup", Tag: "db,prod", Hosts: []models.TaskHostDetail{{Name: "10.0.0.1", Port: 5921}},
+		Notifications: []models.TaskNotification{
+			{Trigger: models.NotifyOnFailure, Channel: models.NotifyChannelSlack, ReceiverIds: "1", Template: "{{.ExitCode}}"},
+			{Trigger: models.NotifyOnFailure, Channel: models.NotifyChannelWebhook},
+		}}
+	SendNotification(task, TaskResult{Result: "Host: [10.0.0.1:5921]\nexit status 2", Err: errors.New("boom"), RetryTimes: 1}, 7)
+	if len(*captured) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(*captured))
+	}
+	msg := (*captured)[0]
+	expected := map[string]interface{}{
+		"template":    "{{.ExitCode}}",
+		"exit_code":   2,
+		"retry_times": int8(1),
+		"log_id":      int64(7),
+		"hosts":       "10.0.0.1:5921",
+		"tags":        "db,prod",
+		"log_url":     "https://cron.example.com/#/task/log?task_id=3&log_id=7",
+	}
+	for key, value := range expected {
+		if msg[key] != value {
+			t.Errorf("expected %s=%v, got %v", key, value, msg[key])
+		}
+	}
+	if msg["start_time"].(time.Time).Unix() != start.Unix() {
+		t.Errorf("unexpected start time %v", msg["start_time"])
+	}
+	if (*captured)[1]["template"] != "" {
+		t.Errorf("rule without template should use the channel template, got %v", (*captured)[1]["template"])
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		result TaskResult
+		want   int
+	}{
+		{TaskResult{Result: "ok"}, 0},
+		{TaskResult{Result: "exit status 1\nexit status 127", Err: errors.New("failed")}, 127},
+		{TaskResult{Result: "", Err: errors.New("exit status 3")}, 3},
+		{TaskResult{Result: "HTTP 500", Err: errors.New("bad status")}, -1},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.result); got != tt.want {
+			t.Errorf("exitCode(%q) = %d, want %d", tt.result.Result, got, tt.want)
+		}
+	}
+}
+
 func stubNotifyPush(t *testing.T) *[]notify.Message {
 	t.Helper()
 	var captured []notify.Message
-	original := notifyPushFunc
+	original, originalLog := notifyPushFunc, notifyLogFunc
+	// 没有数据库的测试不查询执行日志，需要日志信息的测试在调用后恢复 notifyLogFunc
+	notifyLogFunc = func(taskLogId int64) (models.TaskLog, string) { return models.TaskLog{}, "" }
 	notifyPushFunc = func(msg notify.Message) {
 		msgCopy := notify.Message{}
 		for k, v := range msg {
@@ -509,7 +852,7 @@ func stubNotifyPush(t *testing.T) *[]notify.Message {
 		}
 		captured = append(captured, msgCopy)
 	}
-	t.Cleanup(func() { notifyPushFunc = original })
+	t.Cleanup(func() { notifyPushFunc, notifyLogFunc = original, originalLog })
 	return &captured
 }
 
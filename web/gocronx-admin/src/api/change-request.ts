@@ -0,0 +1,119 @@
+import request from '@/utils/http'
+
+// ── Types ─────────────────────────────────────────────────────────────────────
+
+export type ChangeRequestType = 'update' | 'rollback' | 'run' | 'enable' | 'disable' | 'remove'
+export type ChangeRequestStatus = 'pending' | 'approved' | 'rejected' | 'cancelled'
+
+/** Returned by task store / run / rollback when the task is protected */
+export interface ChangeRequestSubmitResult {
+  change_request_id: number
+}
+
+export interface ChangeRequestListParams {
+  page: number
+  page_size: number
+  status?: ChangeRequestStatus | ''
+  task_id?: number
+}
+
+export interface ChangeRequestItem {
+  id: number
+  task_id: number
+  task_name: string
+  type: ChangeRequestType
+  status: ChangeRequestStatus
+  remark: string
+  author: string
+  reviewer: string
+  comment: string
+  created_at: string
+  reviewed_at: string | null
+}
+
+export interface TaskFieldChange {
+  field: string
+  old: string
+  new: string
+}
+
+export interface ChangeRequestDetail {
+  request: ChangeRequestItem
+  changes: TaskFieldChange[]
+}
+
+export interface ApprovalSetting {
+  /** Tasks carrying any of these tags need approval for edits and manual runs */
+  protected_tags: string[]
+}
+
+// ── API functions ─────────────────────────────────────────────────────────────
+
+/**
+ * GET /api/task/change-requests
+ */
+export function fetchChangeRequestList(params: ChangeRequestListParams) {
+  return request.get<{ total: number; data: ChangeRequestItem[] }>({
+    url: '/api/task/change-requests',
+    params
+  })
+}
+
+/**
+ * GET /api/task/change-requests/:id  →  request with field-level changes
+ */
+export function fetchChangeRequestDetail(id: number) {
+  return request.get<ChangeRequestDetail>({
+    url: `/api/task/change-requests/${id}`
+  })
+}
+
+function postReview(id: number, action: 'approve' | 'reject' | 'cancel', comment = '') {
+  const form = new URLSearchParams()
+  form.append('comment', comment)
+  return request.post<null>({
+    url: `/api/task/change-requests/${id}/${action}`,
+    data: form,
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  })
+}
+
+/**
+ * POST /api/task/change-requests/:id/approve
+ */
+export function fetchChangeRequestApprove(id: number, comment = '') {
+  return postReview(id, 'approve', comment)
+}
+
+/**
+ * POST /api/task/change-requests/:id/reject
+ */
+export function fetchChangeRequestReject(id: number, comment = '') {
+  return postReview(id, 'reject', comment)
+}
+
+/**
+ * POST /api/task/change-requests/:id/cancel
+ */
+export function fetchChangeRequestCancel(id: number) {
+  return postReview(id, 'cancel')
+}
+
+/**
+ * GET /api/system/approval
+ */
+export function fetchApprovalSetting() {
+  return request.get<ApprovalSetting>({
+    url: '/api/system/approval'
+  })
+}
+
+/**
+ * POST /api/system/approval/update
+ */
+export function updateApprovalSetting(data: ApprovalSetting) {
+  return request.post<null>({
+    url: '/api/system/approval/update',
+    data
+  })
+}
@@ -0,0 +1,83 @@
+import request from '@/utils/http'
+
+// ── Types ─────────────────────────────────────────────────────────────────────
+
+export type DataSourceDriver = 'mysql' | 'postgres' | 'sqlite'
+
+/** Password is stored encrypted and never returned */
+export interface DataSourceItem {
+  id: number
+  name: string
+  driver: DataSourceDriver
+  host: string
+  port: number
+  user: string
+  database: string
+  params: string
+  remark: string
+  created: string
+  updated: string
+}
+
+/** Leave password empty when editing to keep the saved one */
+export interface DataSourceStoreParams extends Omit<DataSourceItem, 'created' | 'updated'> {
+  password: string
+}
+
+// ── API functions ─────────────────────────────────────────────────────────────
+
+/**
+ * GET /api/system/data-source  →  DataSourceItem[]
+ */
+export function fetchDataSourceList() {
+  return request.get<DataSourceItem[]>({
+    url: '/api/system/data-source'
+  })
+}
+
+function toForm(params: DataSourceStoreParams) {
+  const form = new URLSearchParams()
+  if (params.id) form.append('id', String(params.id))
+  form.append('name', params.name)
+  form.append('driver', params.driver)
+  form.append('host', params.host)
+  form.append('port', String(params.port || 0))
+  form.append('user', params.user)
+  form.append('password', params.password)
+  form.append('database', params.database)
+  form.append('params', params.params)
+  form.append('remark', params.remark)
+  return form
+}
+
+/**
+ * POST /api/system/data-source/store  (create or update)
+ */
+export function saveDataSource(params: DataSourceStoreParams) {
+  return request.post<null>({
+    url: '/api/system/data-source/store',
+    data: toForm(params),
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  })
+}
+
+/**
+ * POST /api/system/data-source/ping  — runs SELECT 1 with the form values
+ */
+export function pingDataSource(params: DataSourceStoreParams) {
+  return request.post<null>({
+    url: '/api/system/data-source/ping',
+    data: toForm(params),
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+    showSuccessMessage: true
+  })
+}
+
+/**
+ * POST /api/system/data-source/remove/:id
+ */
+export function removeDataSource(id: number) {
+  return request.post<null>({
+    url: `/api/system/data-source/remove/${id}`
+  })
+}
@@ -0,0 +1,57 @@
+import request from '@/utils/http'
+
+// ── Types ─────────────────────────────────────────────────────────────────────
+
+/** File content is never returned; services lists the services defined in the set */
+export interface GrpcDescriptorItem {
+  id: number
+  name: string
+  services: string
+  size: number
+  remark: string
+  created: string
+  updated: string
+}
+
+/** Leave file empty when editing to keep the uploaded descriptor set */
+export interface GrpcDescriptorStoreParams {
+  id?: number
+  name: string
+  remark: string
+  file?: File | null
+}
+
+// ── API functions ─────────────────────────────────────────────────────────────
+
+/**
+ * GET /api/system/grpc-descriptor  →  GrpcDescriptorItem[]
+ */
+export function fetchGrpcDescriptorList() {
+  return request.get<GrpcDescriptorItem[]>({
+    url: '/api/system/grpc-descriptor'
+  })
+}
+
+/**
+ * POST /api/system/grpc-descriptor/store  (multipart: id, name, remark, file)
+ */
+export function saveGrpcDescriptor(params: GrpcDescriptorStoreParams) {
+  const form = new FormData()
+  if (params.id) form.append('id', String(params.id))
+  form.append('name', params.name)
+  form.append('remark', params.remark)
+  if (params.file) form.append('file', params.file)
+  return request.post<null>({
+    url: '/api/system/grpc-descriptor/store',
+    data: form
+  })
+}
+
+/**
+ * POST /api/system/grpc-descriptor/remove/:id
+ */
+export function removeGrpcDescriptor(id: number) {
+  return request.post<null>({
+    url: `/api/system/grpc-descriptor/remove/${id}`
+  })
+}
@@ -16,6 +16,16 @@ export interface HostItem {
   port: number
   remark?: string
   created: string
+  /** Expiry of the node certificate issued by the internal CA, null if none */
+  cert_not_after?: string | null
+  /** SSH settings used by SSH tasks; 0 means port 22 */
+  ssh_port?: number
+  ssh_user?: string
+  /** Names of secrets holding the SSH password / private key */
+  ssh_password_secret?: string
+  ssh_key_secret?: string
+  /** Pinned host key (authorized_keys format), saved on the first connection */
+  ssh_host_key?: string
 }
 
 export interface HostStoreParams {
@@ -24,6 +34,11 @@ export interface HostStoreParams {
   alias?: string
   port: number
   remark?: string
+  ssh_port?: number
+  ssh_user?: string
+  ssh_password_secret?: string
+  ssh_key_secret?: string
+  ssh_host_key?: string
 }
 
 export interface AgentTokenResult {
@@ -64,6 +79,11 @@ export function saveHost(params: HostStoreParams) {
   if (params.alias !== undefined) form.append('alias', params.alias)
   form.append('port', String(params.port))
   if (params.remark !== undefined) form.append('remark', params.remark)
+  form.append('ssh_port', String(params.ssh_port ?? 0))
+  form.append('ssh_user', params.ssh_user ?? '')
+  form.append('ssh_password_secret', params.ssh_password_secret ?? '')
+  form.append('ssh_key_secret', params.ssh_key_secret ?? '')
+  form.append('ssh_host_key', params.ssh_host_key ?? '')
 
   return request.post<null>({
     url: '/api/host/store',
@@ -81,6 +101,15 @@ export function pingHost(id: number) {
   })
 }
 
+/**
+ * GET /api/host/ping-ssh/:id  — test the SSH settings, pins the host key on first success
+ */
+export function pingHostSsh(id: number) {
+  return request.get<any>({
+    url: `/api/host/ping-ssh/${id}`
+  })
+}
+
 /**
  * POST /api/host/remove/:id
  */
@@ -90,6 +119,15 @@ export function removeHost(id: number) {
   })
 }
 
+/**
+ * POST /api/host/cert/rotate/:id  — issue and push a new node certificate
+ */
+export function rotateHostCert(id: number) {
+  return request.post<any>({
+    url: `/api/host/cert/rotate/${id}`
+  })
+}
+
 /**
  * POST /api/agent/generate-token  →  { token, expires_at, install_cmd }
  */
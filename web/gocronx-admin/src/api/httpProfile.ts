@@ -0,0 +1,64 @@
+import request from '@/utils/http'
+
+// ── Types ─────────────────────────────────────────────────────────────────────
+
+/** Client key and proxy password are referenced by secret name */
+export interface HttpProfileItem {
+  id: number
+  name: string
+  ca_cert: string
+  client_cert: string
+  client_key_secret: string
+  insecure_skip_verify: boolean
+  proxy_url: string
+  proxy_username: string
+  proxy_password_secret: string
+  remark: string
+  created: string
+  updated: string
+}
+
+export type HttpProfileStoreParams = Omit<HttpProfileItem, 'created' | 'updated'>
+
+// ── API functions ─────────────────────────────────────────────────────────────
+
+/**
+ * GET /api/system/http-profile  →  HttpProfileItem[]
+ */
+export function fetchHttpProfileList() {
+  return request.get<HttpProfileItem[]>({
+    url: '/api/system/http-profile'
+  })
+}
+
+/**
+ * POST /api/system/http-profile/store  (create or update)
+ */
+export function saveHttpProfile(params: HttpProfileStoreParams) {
+  const form = new URLSearchParams()
+  if (params.id) form.append('id', String(params.id))
+  form.append('name', params.name)
+  form.append('ca_cert', params.ca_cert)
+  form.append('client_cert', params.client_cert)
+  form.append('client_key_secret', params.client_key_secret)
+  form.append('insecure_skip_verify', String(params.insecure_skip_verify))
+  form.append('proxy_url', params.proxy_url)
+  form.append('proxy_username', params.proxy_username)
+  form.append('proxy_password_secret', params.proxy_password_secret)
+  form.append('remark', params.remark)
+
+  return request.post<null>({
+    url: '/api/system/http-profile/store',
+    data: form,
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  })
+}
+
+/**
+ * POST /api/system/http-profile/remove/:id
+ */
+export function removeHttpProfile(id: number) {
+  return request.post<null>({
+    url: `/api/system/http-profile/remove/${id}`
+  })
+}
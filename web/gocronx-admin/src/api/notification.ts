@@ -13,10 +13,36 @@ export interface SlackChannel {
   name: string
 }
 
+export type WebhookAuthType = '' | 'bearer' | 'basic'
+
+// Token, password and signing secret are never returned; *_set tells whether one is stored
 export interface WebhookUrl {
   id: number
   name: string
   url: string
+  method: string
+  headers: Record<string, string> | null
+  auth_type: WebhookAuthType
+  username: string
+  timeout: number
+  token_set: boolean
+  password_set: boolean
+  secret_set: boolean
+}
+
+// On update, empty token / password / secret keep the stored values
+export interface WebhookUrlParams {
+  name: string
+  url: string
+  method: string
+  headers: string
+  auth_type: WebhookAuthType
+  token: string
+  username: string
+  password: string
+  timeout: number
+  secret: string
+  remove_secret?: boolean
 }
 
 export interface MailConfig {
@@ -172,17 +198,40 @@ export function updateWebhook(params: { template: string }) {
   })
 }
 
-/**
- * POST /api/system/webhook/url  — add a webhook URL
- */
-export function createWebhookUrl(params: { name: string; url: string }) {
+function webhookUrlForm(params: WebhookUrlParams) {
   const form = new URLSearchParams()
   form.append('name', params.name)
   form.append('url', params.url)
+  form.append('method', params.method)
+  form.append('headers', params.headers)
+  form.append('auth_type', params.auth_type)
+  form.append('token', params.token)
+  form.append('username', params.username)
+  form.append('password', params.password)
+  form.append('timeout', String(params.timeout))
+  form.append('secret', params.secret)
+  form.append('remove_secret', String(!!params.remove_secret))
+  return form
+}
 
+/**
+ * POST /api/system/webhook/url  — add a webhook URL
+ */
+export function createWebhookUrl(params: WebhookUrlParams) {
   return request.post<null>({
     url: '/api/system/webhook/url',
-    data: form,
+    data: webhookUrlForm(params),
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  })
+}
+
+/**
+ * POST /api/system/webhook/url/update/:id  — update method, headers, auth and signing secret
+ */
+export function updateWebhookUrl(id: number, params: WebhookUrlParams) {
+  return request.post<null>({
+    url: `/api/system/webhook/url/update/${id}`,
+    data: webhookUrlForm(params),
     headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
   })
 }
@@ -195,3 +244,353 @@ export function removeWebhookUrl(id: number) {
     url: `/api/system/webhook/url/remove/${id}`
   })
 }
+
+// ── Group bots (DingTalk / WeCom / Feishu) ────────────────────────────────────
+
+export type IMCode = 'dingtalk' | 'wecom' | 'feishu'
+
+export interface IMBot {
+  id: number
+  name: string
+  webhook: string
+  // the signing secret is never returned, only whether one is configured
+  secret_set: boolean
+  at_mobiles: string
+  at_user_ids: string
+  at_all: boolean
+}
+
+export interface IMConfig {
+  template: string
+  bots: IMBot[]
+}
+
+export interface IMBotParams {
+  name: string
+  webhook: string
+  secret: string
+  at_mobiles: string
+  at_user_ids: string
+  at_all: boolean
+}
+
+/**
+ * GET /api/system/:code  →  bot list and message template
+ */
+export function fetchIM(code: IMCode) {
+  return request.get<IMConfig>({
+    url: `/api/system/${code}`
+  })
+}
+
+/**
+ * POST /api/system/:code/update  — update the bot message template
+ */
+export function updateIM(code: IMCode, params: { template: string }) {
+  const form = new URLSearchParams()
+  form.append('template', params.template)
+
+  return request.post<null>({
+    url: `/api/system/${code}/update`,
+    data: form,
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  })
+}
+
+/**
+ * POST /api/system/:code/bot  — add a group bot
+ */
+export function createIMBot(code: IMCode, params: IMBotParams) {
+  const form = new URLSearchParams()
+  form.append('name', params.name)
+  form.append('webhook', params.webhook)
+  form.append('secret', params.secret)
+  form.append('at_mobiles', params.at_mobiles)
+  form.append('at_user_ids', params.at_user_ids)
+  form.append('at_all', String(params.at_all))
+
+  return request.post<null>({
+    url: `/api/system/${code}/bot`,
+    data: form,
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  })
+}
+
+/**
+ * POST /api/system/:code/bot/remove/:id
+ */
+export function removeIMBot(code: IMCode, id: number) {
+  return request.post<null>({
+    url: `/api/system/${code}/bot/remove/${id}`
+  })
+}
+
+// ── Chat apps (Telegram / Discord / Teams) ────────────────────────────────────
+
+export type ChatCode = 'telegram' | 'discord' | 'teams'
+
+// target is the chat id for Telegram and the incoming webhook URL for Discord / Teams
+export interface ChatReceiver {
+  id: number
+  name: string
+  target: string
+}
+
+export interface ChatConfig {
+  // the Telegram bot token is never returned, only whether one is configured
+  token_set: boolean
+  receivers: ChatReceiver[]
+}
+
+/**
+ * GET /api/system/:code  →  receivers and token status
+ */
+export function fetchChat(code: ChatCode) {
+  return request.get<ChatConfig>({
+    url: `/api/system/${code}`
+  })
+}
+
+/**
+ * POST /api/system/telegram/update  — update the Telegram bot token
+ */
+export function updateChatToken(code: ChatCode, token: string) {
+  const form = new URLSearchParams()
+  form.append('token', token)
+
+  return request.post<null>({
+    url: `/api/system/${code}/update`,
+    data: form,
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  })
+}
+
+/**
+ * POST /api/system/:code/receiver  — add a chat or webhook receiver
+ */
+export function createChatReceiver(code: ChatCode, params: { name: string; target: string }) {
+  const form = new URLSearchParams()
+  form.append('name', params.name)
+  form.append('target', params.target)
+
+  return request.post<null>({
+    url: `/api/system/${code}/receiver`,
+    data: form,
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  })
+}
+
+/**
+ * POST /api/system/:code/receiver/remove/:id
+ */
+export function removeChatReceiver(code: ChatCode, id: number) {
+  return request.post<null>({
+    url: `/api/system/${code}/receiver/remove/${id}`
+  })
+}
+
+// ── Incident platforms (PagerDuty / Opsgenie) ─────────────────────────────────
+
+export type IncidentCode = 'pagerduty' | 'opsgenie'
+
+// the integration key is never returned
+export interface IncidentService {
+  id: number
+  name: string
+  api_url: string
+}
+
+export type IncidentStatus = 'open' | 'resolved'
+
+export interface TaskIncident {
+  id: number
+  task_id: number
+  task_name: string
+  channel: number
+  service_id: number
+  dedup_key: string
+  status: IncidentStatus
+  trigger_count: number
+  opened_at: string
+  resolved_at: string | null
+  updated_at: string
+}
+
+/**
+ * GET /api/system/:code  →  { services }
+ */
+export function fetchIncidentServices(code: IncidentCode) {
+  return request.get<{ services: IncidentService[] }>({
+    url: `/api/system/${code}`
+  })
+}
+
+/**
+ * POST /api/system/:code/service  — add an incident service
+ */
+export function createIncidentService(
+  code: IncidentCode,
+  params: { name: string; key: string; api_url: string }
+) {
+  const form = new URLSearchParams()
+  form.append('name', params.name)
+  form.append('key', params.key)
+  form.append('api_url', params.api_url)
+
+  return request.post<null>({
+    url: `/api/system/${code}/service`,
+    data: form,
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  })
+}
+
+/**
+ * POST /api/system/:code/service/remove/:id
+ */
+export function removeIncidentService(code: IncidentCode, id: number) {
+  return request.post<null>({
+    url: `/api/system/${code}/service/remove/${id}`
+  })
+}
+
+/**
+ * GET /api/system/notification/incidents  →  { total, data }
+ */
+export function fetchIncidents(params: {
+  page: number
+  page_size: number
+  status?: IncidentStatus | ''
+  channel?: number
+  task_id?: number
+}) {
+  return request.get<{ total: number; data: TaskIncident[] }>({
+    url: '/api/system/notification/incidents',
+    params
+  })
+}
+
+// ── Delivery history ──────────────────────────────────────────────────────────
+
+export type NotificationDeliveryStatus = 'pending' | 'sending' | 'succeeded' | 'failed'
+
+export interface NotificationDelivery {
+  id: number
+  task_id: number
+  task_name: string
+  // 0 email / 1 slack / 2 webhook / 3 dingtalk / 4 wecom / 5 feishu
+  // 6 telegram / 7 discord / 8 teams / 9 pagerduty / 10 opsgenie
+  channel: number
+  target: string
+  content?: string
+  status: NotificationDeliveryStatus
+  attempts: number
+  max_attempts: number
+  next_attempt_at: string
+  last_error: string
+  created_at: string
+  updated_at: string
+  delivered_at: string | null
+}
+
+export interface NotificationAttempt {
+  id: number
+  attempt: number
+  success: boolean
+  status_code: number
+  error: string
+  // first 1024 characters of the response body
+  response: string
+  latency_ms: number
+  created_at: string
+}
+
+export interface NotificationDeliveryListParams {
+  page: number
+  page_size: number
+  status?: string
+  channel?: number | string
+  task_id?: number | string
+}
+
+/**
+ * GET /api/system/notification/deliveries  →  { total, data }
+ */
+export function fetchNotificationDeliveries(params: NotificationDeliveryListParams) {
+  return request.get<{ total: number; data: NotificationDelivery[] }>({
+    url: '/api/system/notification/deliveries',
+    params
+  })
+}
+
+/**
+ * GET /api/system/notification/deliveries/:id  →  delivery with every attempt
+ */
+export function fetchNotificationDelivery(id: number) {
+  return request.get<{ delivery: NotificationDelivery; attempts: NotificationAttempt[] }>({
+    url: `/api/system/notification/deliveries/${id}`
+  })
+}
+
+/**
+ * POST /api/system/notification/deliveries/:id/resend
+ */
+export function resendNotificationDelivery(id: number) {
+  return request.post<null>({
+    url: `/api/system/notification/deliveries/${id}/resend`
+  })
+}
+
+// ── Site URL / preview / test ─────────────────────────────────────────────────
+
+/**
+ * GET /api/system/notification/site-url  →  external address used for log links
+ */
+export function fetchSiteUrl() {
+  return request.get<{ site_url: string }>({
+    url: '/api/system/notification/site-url'
+  })
+}
+
+/**
+ * POST /api/system/notification/site-url
+ */
+export function updateSiteUrl(siteUrl: string) {
+  return request.post<null>({
+    url: '/api/system/notification/site-url',
+    data: { site_url: siteUrl }
+  })
+}
+
+// channel: same values as TaskNotificationRule.channel; an empty template uses the channel template
+export interface NotificationTestParams {
+  channel: number
+  receiver_ids: string
+  template: string
+  failed: boolean
+}
+
+export interface NotificationTestResult {
+  target: string
+  status_code: number
+  error: string
+}
+
+/**
+ * POST /api/system/notification/preview  →  content rendered from a sample message
+ */
+export function previewNotification(params: NotificationTestParams) {
+  return request.post<{ content: string; targets: string[] }>({
+    url: '/api/system/notification/preview',
+    data: params
+  })
+}
+
+/**
+ * POST /api/system/notification/test  — deliver a sample message right away, bypassing the queue
+ */
+export function testNotification(params: NotificationTestParams) {
+  return request.post<NotificationTestResult[]>({
+    url: '/api/system/notification/test',
+    data: params
+  })
+}
@@ -0,0 +1,57 @@
+import request from '@/utils/http'
+
+// ── Types ─────────────────────────────────────────────────────────────────────
+
+/** Secret values are never returned by the API */
+export interface SecretItem {
+  id: number
+  name: string
+  remark: string
+  created: string
+  updated: string
+}
+
+export interface SecretStoreParams {
+  id?: number
+  name: string
+  /** Empty keeps the current value when updating */
+  value: string
+  remark: string
+}
+
+// ── API functions ─────────────────────────────────────────────────────────────
+
+/**
+ * GET /api/system/secret  →  SecretItem[]
+ */
+export function fetchSecretList() {
+  return request.get<SecretItem[]>({
+    url: '/api/system/secret'
+  })
+}
+
+/**
+ * POST /api/system/secret/store  (create or update)
+ */
+export function saveSecret(params: SecretStoreParams) {
+  const form = new URLSearchParams()
+  if (params.id) form.append('id', String(params.id))
+  form.append('name', params.name)
+  form.append('value', params.value)
+  form.append('remark', params.remark)
+
+  return request.post<null>({
+    url: '/api/system/secret/store',
+    data: form,
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
+  })
+}
+
+/**
+ * POST /api/system/secret/remove/:id
+ */
+export function removeSecret(id: number) {
+  return request.post<null>({
+    url: `/api/system/secret/remove/${id}`
+  })
+}
@@ -6,6 +6,7 @@ export interface TaskLogListParams {
   page: number
   page_size: number
   task_id?: number | string
+  log_id?: number | string
   protocol?: number | string
   status?: number | string
   host_id?: number | string
@@ -37,6 +38,48 @@ export interface TaskLogListItem {
   total_time: number
   retry_times: number
   spec: string
+  /** HTTP exchange details (JSON array of HttpExchange), credentials masked */
+  http_exchange?: string
+}
+
+/** Timing breakdown of an HTTP request in milliseconds */
+export interface HttpExchangeTiming {
+  dns_ms: number
+  connect_ms: number
+  tls_ms: number
+  ttfb_ms: number
+  total_ms: number
+}
+
+/** One HTTP request recorded for a task run: the trigger request or the latest status poll */
+export interface HttpExchange {
+  stage: 'request' | 'poll'
+  method: string
+  url: string
+  request_headers?: Record<string, string>
+  request_body_size: number
+  proto?: string
+  status_code: number
+  response_headers?: Record<string, string>
+  body_size: number
+  body_limit: number
+  body_truncated?: boolean
+  remote_addr?: string
+  conn_reused?: boolean
+  tls_version?: string
+  error?: string
+  started_at: string
+  timing: HttpExchangeTiming
+}
+
+export function parseHttpExchanges(raw?: string): HttpExchange[] {
+  if (!raw) return []
+  try {
+    const list = JSON.parse(raw)
+    return Array.isArray(list) ? list : []
+  } catch {
+    return []
+  }
 }
 
 // ── API functions ─────────────────────────────────────────────────────────────
@@ -1,4 +1,5 @@
 import request from '@/utils/http'
+import type { ChangeRequestSubmitResult } from './change-request'
 
 // ── Types ─────────────────────────────────────────────────────────────────────
 
@@ -19,6 +20,23 @@ export interface TaskHostRef {
   port: number
 }
 
+// channel: 0 email / 1 slack / 2 webhook / 3 dingtalk / 4 wecom / 5 feishu /
+//          6 telegram / 7 discord / 8 teams / 9 pagerduty / 10 opsgenie
+// trigger: 1 failure / 2 always / 3 keyword / 4 first failure / 5 recovery / 6 state change
+export interface TaskNotificationRule {
+  id?: number
+  channel: number
+  receiver_ids: string
+  trigger: number
+  keyword: string
+  // re-notify every N consecutive failures (triggers 4 and 6), 0 = first failure only
+  repeat_every?: number
+  // minimum seconds between notifications on this channel, recovery is never throttled
+  min_interval?: number
+  // overrides the channel template (email / slack / webhook / group bots), empty = channel template
+  template?: string
+}
+
 export interface TaskListItem {
   id: number
   name: string
@@ -26,8 +44,29 @@ export interface TaskListItem {
   protocol: number
   http_method: number
   http_body?: string
+  http_body_type?: string
+  http_content_type?: string
+  http_success_codes?: string
+  http_assertions?: string
+  http_max_response_time?: number
+  http_max_body_size?: number
+  http_auth_type?: string
+  http_auth?: string
+  http_profile_id?: number
+  http_async_mode?: string
+  http_async?: string
   http_headers?: string
   success_pattern?: string
+  sql_data_source_id?: number
+  sql_transaction?: boolean
+  sql_max_rows?: number
+  sql_assertions?: string
+  grpc_method?: string
+  grpc_request?: string
+  grpc_metadata?: string
+  grpc_tls?: boolean
+  grpc_server_name?: string
+  grpc_descriptor_id?: number
   command: string
   timeout: number
   multi: number
@@ -39,11 +78,12 @@ export interface TaskListItem {
   level: number
   dependency_status?: number
   dependency_task_id?: string
-  notify_status?: number
-  notify_type?: number
-  notify_keyword?: string
-  notify_receiver_id?: string
+  notifications?: TaskNotificationRule[]
   log_retention_days?: number
+  sla_max_duration?: number
+  sla_success_interval?: number
+  heartbeat_token?: string
+  heartbeat_grace?: number
   next_run_time: string
   created: string
   hosts: TaskHostRef[]
@@ -64,16 +104,45 @@ export interface TaskStoreParams {
   host_id?: string | number | number[]
   http_method?: number
   http_body?: string
+  http_body_type?: string
+  http_content_type?: string
+  http_success_codes?: string
+  http_assertions?: string
+  http_max_response_time?: number
+  http_max_body_size?: number
+  http_auth_type?: string
+  http_auth?: string
+  http_profile_id?: number
+  http_async_mode?: string
+  http_async?: string
   http_headers?: string
   success_pattern?: string
+  sql_data_source_id?: number
+  sql_transaction?: boolean
+  sql_max_rows?: number
+  sql_assertions?: string
+  grpc_method?: string
+  grpc_request?: string
+  grpc_metadata?: string
+  grpc_tls?: boolean
+  grpc_server_name?: string
+  grpc_descriptor_id?: number
   level?: number
   dependency_status?: number
   dependency_task_id?: string
-  notify_status?: number
-  notify_type?: number
-  notify_keyword?: string
-  notify_receiver_id?: string
+  // JSON-encoded TaskNotificationRule[]
+  notifications?: string
   log_retention_days?: number
+  sla_max_duration?: number
+  sla_success_interval?: number
+  heartbeat_grace?: number
+}
+
+/** Protected tasks are not changed directly, a change request is created for each of them */
+export interface TaskBatchResult {
+  success_count: number
+  total_count: number
+  change_request_ids?: number[]
 }
 
 // ── API functions ─────────────────────────────────────────────────────────────
@@ -111,7 +180,7 @@ export function fetchTaskStore(data: TaskStoreParams) {
       }
     }
   })
-  return request.post<null>({
+  return request.post<ChangeRequestSubmitResult | null>({
     url: '/api/task/store',
     data: form,
     headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
@@ -122,7 +191,7 @@ export function fetchTaskStore(data: TaskStoreParams) {
  * POST /api/task/remove/:id
  */
 export function fetchTaskRemove(id: number) {
-  return request.post<null>({
+  return request.post<ChangeRequestSubmitResult | null>({
     url: `/api/task/remove/${id}`
   })
 }
@@ -131,7 +200,7 @@ export function fetchTaskRemove(id: number) {
  * POST /api/task/enable/:id
  */
 export function fetchTaskEnable(id: number) {
-  return request.post<null>({
+  return request.post<ChangeRequestSubmitResult | null>({
     url: `/api/task/enable/${id}`
   })
 }
@@ -140,7 +209,7 @@ export function fetchTaskEnable(id: number) {
  * POST /api/task/disable/:id
  */
 export function fetchTaskDisable(id: number) {
-  return request.post<null>({
+  return request.post<ChangeRequestSubmitResult | null>({
     url: `/api/task/disable/${id}`
   })
 }
@@ -149,12 +218,21 @@ export function fetchTaskDisable(id: number) {
  * GET /api/task/run/:id  — trigger immediate run
  */
 export function fetchTaskRunOnce(id: number) {
-  return request.get<null>({
+  return request.get<ChangeRequestSubmitResult | null>({
     url: `/api/task/run/${id}`,
     params: { _t: Date.now() }
   })
 }
 
+/**
+ * POST /api/task/heartbeat-token/:id  — issue a new ping token, the old URLs stop working
+ */
+export function fetchResetHeartbeatToken(id: number) {
+  return request.post<{ heartbeat_token: string }>({
+    url: `/api/task/heartbeat-token/${id}`
+  })
+}
+
 /**
  * GET /api/task/tags  →  string[]
  */
@@ -192,30 +270,30 @@ export function fetchCronPreview(params: { spec: string; timezone?: string; coun
 }
 
 /**
- * POST /api/task/batch-enable  →  null
+ * POST /api/task/batch-enable  →  TaskBatchResult
  */
 export function fetchBatchEnable(ids: number[]) {
-  return request.post<null>({
+  return request.post<TaskBatchResult>({
     url: '/api/task/batch-enable',
     data: { ids }
   })
 }
 
 /**
- * POST /api/task/batch-disable  →  null
+ * POST /api/task/batch-disable  →  TaskBatchResult
  */
 export function fetchBatchDisable(ids: number[]) {
-  return request.post<null>({
+  return request.post<TaskBatchResult>({
     url: '/api/task/batch-disable',
     data: { ids }
   })
 }
 
 /**
- * POST /api/task/batch-remove  →  null
+ * POST /api/task/batch-remove  →  TaskBatchResult
  */
 export function fetchBatchRemove(ids: number[]) {
-  return request.post<null>({
+  return request.post<TaskBatchResult>({
     url: '/api/task/batch-remove',
     data: { ids }
   })
@@ -0,0 +1,61 @@
+import request from '@/utils/http'
+
+// ── Types ─────────────────────────────────────────────────────────────────────
+
+/** File content is never returned; nodes download it through a signed URL */
+export interface TaskArtifactItem {
+  id: number
+  task_id: number
+  name: string
+  version: number
+  sha256: string
+  size: number
+  username: string
+  created: string
+}
+
+// ── API functions ─────────────────────────────────────────────────────────────
+
+/**
+ * GET /api/task/artifact/:id  →  TaskArtifactItem[] (every kept version, newest first per name)
+ */
+export function fetchTaskArtifacts(taskId: number) {
+  return request.get<TaskArtifactItem[]>({
+    url: `/api/task/artifact/${taskId}`
+  })
+}
+
+/**
+ * POST /api/task/artifact/upload/:id  (multipart: file, name)
+ * Uploading an existing name adds a new version.
+ */
+export function uploadTaskArtifact(taskId: number, file: File, name?: string) {
+  const form = new FormData()
+  form.append('file', file)
+  if (name) form.append('name', name)
+  return request.post<TaskArtifactItem>({
+    url: `/api/task/artifact/upload/${taskId}`,
+    data: form
+  })
+}
+
+/**
+ * POST /api/task/artifact/restore/:id/:artifact_id  — copy an old version as the newest one
+ */
+export function restoreTaskArtifact(taskId: number, artifactId: number) {
+  return request.post<TaskArtifactItem>({
+    url: `/api/task/artifact/restore/${taskId}/${artifactId}`
+  })
+}
+
+/**
+ * POST /api/task/artifact/remove/:id  (form: name) — remove every version of the file
+ */
+export function removeTaskArtifact(taskId: number, name: string) {
+  const form = new URLSearchParams()
+  form.append('name', name)
+  return request.post<null>({
+    url: `/api/task/artifact/remove/${taskId}`,
+    data: form
+  })
+}
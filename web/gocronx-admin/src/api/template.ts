@@ -8,6 +8,25 @@ export interface TemplateListParams {
   name?: string
 }
 
+export interface TemplateParameter {
+  name: string
+  type: 'string' | 'int' | 'bool' | 'enum'
+  default?: string
+  required?: boolean
+  pattern?: string
+  description?: string
+  options?: string[]
+}
+
+export interface TemplateApplyParams {
+  params?: Record<string, string | number | boolean>
+  /** Create the task directly instead of returning the rendered template */
+  create?: boolean
+  task_name?: string
+  host_id?: string
+  enable?: boolean
+}
+
 export interface TemplateListItem {
   id: number
   name: string
@@ -19,6 +38,10 @@ export interface TemplateListItem {
   tag?: string
   http_method?: number
   http_body?: string
+  http_body_type?: string
+  http_content_type?: string
+  http_body_type?: string
+  http_content_type?: string
   http_headers?: string
   success_pattern?: string
   timeout?: number
@@ -30,6 +53,7 @@ export interface TemplateListItem {
   notify_type?: number
   notify_keyword?: string
   log_retention_days?: number
+  parameters?: TemplateParameter[] | null
   is_builtin?: number
   created_at?: string
   updated_at?: string
@@ -58,6 +82,7 @@ export interface TemplateStoreParams {
   notify_type?: number
   notify_keyword?: string
   log_retention_days?: number
+  parameters?: TemplateParameter[]
 }
 
 // ── API functions ─────────────────────────────────────────────────────────────
@@ -97,6 +122,9 @@ export function fetchTemplateStore(params: TemplateStoreParams) {
   if (params.tag !== undefined) form.append('tag', params.tag)
   if (params.http_method !== undefined) form.append('http_method', String(params.http_method))
   if (params.http_body !== undefined) form.append('http_body', params.http_body)
+  if (params.http_body_type !== undefined) form.append('http_body_type', params.http_body_type)
+  if (params.http_content_type !== undefined)
+    form.append('http_content_type', params.http_content_type)
   if (params.http_headers !== undefined) form.append('http_headers', params.http_headers)
   if (params.success_pattern !== undefined) form.append('success_pattern', params.success_pattern)
   if (params.timeout !== undefined) form.append('timeout', String(params.timeout))
@@ -110,6 +138,7 @@ export function fetchTemplateStore(params: TemplateStoreParams) {
   if (params.notify_keyword !== undefined) form.append('notify_keyword', params.notify_keyword)
   if (params.log_retention_days !== undefined)
     form.append('log_retention_days', String(params.log_retention_days))
+  if (params.parameters !== undefined) form.append('parameters', JSON.stringify(params.parameters))
 
   return request.post<null>({
     url: '/api/template/store',
@@ -127,6 +156,17 @@ export function fetchTemplateRemove(id: number) {
   })
 }
 
+/**
+ * POST /api/template/apply/:id  →  rendered template, or { task_id, template } when create=true
+ * Parameter values are validated server-side against the template's declared parameters.
+ */
+export function fetchTemplateApply(id: number, params: TemplateApplyParams = {}) {
+  return request.post<any>({
+    url: `/api/template/apply/${id}`,
+    data: params
+  })
+}
+
 /**
  * POST /api/template/save-from-task
  * Clone the current task's scheduling/command fields into a new template.
@@ -149,3 +189,45 @@ export function fetchTemplateSaveFromTask(params: {
     headers: { 'Content-Type': 'application/x-www-form-urlencoded' }
   })
 }
+
+export type TemplateConflictMode = 'skip' | 'overwrite' | 'rename'
+
+export interface TemplateExportResult {
+  filename: string
+  format: 'json' | 'yaml'
+  count: number
+  content: string
+}
+
+export interface TemplateImportResult {
+  created: string[]
+  overwritten: string[]
+  renamed: { from: string; to: string }[]
+  skipped: string[]
+  failed: { name: string; error: string }[]
+}
+
+/**
+ * GET /api/template/export?ids=1,2&format=yaml
+ * Exports all templates when ids is empty. The bundle is returned as text in
+ * `content`; the caller turns it into a file download.
+ */
+export function fetchTemplateExport(ids: number[], format: 'json' | 'yaml' = 'json') {
+  return request.get<TemplateExportResult>({
+    url: '/api/template/export',
+    params: { ids: ids.join(','), format }
+  })
+}
+
+/**
+ * POST /api/template/import  (multipart: file, conflict)
+ */
+export function fetchTemplateImport(file: File, conflict: TemplateConflictMode = 'skip') {
+  const form = new FormData()
+  form.append('file', file)
+  form.append('conflict', conflict)
+  return request.post<TemplateImportResult>({
+    url: '/api/template/import',
+    data: form
+  })
+}
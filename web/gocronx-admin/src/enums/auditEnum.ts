@@ -20,7 +20,14 @@ export const AUDIT_ACTIONS = [
   { value: 'batch-disable', labelKey: 'audit.action_batch_disable' },
   { value: 'batch-remove', labelKey: 'audit.action_batch_remove' },
   { value: 'change-password', labelKey: 'audit.action_change_password' },
-  { value: 'reset-password', labelKey: 'audit.action_reset_password' }
+  { value: 'reset-password', labelKey: 'audit.action_reset_password' },
+  { value: 'import', labelKey: 'audit.action_import' },
+  { value: 'rollback', labelKey: 'audit.action_rollback' },
+  { value: 'approve', labelKey: 'audit.action_approve' },
+  { value: 'reject', labelKey: 'audit.action_reject' },
+  { value: 'cancel', labelKey: 'audit.action_cancel' },
+  { value: 'reset-token', labelKey: 'audit.action_reset_token' },
+  { value: 'test-notification', labelKey: 'audit.action_test_notification' }
 ] as const
 
 export const MODULE_TAG_TYPES: Record<
@@ -47,5 +54,12 @@ export const ACTION_TAG_TYPES: Record<
   'batch-disable': 'info',
   'batch-remove': 'danger',
   'change-password': 'warning',
-  'reset-password': 'warning'
+  'reset-password': 'warning',
+  import: 'success',
+  rollback: 'warning',
+  approve: 'success',
+  reject: 'danger',
+  cancel: 'info',
+  'reset-token': 'warning',
+  'test-notification': 'info'
 }
@@ -0,0 +1,185 @@
+/**
+ * HTTP task enum definitions
+ */
+
+export const HTTP_METHODS = [
+  { value: 1, label: 'GET' },
+  { value: 2, label: 'POST' },
+  { value: 3, label: 'PUT' },
+  { value: 4, label: 'PATCH' },
+  { value: 5, label: 'DELETE' },
+  { value: 6, label: 'HEAD' }
+] as const
+
+// '' keeps the legacy behaviour: POST sends a non-empty body as JSON, otherwise the URL query as form data
+export const HTTP_BODY_TYPES = [
+  { value: '', labelKey: 'task.httpBodyTypeAuto' },
+  { value: 'json', labelKey: 'task.httpBodyTypeJson' },
+  { value: 'form', labelKey: 'task.httpBodyTypeForm' },
+  { value: 'multipart', labelKey: 'task.httpBodyTypeMultipart' },
+  { value: 'raw', labelKey: 'task.httpBodyTypeRaw' }
+] as const
+
+/** GET and HEAD requests never carry a body */
+export function httpMethodHasBody(method: number): boolean {
+  return method !== 1 && method !== 6
+}
+
+export function httpMethodName(method: number): string {
+  return HTTP_METHODS.find((m) => m.value === method)?.label ?? 'GET'
+}
+
+// Response assertion operators; exists / not_exists take no value
+export const HTTP_ASSERT_OPS = [
+  '==',
+  '!=',
+  '>',
+  '>=',
+  '<',
+  '<=',
+  'contains',
+  'not_contains',
+  'matches',
+  'exists',
+  'not_exists'
+] as const
+
+export interface HttpAssertion {
+  source: 'json' | 'header'
+  target: string
+  op: string
+  value: string
+}
+
+export function parseHttpAssertions(raw?: string): HttpAssertion[] {
+  if (!raw) return []
+  try {
+    const list = JSON.parse(raw)
+    return Array.isArray(list) ? list : []
+  } catch {
+    return []
+  }
+}
+
+// Credentials are referenced by secret name; the values live in the secret store
+export const HTTP_AUTH_TYPES = [
+  { value: '', labelKey: 'task.httpAuthNone' },
+  { value: 'basic', labelKey: 'task.httpAuthBasic' },
+  { value: 'bearer', labelKey: 'task.httpAuthBearer' },
+  { value: 'oauth2', labelKey: 'task.httpAuthOAuth2' }
+] as const
+
+export interface HttpAuthConfig {
+  username: string
+  password_secret: string
+  token_secret: string
+  token_url: string
+  client_id: string
+  client_secret: string
+  scope: string
+}
+
+export function emptyHttpAuth(): HttpAuthConfig {
+  return {
+    username: '',
+    password_secret: '',
+    token_secret: '',
+    token_url: '',
+    client_id: '',
+    client_secret: '',
+    scope: ''
+  }
+}
+
+export function parseHttpAuth(raw?: string): HttpAuthConfig {
+  const config = emptyHttpAuth()
+  if (!raw) return config
+  try {
+    return { ...config, ...JSON.parse(raw) }
+  } catch {
+    return config
+  }
+}
+
+/** Keeps only the fields used by the auth type */
+export function httpAuthJson(type: string, config: HttpAuthConfig): string {
+  switch (type) {
+    case 'basic':
+      return JSON.stringify({ username: config.username, password_secret: config.password_secret })
+    case 'bearer':
+      return JSON.stringify({ token_secret: config.token_secret })
+    case 'oauth2':
+      return JSON.stringify({
+        token_url: config.token_url,
+        client_id: config.client_id,
+        client_secret: config.client_secret,
+        ...(config.scope ? { scope: config.scope } : {})
+      })
+    default:
+      return ''
+  }
+}
+
+// Async jobs: poll a status URL or wait for a callback after the trigger request
+export const HTTP_ASYNC_MODES = [
+  { value: '', labelKey: 'task.httpAsyncNone' },
+  { value: 'poll', labelKey: 'task.httpAsyncPoll' },
+  { value: 'callback', labelKey: 'task.httpAsyncCallback' }
+] as const
+
+/** Extract is edited as rows and stored as an object of name => JSONPath or header:Name */
+export interface HttpAsyncConfig {
+  extract: { name: string; source: string }[]
+  status_url: string
+  interval: number
+  timeout: number
+  success: HttpAssertion[]
+  failure: HttpAssertion[]
+}
+
+export function emptyHttpAsync(): HttpAsyncConfig {
+  return { extract: [], status_url: '', interval: 10, timeout: 3600, success: [], failure: [] }
+}
+
+export function parseHttpAsync(raw?: string): HttpAsyncConfig {
+  const config = emptyHttpAsync()
+  if (!raw) return config
+  try {
+    const data = JSON.parse(raw)
+    return {
+      extract: Object.entries(data.extract || {}).map(([name, source]) => ({
+        name,
+        source: String(source)
+      })),
+      status_url: data.status_url || '',
+      interval: data.interval || config.interval,
+      timeout: data.timeout || config.timeout,
+      success: Array.isArray(data.success) ? data.success : [],
+      failure: Array.isArray(data.failure) ? data.failure : []
+    }
+  } catch {
+    return config
+  }
+}
+
+/** Keeps only the fields used by the mode; rows without a name or target are dropped */
+export function httpAsyncJson(mode: string, config: HttpAsyncConfig): string {
+  if (!mode) return ''
+  const conditions = (list: HttpAssertion[]) =>
+    list
+      .filter((a) => a.target)
+      .map((a) => ({ ...a, value: a.op === 'exists' || a.op === 'not_exists' ? '' : a.value }))
+  const data: Record<string, unknown> = {
+    timeout: config.timeout,
+    success: conditions(config.success),
+    failure: conditions(config.failure)
+  }
+  if (mode === 'poll') {
+    data.extract = Object.fromEntries(
+      config.extract.filter((e) => e.name && e.source).map((e) => [e.name, e.source])
+    )
+    data.status_url = config.status_url
+    data.interval = config.interval
+  }
+  return JSON.stringify(data)
+}
@@ -0,0 +1,29 @@
+// Row-count assertions of SQL tasks; every assertion must hold for the run to succeed
+export const SQL_ASSERT_TARGETS = [
+  { value: 'rows', labelKey: 'task.sqlAssertRows' },
+  { value: 'affected', labelKey: 'task.sqlAssertAffected' }
+] as const
+
+export const SQL_ASSERT_OPS = ['==', '!=', '>', '>=', '<', '<='] as const
+
+export interface SqlAssertion {
+  target: 'rows' | 'affected'
+  op: string
+  value: number
+}
+
+export function parseSqlAssertions(raw?: string): SqlAssertion[] {
+  if (!raw) return []
+  try {
+    const list = JSON.parse(raw)
+    return Array.isArray(list) ? list : []
+  } catch {
+    return []
+  }
+}
+
+export function sqlAssertionsJson(list: SqlAssertion[]) {
+  return list.length
+    ? JSON.stringify(list.map((a) => ({ ...a, value: Number(a.value) || 0 })))
+    : ''
+}
@@ -21,6 +21,17 @@ export const systemRoutes: AppRouteRecord = {
         roles: ['R_SUPER', 'R_ADMIN']
       }
     },
+    {
+      path: 'notification-delivery',
+      name: 'NotificationDelivery',
+      component: '/system/notification-delivery/index',
+      meta: {
+        title: 'menus.system.notificationDelivery',
+        icon: 'ri:mail-send-line',
+        keepAlive: true,
+        roles: ['R_SUPER', 'R_ADMIN']
+      }
+    },
     {
       path: 'login-log',
       name: 'LoginLog',
@@ -65,6 +76,50 @@ export const systemRoutes: AppRouteRecord = {
         roles: ['R_SUPER', 'R_ADMIN']
       }
     },
+    {
+      path: 'secret',
+      name: 'Secret',
+      component: '/system/secret/index',
+      meta: {
+        title: 'menus.system.secret',
+        icon: 'ri:lock-password-line',
+        keepAlive: true,
+        roles: ['R_SUPER', 'R_ADMIN']
+      }
+    },
+    {
+      path: 'http-profile',
+      name: 'HttpProfile',
+      component: '/system/http-profile/index',
+      meta: {
+        title: 'menus.system.httpProfile',
+        icon: 'ri:shield-keyhole-line',
+        keepAlive: true,
+        roles: ['R_SUPER', 'R_ADMIN']
+      }
+    },
+    {
+      path: 'data-source',
+      name: 'DataSource',
+      component: '/system/data-source/index',
+      meta: {
+        title: 'menus.system.dataSource',
+        icon: 'ri:database-2-line',
+        keepAlive: true,
+        roles: ['R_SUPER', 'R_ADMIN']
+      }
+    },
+    {
+      path: 'grpc-descriptor',
+      name: 'GrpcDescriptor',
+      component: '/system/grpc-descriptor/index',
+      meta: {
+        title: 'menus.system.grpcDescriptor',
+        icon: 'ri:file-code-line',
+        keepAlive: true,
+        roles: ['R_SUPER', 'R_ADMIN']
+      }
+    },
     {
       path: 'ai-config',
       name: 'AiConfig',
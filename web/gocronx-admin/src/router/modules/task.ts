@@ -65,6 +65,17 @@ export const taskRoutes: AppRouteRecord = {
         allowAbsolutePath: true
       }
     },
+    {
+      path: '/task/change-requests',
+      name: 'TaskChangeRequests',
+      component: '/task/change-requests',
+      meta: {
+        title: 'menus.task.changeRequests',
+        icon: 'ri:shield-check-line',
+        keepAlive: false,
+        allowAbsolutePath: true
+      }
+    },
     // ── hidden child routes for create/edit flows ────────────────────────────
     {
       path: '/task/create',